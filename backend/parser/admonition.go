@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// admonitionKinds are the callout styles chapters can use. aside is meant for
+// presenter remarks that should stand apart from the story text.
+var admonitionKinds = map[string]string{
+	"note":      "Note",
+	"tip":       "Tip",
+	"hint":      "Hint",
+	"info":      "Info",
+	"important": "Important",
+	"warning":   "Warning",
+	"caution":   "Caution",
+	"danger":    "Danger",
+	"aside":     "Aside",
+}
+
+var (
+	// admonitionFencePattern matches the ":::warning Optional title" opener.
+	admonitionFencePattern = regexp.MustCompile(`^:::[ \t]*([A-Za-z]+)(?:[ \t]+(.*?))?[ \t]*\n?$`)
+	// admonitionClosePattern matches the ::: that ends a fenced callout.
+	admonitionClosePattern = regexp.MustCompile(`^:::[ \t]*\n?$`)
+	// alertPattern matches the "[!NOTE] Optional title" first line of a callout blockquote.
+	alertPattern = regexp.MustCompile(`^\[!([A-Za-z]+)\](?:[ \t]+(.*?))?[ \t]*\n?$`)
+)
+
+// KindAdmonition is the node kind of callout blocks.
+var KindAdmonition = ast.NewNodeKind("Admonition")
+
+// Admonition is a callout block, such as a hint or a warning.
+type Admonition struct {
+	ast.BaseBlock
+
+	AdmonitionKind string // one of admonitionKinds
+	Title          string
+}
+
+// Kind implements ast.Node.
+func (n *Admonition) Kind() ast.NodeKind {
+	return KindAdmonition
+}
+
+// Dump implements ast.Node.
+func (n *Admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.AdmonitionKind, "Title": n.Title}, nil)
+}
+
+// newAdmonition returns a callout of a known kind, or nil for anything else.
+// A missing title defaults to the name of the kind.
+func newAdmonition(kind, title string) *Admonition {
+	kind = strings.ToLower(kind)
+
+	name, ok := admonitionKinds[kind]
+	if !ok {
+		return nil
+	}
+
+	if title == "" {
+		title = name
+	}
+
+	return &Admonition{AdmonitionKind: kind, Title: title}
+}
+
+// admonitionParser parses callouts fenced by ::: lines:
+//
+//	:::warning Mind the gap
+//	The bridge is out.
+//	:::
+type admonitionParser struct{}
+
+func (admonitionParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (admonitionParser) Open(_ ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	if pc.BlockOffset() < 0 {
+		return nil, parser.NoChildren
+	}
+
+	match := admonitionFencePattern.FindSubmatch(line[pc.BlockOffset():])
+	if match == nil {
+		return nil, parser.NoChildren
+	}
+
+	node := newAdmonition(string(match[1]), string(match[2]))
+	if node == nil {
+		return nil, parser.NoChildren
+	}
+
+	reader.Advance(segment.Len() - trailingNewline(line))
+
+	return node, parser.HasChildren
+}
+
+func (admonitionParser) Continue(_ ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if admonitionClosePattern.Match(bytes.TrimLeft(line, " \t")) {
+		reader.Advance(segment.Len() - trailingNewline(line))
+
+		return parser.Close
+	}
+
+	return parser.Continue | parser.HasChildren
+}
+
+func (admonitionParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+func (admonitionParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (admonitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// trailingNewline returns 1 when the line ends in a newline.
+func trailingNewline(line []byte) int {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		return 1
+	}
+
+	return 0
+}
+
+// alertTransformer turns GitHub style alert blockquotes into callouts:
+//
+//	> [!NOTE]
+//	> Useful information.
+type alertTransformer struct{}
+
+func (alertTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+
+	var quotes []*ast.Blockquote
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if quote, ok := node.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, quote)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		paragraph, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || paragraph.Lines().Len() == 0 {
+			continue
+		}
+
+		first := paragraph.Lines().At(0)
+
+		match := alertPattern.FindSubmatch(first.Value(source))
+		if match == nil {
+			continue
+		}
+
+		node := newAdmonition(string(match[1]), string(match[2]))
+		if node == nil {
+			continue
+		}
+
+		dropFirstLine(paragraph, first, source)
+
+		if !paragraph.HasChildren() {
+			quote.RemoveChild(quote, paragraph)
+		}
+
+		for child := quote.FirstChild(); child != nil; child = quote.FirstChild() {
+			node.AppendChild(node, child)
+		}
+
+		quote.Parent().ReplaceChild(quote.Parent(), quote, node)
+	}
+}
+
+// dropFirstLine removes the inline nodes of a paragraph's first line.
+func dropFirstLine(paragraph *ast.Paragraph, first text.Segment, source []byte) {
+	end := first.Start + len(util.TrimRightSpace(first.Value(source)))
+
+	for child := paragraph.FirstChild(); child != nil; child = paragraph.FirstChild() {
+		paragraph.RemoveChild(paragraph, child)
+
+		if t, ok := child.(*ast.Text); ok && t.Segment.Stop >= end {
+			return
+		}
+	}
+}
+
+// admonitionRenderer renders callouts as
+// <div class="admonition admonition-warning"> with a title paragraph.
+type admonitionRenderer struct{}
+
+func (r admonitionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindAdmonition, r.renderAdmonition)
+}
+
+func (admonitionRenderer) renderAdmonition(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Admonition)
+
+	_, _ = w.WriteString(`<div class="admonition admonition-` + n.AdmonitionKind + `">` + "\n")
+	_, _ = w.WriteString(`<p class="admonition-title">`)
+	_, _ = w.Write(util.EscapeHTML([]byte(n.Title)))
+	_, _ = w.WriteString("</p>\n")
+
+	return ast.WalkContinue, nil
+}
+
+// admonitions is the goldmark extension for ::: fenced and > [!NOTE] callouts.
+type admonitions struct{}
+
+func (admonitions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(admonitionParser{}, 100)),
+		parser.WithASTTransformers(util.Prioritized(alertTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(admonitionRenderer{}, 500)),
+	)
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdmonitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "alert",
+			markdown: "> [!NOTE]\n> Useful *information*.",
+			want:     []string{`<div class="admonition admonition-note">`, `<p class="admonition-title">Note</p>`, "<p>Useful <em>information</em>.</p>"},
+			notWant:  []string{"<blockquote>", "[!NOTE]"},
+		},
+		{
+			name:     "alert with title",
+			markdown: "> [!warning] Mind <the> gap\n>\n> The bridge is out.",
+			want:     []string{`<div class="admonition admonition-warning">`, `<p class="admonition-title">Mind &lt;the&gt; gap</p>`, "<p>The bridge is out.</p>"},
+		},
+		{
+			name:     "unknown alert stays a blockquote",
+			markdown: "> [!BOGUS]\n> text",
+			want:     []string{"<blockquote>", "[!BOGUS]"},
+			notWant:  []string{"admonition"},
+		},
+		{
+			name:     "fenced",
+			markdown: "Before\n:::danger Boss fight\nThe **dragon** wakes.\n\n- run\n:::\nAfter",
+			want:     []string{"<p>Before</p>", `<div class="admonition admonition-danger">`, `<p class="admonition-title">Boss fight</p>`, "<strong>dragon</strong>", "<li>run</li>\n</ul>\n</div>", "<p>After</p>"},
+			notWant:  []string{":::"},
+		},
+		{
+			name:     "unknown fence stays text",
+			markdown: ":::nope\nbody\n:::",
+			want:     []string{":::nope"},
+			notWant:  []string{"admonition"},
+		},
+		{
+			name:     "fence inside code",
+			markdown: "```\n:::note\n```",
+			want:     []string{"<pre><code>:::note"},
+			notWant:  []string{"admonition"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := convertMarkdown(markdownRenderer, []byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("convertMarkdown() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("convertMarkdown() = %s, want it to contain %q", content, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(content, notWant) {
+					t.Errorf("convertMarkdown() = %s, must not contain %q", content, notWant)
+				}
+			}
+		})
+	}
+}
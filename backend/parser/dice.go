@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultDice is rolled when a roll chapter does not name its dice.
+const defaultDice = "1d20"
+
+// Dice is a dice expression such as 2d6: Count dice with Sides faces each.
+type Dice struct {
+	Count int
+	Sides int
+}
+
+var dicePattern = regexp.MustCompile(`^(\d*)d(\d+)$`)
+
+// ParseDice parses dice notation. The count may be omitted ("d20").
+func ParseDice(s string) (Dice, error) {
+	if s == "" {
+		s = defaultDice
+	}
+
+	match := dicePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if match == nil {
+		return Dice{}, fmt.Errorf("invalid dice '%s', expected something like 2d6", s)
+	}
+
+	d := Dice{Count: 1}
+
+	if match[1] != "" {
+		d.Count, _ = strconv.Atoi(match[1])
+	}
+
+	d.Sides, _ = strconv.Atoi(match[2])
+
+	if d.Count < 1 || d.Count > 20 {
+		return Dice{}, fmt.Errorf("invalid dice '%s': roll between 1 and 20 dice", s)
+	}
+
+	if d.Sides < 2 || d.Sides > 100 {
+		return Dice{}, fmt.Errorf("invalid dice '%s': dice need between 2 and 100 sides", s)
+	}
+
+	return d, nil
+}
+
+// String returns the dice in NdS notation.
+func (d Dice) String() string {
+	return fmt.Sprintf("%dd%d", d.Count, d.Sides)
+}
+
+// Roll rolls every die with a PCG generator seeded with (seed, 0).
+func (d Dice) Roll(seed uint64) []int {
+	rng := rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // rolls must be reproducible from the seed
+
+	faces := make([]int, d.Count)
+	for i := range faces {
+		faces[i] = 1 + rng.IntN(d.Sides)
+	}
+
+	return faces
+}
+
+// DiceRoll is the auditable result of a roll chapter: rolling the same
+// chapter with the same seed and modifier yields the same result.
+type DiceRoll struct {
+	Seed      uint64 `json:"seed"`
+	Dice      string `json:"dice"`
+	Faces     []int  `json:"faces"`
+	Modifier  int    `json:"modifier"`
+	Total     int    `json:"total"` // sum of the faces plus the modifier
+	Threshold int    `json:"threshold"`
+	Success   bool   `json:"success"` // Total reached Threshold
+	Next      string `json:"next"`
+}
+
+// IsRoll reports whether the chapter branches on a dice roll.
+func (m ChapterMetadata) IsRoll() bool {
+	return m.Type == "roll"
+}
+
+// rollModifier resolves the modifier against the story state. It is either a
+// whole number or the name of a numeric story variable; anything else adds 0.
+func (m ChapterMetadata) rollModifier(state State) int {
+	if m.Modifier == "" {
+		return 0
+	}
+
+	if n, err := strconv.Atoi(m.Modifier); err == nil {
+		return n
+	}
+
+	f, _ := toFloat(state.Lookup(m.Modifier))
+
+	return int(f)
+}
+
+// RollDice rolls the chapter's dice and picks the success or failure branch.
+func (m ChapterMetadata) RollDice(state State, seed uint64) (DiceRoll, error) {
+	if !m.IsRoll() {
+		return DiceRoll{}, fmt.Errorf("chapter %s is not a roll chapter", m.ID)
+	}
+
+	dice, err := ParseDice(m.Dice)
+	if err != nil {
+		return DiceRoll{}, err
+	}
+
+	roll := DiceRoll{
+		Seed:      seed,
+		Dice:      dice.String(),
+		Faces:     dice.Roll(seed),
+		Modifier:  m.rollModifier(state),
+		Threshold: m.Threshold,
+	}
+
+	roll.Total = roll.Modifier
+	for _, face := range roll.Faces {
+		roll.Total += face
+	}
+
+	roll.Success = roll.Total >= roll.Threshold
+
+	roll.Next = m.Failure
+	if roll.Success {
+		roll.Next = m.Success
+	}
+
+	return roll, nil
+}
+
+// validateRoll checks a roll chapter's dice and that both branches are set.
+func (m ChapterMetadata) validateRoll() error {
+	if _, err := ParseDice(m.Dice); err != nil {
+		return err
+	}
+
+	if m.Success == "" || m.Failure == "" {
+		return errors.New("roll chapters need both a success and a failure chapter")
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDice(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Dice
+		wantErr bool
+	}{
+		{"2d6", Dice{Count: 2, Sides: 6}, false},
+		{"d20", Dice{Count: 1, Sides: 20}, false},
+		{"", Dice{Count: 1, Sides: 20}, false},
+		{" 3D8 ", Dice{Count: 3, Sides: 8}, false},
+		{"0d6", Dice{}, true},
+		{"2d1", Dice{}, true},
+		{"two dice", Dice{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDice(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDice(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseDice(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollDice(t *testing.T) {
+	meta := ChapterMetadata{
+		ID:        "bridge",
+		Type:      "roll",
+		Dice:      "2d6",
+		Threshold: 8,
+		Modifier:  "luck",
+		Success:   "other-side",
+		Failure:   "river",
+	}
+
+	for seed := range uint64(200) {
+		roll, err := meta.RollDice(State{"luck": 2}, seed)
+		if err != nil {
+			t.Fatalf("RollDice() error = %v", err)
+		}
+
+		if len(roll.Faces) != 2 || roll.Modifier != 2 || roll.Dice != "2d6" {
+			t.Fatalf("roll = %+v, want two faces and a modifier of 2", roll)
+		}
+
+		sum := roll.Modifier
+		for _, face := range roll.Faces {
+			if face < 1 || face > 6 {
+				t.Fatalf("face %d out of range", face)
+			}
+
+			sum += face
+		}
+
+		if roll.Total != sum || roll.Success != (sum >= 8) {
+			t.Fatalf("roll = %+v, total and success do not match the faces", roll)
+		}
+
+		if want := map[bool]string{true: "other-side", false: "river"}[roll.Success]; roll.Next != want {
+			t.Fatalf("next = %q, want %q", roll.Next, want)
+		}
+
+		again, _ := meta.RollDice(State{"luck": 2}, seed)
+		if !reflect.DeepEqual(again, roll) {
+			t.Fatalf("same seed gave %+v and %+v", roll, again)
+		}
+	}
+}
+
+func TestRollModifier(t *testing.T) {
+	state := State{"luck": 3, "crew": map[string]any{"skill": 1.9}, "name": "Sam"}
+
+	tests := map[string]int{
+		"":           0,
+		"2":          2,
+		"-1":         -1,
+		"luck":       3,
+		"crew.skill": 1,
+		"name":       0,
+		"missing":    0,
+	}
+
+	for modifier, want := range tests {
+		if got := (ChapterMetadata{Modifier: modifier}).rollModifier(state); got != want {
+			t.Errorf("rollModifier(%q) = %d, want %d", modifier, got, want)
+		}
+	}
+}
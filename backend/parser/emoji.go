@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/yuin/goldmark-emoji/definition"
+)
+
+// shortcodePattern matches emoji shortcodes such as :rocket:.
+var shortcodePattern = regexp.MustCompile(`:([A-Za-z0-9_+-]+):`)
+
+// emojis returns the GitHub emoji set with the configured shortcodes taking
+// precedence.
+func (o renderOptions) emojis() definition.Emojis {
+	custom := make([]definition.Emoji, 0, len(o.emoji))
+	for _, name := range slices.Sorted(maps.Keys(o.emoji)) {
+		custom = append(custom, definition.NewEmoji(name, []rune(o.emoji[name]), name))
+	}
+
+	emojis := definition.NewEmojis(custom...)
+	emojis.Add(definition.Github())
+
+	return emojis
+}
+
+// validateEmoji reports shortcodes that chapters could not use.
+func (o renderOptions) validateEmoji() error {
+	for name, value := range o.emoji {
+		if !shortcodePattern.MatchString(":" + name + ":") {
+			return fmt.Errorf("invalid emoji shortcode %q: use letters, digits, _, + and -", name)
+		}
+
+		if value == "" {
+			return fmt.Errorf("emoji shortcode %q has no replacement", name)
+		}
+	}
+
+	return nil
+}
+
+// expandEmoji replaces known shortcodes in plain text. Unknown ones are kept
+// as written.
+func (r *chapterRenderer) expandEmoji(text string) string {
+	return shortcodePattern.ReplaceAllStringFunc(text, func(code string) string {
+		if emoji, ok := r.emoji.Get(code[1 : len(code)-1]); ok && emoji.IsUnicode() {
+			return string(emoji.Unicode)
+		}
+
+		return code
+	})
+}
+
+// expandMetadataEmoji replaces shortcodes in the frontmatter text voters see:
+// the question, the certificate headline and the choices.
+func (r *chapterRenderer) expandMetadataEmoji(meta *ChapterMetadata) {
+	meta.Question = r.expandEmoji(meta.Question)
+	meta.Certificate = r.expandEmoji(meta.Certificate)
+
+	for i := range meta.Choices {
+		meta.Choices[i].Label = r.expandEmoji(meta.Choices[i].Label)
+		meta.Choices[i].Description = r.expandEmoji(meta.Choices[i].Description)
+	}
+
+	for i := range meta.Outcomes {
+		meta.Outcomes[i].Label = r.expandEmoji(meta.Outcomes[i].Label)
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmojiShortcodes(t *testing.T) {
+	dir := t.TempDir()
+
+	index := filepath.Join(dir, "story.yaml")
+	if err := os.WriteFile(index, []byte("start: launch\nemoji:\n  k8s: \"☸️\"\n  fire: \"🐉\""), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	chapter := `---
+id: launch
+type: decision
+question: "Ship it? :rocket:"
+choices:
+  - id: yes
+    label: "Deploy to :k8s:"
+    description: "Ready at 10:30:00, :unknown: stays"
+    next: launch
+---
+# Launch :rocket:
+
+The :fire: wakes up.`
+	if err := os.WriteFile(filepath.Join(dir, "launch.md"), []byte(chapter), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	engine, err := NewStoryEngine(index, dir)
+	if err != nil {
+		t.Fatalf("NewStoryEngine() error = %v", err)
+	}
+
+	launch, err := engine.GetChapter("launch")
+	if err != nil {
+		t.Fatalf("GetChapter() error = %v", err)
+	}
+
+	for _, want := range []string{"Launch 🚀</h1>", "The 🐉 wakes up."} {
+		if !strings.Contains(launch.Content, want) {
+			t.Errorf("Content = %q, want it to contain %q", launch.Content, want)
+		}
+	}
+
+	meta := launch.Metadata
+	if meta.Question != "Ship it? 🚀" {
+		t.Errorf("Question = %q, want the shortcode expanded", meta.Question)
+	}
+
+	if meta.Choices[0].Label != "Deploy to ☸️" {
+		t.Errorf("Label = %q, want the story's own shortcode expanded", meta.Choices[0].Label)
+	}
+
+	if meta.Choices[0].Description != "Ready at 10:30:00, :unknown: stays" {
+		t.Errorf("Description = %q, want text without known shortcodes kept", meta.Choices[0].Description)
+	}
+
+	if !strings.Contains(launch.RawMD, "# Launch :rocket:") {
+		t.Errorf("RawMD = %q, want the markdown as written", launch.RawMD)
+	}
+
+	if err := os.WriteFile(index, []byte("start: launch\nemoji:\n  \"bad code\": x"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	if _, err := NewStoryEngine(index, dir); err == nil || !strings.Contains(err.Error(), "bad code") {
+		t.Errorf("NewStoryEngine(invalid shortcode) error = %v, want it to name the shortcode", err)
+	}
+}
@@ -0,0 +1,204 @@
+package parser
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed export.html
+var exportPage string
+
+var exportTemplate = template.Must(template.New("export").Funcs(template.FuncMap{"page": pageFile}).Parse(exportPage))
+
+// exportLink is a way on from an exported chapter.
+type exportLink struct {
+	To          string
+	Label       string
+	Description string
+	Missing     bool // no chapter has the target
+}
+
+type exportChapter struct {
+	Content  template.HTML
+	Question string
+	Links    []exportLink
+	Ending   bool
+}
+
+type exportData struct {
+	Story   string // the story's title
+	Title   string // the chapter's title
+	Start   string
+	Chapter *exportChapter // nil on the index page
+	Acts    []OutlineAct
+}
+
+// Export writes the story to dir as static HTML for reading at one's own
+// pace after the talk: an index page with the chapters in outline order and
+// a page per chapter, linking to where each choice, outcome or next leads.
+// There is no voting; readers pick for themselves. Chapters are the HTML
+// the engine rendered, templates with an empty story state, so an engine
+// made WithAssetURL("assets/") finds its images in the bundle. The media of
+// the content directory are copied to dir/assets, over the files of assets,
+// such as the frontend's stylesheet.
+func (se *StoryEngine) Export(dir, title string, assets fs.FS) error {
+	chapters, err := se.AllChapters()
+	if err != nil {
+		return fmt.Errorf("failed to load chapters: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	start := se.Story.Flow.Start
+	outline := BuildOutline(start, chapters)
+
+	index := exportData{Story: title, Start: start}
+
+	for _, act := range outline.Acts {
+		reachable := OutlineAct{Name: act.Name}
+
+		for _, chapter := range act.Chapters {
+			if chapter.Depth < 0 {
+				continue
+			}
+
+			reachable.Chapters = append(reachable.Chapters, chapter)
+
+			page := exportData{
+				Story:   title,
+				Title:   chapter.Title,
+				Start:   start,
+				Chapter: exportPageChapter(chapters[chapter.ID], chapters),
+			}
+
+			if page.Title == "" {
+				page.Title = chapter.ID
+			}
+
+			if err := writePage(filepath.Join(dir, pageFile(chapter.ID)), page); err != nil {
+				return err
+			}
+		}
+
+		if len(reachable.Chapters) > 0 {
+			index.Acts = append(index.Acts, reachable)
+		}
+	}
+
+	if err := writePage(filepath.Join(dir, "index.html"), index); err != nil {
+		return err
+	}
+
+	if assets != nil {
+		if err := copyFiles(filepath.Join(dir, "assets"), assets, func(string) bool { return true }); err != nil {
+			return fmt.Errorf("failed to copy assets: %w", err)
+		}
+	}
+
+	// the files the server serves under /assets/
+	isMedia := func(name string) bool { return mediaExtensions[strings.ToLower(path.Ext(name))] }
+
+	if err := copyFiles(filepath.Join(dir, "assets"), os.DirFS(se.ContentDir), isMedia); err != nil {
+		return fmt.Errorf("failed to copy media: %w", err)
+	}
+
+	return nil
+}
+
+// exportPageChapter is what the page of a chapter shows.
+func exportPageChapter(chapter *Chapter, chapters map[string]*Chapter) *exportChapter {
+	meta := chapter.Metadata
+	out := &exportChapter{
+		Content:  template.HTML(chapter.Content), //nolint:gosec // rendered by the engine, as the live view shows it
+		Question: meta.Question,
+		Ending:   meta.IsEnding(),
+	}
+
+	if meta.Type == "decision" {
+		for _, choice := range meta.Choices {
+			label := choice.Label
+			if label == "" {
+				label = choice.ID
+			}
+
+			if choice.Icon != "" {
+				label = choice.Icon + " " + label
+			}
+
+			_, found := chapters[choice.Next]
+			out.Links = append(out.Links, exportLink{To: choice.Next, Label: label, Description: choice.Description, Missing: !found})
+		}
+
+		return out
+	}
+
+	for _, edge := range graphEdges(meta.ID, meta) {
+		label := edge.Label
+		if label == "" {
+			label = "Continue"
+		}
+
+		_, found := chapters[edge.To]
+		out.Links = append(out.Links, exportLink{To: edge.To, Label: label, Missing: !found})
+	}
+
+	return out
+}
+
+// pageFile is the file name of a chapter's page. Characters that don't
+// belong in file names or links become underscores.
+func pageFile(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, id) + ".html"
+}
+
+func writePage(name string, data exportData) error {
+	file, err := os.Create(filepath.Clean(name))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	if err := exportTemplate.Execute(file, data); err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return file.Close()
+}
+
+// copyFiles copies the files of fsys that keep accepts to dir, replacing
+// files of an earlier export.
+func copyFiles(dir string, fsys fs.FS, keep func(name string) bool) error {
+	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !keep(name) {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, content, 0o644) //nolint:gosec // published files
+	})
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"story.yaml": "start: intro",
+		"intro.md":   "---\nid: intro\ntype: story\nnext: fork\n---\n# Intro\n\n![map](images/map.png)",
+		"fork.md": `---
+id: fork
+type: decision
+question: Which way?
+choices:
+  - id: left
+    label: Go left
+    description: Into the dark
+    next: end
+  - id: right
+    next: nowhere
+---
+# Fork`,
+		"end.md":         "---\nid: end\ntype: terminal\n---\n# The end of {{.LastWinnerLabel}}",
+		"attic.md":       "---\nid: attic\ntype: story\nnext: end\n---\n# Attic",
+		"images/map.png": "png",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir, WithAssetURL("assets/"))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "site")
+	assets := fstest.MapFS{"pixel.css": {Data: []byte("css")}}
+
+	// exporting again replaces the earlier export
+	for range 2 {
+		if err := engine.Export(out, "Heist <1>", assets); err != nil {
+			t.Fatalf("Export() failed: %v", err)
+		}
+	}
+
+	read := func(name string) string {
+		t.Helper()
+
+		content, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("export has no %s: %v", name, err)
+		}
+
+		return string(content)
+	}
+
+	tests := map[string][]string{
+		"index.html": {
+			"<title>Heist &lt;1&gt;</title>",
+			`<a class="start pixel-btn" href="intro.html">`,
+			`<a href="fork.html">Fork</a> <span class="type">decision</span>`,
+		},
+		"intro.html": {
+			"<title>Intro - Heist &lt;1&gt;</title>",
+			`<img src="assets/images/map.png" alt="map" />`,
+			`href="fork.html"><span class="pixel-text">Continue</span>`,
+		},
+		"fork.html": {
+			`<h2 class="question pixel-heading">Which way?</h2>`,
+			`href="end.html"><span class="pixel-text">Go left</span><span class="pixel-text-sm">Into the dark</span>`,
+			`<span class="pixel-text">right</span><span class="pixel-text-sm">This chapter is missing.</span>`,
+		},
+		"end.html": {
+			"The end of</h1>",
+			`<a class="pixel-btn" href="intro.html">Play again</a>`,
+		},
+		"assets/pixel.css":      {"css"},
+		"assets/images/map.png": {"png"},
+	}
+
+	for name, wants := range tests {
+		got := read(name)
+
+		for _, want := range wants {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, got)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "attic.html")); err == nil {
+		t.Error("exported a chapter the start can't reach")
+	}
+
+	if got := pageFile("act 1/intro"); got != "act_1_intro.html" {
+		t.Errorf("pageFile() = %q, want act_1_intro.html", got)
+	}
+}
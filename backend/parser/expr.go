@@ -0,0 +1,428 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// State holds story variables that conditions are evaluated against. Dotted
+// identifiers such as flags.found_key look up nested maps.
+type State map[string]any
+
+// Lookup resolves a dotted path; missing keys resolve to nil.
+func (s State) Lookup(path string) any {
+	var current any = map[string]any(s)
+
+	for part := range strings.SplitSeq(path, ".") {
+		switch m := current.(type) {
+		case map[string]any:
+			current = m[part]
+		case State:
+			current = m[part]
+		default:
+			return nil
+		}
+	}
+
+	return current
+}
+
+// Expr is a compiled condition expression.
+type Expr interface {
+	Eval(state State) (any, error)
+}
+
+// CompileExpr parses a condition expression. The grammar is deliberately small:
+// literals (numbers, 'strings', true, false), dotted identifiers, parentheses,
+// !, &&, ||, ==, !=, <, <=, > and >=.
+func CompileExpr(src string) (Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression %q", p.tokens[p.pos].text, src)
+	}
+
+	return expr, nil
+}
+
+// EvalCondition compiles and evaluates an expression for its truthiness.
+func EvalCondition(src string, state State) (bool, error) {
+	expr, err := CompileExpr(src)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := expr.Eval(state)
+	if err != nil {
+		return false, err
+	}
+
+	return truthy(v), nil
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == '-') {
+				i++
+			}
+
+			tokens = append(tokens, token{tokIdent, string(runes[start:i])})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+
+			tokens = append(tokens, token{tokNumber, string(runes[start:i])})
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression %q", src)
+			}
+
+			tokens = append(tokens, token{tokString, string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			op := ""
+
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q in expression %q", r, src)
+			}
+
+			tokens = append(tokens, token{tokOp, op})
+			i += len([]rune(op))
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peekOp(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokOp {
+		return "", false
+	}
+
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *exprParser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.peekOp("||"); !ok {
+			return left, nil
+		}
+
+		p.pos++
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{op: "||", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseAnd() (Expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.peekOp("&&"); !ok {
+			return left, nil
+		}
+
+		p.pos++
+
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseComparison() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.peekOp("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+
+	p.pos++
+
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return binaryExpr{op: op, left: left, right: right}, nil
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	if _, ok := p.peekOp("!"); ok {
+		p.pos++
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (Expr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of expression")
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+
+		return literalExpr{value: f}, nil
+	case tokString:
+		return literalExpr{value: tok.text}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literalExpr{value: true}, nil
+		case "false":
+			return literalExpr{value: false}, nil
+		}
+
+		return identExpr{path: tok.text}, nil
+	case tokOp:
+		if tok.text != "(" {
+			return nil, fmt.Errorf("unexpected %q", tok.text)
+		}
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := p.peekOp(")"); !ok {
+			return nil, errors.New("missing closing parenthesis")
+		}
+
+		p.pos++
+
+		return inner, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+type literalExpr struct{ value any }
+
+func (e literalExpr) Eval(State) (any, error) { return e.value, nil }
+
+type identExpr struct{ path string }
+
+func (e identExpr) Eval(state State) (any, error) { return state.Lookup(e.path), nil }
+
+type notExpr struct{ operand Expr }
+
+func (e notExpr) Eval(state State) (any, error) {
+	v, err := e.operand.Eval(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return !truthy(v), nil
+}
+
+type binaryExpr struct {
+	op          string
+	left, right Expr
+}
+
+func (e binaryExpr) Eval(state State) (any, error) {
+	l, err := e.left.Eval(state)
+	if err != nil {
+		return nil, err
+	}
+
+	// short-circuit the logical operators
+	switch e.op {
+	case "&&":
+		if !truthy(l) {
+			return false, nil
+		}
+	case "||":
+		if truthy(l) {
+			return true, nil
+		}
+	}
+
+	r, err := e.right.Eval(state)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "&&", "||":
+		return truthy(r), nil
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	}
+
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+
+	if !lok || !rok {
+		// missing variables compare as false rather than failing the story
+		return false, nil
+	}
+
+	switch e.op {
+	case "<":
+		return lf < rf, nil
+	case "<=":
+		return lf <= rf, nil
+	case ">":
+		return lf > rf, nil
+	case ">=":
+		return lf >= rf, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %q", e.op)
+}
+
+func truthy(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	}
+
+	if f, ok := toFloat(v); ok {
+		return f != 0
+	}
+
+	return true
+}
+
+func equal(a, b any) bool {
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+
+	if aok && bok {
+		return af == bf
+	}
+
+	switch a.(type) {
+	case string, bool, nil:
+		return a == b
+	}
+
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestEvalCondition(t *testing.T) {
+	state := State{
+		"score": 42,
+		"name":  "gopher",
+		"flags": map[string]any{
+			"found_key": true,
+			"lost_map":  false,
+		},
+	}
+
+	tests := []struct {
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{expr: "flags.found_key", want: true},
+		{expr: "flags.lost_map", want: false},
+		{expr: "!flags.lost_map", want: true},
+		{expr: "flags.missing", want: false},
+		{expr: "score > 40", want: true},
+		{expr: "score >= 43", want: false},
+		{expr: "score == 42 && name == 'gopher'", want: true},
+		{expr: "score < 10 || flags.found_key", want: true},
+		{expr: "!(score < 10 || flags.lost_map)", want: true},
+		{expr: `name != "gopher"`, want: false},
+		{expr: "missing > 3", want: false},
+		{expr: "score > -1", want: true},
+		{expr: "true", want: true},
+		{expr: "", wantErr: true},
+		{expr: "score >", wantErr: true},
+		{expr: "(score > 1", wantErr: true},
+		{expr: "score $ 1", wantErr: true},
+		{expr: "name == 'open", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := EvalCondition(tt.expr, state)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvalCondition(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("EvalCondition(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateLookup(t *testing.T) {
+	state := State{"a": State{"b": map[string]any{"c": 1}}}
+
+	if got := state.Lookup("a.b.c"); got != 1 {
+		t.Errorf("Lookup(a.b.c) = %v, want 1", got)
+	}
+
+	if got := state.Lookup("a.b.c.d"); got != nil {
+		t.Errorf("Lookup(a.b.c.d) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,313 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StoryError is a validation problem located in a chapter file.
+type StoryError struct {
+	File string // chapter file relative to the content directory
+	Line int    // line in the file, 0 when unknown
+	Err  error
+}
+
+func (e *StoryError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+func (e *StoryError) Unwrap() error {
+	return e.Err
+}
+
+// Edge is a way out of a chapter.
+type Edge struct {
+	To  string `json:"to"`
+	Via string `json:"via"` // what leads there, such as "choice 'a'", for messages
+}
+
+// Edges lists every chapter the chapter can lead to.
+func (m ChapterMetadata) Edges() []Edge {
+	return edges(m)
+}
+
+// edges lists every chapter a chapter can lead to.
+func edges(meta ChapterMetadata) []Edge {
+	var out []Edge
+
+	if meta.Next != "" {
+		out = append(out, Edge{To: meta.Next, Via: "next"})
+	}
+
+	for _, condition := range meta.Conditions {
+		out = append(out, Edge{To: condition.Next, Via: fmt.Sprintf("condition %q", condition.If)})
+	}
+
+	for _, choice := range meta.Choices {
+		out = append(out, Edge{To: choice.Next, Via: fmt.Sprintf("choice '%s'", choice.ID)})
+	}
+
+	if meta.IsRandom() {
+		for _, outcome := range meta.Outcomes {
+			out = append(out, Edge{To: outcome.Next, Via: fmt.Sprintf("outcome '%s'", outcome.ID)})
+		}
+	}
+
+	if meta.IsRoll() {
+		for _, next := range []string{meta.Success, meta.Failure} {
+			if next != "" {
+				out = append(out, Edge{To: next, Via: "roll"})
+			}
+		}
+	}
+
+	return out
+}
+
+// chapterLines records where a chapter's frontmatter names things.
+type chapterLines struct {
+	id      int            // line of the chapter ID
+	targets map[string]int // chapter ID -> first line naming it as a target
+}
+
+// readChapterLines locates the chapter ID and targets in a chapter file.
+// Anything it cannot find is reported on line 0.
+func readChapterLines(path string) chapterLines {
+	lines := chapterLines{targets: map[string]int{}}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return lines
+	}
+
+	frontmatter, _, err := splitFrontmatter(content)
+	if err != nil || len(frontmatter) == 0 {
+		return lines
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(frontmatter, &root); err != nil {
+		return lines
+	}
+
+	// the frontmatter starts below the opening ---
+	const offset = 1
+
+	target := func(id string, line int) {
+		if _, ok := lines.targets[id]; !ok {
+			lines.targets[id] = line + offset
+		}
+	}
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		for i := 0; node.Kind == yaml.MappingNode && i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				continue
+			}
+
+			switch key.Value {
+			case "id":
+				if lines.id == 0 {
+					lines.id = value.Line + offset
+				}
+			case "next", "success", "failure":
+				if match := inlineConditionPattern.FindStringSubmatch(value.Value); match != nil {
+					target(match[1], value.Line)
+					target(match[3], value.Line)
+				} else {
+					target(strings.TrimSpace(value.Value), value.Line)
+				}
+			}
+		}
+
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(&root)
+
+	return lines
+}
+
+// validateGraph checks how chapters connect: targets that do not exist,
+// chapters the start cannot reach, chapters that are not endings but lead
+// nowhere, and loops that no path leaves.
+func (se *StoryEngine) validateGraph(chapters map[string]*Chapter) []error {
+	var errs []error
+
+	ids := make([]string, 0, len(chapters))
+	for id := range chapters {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	lines := make(map[string]chapterLines, len(ids))
+	located := func(id string, line int, err error) error {
+		return &StoryError{File: se.Story.Nodes[id].File, Line: line, Err: err}
+	}
+
+	graph := make(map[string][]string, len(ids))
+
+	for _, id := range ids {
+		lines[id] = readChapterLines(filepath.Join(se.ContentDir, se.Story.Nodes[id].File))
+		meta := chapters[id].Metadata
+
+		out := edges(meta)
+		for _, edge := range out {
+			if _, ok := chapters[edge.To]; ok {
+				graph[id] = append(graph[id], edge.To)
+
+				continue
+			}
+
+			// chapters that failed to parse are reported on their own
+			if _, ok := se.Story.Nodes[edge.To]; ok {
+				continue
+			}
+
+			err := fmt.Errorf("%s in node '%s' points to unknown node '%s'", edge.Via, id, edge.To)
+			if edge.To == "" {
+				err = fmt.Errorf("%s in node '%s' has no next", edge.Via, id)
+			}
+
+			errs = append(errs, located(id, lines[id].targets[edge.To], err))
+		}
+
+		if len(out) == 0 && !meta.IsEnding() {
+			err := fmt.Errorf("node '%s' is a dead end: it is not an ending and has no next, choices or outcomes", id)
+			errs = append(errs, located(id, lines[id].id, err))
+		}
+	}
+
+	reachable := map[string]bool{}
+
+	queue := []string{se.Story.Flow.Start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if reachable[id] {
+			continue
+		}
+
+		reachable[id] = true
+		queue = append(queue, graph[id]...)
+	}
+
+	for _, id := range ids {
+		if !reachable[id] {
+			err := fmt.Errorf("node '%s' is unreachable from start node '%s'", id, se.Story.Flow.Start)
+			errs = append(errs, located(id, lines[id].id, err))
+		}
+	}
+
+	for _, loop := range closedLoops(ids, graph, chapters) {
+		err := fmt.Errorf("nodes %s form a loop with no way out", strings.Join(loop, " -> "))
+		errs = append(errs, located(loop[0], lines[loop[0]].id, err))
+	}
+
+	return errs
+}
+
+// closedLoops finds the cycles of the graph that no edge leaves and that hold
+// no ending, so a story entering them can never finish. Each loop is returned
+// as its sorted node IDs.
+func closedLoops(ids []string, graph map[string][]string, chapters map[string]*Chapter) [][]string {
+	// Tarjan's strongly connected components
+	var (
+		index   = map[string]int{}
+		low     = map[string]int{}
+		onStack = map[string]bool{}
+		stack   []string
+		loops   [][]string
+		counter int
+	)
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = counter
+		low[id] = counter
+		counter++
+
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range graph[id] {
+			if _, seen := index[next]; !seen {
+				visit(next)
+				low[id] = min(low[id], low[next])
+			} else if onStack[next] {
+				low[id] = min(low[id], index[next])
+			}
+		}
+
+		if low[id] != index[id] {
+			return
+		}
+
+		var component []string
+
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+
+			component = append(component, top)
+
+			if top == id {
+				break
+			}
+		}
+
+		if isClosedLoop(component, graph, chapters) {
+			slices.Sort(component)
+			loops = append(loops, component)
+		}
+	}
+
+	for _, id := range ids {
+		if _, seen := index[id]; !seen {
+			visit(id)
+		}
+	}
+
+	slices.SortFunc(loops, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+
+	return loops
+}
+
+// isClosedLoop reports whether a strongly connected component is a cycle
+// without an ending and without an edge leading out of it.
+func isClosedLoop(component []string, graph map[string][]string, chapters map[string]*Chapter) bool {
+	if len(component) == 1 && !slices.Contains(graph[component[0]], component[0]) {
+		return false
+	}
+
+	for _, id := range component {
+		if chapters[id].Metadata.IsEnding() {
+			return false
+		}
+
+		for _, next := range graph[id] {
+			if !slices.Contains(component, next) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateStory_Graph(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"story.yaml": "start: intro",
+		"intro.md":   "---\nid: intro\ntype: story\nnext: fork\n---\n# Intro",
+		"fork.md": `---
+id: fork
+type: decision
+choices:
+  - id: left
+    next: hall
+  - id: right
+    next: nowhere
+---
+# Fork`,
+		// hall and mirror chase each other forever
+		"hall.md":   "---\nid: hall\ntype: story\nnext: mirror\n---\n# Hall",
+		"mirror.md": "---\nid: mirror\ntype: story\nnext: hall\n---\n# Mirror",
+		// the garden loop can be left through the gate
+		"garden.md": "---\nid: garden\ntype: story\nnext: gate\n---\n# Garden",
+		"gate.md":   "---\nid: gate\ntype: story\nnext: garden if lost else end\n---\n# Gate",
+		"end.md":    "---\nid: end\ntype: terminal\n---\n# The end",
+		"attic.md":  "---\ntype: story\nid: attic\n---\n# Nobody comes here",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	var got []string
+
+	for _, err := range engine.ValidateStory() {
+		var storyErr *StoryError
+		if !errors.As(err, &storyErr) {
+			t.Errorf("error %v has no file context", err)
+
+			continue
+		}
+
+		got = append(got, err.Error())
+	}
+
+	want := []string{
+		"attic.md:3: node 'attic' is a dead end: it is not an ending and has no next, choices or outcomes",
+		"fork.md:8: choice 'right' in node 'fork' points to unknown node 'nowhere'",
+		"attic.md:3: node 'attic' is unreachable from start node 'intro'",
+		"end.md:2: node 'end' is unreachable from start node 'intro'",
+		"garden.md:2: node 'garden' is unreachable from start node 'intro'",
+		"gate.md:2: node 'gate' is unreachable from start node 'intro'",
+		"hall.md:2: nodes hall -> mirror form a loop with no way out",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("ValidateStory() =\n%q\nwant\n%q", got, want)
+	}
+}
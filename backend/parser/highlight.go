@@ -0,0 +1,32 @@
+package parser
+
+import (
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+)
+
+// DefaultCodeTheme is the chroma style fenced code blocks are highlighted
+// with unless WithCodeTheme picks another.
+const DefaultCodeTheme = "github"
+
+// WithCodeTheme highlights fenced code blocks with the named chroma style,
+// such as "monokai" or "dracula".
+func WithCodeTheme(name string) EngineOption {
+	return func(o *renderOptions) {
+		o.codeTheme = name
+	}
+}
+
+// codeHighlighting colours fenced code blocks by their language at parse time,
+// so clients receive ready to show HTML. Colours are inlined, the pages need
+// no stylesheet for them.
+func codeHighlighting(theme string) goldmark.Extender {
+	return highlighting.NewHighlighting(
+		highlighting.WithStyle(theme),
+		highlighting.WithFormatOptions(
+			chromahtml.WithClasses(false),
+			chromahtml.TabWidth(2),
+		),
+	)
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCodeHighlighting(t *testing.T) {
+	dir := t.TempDir()
+
+	index := filepath.Join(dir, "story.yaml")
+	if err := os.WriteFile(index, []byte("start: deploy"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	chapter := "---\nid: deploy\ntype: terminal\n---\n```yaml\nkind: Pod\n```\n\n```\nplain text\n```"
+	if err := os.WriteFile(filepath.Join(dir, "deploy.md"), []byte(chapter), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	themed := func(opts ...EngineOption) string {
+		t.Helper()
+
+		engine, err := NewStoryEngine(index, dir, opts...)
+		if err != nil {
+			t.Fatalf("NewStoryEngine() error = %v", err)
+		}
+
+		chapter, err := engine.GetChapter("deploy")
+		if err != nil {
+			t.Fatalf("GetChapter() error = %v", err)
+		}
+
+		return chapter.Content
+	}
+
+	github := themed()
+	if !strings.Contains(github, "<span style=") || !strings.Contains(github, "kind</span>") {
+		t.Errorf("default theme content = %s, want highlighted YAML", github)
+	}
+
+	if !strings.Contains(github, "plain text") {
+		t.Errorf("default theme content = %s, want the block without a language kept", github)
+	}
+
+	if monokai := themed(WithCodeTheme("monokai")); monokai == github || !strings.Contains(monokai, "background-color:#272822") {
+		t.Errorf("monokai content = %s, want the monokai background", monokai)
+	}
+
+	if _, err := NewStoryEngine(index, dir, WithCodeTheme("no-such-theme")); err == nil || !strings.Contains(err.Error(), "no-such-theme") {
+		t.Errorf("NewStoryEngine(unknown theme) error = %v, want it to name the theme", err)
+	}
+}
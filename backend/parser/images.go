@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// WithAssetURL points relative image references, such as
+// ![map](images/map.png), at prefix followed by the path, for example
+// /adventure/assets/images/map.png when the server is mounted under
+// /adventure behind a proxy. The default is DefaultAssetURL; an empty prefix
+// keeps references as written.
+func WithAssetURL(prefix string) EngineOption {
+	return func(o *renderOptions) {
+		o.assetURL = prefix
+	}
+}
+
+// WithInlineImages embeds images of the content directory up to maxBytes in
+// the chapter HTML as data URIs, saving a request per image. Zero disables
+// inlining.
+func WithInlineImages(maxBytes int64) EngineOption {
+	return func(o *renderOptions) {
+		o.inlineImages = maxBytes
+	}
+}
+
+// chapterImages is the goldmark extension resolving relative image
+// references, which are relative to the content directory.
+type chapterImages renderOptions
+
+func (c chapterImages) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(c, 200)))
+}
+
+func (c chapterImages) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if image, ok := node.(*ast.Image); ok && entering {
+			image.Destination = c.resolve(image.Destination)
+		}
+
+		return ast.WalkContinue, nil
+	})
+}
+
+// resolve maps a relative image reference to a data URI or the asset route.
+// Absolute paths, URLs and references leaving the content directory are kept.
+func (c chapterImages) resolve(destination []byte) []byte {
+	ref, err := url.Parse(string(destination))
+	if err != nil || ref.Scheme != "" || ref.Host != "" || ref.Path == "" || strings.HasPrefix(ref.Path, "/") {
+		return destination
+	}
+
+	rel := path.Clean(ref.Path)
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return destination
+	}
+
+	if data, ok := c.inline(rel); ok {
+		return data
+	}
+
+	if c.assetURL == "" {
+		return destination
+	}
+
+	ref.Path, ref.RawPath = rel, ""
+
+	return []byte(c.assetURL + ref.String())
+}
+
+// inline returns the image at rel as a data URI when inlining is enabled and
+// the image is small enough.
+func (c chapterImages) inline(rel string) ([]byte, bool) {
+	if c.inlineImages == 0 || c.contentDir == "" {
+		return nil, false
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(path.Ext(rel)))
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, false
+	}
+
+	file := filepath.Join(c.contentDir, filepath.FromSlash(rel))
+
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() || info.Size() > c.inlineImages {
+		return nil, false
+	}
+
+	content, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, false
+	}
+
+	return []byte("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content)), true
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChapterImages(t *testing.T) {
+	dir := t.TempDir()
+
+	index := filepath.Join(dir, "story.yaml")
+	if err := os.WriteFile(index, []byte("start: map"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "images"), 0755); err != nil {
+		t.Fatalf("failed to create images dir: %v", err)
+	}
+
+	small := []byte("\x89PNG tiny")
+	if err := os.WriteFile(filepath.Join(dir, "images", "icon.png"), small, 0600); err != nil {
+		t.Fatalf("failed to write icon: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "images", "map.png"), make([]byte, 4096), 0600); err != nil {
+		t.Fatalf("failed to write map: %v", err)
+	}
+
+	chapter := `---
+id: map
+type: terminal
+---
+![Icon](images/icon.png)
+![Map](./images/map.png "The map")
+![Remote](https://example.com/a.png)
+![Absolute](/media/b.png)
+![Outside](../secret.png)
+`
+	if err := os.WriteFile(filepath.Join(dir, "map.md"), []byte(chapter), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	content := func(opts ...EngineOption) string {
+		t.Helper()
+
+		engine, err := NewStoryEngine(index, dir, opts...)
+		if err != nil {
+			t.Fatalf("NewStoryEngine() error = %v", err)
+		}
+
+		chapter, err := engine.GetChapter("map")
+		if err != nil {
+			t.Fatalf("GetChapter() error = %v", err)
+		}
+
+		return chapter.Content
+	}
+
+	tests := []struct {
+		name    string
+		opts    []EngineOption
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				`src="/assets/images/icon.png"`,
+				`src="/assets/images/map.png" alt="Map" title="The map"`,
+				`src="https://example.com/a.png"`,
+				`src="/media/b.png"`,
+				`src="../secret.png"`,
+			},
+		},
+		{
+			name: "behind a proxy",
+			opts: []EngineOption{WithAssetURL("/adventure/assets/")},
+			want: []string{`src="/adventure/assets/images/icon.png"`, `src="/adventure/assets/images/map.png"`},
+		},
+		{
+			name: "kept as written",
+			opts: []EngineOption{WithAssetURL("")},
+			want: []string{`src="images/icon.png"`, `src="./images/map.png"`},
+		},
+		{
+			name:    "inlined",
+			opts:    []EngineOption{WithInlineImages(1024)},
+			want:    []string{`src="data:image/png;base64,` + base64.StdEncoding.EncodeToString(small) + `"`, `src="/assets/images/map.png"`},
+			notWant: []string{`src="/assets/images/icon.png"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := content(tt.opts...)
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Content = %s, want it to contain %s", got, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Content = %s, must not contain %s", got, notWant)
+				}
+			}
+		})
+	}
+}
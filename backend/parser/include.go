@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// includePattern matches the {{include "path/to/partial.md"}} directive.
+var includePattern = regexp.MustCompile(`\{\{\s*include\s+"([^"]+)"\s*\}\}`)
+
+// expandIncludes replaces include directives in a chapter's markdown with the
+// partials they name, then appends the partials listed in the includes
+// frontmatter. Paths are relative to dir, the content directory; file is the
+// chapter itself, so a partial including it again is reported as a cycle.
+func expandIncludes(markdown []byte, includes []string, dir, file string) ([]byte, error) {
+	for _, name := range includes {
+		markdown = fmt.Appendf(markdown, "\n\n{{include %q}}\n", name)
+	}
+
+	return resolveIncludes(markdown, dir, []string{file})
+}
+
+// resolveIncludes expands every directive in markdown. stack holds the files
+// being expanded, outermost first.
+func resolveIncludes(markdown []byte, dir string, stack []string) ([]byte, error) {
+	var firstErr error
+
+	out := includePattern.ReplaceAllFunc(markdown, func(directive []byte) []byte {
+		if firstErr != nil {
+			return directive
+		}
+
+		name := string(includePattern.FindSubmatch(directive)[1])
+
+		partial, err := readPartial(dir, name, stack)
+		if err != nil {
+			firstErr = err
+
+			return directive
+		}
+
+		return partial
+	})
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return out, nil
+}
+
+// readPartial loads and expands one partial. Frontmatter in partials is ignored.
+func readPartial(dir, name string, stack []string) ([]byte, error) {
+	path := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(path) {
+		return nil, fmt.Errorf("include %q: path must stay inside the content directory", name)
+	}
+
+	if slices.Contains(stack, path) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, path), " -> "))
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return nil, fmt.Errorf("include %q in %s: %w", name, stack[len(stack)-1], err)
+	}
+
+	_, body, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", name, err)
+	}
+
+	return resolveIncludes(body, dir, append(slices.Clone(stack), path))
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestParseMarkdownFile_Includes(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"intro.md": `---
+id: intro
+type: story
+includes: [common/footer.md]
+---
+# Welcome
+
+{{include "common/rules.md"}}
+`,
+		"common/rules.md":  "## Rules\n\n{{ include \"common/safety.md\" }}\n",
+		"common/safety.md": "---\ntitle: ignored\n---\nStay safe.\n",
+		"common/footer.md": "Thanks for playing!\n",
+	})
+
+	chapter, err := ParseMarkdownFile(filepath.Join(dir, "intro.md"))
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() error = %v", err)
+	}
+
+	for _, want := range []string{"Rules</h2>", "Stay safe.", "Thanks for playing!"} {
+		if !strings.Contains(chapter.Content, want) {
+			t.Errorf("content = %s, want it to contain %q", chapter.Content, want)
+		}
+	}
+
+	if strings.Contains(chapter.Content, "title: ignored") {
+		t.Error("frontmatter of partials must not be rendered")
+	}
+
+	if !strings.Contains(chapter.RawMD, `{{include "common/rules.md"}}`) {
+		t.Error("RawMD must keep the include directive so the editor can save it back")
+	}
+}
+
+func TestParseMarkdownFile_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.md": "{{include \"b.md\"}}",
+				"b.md": "{{include \"c.md\"}}",
+				"c.md": "{{include \"b.md\"}}",
+			},
+			wantErr: "include cycle: a.md -> b.md -> c.md -> b.md",
+		},
+		{
+			name:    "self",
+			files:   map[string]string{"a.md": "{{include \"a.md\"}}"},
+			wantErr: "include cycle: a.md -> a.md",
+		},
+		{
+			name:    "missing",
+			files:   map[string]string{"a.md": "{{include \"common/nope.md\"}}"},
+			wantErr: `include "common/nope.md" in a.md`,
+		},
+		{
+			name:    "escape",
+			files:   map[string]string{"a.md": "{{include \"../secret.md\"}}"},
+			wantErr: "must stay inside the content directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			_, err := ParseMarkdownFile(filepath.Join(dir, "a.md"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseMarkdownFile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package parser
+
+// InventoryKey is the story variable that holds collected items as a map of
+// item name to count, so conditions can test for them, e.g. `inventory.rope`.
+const InventoryKey = "inventory"
+
+// Inventory returns the collected items and how many of each are held.
+func (s State) Inventory() map[string]int {
+	items := map[string]int{}
+
+	held, _ := s[InventoryKey].(map[string]any)
+	for name, count := range held {
+		if n, ok := toFloat(count); ok && n > 0 {
+			items[name] = int(n)
+		}
+	}
+
+	return items
+}
+
+// Grant adds one of each listed item to the inventory.
+func (s State) Grant(items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	held := s.inventory()
+	for _, item := range items {
+		n, _ := toFloat(held[item])
+		held[item] = int(n) + 1
+	}
+}
+
+// Consume removes one of each listed item. Items that are not held are ignored.
+func (s State) Consume(items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	held := s.inventory()
+	for _, item := range items {
+		n, _ := toFloat(held[item])
+		if n <= 1 {
+			delete(held, item)
+
+			continue
+		}
+
+		held[item] = int(n) - 1
+	}
+}
+
+// HasItems reports whether the inventory holds every listed item; an item
+// listed twice must be held twice.
+func (s State) HasItems(items []string) bool {
+	needed := map[string]int{}
+	for _, item := range items {
+		needed[item]++
+	}
+
+	held := s.Inventory()
+	for item, n := range needed {
+		if held[item] < n {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Enter applies everything a chapter changes when it is visited: its variable
+// assignments, then the items it grants and consumes.
+func (s State) Enter(meta ChapterMetadata) {
+	s.Apply(meta.Set)
+	s.Grant(meta.Grants)
+	s.Consume(meta.Consumes)
+}
+
+func (s State) inventory() map[string]any {
+	switch held := s[InventoryKey].(type) {
+	case map[string]any:
+		return held
+	case State:
+		return held
+	}
+
+	held := map[string]any{}
+	s[InventoryKey] = held
+
+	return held
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInventory(t *testing.T) {
+	state := State{}
+
+	state.Enter(ChapterMetadata{Grants: []string{"rope", "torch", "torch"}})
+
+	if want := map[string]int{"rope": 1, "torch": 2}; !reflect.DeepEqual(state.Inventory(), want) {
+		t.Errorf("Inventory() = %v, want %v", state.Inventory(), want)
+	}
+
+	if !state.HasItems([]string{"rope", "torch", "torch"}) {
+		t.Error("HasItems should count duplicates as separate items")
+	}
+
+	if state.HasItems([]string{"map"}) {
+		t.Error("HasItems(map) = true for an item never granted")
+	}
+
+	if ok, _ := EvalCondition("inventory.rope && inventory.torch >= 2", state); !ok {
+		t.Error("inventory should be visible to conditions")
+	}
+
+	state.Enter(ChapterMetadata{Consumes: []string{"rope", "torch", "map"}})
+
+	if want := map[string]int{"torch": 1}; !reflect.DeepEqual(state.Inventory(), want) {
+		t.Errorf("Inventory() after consume = %v, want %v", state.Inventory(), want)
+	}
+
+	if ok, _ := EvalCondition("inventory.rope", state); ok {
+		t.Error("a consumed item should no longer satisfy conditions")
+	}
+}
+
+func TestParseMarkdown_Inventory(t *testing.T) {
+	content := []byte(`---
+id: cliff
+type: decision
+grants: [torch]
+consumes: [rope]
+choices:
+  - id: climb
+    label: Climb down
+    next: cave
+    requires: [rope]
+    hide_locked: true
+---
+# Cliff`)
+
+	chapter, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	meta := chapter.Metadata
+	if !reflect.DeepEqual(meta.Grants, []string{"torch"}) || !reflect.DeepEqual(meta.Consumes, []string{"rope"}) {
+		t.Errorf("grants = %v, consumes = %v", meta.Grants, meta.Consumes)
+	}
+
+	choice := meta.Choices[0]
+	if !reflect.DeepEqual(choice.Requires, []string{"rope"}) || !choice.HideLocked {
+		t.Errorf("choice = %+v, want requires [rope] and hide_locked", choice)
+	}
+}
+
+func TestValidateStory_UngrantedItem(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapter, err := engine.GetChapter("choice1")
+	if err != nil {
+		t.Fatalf("failed to get chapter: %v", err)
+	}
+
+	chapter.Metadata.Choices[0].Requires = []string{"rope"}
+
+	errors := engine.ValidateStory()
+	if len(errors) != 1 || !strings.Contains(errors[0].Error(), "'rope'") {
+		t.Fatalf("expected 1 error about rope, got %v", errors)
+	}
+
+	intro, err := engine.GetChapter("intro")
+	if err != nil {
+		t.Fatalf("failed to get chapter: %v", err)
+	}
+
+	intro.Metadata.Grants = []string{"rope"}
+
+	if errors := engine.ValidateStory(); len(errors) != 0 {
+		t.Errorf("expected no errors once rope is granted, got %v", errors)
+	}
+}
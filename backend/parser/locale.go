@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// localizedFilePattern matches translated chapter files such as intro.de.md
+// or intro.pt-BR.md.
+var localizedFilePattern = regexp.MustCompile(`^(.+)\.([a-zA-Z]{2}(?:[-_][a-zA-Z]{2,4})?)\.md$`)
+
+// splitLocalizedFile returns the chapter file a translation belongs to and its
+// language, or ok false when name is not a translation.
+func splitLocalizedFile(name string) (base, lang string, ok bool) {
+	match := localizedFilePattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", "", false
+	}
+
+	return match[1] + ".md", NormalizeLang(match[2]), true
+}
+
+// NormalizeLang brings a language tag into the form translations are keyed
+// by: lower case with a dash, such as "pt-br".
+func NormalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// langFallbacks lists the languages to try for a requested one, most specific
+// first: "de-at" falls back to "de".
+func langFallbacks(lang string) []string {
+	lang = NormalizeLang(lang)
+	if lang == "" {
+		return nil
+	}
+
+	out := []string{lang}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		out = append(out, base)
+	}
+
+	return out
+}
+
+// translation finds the file of the chapter in the given language, following
+// the fallbacks of langFallbacks. It returns an empty file when the chapter
+// should be shown in the story's default language.
+func (se *StoryEngine) translation(nodeID, lang string) (file, found string) {
+	node := se.Story.Nodes[nodeID]
+
+	for _, candidate := range langFallbacks(lang) {
+		if candidate == se.Story.Language {
+			return "", ""
+		}
+
+		if file, ok := node.Translations[candidate]; ok {
+			return file, candidate
+		}
+	}
+
+	return "", ""
+}
+
+// GetLocalizedChapter retrieves a chapter in the given language. Translations
+// replace the content, question, certificate headline and choice labels and
+// descriptions; everything else, like where choices lead, always comes from
+// the chapter in the default language. Languages without a translation fall
+// back to the default language.
+func (se *StoryEngine) GetLocalizedChapter(nodeID, lang string) (*Chapter, error) {
+	chapter, err := se.GetChapter(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, lang := se.translation(nodeID, lang)
+	if file == "" {
+		return chapter, nil
+	}
+
+	key := nodeID + "." + lang
+	if localized, ok := se.cached(key); ok {
+		return localized, nil
+	}
+
+	translated, err := se.parseChapterFile(filepath.Join(se.ContentDir, file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s translation of chapter %s: %w", lang, nodeID, err)
+	}
+
+	localized := localize(chapter, translated)
+	localized.Lang = lang
+	se.cache(key, localized)
+
+	return localized, nil
+}
+
+// localize overlays the text of a translation on a chapter.
+func localize(chapter, translated *Chapter) *Chapter {
+	out := *chapter
+	out.Content = translated.Content
+	out.RawMD = translated.RawMD
+	out.template = translated.template
+
+	if translated.Notes != "" {
+		out.Notes = translated.Notes
+	}
+
+	out.Metadata.Choices = slices.Clone(chapter.Metadata.Choices)
+
+	if translated.Metadata.Question != "" {
+		out.Metadata.Question = translated.Metadata.Question
+	}
+
+	if translated.Metadata.Certificate != "" {
+		out.Metadata.Certificate = translated.Metadata.Certificate
+	}
+
+	for _, text := range translated.Metadata.Choices {
+		for i, choice := range out.Metadata.Choices {
+			if choice.ID != text.ID {
+				continue
+			}
+
+			if text.Label != "" {
+				out.Metadata.Choices[i].Label = text.Label
+			}
+
+			if text.Description != "" {
+				out.Metadata.Choices[i].Description = text.Description
+			}
+		}
+	}
+
+	return &out
+}
+
+// validateTranslations checks that every translation of a chapter parses and
+// only names choices the chapter has.
+func (se *StoryEngine) validateTranslations(nodeID string, chapter *Chapter) []error {
+	var errs []error
+
+	node := se.Story.Nodes[nodeID]
+
+	for _, lang := range slices.Sorted(maps.Keys(node.Translations)) {
+		translated, err := se.parseChapterFile(filepath.Join(se.ContentDir, node.Translations[lang]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %s translation of node '%s': %w", lang, nodeID, err))
+
+			continue
+		}
+
+		for _, text := range translated.Metadata.Choices {
+			if !slices.ContainsFunc(chapter.Metadata.Choices, func(choice Choice) bool { return choice.ID == text.ID }) {
+				errs = append(errs, fmt.Errorf("%s translation of node '%s' names unknown choice '%s'", lang, nodeID, text.ID))
+			}
+		}
+	}
+
+	return errs
+}
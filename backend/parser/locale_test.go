@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetLocalizedChapter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"story.yaml": "start: door\nlanguage: en",
+		"door.md": `---
+id: door
+type: decision
+question: Which door?
+choices:
+  - id: red
+    label: The red door
+    next: end
+  - id: blue
+    label: The blue door
+    description: It hums.
+    next: end
+---
+# Two doors`,
+		"door.de.md": `---
+question: Welche Tür?
+next: ignored
+choices:
+  - id: red
+    label: Die rote Tür
+---
+# Zwei Türen`,
+		"door.pt-BR.md": "# Duas portas",
+		"door.en.md":    "# Never used, English is the default",
+		"end.md":        "---\nid: end\ntype: terminal\n---\n# The end",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	if len(engine.Story.Nodes) != 2 {
+		t.Fatalf("got %d nodes, want translations not to be chapters", len(engine.Story.Nodes))
+	}
+
+	tests := []struct {
+		lang        string
+		wantLang    string
+		wantContent string
+		wantRed     string
+	}{
+		{"de", "de", "Zwei Türen", "Die rote Tür"},
+		{"de-AT", "de", "Zwei Türen", "Die rote Tür"},
+		{"pt_BR", "pt-br", "Duas portas", "The red door"},
+		{"en-US", "", "Two doors", "The red door"},
+		{"fr", "", "Two doors", "The red door"},
+		{"", "", "Two doors", "The red door"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			chapter, err := engine.GetLocalizedChapter("door", tt.lang)
+			if err != nil {
+				t.Fatalf("GetLocalizedChapter() error = %v", err)
+			}
+
+			if chapter.Lang != tt.wantLang || !strings.Contains(chapter.Content, tt.wantContent) {
+				t.Errorf("chapter in %q = %q (%s), want %q (%s)", tt.lang, chapter.Lang, chapter.Content, tt.wantLang, tt.wantContent)
+			}
+
+			choices := chapter.Metadata.Choices
+			if choices[0].Label != tt.wantRed || choices[1].Description != "It hums." {
+				t.Errorf("choices = %+v, want red labelled %q and the blue description kept", choices, tt.wantRed)
+			}
+
+			if chapter.Metadata.Next != "" || choices[0].Next != "end" {
+				t.Errorf("translation changed the story structure: %+v", chapter.Metadata)
+			}
+		})
+	}
+
+	german, _ := engine.GetLocalizedChapter("door", "de")
+	if german.Metadata.Question != "Welche Tür?" {
+		t.Errorf("question = %q, want the German one", german.Metadata.Question)
+	}
+
+	if english, _ := engine.GetChapter("door"); english.Metadata.Choices[0].Label != "The red door" {
+		t.Error("localizing must not change the chapter in the default language")
+	}
+
+	if errors := engine.ValidateStory(); len(errors) != 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "door.fr.md"), []byte("---\nchoices:\n  - id: green\n    label: Vert\n---\n"), 0600); err != nil {
+		t.Fatalf("failed to write translation: %v", err)
+	}
+
+	engine, err = NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	errors := engine.ValidateStory()
+	if len(errors) != 1 || !strings.Contains(errors[0].Error(), "unknown choice 'green'") {
+		t.Errorf("expected an error about the unknown choice, got %v", errors)
+	}
+}
@@ -6,10 +6,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"text/template"
 
+	"github.com/microcosm-cc/bluemonday"
 	"github.com/yuin/goldmark"
+	emoji "github.com/yuin/goldmark-emoji"
+	"github.com/yuin/goldmark-emoji/definition"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/renderer/html"
 	"gopkg.in/yaml.v3"
 )
@@ -17,12 +24,81 @@ import (
 // ChapterMetadata represents the YAML frontmatter in a markdown file.
 type ChapterMetadata struct {
 	ID       string   `yaml:"id"`
-	Type     string   `yaml:"type"` // story, decision, game-over, terminal
+	Type     string   `yaml:"type"` // story, decision, random, roll, game-over, terminal
 	Timer    int      `yaml:"timer,omitempty"`
 	Terminal bool     `yaml:"terminal,omitempty"`
 	Next     string   `yaml:"next,omitempty"`
 	Question string   `yaml:"question,omitempty"`
 	Choices  []Choice `yaml:"choices,omitempty"`
+
+	RequiresFeature string      `yaml:"requires_feature,omitempty"` // chapter is unreachable unless this feature is enabled
+	Conditions      []Condition `yaml:"conditions,omitempty"`       // evaluated in order before falling back to Next
+	Set             Assignments `yaml:"set,omitempty"`              // story variables updated when the chapter is visited
+	Grants          []string    `yaml:"grants,omitempty"`           // items added to the inventory when the chapter is visited
+	Consumes        []string    `yaml:"consumes,omitempty"`         // items removed from the inventory when the chapter is visited
+	Outcomes        []Outcome   `yaml:"outcomes,omitempty"`         // weighted next chapters of a random chapter
+	TimerMode       string      `yaml:"timer_mode,omitempty"`       // "adaptive" paces the vote by participation
+	TimerMin        int         `yaml:"timer_min,omitempty"`        // shortest adaptive vote in seconds
+	TimerMax        int         `yaml:"timer_max,omitempty"`        // longest adaptive vote in seconds
+	AllowChange     *bool       `yaml:"allow_change,omitempty"`     // false keeps voters to the first ballot they cast (default true)
+	Dice            string      `yaml:"dice,omitempty"`             // dice a roll chapter rolls, such as 2d6 (default 1d20)
+	Threshold       int         `yaml:"threshold,omitempty"`        // total a roll needs to succeed
+	Modifier        string      `yaml:"modifier,omitempty"`         // number or story variable added to a roll
+	Success         string      `yaml:"success,omitempty"`          // next chapter when a roll succeeds
+	Failure         string      `yaml:"failure,omitempty"`          // next chapter when a roll fails
+	Certificate     string      `yaml:"certificate,omitempty"`      // headline of voter certificates when the story ends here
+	Includes        []string    `yaml:"includes,omitempty"`         // partials appended to the chapter, relative to the content directory
+	Literal         bool        `yaml:"literal,omitempty"`          // show {{ }} as written instead of rendering the chapter as a template
+	Checkpoint      bool        `yaml:"checkpoint,omitempty"`       // the presenter can rewind to this chapter in one step
+	Tags            []string    `yaml:"tags,omitempty"`             // free-form labels for grouping chapters, such as act-1
+	Notes           string      `yaml:"notes,omitempty" json:"-"`   // speaker notes, served as Chapter.Notes to presenters only
+
+	// Extra holds custom fields for frontends, such as a background colour or
+	// a music track. The engine ignores them and passes them on as written.
+	Extra map[string]any `yaml:"extra,omitempty"`
+}
+
+// Condition routes to Next when the If expression holds for the story state.
+type Condition struct {
+	If   string `yaml:"if"`
+	Next string `yaml:"next"`
+}
+
+// inlineConditionPattern matches the shorthand `next: a if expr else b`.
+var inlineConditionPattern = regexp.MustCompile(`^\s*(\S+)\s+if\s+(.+?)\s+else\s+(\S+)\s*$`)
+
+// normalizeConditions rewrites the inline `next: a if expr else b` form into
+// a condition plus a plain fallback next.
+func (m *ChapterMetadata) normalizeConditions() {
+	match := inlineConditionPattern.FindStringSubmatch(m.Next)
+	if match == nil {
+		return
+	}
+
+	m.Conditions = append(m.Conditions, Condition{If: match[2], Next: match[1]})
+	m.Next = match[3]
+}
+
+// HasTags reports whether the chapter carries every one of the given tags.
+func (m ChapterMetadata) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(m.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsEnding reports whether the chapter concludes a run of the story.
+func (m ChapterMetadata) IsEnding() bool {
+	return m.Terminal || m.Type == "terminal" || m.Type == "game-over"
+}
+
+// ChangesAllowed reports whether voters may change their ballot on the
+// chapter's decision.
+func (m ChapterMetadata) ChangesAllowed() bool {
+	return m.AllowChange == nil || *m.AllowChange
 }
 
 // Choice represents a voting option.
@@ -33,13 +109,28 @@ type Choice struct {
 	Next        string `yaml:"next"`
 	Risk        string `yaml:"risk,omitempty"` // low, medium, high
 	Icon        string `yaml:"icon,omitempty"`
+	Preview     string `yaml:"preview,omitempty"` // image or clip path relative to the content directory
+	Correct     bool   `yaml:"correct,omitempty"` // marks the decision as a quiz with this as a right answer
+	Points      int    `yaml:"points,omitempty"`  // added to the session score when the choice wins
+
+	Threshold float64 `yaml:"threshold,omitempty"` // share of the votes, such as 0.66, the choice needs to win
+	Default   bool    `yaml:"default,omitempty"`   // wins instead when a choice with a threshold leads without reaching it
+
+	Requires   []string `yaml:"requires,omitempty"`    // items the inventory must hold for the choice to be available
+	HideLocked bool     `yaml:"hide_locked,omitempty"` // hide the choice instead of showing it disabled while locked
+	Locked     bool     `yaml:"-"`                     // set at serve time when requirements are not met
 }
 
 // Chapter represents a parsed chapter with metadata and content.
 type Chapter struct {
 	Metadata ChapterMetadata
-	Content  string
+	Content  string // HTML, rendered with empty TemplateData for templates
 	RawMD    string
+	Lang     string // language of a translation, empty for the story's default language
+	Notes    string // HTML speaker notes for presenters, never sent to voters
+
+	template *template.Template // set when the content uses story state, see Render
+	markdown *chapterRenderer   // renderer the content was converted with, reused by Render
 }
 
 // ParseMarkdownFile reads and parses a markdown file with YAML frontmatter.
@@ -49,11 +140,18 @@ func ParseMarkdownFile(filePath string) (*Chapter, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return ParseMarkdown(content)
+	return parseMarkdown(content, filepath.Dir(filePath), filepath.Base(filePath), markdownRenderer)
 }
 
-// ParseMarkdown parses markdown content with YAML frontmatter.
+// ParseMarkdown parses markdown content with YAML frontmatter. Include
+// directives are only expanded for files, see ParseMarkdownFile.
 func ParseMarkdown(content []byte) (*Chapter, error) {
+	return parseMarkdown(content, "", "", markdownRenderer)
+}
+
+// parseMarkdown parses a chapter, expanding includes relative to includeDir
+// unless it is empty, and converts it to HTML with md.
+func parseMarkdown(content []byte, includeDir, file string, md *chapterRenderer) (*Chapter, error) {
 	frontmatter, markdown, err := splitFrontmatter(content)
 	if err != nil {
 		return nil, err
@@ -67,32 +165,111 @@ func ParseMarkdown(content []byte) (*Chapter, error) {
 		}
 	}
 
+	metadata.normalizeConditions()
+	md.expandMetadataEmoji(&metadata)
+
+	body, notes := splitNotes(markdown)
+
+	rendered := body
+	if includeDir != "" {
+		if rendered, err = expandIncludes(body, metadata.Includes, includeDir, file); err != nil {
+			return nil, err
+		}
+	}
+
+	chapter := &Chapter{
+		Metadata: metadata,
+		RawMD:    string(markdown),
+		markdown: md,
+	}
+
+	if chapter.Notes, err = renderNotes(md, metadata.Notes, notes); err != nil {
+		return nil, err
+	}
+
+	if !metadata.Literal {
+		if chapter.template, err = parseTemplate(file, rendered); err != nil {
+			return nil, err
+		}
+	}
+
+	if chapter.template != nil {
+		// a template that needs state to execute shows its source until it is served
+		if content, err := chapter.Render(TemplateData{}); err == nil {
+			chapter.Content = content
+
+			return chapter, nil
+		}
+	}
+
+	if chapter.Content, err = convertMarkdown(md, rendered); err != nil {
+		return nil, err
+	}
+
+	return chapter, nil
+}
+
+// markdownRenderer converts the markdown of chapters parsed outside a story
+// engine to HTML.
+var markdownRenderer = newMarkdownRenderer(defaultRenderOptions())
+
+// chapterRenderer converts chapter markdown to HTML and expands emoji
+// shortcodes in frontmatter text. It is safe for concurrent use.
+type chapterRenderer struct {
+	markdown  goldmark.Markdown
+	emoji     definition.Emojis
+	sanitizer *bluemonday.Policy
+}
+
+// newMarkdownRenderer returns a chapter renderer configured by opts.
+func newMarkdownRenderer(opts renderOptions) *chapterRenderer {
+	emojis := opts.emojis()
+
+	rendererOptions := []renderer.Option{
+		html.WithHardWraps(),
+		html.WithXHTML(),
+	}
+
+	// raw HTML is only safe to keep when the sanitizer gets to clean it
+	if opts.sanitizer != nil {
+		rendererOptions = append(rendererOptions, html.WithUnsafe())
+	}
+
 	md := goldmark.New(
 		goldmark.WithExtensions(
 			extension.GFM,
 			extension.Table,
 			extension.Strikethrough,
 			extension.TaskList,
+			extension.Footnote,
+			extension.DefinitionList,
+			admonitions{},
+			codeHighlighting(opts.codeTheme),
+			chapterImages(opts),
+			emoji.New(emoji.WithEmojis(emojis), emoji.WithRenderingMethod(emoji.Unicode)),
 		),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithXHTML(),
-		),
+		goldmark.WithRendererOptions(rendererOptions...),
 	)
 
+	return &chapterRenderer{markdown: md, emoji: emojis, sanitizer: opts.sanitizer}
+}
+
+// convertMarkdown renders markdown as HTML with md, sanitizing the result when
+// md has a policy.
+func convertMarkdown(md *chapterRenderer, markdown []byte) (string, error) {
 	var buf bytes.Buffer
-	if err := md.Convert(markdown, &buf); err != nil {
-		return nil, fmt.Errorf("failed to convert markdown: %w", err)
+	if err := md.markdown.Convert(markdown, &buf); err != nil {
+		return "", fmt.Errorf("failed to convert markdown: %w", err)
 	}
 
-	return &Chapter{
-		Metadata: metadata,
-		Content:  buf.String(),
-		RawMD:    string(markdown),
-	}, nil
+	if md.sanitizer != nil {
+		return md.sanitizer.Sanitize(buf.String()), nil
+	}
+
+	return buf.String(), nil
 }
 
 // splitFrontmatter splits YAML frontmatter from markdown content
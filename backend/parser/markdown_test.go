@@ -277,13 +277,29 @@ func TestMarkdownFeatures(t *testing.T) {
 		{
 			name: "code block",
 			markdown: "```go\nfunc main() {}\n```",
-			contains: []string{"<code", "func main()"},
+			contains: []string{"<code", "func</span>", "main</span>() {}"},
 		},
 		{
 			name: "links",
 			markdown: "[Link text](https://example.com)",
 			contains: []string{"<a", "href", "example.com"},
 		},
+		{
+			name:     "footnotes",
+			markdown: "The scheduler gave up.[^oom]\n\n[^oom]: See the OOM killer docs.",
+			contains: []string{
+				`<a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a>`,
+				`<div class="footnotes" role="doc-endnotes">`,
+				`<li id="fn:1">`,
+				"See the OOM killer docs.",
+				`class="footnote-backref"`,
+			},
+		},
+		{
+			name:     "definition list",
+			markdown: "Pod\n: The smallest deployable unit.\n\nNode\n: A worker machine.",
+			contains: []string{"<dl>", "<dt>Pod</dt>", "<dd>The smallest deployable unit.</dd>", "<dt>Node</dt>", "</dl>"},
+		},
 	}
 
 	for _, tt := range tests {
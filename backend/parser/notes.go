@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// notesDelimiter is the line separating a chapter's text from the speaker
+// notes written below it.
+var notesDelimiter = regexp.MustCompile(`(?m)^[ \t]*<!--[ \t]*notes[ \t]*-->[ \t]*$`)
+
+// splitNotes splits the speaker notes below a <!-- notes --> line off a
+// chapter's markdown.
+func splitNotes(markdown []byte) (body, notes []byte) {
+	loc := notesDelimiter.FindIndex(markdown)
+	if loc == nil {
+		return markdown, nil
+	}
+
+	return markdown[:loc[0]], markdown[loc[1]:]
+}
+
+// renderNotes converts the notes frontmatter followed by the notes below the
+// delimiter to HTML. Chapters without notes yield an empty string.
+func renderNotes(md *chapterRenderer, frontmatter string, body []byte) (string, error) {
+	notes := bytes.TrimSpace(append([]byte(frontmatter+"\n\n"), body...))
+	if len(notes) == 0 {
+		return "", nil
+	}
+
+	return convertMarkdown(md, notes)
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSpeakerNotes(t *testing.T) {
+	tests := []struct {
+		name        string
+		markdown    string
+		wantContent string
+		wantNotes   []string
+		notContent  string
+	}{
+		{
+			name:        "delimiter",
+			markdown:    "---\nid: a\n---\n# The bridge\n\n<!-- notes -->\nPause for *effect*.",
+			wantContent: "The bridge</h1>",
+			wantNotes:   []string{"<p>Pause for <em>effect</em>.</p>"},
+			notContent:  "Pause",
+		},
+		{
+			name:        "frontmatter",
+			markdown:    "---\nid: a\nnotes: The troll is the CTO.\n---\n# The bridge",
+			wantContent: "The bridge</h1>",
+			wantNotes:   []string{"The troll is the CTO."},
+			notContent:  "troll",
+		},
+		{
+			name:        "both",
+			markdown:    "---\nid: a\nnotes: First.\n---\n# The bridge\n<!--notes-->\nSecond.",
+			wantContent: "The bridge</h1>",
+			wantNotes:   []string{"<p>First.</p>\n<p>Second.</p>"},
+			notContent:  "Second",
+		},
+		{
+			name:        "none",
+			markdown:    "---\nid: a\n---\n# The bridge\n<!-- a comment -->",
+			wantContent: "The bridge</h1>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chapter, err := ParseMarkdown([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("ParseMarkdown() error = %v", err)
+			}
+
+			if !strings.Contains(chapter.Content, tt.wantContent) {
+				t.Errorf("Content = %q, want it to contain %q", chapter.Content, tt.wantContent)
+			}
+
+			if tt.notContent != "" && strings.Contains(chapter.Content, tt.notContent) {
+				t.Errorf("Content = %q, must not contain the notes", chapter.Content)
+			}
+
+			if len(tt.wantNotes) == 0 && chapter.Notes != "" {
+				t.Errorf("Notes = %q, want none", chapter.Notes)
+			}
+
+			for _, want := range tt.wantNotes {
+				if !strings.Contains(chapter.Notes, want) {
+					t.Errorf("Notes = %q, want it to contain %q", chapter.Notes, want)
+				}
+			}
+
+			if !strings.Contains(chapter.RawMD, "# The bridge") {
+				t.Errorf("RawMD = %q, want the markdown as written", chapter.RawMD)
+			}
+
+			metadata, _ := json.Marshal(chapter.Metadata)
+			if strings.Contains(string(metadata), "troll") {
+				t.Errorf("metadata JSON = %s, must not carry the notes", metadata)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// DefaultAssetURL is where the server serves files of the content directory,
+// see WithAssetURL.
+const DefaultAssetURL = "/assets/"
+
+// EngineOption configures how a StoryEngine renders chapters.
+type EngineOption func(*renderOptions)
+
+// renderOptions are the settings a markdown renderer is built from.
+type renderOptions struct {
+	codeTheme    string
+	assetURL     string             // prefix for relative image references, empty keeps them as written
+	inlineImages int64              // images up to this many bytes become data URIs, 0 disables inlining
+	contentDir   string             // where relative image references are read from for inlining
+	emoji        map[string]string  // shortcodes added to, or replacing, the GitHub set
+	sanitizer    *bluemonday.Policy // cleans rendered HTML when set
+}
+
+// defaultRenderOptions are the settings of an engine without options.
+func defaultRenderOptions() renderOptions {
+	return renderOptions{codeTheme: DefaultCodeTheme, assetURL: DefaultAssetURL}
+}
+
+// validate reports settings the renderer cannot honour.
+func (o renderOptions) validate() error {
+	if _, ok := styles.Registry[o.codeTheme]; !ok {
+		return fmt.Errorf("unknown code theme %q, see https://xyproto.github.io/splash/docs/ for the available themes", o.codeTheme)
+	}
+
+	if o.inlineImages < 0 {
+		return fmt.Errorf("inline image size limit must not be negative: %d", o.inlineImages)
+	}
+
+	return o.validateEmoji()
+}
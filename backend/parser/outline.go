@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// headingPattern matches the first markdown heading of a chapter.
+var headingPattern = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+
+// Title returns the first heading of the chapter, or an empty string when it
+// has none.
+func (c *Chapter) Title() string {
+	if match := headingPattern.FindStringSubmatch(c.RawMD); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
+
+// actTagPrefix marks the tags that name the act a chapter belongs to, such as act-1.
+const actTagPrefix = "act-"
+
+// Outline is the table of contents of a story.
+type Outline struct {
+	Start string       `json:"start"`
+	Acts  []OutlineAct `json:"acts"`
+}
+
+// OutlineAct is a run of chapters sharing an act tag, in story order.
+type OutlineAct struct {
+	Name     string           `json:"name"` // the act tag, empty for chapters outside any act
+	Chapters []OutlineChapter `json:"chapters"`
+}
+
+// OutlineChapter is one entry of the outline.
+type OutlineChapter struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Title    string   `json:"title,omitempty"` // first heading of the chapter
+	Question string   `json:"question,omitempty"`
+	Depth    int      `json:"depth"` // steps from the start chapter, -1 when unreachable
+	Next     []string `json:"next,omitempty"`
+	Decision bool     `json:"decision"`
+	Ending   bool     `json:"ending"`
+
+	// Visited and Current are left to callers that track a run.
+	Visited bool `json:"visited"`
+	Current bool `json:"current"`
+}
+
+// BuildOutline orders chapters breadth first from the start chapter, so
+// chapters closer to the start come first, followed by unreachable chapters
+// sorted by ID. Chapters are grouped into acts by their act- tag; a chapter
+// without one belongs to the act of the chapter it was first reached from.
+// Edges to chapters missing from chapters are left out.
+func BuildOutline(start string, chapters map[string]*Chapter) Outline {
+	var (
+		order = make([]string, 0, len(chapters))
+		depth = map[string]int{}
+		act   = map[string]string{}
+	)
+
+	visit := func(id string, d int, inherited string) {
+		if _, seen := depth[id]; seen {
+			return
+		}
+
+		depth[id] = d
+		act[id] = inherited
+
+		if name := actTag(chapters[id].Metadata.Tags); name != "" {
+			act[id] = name
+		}
+
+		order = append(order, id)
+	}
+
+	if _, ok := chapters[start]; ok {
+		visit(start, 0, "")
+	}
+
+	for i := 0; i < len(order); i++ {
+		id := order[i]
+
+		for _, edge := range chapters[id].Metadata.Edges() {
+			if _, ok := chapters[edge.To]; ok {
+				visit(edge.To, depth[id]+1, act[id])
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(chapters)) {
+		visit(id, -1, "")
+	}
+
+	outline := Outline{Start: start, Acts: []OutlineAct{}}
+	acts := map[string]int{} // act name -> index in outline.Acts
+
+	for _, id := range order {
+		i, ok := acts[act[id]]
+		if !ok {
+			i = len(outline.Acts)
+			acts[act[id]] = i
+			outline.Acts = append(outline.Acts, OutlineAct{Name: act[id]})
+		}
+
+		outline.Acts[i].Chapters = append(outline.Acts[i].Chapters, outlineChapter(id, depth[id], chapters))
+	}
+
+	return outline
+}
+
+// outlineChapter summarises one chapter for the outline.
+func outlineChapter(id string, depth int, chapters map[string]*Chapter) OutlineChapter {
+	chapter := chapters[id]
+	meta := chapter.Metadata
+
+	entry := OutlineChapter{
+		ID:       id,
+		Type:     meta.Type,
+		Title:    chapter.Title(),
+		Question: meta.Question,
+		Depth:    depth,
+		Decision: meta.Type == "decision",
+		Ending:   meta.IsEnding(),
+	}
+
+	for _, edge := range meta.Edges() {
+		if _, ok := chapters[edge.To]; ok && !slices.Contains(entry.Next, edge.To) {
+			entry.Next = append(entry.Next, edge.To)
+		}
+	}
+
+	return entry
+}
+
+// actTag returns the first tag naming an act, such as act-1.
+func actTag(tags []string) string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, actTagPrefix) {
+			return tag
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuildOutline(t *testing.T) {
+	dir := t.TempDir()
+
+	index := filepath.Join(dir, "story.yaml")
+	if err := os.WriteFile(index, []byte("start: intro"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	chapters := map[string]string{
+		"intro.md":  "---\nid: intro\ntype: story\nnext: gate\n---\n# Welcome aboard\n",
+		"gate.md":   "---\nid: gate\ntype: decision\nquestion: Which door?\ntags: [act-1]\nchoices:\n  - id: left\n    label: Left\n    next: hall\n  - id: right\n    label: Right\n    next: pit\n---\n## The gate ##\n",
+		"hall.md":   "---\nid: hall\ntype: story\nnext: throne\n---\nNo heading here.\n",
+		"pit.md":    "---\nid: pit\ntype: game-over\n---\n# The pit\n",
+		"throne.md": "---\nid: throne\ntype: terminal\ntags: [act-2, finale]\n---\n# The throne\n",
+		"cut.md":    "---\nid: cut\ntype: terminal\n---\n# Deleted scene\n",
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(index, dir)
+	if err != nil {
+		t.Fatalf("NewStoryEngine() error = %v", err)
+	}
+
+	all, err := engine.AllChapters()
+	if err != nil {
+		t.Fatalf("AllChapters() error = %v", err)
+	}
+
+	outline := BuildOutline("intro", all)
+
+	if outline.Start != "intro" {
+		t.Errorf("Start = %s, want intro", outline.Start)
+	}
+
+	type act struct {
+		name     string
+		chapters []string
+	}
+
+	var got []act
+
+	for _, a := range outline.Acts {
+		ids := []string{}
+		for _, chapter := range a.Chapters {
+			ids = append(ids, chapter.ID)
+		}
+
+		got = append(got, act{a.Name, ids})
+	}
+
+	want := []act{
+		{"", []string{"intro", "cut"}},
+		{"act-1", []string{"gate", "hall", "pit"}},
+		{"act-2", []string{"throne"}},
+	}
+
+	if !slices.EqualFunc(got, want, func(a, b act) bool { return a.name == b.name && slices.Equal(a.chapters, b.chapters) }) {
+		t.Fatalf("acts = %v, want %v", got, want)
+	}
+
+	byID := map[string]OutlineChapter{}
+	for _, a := range outline.Acts {
+		for _, chapter := range a.Chapters {
+			byID[chapter.ID] = chapter
+		}
+	}
+
+	tests := []struct {
+		id       string
+		title    string
+		depth    int
+		next     []string
+		decision bool
+		ending   bool
+	}{
+		{"intro", "Welcome aboard", 0, []string{"gate"}, false, false},
+		{"gate", "The gate", 1, []string{"hall", "pit"}, true, false},
+		{"hall", "", 2, []string{"throne"}, false, false},
+		{"pit", "The pit", 2, nil, false, true},
+		{"throne", "The throne", 3, nil, false, true},
+		{"cut", "Deleted scene", -1, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		got := byID[tt.id]
+		if got.Title != tt.title || got.Depth != tt.depth || !slices.Equal(got.Next, tt.next) || got.Decision != tt.decision || got.Ending != tt.ending {
+			t.Errorf("chapter %s = %+v, want title %q, depth %d, next %v, decision %v, ending %v",
+				tt.id, got, tt.title, tt.depth, tt.next, tt.decision, tt.ending)
+		}
+	}
+
+	if byID["gate"].Question != "Which door?" {
+		t.Errorf("gate question = %q, want Which door?", byID["gate"].Question)
+	}
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"maps"
+	"slices"
+)
+
+// Progress is how far along the story a chapter is, for progress indicators
+// such as "decision 2 of 5".
+type Progress struct {
+	Depth     int `json:"depth"`     // fewest steps from the start chapter, -1 when unreachable
+	Remaining int `json:"remaining"` // fewest steps to an ending, -1 when no ending can be reached
+	Decision  int `json:"decision"`  // decisions reached in the run, counting this chapter
+	Decisions int `json:"decisions"` // Decision plus the decisions on the shortest way to an ending
+}
+
+// ChapterProgress computes the progress of chapter id in a run that reached
+// decided decisions before it. Shortest ways are counted in steps; among
+// equally short ways to an ending, the one with the fewest decisions counts.
+func ChapterProgress(start, id string, decided int, chapters map[string]*Chapter) Progress {
+	isDecision := func(id string) int {
+		if chapters[id].Metadata.Type == "decision" {
+			return 1
+		}
+
+		return 0
+	}
+
+	progress := Progress{Depth: -1, Remaining: -1, Decision: decided, Decisions: decided}
+
+	if _, ok := chapters[id]; !ok {
+		return progress
+	}
+
+	progress.Decision += isDecision(id)
+	progress.Decisions = progress.Decision
+
+	forward := map[string][]string{}
+	backward := map[string][]string{}
+
+	var endings []string
+
+	for from, chapter := range chapters {
+		if chapter.Metadata.IsEnding() {
+			endings = append(endings, from)
+		}
+
+		for _, edge := range chapter.Metadata.Edges() {
+			if _, ok := chapters[edge.To]; ok {
+				forward[from] = append(forward[from], edge.To)
+				backward[edge.To] = append(backward[edge.To], from)
+			}
+		}
+	}
+
+	if depth, ok := distances([]string{start}, forward)[id]; ok {
+		progress.Depth = depth
+	}
+
+	remaining := distances(endings, backward)
+
+	steps, ok := remaining[id]
+	if !ok {
+		return progress
+	}
+
+	progress.Remaining = steps
+
+	// fewest decisions on a shortest way to an ending, chapter included,
+	// filled in from the endings outwards
+	order := slices.SortedFunc(maps.Keys(remaining), func(a, b string) int {
+		return remaining[a] - remaining[b]
+	})
+
+	ahead := make(map[string]int, len(order))
+
+	for _, node := range order {
+		fewest := 0
+
+		if remaining[node] > 0 {
+			fewest = -1
+
+			for _, next := range forward[node] {
+				if n, ok := ahead[next]; ok && remaining[next] == remaining[node]-1 && (fewest < 0 || n < fewest) {
+					fewest = n
+				}
+			}
+		}
+
+		ahead[node] = isDecision(node) + fewest
+	}
+
+	progress.Decisions = decided + ahead[id]
+
+	return progress
+}
+
+// distances returns the fewest steps from any of the sources to every chapter
+// reachable over graph.
+func distances(sources []string, graph map[string][]string) map[string]int {
+	dist := make(map[string]int, len(sources))
+	queue := make([]string, 0, len(sources))
+
+	for _, id := range sources {
+		dist[id] = 0
+		queue = append(queue, id)
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, next := range graph[id] {
+			if _, seen := dist[next]; !seen {
+				dist[next] = dist[id] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return dist
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChapterProgress(t *testing.T) {
+	dir := t.TempDir()
+
+	index := filepath.Join(dir, "story.yaml")
+	if err := os.WriteFile(index, []byte("start: intro"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	chapters := map[string]string{
+		"intro.md":  "---\nid: intro\ntype: story\nnext: d1\n---\n",
+		"d1.md":     "---\nid: d1\ntype: decision\nchoices:\n  - id: x\n    label: X\n    next: plain\n  - id: y\n    label: Y\n    next: d2\n---\n",
+		"plain.md":  "---\nid: plain\ntype: story\nnext: end-a\n---\n",
+		"d2.md":     "---\nid: d2\ntype: decision\nchoices:\n  - id: a\n    label: A\n    next: end-a\n  - id: b\n    label: B\n    next: end-b\n---\n",
+		"end-a.md":  "---\nid: end-a\ntype: terminal\n---\n",
+		"end-b.md":  "---\nid: end-b\ntype: game-over\n---\n",
+		"orphan.md": "---\nid: orphan\ntype: story\nnext: end-a\n---\n",
+		"loop.md":   "---\nid: loop\ntype: story\nnext: loop\n---\n",
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(index, dir)
+	if err != nil {
+		t.Fatalf("NewStoryEngine() error = %v", err)
+	}
+
+	all, err := engine.AllChapters()
+	if err != nil {
+		t.Fatalf("AllChapters() error = %v", err)
+	}
+
+	tests := []struct {
+		id      string
+		decided int
+		want    Progress
+	}{
+		// the way through plain is as short as the one through d2, with fewer decisions
+		{"intro", 0, Progress{Depth: 0, Remaining: 3, Decision: 0, Decisions: 1}},
+		{"d1", 0, Progress{Depth: 1, Remaining: 2, Decision: 1, Decisions: 1}},
+		{"d2", 1, Progress{Depth: 2, Remaining: 1, Decision: 2, Decisions: 2}},
+		{"end-a", 1, Progress{Depth: 3, Remaining: 0, Decision: 1, Decisions: 1}},
+		{"orphan", 0, Progress{Depth: -1, Remaining: 1, Decision: 0, Decisions: 0}},
+		{"loop", 0, Progress{Depth: -1, Remaining: -1, Decision: 0, Decisions: 0}},
+		{"missing", 2, Progress{Depth: -1, Remaining: -1, Decision: 2, Decisions: 2}},
+	}
+
+	for _, tt := range tests {
+		if got := ChapterProgress("intro", tt.id, tt.decided, all); got != tt.want {
+			t.Errorf("ChapterProgress(%s, %d) = %+v, want %+v", tt.id, tt.decided, got, tt.want)
+		}
+	}
+}
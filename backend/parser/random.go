@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+// Outcome is one possible result of a `type: random` chapter.
+type Outcome struct {
+	ID     string `yaml:"id"`
+	Label  string `yaml:"label,omitempty"`
+	Next   string `yaml:"next"`
+	Weight int    `yaml:"weight,omitempty"` // relative chance, defaults to 1
+}
+
+// weight returns the outcome's effective weight.
+func (o Outcome) weight() int {
+	if o.Weight == 0 {
+		return 1
+	}
+
+	return o.Weight
+}
+
+// Roll is the auditable result of picking a random outcome: replaying
+// PickOutcome with the same outcomes and seed yields the same roll.
+type Roll struct {
+	Seed    uint64  `json:"seed"`
+	Roll    int     `json:"roll"`  // 0 <= Roll < Total
+	Total   int     `json:"total"` // sum of all weights
+	Outcome Outcome `json:"outcome"`
+}
+
+// PickOutcome draws an outcome with probability proportional to its weight,
+// using a PCG generator seeded with (seed, 0).
+func PickOutcome(outcomes []Outcome, seed uint64) (Roll, error) {
+	total := 0
+
+	for _, o := range outcomes {
+		if o.Weight < 0 {
+			return Roll{}, fmt.Errorf("outcome '%s' has negative weight %d", o.ID, o.Weight)
+		}
+
+		total += o.weight()
+	}
+
+	if total == 0 {
+		return Roll{}, errors.New("no outcomes to pick from")
+	}
+
+	n := rand.New(rand.NewPCG(seed, 0)).IntN(total) //nolint:gosec // outcomes must be reproducible from the seed
+
+	roll := Roll{Seed: seed, Roll: n, Total: total}
+
+	for _, o := range outcomes {
+		if n < o.weight() {
+			roll.Outcome = o
+
+			break
+		}
+
+		n -= o.weight()
+	}
+
+	return roll, nil
+}
+
+// IsRandom reports whether the chapter picks its next chapter at random.
+func (m ChapterMetadata) IsRandom() bool {
+	return m.Type == "random"
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPickOutcome(t *testing.T) {
+	outcomes := []Outcome{
+		{ID: "hit", Next: "a", Weight: 3},
+		{ID: "miss", Next: "b"},
+	}
+
+	counts := map[string]int{}
+
+	for seed := range uint64(1000) {
+		roll, err := PickOutcome(outcomes, seed)
+		if err != nil {
+			t.Fatalf("PickOutcome() error = %v", err)
+		}
+
+		if roll.Total != 4 || roll.Roll < 0 || roll.Roll >= roll.Total || roll.Seed != seed {
+			t.Fatalf("roll = %+v, want total 4 and roll in range", roll)
+		}
+
+		again, _ := PickOutcome(outcomes, seed)
+		if again != roll {
+			t.Fatalf("same seed gave %+v and %+v", roll, again)
+		}
+
+		counts[roll.Outcome.ID]++
+	}
+
+	// a 3:1 weighting should land well inside these bounds over 1000 rolls
+	if counts["hit"] < 650 || counts["hit"] > 850 {
+		t.Errorf("hit picked %d times out of 1000, want about 750", counts["hit"])
+	}
+}
+
+func TestPickOutcome_Invalid(t *testing.T) {
+	if _, err := PickOutcome(nil, 1); err == nil {
+		t.Error("PickOutcome(nil) succeeded, want error")
+	}
+
+	if _, err := PickOutcome([]Outcome{{ID: "x", Next: "a", Weight: -1}}, 1); err == nil {
+		t.Error("PickOutcome with a negative weight succeeded, want error")
+	}
+}
+
+func TestRollChapter(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	dice := `---
+id: dice
+type: random
+outcomes:
+  - id: lucky
+    label: Lucky break
+    next: path-a
+    weight: 2
+  - id: unlucky
+    next: path-b
+---
+# The dice decide`
+	os.WriteFile(filepath.Join(engine.ContentDir, "dice.md"), []byte(dice), 0600)
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), engine.ContentDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	next, roll, err := engine.RollChapter("dice", 42)
+	if err != nil {
+		t.Fatalf("RollChapter() error = %v", err)
+	}
+
+	if next.Metadata.ID != roll.Outcome.Next {
+		t.Errorf("next = %q, want the outcome's %q", next.Metadata.ID, roll.Outcome.Next)
+	}
+
+	again, _, _ := engine.RollChapter("dice", 42)
+	if again.Metadata.ID != next.Metadata.ID {
+		t.Errorf("replaying seed 42 gave %q, want %q", again.Metadata.ID, next.Metadata.ID)
+	}
+
+	if _, _, err := engine.RollChapter("intro", 42); err == nil {
+		t.Error("RollChapter on a story chapter succeeded, want error")
+	}
+
+	// make the dice part of the story so they are not reported as unreachable
+	decision, _ := engine.GetChapter("choice1")
+	decision.Metadata.Choices = append(decision.Metadata.Choices, Choice{ID: "gamble", Next: "dice"})
+
+	if errors := engine.ValidateStory(); len(errors) != 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+
+	chapter, _ := engine.GetChapter("dice")
+	chapter.Metadata.Outcomes[1].Next = "missing"
+
+	if errors := engine.ValidateStory(); len(errors) != 1 {
+		t.Errorf("expected 1 error for the unknown outcome target, got %v", errors)
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// allowPattern matches an allow-list entry such as "video" or
+// "iframe[src width height]".
+var allowPattern = regexp.MustCompile(`^([a-z][a-z0-9]*)(?:\[([a-z0-9\- ]*)\])?$`)
+
+// WithSanitizer runs the HTML of every chapter, and its speaker notes,
+// through policy, for stories written by people who should not be able to run
+// scripts on the presenter and voter pages. Raw HTML in chapters, which is
+// dropped otherwise, is kept where the policy allows it.
+func WithSanitizer(policy *bluemonday.Policy) EngineOption {
+	return func(o *renderOptions) {
+		o.sanitizer = policy
+	}
+}
+
+// SanitizePolicy returns an allow-list for untrusted stories. It keeps what
+// chapter markdown renders to, such as callouts, footnotes, task lists and
+// highlighted code, plus the extra elements given as "video" or, with the
+// attributes they may carry, "iframe[src width height]".
+func SanitizePolicy(allow ...string) (*bluemonday.Policy, error) {
+	policy := bluemonday.UGCPolicy()
+
+	policy.AllowDataURIImages()
+	policy.AllowAttrs("class").Globally()
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[\w:.-]+$`)).Globally()
+	policy.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).Globally()
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.AllowAttrs("tabindex").OnElements("pre")
+
+	// colours of highlighted code
+	policy.AllowStyles("color", "background-color", "font-weight", "font-style", "text-decoration", "display").
+		OnElements("pre", "span")
+	policy.AllowStyles("tab-size", "-moz-tab-size", "-o-tab-size").
+		Matching(regexp.MustCompile(`^\d+$`)).OnElements("pre")
+
+	for _, entry := range allow {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		match := allowPattern.FindStringSubmatch(entry)
+		if match == nil {
+			return nil, fmt.Errorf("invalid sanitizer allow-list entry %q, want an element such as video or iframe[src width]", entry)
+		}
+
+		policy.AllowElements(match[1])
+
+		if attrs := strings.Fields(match[2]); len(attrs) > 0 {
+			policy.AllowAttrs(attrs...).OnElements(match[1])
+		}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizer(t *testing.T) {
+	dir := t.TempDir()
+
+	index := filepath.Join(dir, "story.yaml")
+	if err := os.WriteFile(index, []byte("start: vault"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	chapter := "---\n" +
+		"id: vault\n" +
+		"type: terminal\n" +
+		"---\n" +
+		"# The vault\n\n" +
+		"<script>alert('pwned')</script>\n\n" +
+		"<img src=\"x.png\" onerror=\"alert(1)\">\n\n" +
+		"[Click](javascript:alert(1)) and <a href=\"https://example.com\" onclick=\"steal()\">leave</a>.\n\n" +
+		"<details><summary>Hint</summary>Try the door.</details>\n\n" +
+		"<iframe src=\"https://example.com/map\" width=\"300\" onload=\"alert(1)\"></iframe>\n\n" +
+		":::warning Careful\nThe floor is lava.\n:::\n\n" +
+		"- [x] Found the key\n\n" +
+		"```go\nfunc main() {}\n```\n\n" +
+		"<!-- notes -->\n" +
+		"Remember to pause. <script>alert('notes')</script>\n"
+
+	if err := os.WriteFile(filepath.Join(dir, "vault.md"), []byte(chapter), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	render := func(opts ...EngineOption) *Chapter {
+		t.Helper()
+
+		engine, err := NewStoryEngine(index, dir, opts...)
+		if err != nil {
+			t.Fatalf("NewStoryEngine() error = %v", err)
+		}
+
+		chapter, err := engine.GetChapter("vault")
+		if err != nil {
+			t.Fatalf("GetChapter() error = %v", err)
+		}
+
+		return chapter
+	}
+
+	withPolicy := func(allow ...string) []EngineOption {
+		t.Helper()
+
+		policy, err := SanitizePolicy(allow...)
+		if err != nil {
+			t.Fatalf("SanitizePolicy() error = %v", err)
+		}
+
+		return []EngineOption{WithSanitizer(policy)}
+	}
+
+	tests := []struct {
+		name    string
+		opts    []EngineOption
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "raw HTML omitted without a sanitizer",
+			want:    []string{"<!-- raw HTML omitted -->"},
+			notWant: []string{"<script>", "<details>", "<iframe"},
+		},
+		{
+			name: "sanitized",
+			opts: withPolicy(),
+			want: []string{
+				`<div class="admonition admonition-warning">`,
+				`<input checked="" disabled="" type="checkbox"`,
+				`<span style="color:`,
+				`<a href="https://example.com" rel="nofollow">leave</a>`,
+				`<img src="x.png">`,
+				"<details><summary>Hint</summary>Try the door.</details>",
+			},
+			notWant: []string{"<script>", "alert(", "onerror", "onclick", "onload", "javascript:", "<iframe"},
+		},
+		{
+			name:    "extra elements allowed",
+			opts:    withPolicy("iframe[src width]"),
+			want:    []string{`<iframe src="https://example.com/map" width="300"></iframe>`},
+			notWant: []string{"<script>", "onload"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(tt.opts...)
+
+			for _, want := range tt.want {
+				if !strings.Contains(got.Content, want) {
+					t.Errorf("Content = %s, want it to contain %s", got.Content, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got.Content, notWant) {
+					t.Errorf("Content = %s, must not contain %s", got.Content, notWant)
+				}
+			}
+
+			if strings.Contains(got.Notes, "<script>") || !strings.Contains(got.Notes, "Remember to pause.") {
+				t.Errorf("Notes = %s, want the text without the script", got.Notes)
+			}
+		})
+	}
+}
+
+func TestSanitizePolicyInvalidEntry(t *testing.T) {
+	for _, entry := range []string{"<iframe>", "iframe[src", "Video", "iframe[src=x]"} {
+		if _, err := SanitizePolicy(entry); err == nil {
+			t.Errorf("SanitizePolicy(%q) error = nil, want an error", entry)
+		}
+	}
+
+	if _, err := SanitizePolicy(" video ", "", "iframe[src width height]"); err != nil {
+		t.Errorf("SanitizePolicy() error = %v", err)
+	}
+}
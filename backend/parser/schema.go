@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// chapterTypes are the chapter types the engine knows. An empty type is a
+// plain story chapter.
+var chapterTypes = map[string]bool{
+	"":          true,
+	"story":     true,
+	"decision":  true,
+	"random":    true,
+	"roll":      true,
+	"game-over": true,
+	"terminal":  true,
+}
+
+// unknownFieldPattern matches the errors yaml reports for fields a struct
+// does not have.
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type`)
+
+// validateSchema checks a chapter's frontmatter against what the engine
+// understands: known fields and types, and decisions with choices that have
+// distinct IDs. Misspelt fields would otherwise be ignored without a word.
+func (se *StoryEngine) validateSchema(nodeID string, chapter *Chapter) []error {
+	file := se.Story.Nodes[nodeID].File
+	located := func(line int, err error) error {
+		return &StoryError{File: file, Line: line, Err: err}
+	}
+
+	var errs []error
+
+	errs = append(errs, unknownFields(filepath.Join(se.ContentDir, file), file)...)
+
+	meta := chapter.Metadata
+	if !chapterTypes[meta.Type] {
+		errs = append(errs, located(0, fmt.Errorf("node '%s' has unknown type '%s'", nodeID, meta.Type)))
+	}
+
+	if meta.Type == "decision" && len(meta.Choices) == 0 {
+		errs = append(errs, located(0, fmt.Errorf("decision node '%s' has no choices", nodeID)))
+	}
+
+	seen := map[string]bool{}
+
+	for i, choice := range meta.Choices {
+		switch {
+		case choice.ID == "":
+			errs = append(errs, located(0, fmt.Errorf("choice %d in node '%s' has no id", i+1, nodeID)))
+		case seen[choice.ID]:
+			errs = append(errs, located(0, fmt.Errorf("node '%s' has more than one choice '%s'", nodeID, choice.ID)))
+		}
+
+		seen[choice.ID] = true
+	}
+
+	return errs
+}
+
+// unknownFields reports the frontmatter fields of a chapter file that the
+// engine does not know, such as a misspelt "choises".
+func unknownFields(path, file string) []error {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil
+	}
+
+	frontmatter, _, err := splitFrontmatter(content)
+	if err != nil || len(frontmatter) == 0 {
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(frontmatter))
+	decoder.KnownFields(true)
+
+	var (
+		meta    ChapterMetadata
+		typeErr *yaml.TypeError
+		errs    []error
+	)
+
+	if err := decoder.Decode(&meta); !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	for _, message := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+
+		// the frontmatter starts below the opening ---
+		line, _ := strconv.Atoi(match[1])
+		errs = append(errs, &StoryError{File: file, Line: line + 1, Err: fmt.Errorf("unknown frontmatter field '%s'", match[2])})
+	}
+
+	return errs
+}
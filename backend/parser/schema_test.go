@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateStory_Schema(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"story.yaml": "start: fork",
+		"fork.md": `---
+id: fork
+type: decison
+choises: []
+choices:
+  - id: left
+    nxt: end
+  - id: left
+    next: end
+  - label: Nameless
+    next: end
+---
+# Fork`,
+		"vote.md": "---\nid: vote\ntype: decision\nnext: end\n---\n# Nothing to vote on",
+		"end.md":  "---\nid: end\ntype: terminal\n---\n# The end",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	var got []string
+	for _, err := range engine.ValidateStory() {
+		got = append(got, err.Error())
+	}
+
+	slices.Sort(got)
+
+	want := []string{
+		"fork.md: choice 'left' in node 'fork' has no next",
+		"fork.md: choice 3 in node 'fork' has no id",
+		"fork.md: node 'fork' has more than one choice 'left'",
+		"fork.md: node 'fork' has unknown type 'decison'",
+		"fork.md:4: unknown frontmatter field 'choises'",
+		"fork.md:7: unknown frontmatter field 'nxt'",
+		"vote.md: decision node 'vote' has no choices",
+		"vote.md:2: node 'vote' is unreachable from start node 'fork'",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("ValidateStory() =\n%q\nwant\n%q", got, want)
+	}
+}
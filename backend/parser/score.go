@@ -0,0 +1,34 @@
+package parser
+
+// ScoreKey is the story variable that holds the session score, the points of
+// every winning choice added up, so conditions can test it, e.g. `score >= 50`.
+const ScoreKey = "score"
+
+// Score returns the session score.
+func (s State) Score() int {
+	n, _ := toFloat(s[ScoreKey])
+
+	return int(n)
+}
+
+// Award adds points, which may be negative, to the session score.
+func (s State) Award(points int) {
+	if points == 0 {
+		return
+	}
+
+	current, _ := toFloat(s[ScoreKey])
+	s[ScoreKey] = normalizeNumber(current + float64(points))
+}
+
+// ChoicePoints returns the points of the chapter's choice with the given ID,
+// zero when it has none or there is no such choice.
+func (m ChapterMetadata) ChoicePoints(choiceID string) int {
+	for _, choice := range m.Choices {
+		if choice.ID == choiceID {
+			return choice.Points
+		}
+	}
+
+	return 0
+}
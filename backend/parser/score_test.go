@@ -0,0 +1,57 @@
+package parser
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	state := State{}
+
+	state.Award(30)
+	state.Award(0)
+	state.Award(-5)
+
+	if state.Score() != 25 {
+		t.Errorf("Score() = %d, want 25", state.Score())
+	}
+
+	if ok, _ := EvalCondition("score > 20", state); !ok {
+		t.Error("score should be visible to conditions")
+	}
+
+	// chapters can set the score as any other variable
+	state.Enter(ChapterMetadata{Set: Assignments{ScoreKey: "+10"}})
+
+	if state.Score() != 35 {
+		t.Errorf("Score() after set = %d, want 35", state.Score())
+	}
+}
+
+func TestParseMarkdown_Points(t *testing.T) {
+	content := []byte(`---
+id: incident
+type: decision
+choices:
+  - id: rollback
+    label: Roll back
+    next: calm
+    points: 20
+  - id: yolo
+    label: Push to prod
+    next: fire
+    points: -10
+  - id: wait
+    label: Wait
+    next: calm
+---
+# Incident`)
+
+	chapter, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	for choice, want := range map[string]int{"rollback": 20, "yolo": -10, "wait": 0, "missing": 0} {
+		if got := chapter.Metadata.ChoicePoints(choice); got != want {
+			t.Errorf("ChoicePoints(%s) = %d, want %d", choice, got, want)
+		}
+	}
+}
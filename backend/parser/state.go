@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Assignments are the variable updates a chapter applies when it is visited.
+// Numbers written with an explicit sign (+10, -5) are relative to the current
+// value; everything else replaces it. Dotted keys address nested variables.
+type Assignments map[string]any
+
+// UnmarshalYAML keeps the sign of relative numbers, which plain decoding loses,
+// by storing them as strings such as "+10".
+func (a *Assignments) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: set must be a mapping", node.Line)
+	}
+
+	out := make(Assignments, len(node.Content)/2)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if isRelative(value) {
+			out[key.Value] = value.Value
+
+			continue
+		}
+
+		var v any
+		if err := value.Decode(&v); err != nil {
+			return fmt.Errorf("set %s: %w", key.Value, err)
+		}
+
+		out[key.Value] = v
+	}
+
+	*a = out
+
+	return nil
+}
+
+func isRelative(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+
+	tag := node.ShortTag()
+
+	return (tag == "!!int" || tag == "!!float") && (strings.HasPrefix(node.Value, "+") || strings.HasPrefix(node.Value, "-"))
+}
+
+// relativeDelta reports the delta of a relative assignment value.
+func relativeDelta(v any) (float64, bool) {
+	s, ok := v.(string)
+	if !ok || (!strings.HasPrefix(s, "+") && !strings.HasPrefix(s, "-")) {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
+
+// Apply updates the state with the given assignments. Relative updates to a
+// missing or non-numeric variable start from zero.
+func (s State) Apply(set Assignments) {
+	for key, value := range set {
+		parent, name := s.parentOf(key)
+
+		if delta, ok := relativeDelta(value); ok {
+			current, _ := toFloat(parent[name])
+			value = normalizeNumber(current + delta)
+		}
+
+		parent[name] = value
+	}
+}
+
+// parentOf walks a dotted key, creating intermediate maps as needed, and
+// returns the map holding the final segment.
+func (s State) parentOf(key string) (map[string]any, string) {
+	parts := strings.Split(key, ".")
+	current := map[string]any(s)
+
+	for _, part := range parts[:len(parts)-1] {
+		switch next := current[part].(type) {
+		case map[string]any:
+			current = next
+		case State:
+			current = next
+		default:
+			child := map[string]any{}
+			current[part] = child
+			current = child
+		}
+	}
+
+	return current, parts[len(parts)-1]
+}
+
+// Clone returns a deep copy of the state.
+func (s State) Clone() State {
+	return State(cloneMap(s))
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	out := maps.Clone(m)
+	if out == nil {
+		out = map[string]any{}
+	}
+
+	for k, v := range out {
+		switch nested := v.(type) {
+		case map[string]any:
+			out[k] = cloneMap(nested)
+		case State:
+			out[k] = cloneMap(nested)
+		}
+	}
+
+	return out
+}
+
+// normalizeNumber keeps whole numbers as ints so they render without decimals.
+func normalizeNumber(f float64) any {
+	if f == float64(int(f)) {
+		return int(f)
+	}
+
+	return f
+}
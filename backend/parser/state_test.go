@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdown_Set(t *testing.T) {
+	content := []byte(`---
+id: camp
+type: story
+set: {crew_morale: +10, fuel: -2, has_map: true, name: Sam, flags.found_key: true, level: 3}
+---
+
+# Camp
+`)
+
+	chapter, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	want := Assignments{
+		"crew_morale":     "+10",
+		"fuel":            "-2",
+		"has_map":         true,
+		"name":            "Sam",
+		"flags.found_key": true,
+		"level":           3,
+	}
+
+	if !reflect.DeepEqual(chapter.Metadata.Set, want) {
+		t.Errorf("Set = %#v, want %#v", chapter.Metadata.Set, want)
+	}
+}
+
+func TestStateApply(t *testing.T) {
+	state := State{"crew_morale": 50, "fuel": 1.5}
+
+	state.Apply(Assignments{
+		"crew_morale":     "+10",
+		"fuel":            "-0.5",
+		"gold":            "+3",
+		"has_map":         true,
+		"flags.found_key": true,
+	})
+
+	want := State{
+		"crew_morale": 60,
+		"fuel":        1,
+		"gold":        3,
+		"has_map":     true,
+		"flags":       map[string]any{"found_key": true},
+	}
+
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("state = %#v, want %#v", state, want)
+	}
+
+	if ok, _ := EvalCondition("flags.found_key && crew_morale > 55", state); !ok {
+		t.Error("applied variables should be visible to conditions")
+	}
+}
+
+func TestStateClone(t *testing.T) {
+	state := State{"flags": map[string]any{"found_key": true}}
+
+	clone := state.Clone()
+	clone.Apply(Assignments{"flags.found_key": false})
+
+	if state.Lookup("flags.found_key") != true {
+		t.Error("modifying a clone changed the original state")
+	}
+}
@@ -4,19 +4,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
 
 // StoryIndex represents the minimal index file that just defines the start.
 type StoryIndex struct {
-	Start string `yaml:"start"`
+	Start    string            `yaml:"start"`
+	Title    string            `yaml:"title,omitempty"`
+	Language string            `yaml:"language,omitempty"` // language the chapters are written in, such as "en"
+	Emoji    map[string]string `yaml:"emoji,omitempty"`    // extra emoji shortcodes, such as k8s: ☸️
 }
 
 // Story represents the entire adventure flow (built from chapters).
 type Story struct {
-	Flow  StoryFlow            `yaml:"flow"`
-	Nodes map[string]StoryNode `yaml:"nodes"`
+	Title    string               `yaml:"title,omitempty"`
+	Language string               `yaml:"language,omitempty"`
+	Flow     StoryFlow            `yaml:"flow"`
+	Nodes    map[string]StoryNode `yaml:"nodes"`
 }
 
 // StoryFlow defines the entry point.
@@ -27,20 +34,31 @@ type StoryFlow struct {
 // StoryNode represents a node in the adventure flow.
 type StoryNode struct {
 	File     string `yaml:"file"`
-	Type     string `yaml:"type"` // story, decision, game-over, terminal
+	Type     string `yaml:"type"` // story, decision, random, roll, game-over, terminal
 	Terminal bool   `yaml:"terminal,omitempty"`
 	Next     string `yaml:"next,omitempty"`
+
+	Conditions   []Condition       `yaml:"conditions,omitempty"`
+	Translations map[string]string `yaml:"translations,omitempty"` // language -> file, such as "de" -> intro.de.md
 }
 
 // StoryEngine manages the adventure state and navigation.
 type StoryEngine struct {
 	Story      *Story
 	ContentDir string
-	chapters   map[string]*Chapter // Cache parsed chapters
+	markdown   *chapterRenderer // converts chapter markdown, configured by EngineOptions
+
+	mu       sync.RWMutex        // guards chapters, which requests and the content watcher fill concurrently
+	chapters map[string]*Chapter // Cache parsed chapters
 }
 
 // NewStoryEngine creates a new story engine.
-func NewStoryEngine(indexPath, contentDir string) (*StoryEngine, error) {
+func NewStoryEngine(indexPath, contentDir string, opts ...EngineOption) (*StoryEngine, error) {
+	options := defaultRenderOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	content, err := os.ReadFile(filepath.Clean(indexPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read index file: %w", err)
@@ -51,21 +69,43 @@ func NewStoryEngine(indexPath, contentDir string) (*StoryEngine, error) {
 		return nil, fmt.Errorf("failed to parse index YAML: %w", err)
 	}
 
+	options.contentDir = contentDir
+	options.emoji = index.Emoji
+
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
 	story, err := buildStoryFromChapters(contentDir, index.Start)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build story from chapters: %w", err)
 	}
 
+	story.Title = index.Title
+	story.Language = NormalizeLang(index.Language)
+
 	return &StoryEngine{
 		Story:      story,
 		ContentDir: contentDir,
 		chapters:   make(map[string]*Chapter),
+		markdown:   newMarkdownRenderer(options),
 	}, nil
 }
 
+// parseChapterFile parses a chapter file of the story with the engine's renderer.
+func (se *StoryEngine) parseChapterFile(filePath string) (*Chapter, error) {
+	content, err := os.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return parseMarkdown(content, filepath.Dir(filePath), filepath.Base(filePath), se.markdown)
+}
+
 // buildStoryFromChapters scans the content directory and builds the story graph.
 func buildStoryFromChapters(contentDir, startNode string) (*Story, error) {
 	nodes := make(map[string]StoryNode)
+	translations := make(map[string]map[string]string) // chapter file -> language -> translation file
 
 	files, err := filepath.Glob(filepath.Join(contentDir, "*.md"))
 	if err != nil {
@@ -73,6 +113,17 @@ func buildStoryFromChapters(contentDir, startNode string) (*Story, error) {
 	}
 
 	for _, filePath := range files {
+		// translations are loaded on demand, see GetLocalizedChapter
+		if base, lang, ok := splitLocalizedFile(filepath.Base(filePath)); ok {
+			if translations[base] == nil {
+				translations[base] = make(map[string]string)
+			}
+
+			translations[base][lang] = filepath.Base(filePath)
+
+			continue
+		}
+
 		chapter, err := ParseMarkdownFile(filePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
@@ -92,11 +143,19 @@ func buildStoryFromChapters(contentDir, startNode string) (*Story, error) {
 			Type:     chapter.Metadata.Type,
 			Terminal: chapter.Metadata.Terminal || chapter.Metadata.Type == "terminal",
 			Next:     chapter.Metadata.Next,
+
+			Conditions: chapter.Metadata.Conditions,
 		}
 
 		nodes[chapter.Metadata.ID] = node
 	}
 
+	for id, node := range nodes {
+		if node.Translations = translations[node.File]; node.Translations != nil {
+			nodes[id] = node
+		}
+	}
+
 	if _, ok := nodes[startNode]; !ok {
 		return nil, fmt.Errorf("start node '%s' not found in chapters", startNode)
 	}
@@ -109,7 +168,7 @@ func buildStoryFromChapters(contentDir, startNode string) (*Story, error) {
 
 // GetChapter retrieves and parses a chapter by node ID.
 func (se *StoryEngine) GetChapter(nodeID string) (*Chapter, error) {
-	if chapter, ok := se.chapters[nodeID]; ok {
+	if chapter, ok := se.cached(nodeID); ok {
 		return chapter, nil
 	}
 
@@ -120,7 +179,7 @@ func (se *StoryEngine) GetChapter(nodeID string) (*Chapter, error) {
 
 	filePath := filepath.Join(se.ContentDir, node.File)
 
-	chapter, err := ParseMarkdownFile(filePath)
+	chapter, err := se.parseChapterFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse chapter %s: %w", nodeID, err)
 	}
@@ -137,11 +196,29 @@ func (se *StoryEngine) GetChapter(nodeID string) (*Chapter, error) {
 		chapter.Metadata.Next = node.Next
 	}
 
-	se.chapters[nodeID] = chapter
+	se.cache(nodeID, chapter)
 
 	return chapter, nil
 }
 
+// cached returns the parsed chapter cached under key.
+func (se *StoryEngine) cached(key string) (*Chapter, bool) {
+	se.mu.RLock()
+	defer se.mu.RUnlock()
+
+	chapter, ok := se.chapters[key]
+
+	return chapter, ok
+}
+
+// cache keeps a parsed chapter under key for later lookups.
+func (se *StoryEngine) cache(key string, chapter *Chapter) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	se.chapters[key] = chapter
+}
+
 // GetStartChapter returns the first chapter.
 func (se *StoryEngine) GetStartChapter() (*Chapter, error) {
 	return se.GetChapter(se.Story.Flow.Start)
@@ -149,16 +226,68 @@ func (se *StoryEngine) GetStartChapter() (*Chapter, error) {
 
 // GetNextChapter gets the next chapter based on current node.
 func (se *StoryEngine) GetNextChapter(currentNodeID string) (*Chapter, error) {
+	return se.NextChapter(currentNodeID, nil)
+}
+
+// NextChapter gets the next chapter based on the current node, following the
+// first of its conditions that holds for the given state before falling back
+// to the plain next.
+func (se *StoryEngine) NextChapter(currentNodeID string, state State) (*Chapter, error) {
 	chapter, err := se.GetChapter(currentNodeID)
 	if err != nil {
 		return nil, err
 	}
 
-	if chapter.Metadata.Next == "" {
+	next, err := ResolveNext(chapter.Metadata, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve next chapter for %s: %w", currentNodeID, err)
+	}
+
+	if next == "" {
 		return nil, fmt.Errorf("no next chapter defined for %s", currentNodeID)
 	}
 
-	return se.GetChapter(chapter.Metadata.Next)
+	return se.GetChapter(next)
+}
+
+// ResolveNext returns the chapter ID a chapter leads to for the given state.
+func ResolveNext(meta ChapterMetadata, state State) (string, error) {
+	for _, condition := range meta.Conditions {
+		ok, err := EvalCondition(condition.If, state)
+		if err != nil {
+			return "", fmt.Errorf("condition %q: %w", condition.If, err)
+		}
+
+		if ok {
+			return condition.Next, nil
+		}
+	}
+
+	return meta.Next, nil
+}
+
+// RollChapter picks the next chapter of a random chapter with the given seed.
+func (se *StoryEngine) RollChapter(currentNodeID string, seed uint64) (*Chapter, Roll, error) {
+	chapter, err := se.GetChapter(currentNodeID)
+	if err != nil {
+		return nil, Roll{}, err
+	}
+
+	if !chapter.Metadata.IsRandom() {
+		return nil, Roll{}, fmt.Errorf("chapter %s is not a random chapter", currentNodeID)
+	}
+
+	roll, err := PickOutcome(chapter.Metadata.Outcomes, seed)
+	if err != nil {
+		return nil, Roll{}, fmt.Errorf("failed to roll %s: %w", currentNodeID, err)
+	}
+
+	next, err := se.GetChapter(roll.Outcome.Next)
+	if err != nil {
+		return nil, Roll{}, err
+	}
+
+	return next, roll, nil
 }
 
 // GetChapterByChoice gets the next chapter based on a choice ID.
@@ -193,10 +322,16 @@ func (se *StoryEngine) AllChapters() (map[string]*Chapter, error) {
 	return out, nil
 }
 
-// ValidateStory checks if all nodes and files exist.
+// ValidateStory checks if all nodes and files exist and analyses how the
+// chapters connect, see validateGraph.
 func (se *StoryEngine) ValidateStory() []error {
 	var errors []error
 
+	chapters := make(map[string]*Chapter, len(se.Story.Nodes))
+
+	granted := map[string]bool{}
+	required := map[string][]string{} // item -> "choice in node" descriptions
+
 	if _, ok := se.Story.Nodes[se.Story.Flow.Start]; !ok {
 		errors = append(errors, fmt.Errorf("start node '%s' not found", se.Story.Flow.Start))
 	}
@@ -209,10 +344,145 @@ func (se *StoryEngine) ValidateStory() []error {
 			continue
 		}
 
-		if _, err := se.GetChapter(nodeID); err != nil {
+		chapter, err := se.GetChapter(nodeID)
+		if err != nil {
 			errors = append(errors, fmt.Errorf("failed to parse node '%s': %w", nodeID, err))
+
+			continue
+		}
+
+		chapters[nodeID] = chapter
+
+		errors = append(errors, se.validateSchema(nodeID, chapter)...)
+
+		for _, condition := range chapter.Metadata.Conditions {
+			if _, err := CompileExpr(condition.If); err != nil {
+				errors = append(errors, fmt.Errorf("invalid condition in node '%s': %w", nodeID, err))
+			}
+		}
+
+		if chapter.Metadata.IsRandom() {
+			if _, err := PickOutcome(chapter.Metadata.Outcomes, 0); err != nil {
+				errors = append(errors, fmt.Errorf("invalid outcomes in node '%s': %w", nodeID, err))
+			}
+		}
+
+		if chapter.Metadata.IsRoll() {
+			if err := chapter.Metadata.validateRoll(); err != nil {
+				errors = append(errors, fmt.Errorf("invalid roll in node '%s': %w", nodeID, err))
+			}
+		}
+
+		errors = append(errors, se.validateTranslations(nodeID, chapter)...)
+
+		if err := chapter.Metadata.validateThresholds(); err != nil {
+			errors = append(errors, fmt.Errorf("invalid thresholds in node '%s': %w", nodeID, err))
+		}
+
+		if err := chapter.Metadata.validateTimer(); err != nil {
+			errors = append(errors, fmt.Errorf("invalid timer in node '%s': %w", nodeID, err))
+		}
+
+		for _, item := range chapter.Metadata.Grants {
+			granted[item] = true
+		}
+
+		for _, choice := range chapter.Metadata.Choices {
+			for _, item := range choice.Requires {
+				required[item] = append(required[item], fmt.Sprintf("choice '%s' in node '%s'", choice.ID, nodeID))
+			}
+
+			if choice.Preview == "" {
+				continue
+			}
+
+			if _, err := se.ResolveMedia(choice.Preview); err != nil {
+				errors = append(errors, fmt.Errorf("invalid preview for choice '%s' in node '%s': %w", choice.ID, nodeID, err))
+			}
+		}
+	}
+
+	for item, uses := range required {
+		if granted[item] {
+			continue
+		}
+
+		for _, use := range uses {
+			errors = append(errors, fmt.Errorf("%s requires item '%s' that no chapter grants", use, item))
 		}
 	}
 
+	if _, ok := chapters[se.Story.Flow.Start]; ok {
+		errors = append(errors, se.validateGraph(chapters)...)
+	}
+
 	return errors
 }
+
+// mediaExtensions lists the file types allowed as choice previews. SVG is left
+// out, as it can carry script that would run next to the presenter pages.
+var mediaExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".webp": true,
+	".mp4":  true,
+	".webm": true,
+	".mp3":  true,
+	".ogg":  true,
+}
+
+// RenderMarkdown converts markdown to HTML the way the engine renders
+// chapters, for content made up while the story is played.
+func (se *StoryEngine) RenderMarkdown(markdown string) (string, error) {
+	return convertMarkdown(se.markdown, []byte(markdown))
+}
+
+// ResolveMedia validates a media path relative to the content directory and
+// returns its absolute location. Guards against path traversal and unknown
+// file types.
+func (se *StoryEngine) ResolveMedia(rel string) (string, error) {
+	if rel == "" || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("media path must be relative: %q", rel)
+	}
+
+	if !mediaExtensions[strings.ToLower(filepath.Ext(rel))] {
+		return "", fmt.Errorf("unsupported media type: %q", rel)
+	}
+
+	candidate := filepath.Join(se.ContentDir, filepath.FromSlash(rel))
+
+	if !within(se.ContentDir, candidate) {
+		return "", fmt.Errorf("media path escapes content directory: %q", rel)
+	}
+
+	// symlinks in the content directory must not lead out of it either
+	resolved, err := filepath.EvalSymlinks(candidate)
+	if err != nil {
+		return "", fmt.Errorf("media not found: %q", rel)
+	}
+
+	root, err := filepath.EvalSymlinks(se.ContentDir)
+	if err != nil || !within(root, resolved) || !mediaExtensions[strings.ToLower(filepath.Ext(resolved))] {
+		return "", fmt.Errorf("media path escapes content directory: %q", rel)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("media not found: %q", rel)
+	}
+
+	if info.IsDir() {
+		return "", fmt.Errorf("media path is a directory: %q", rel)
+	}
+
+	return candidate, nil
+}
+
+// within reports whether path lies inside the directory root.
+func within(root, path string) bool {
+	inside, err := filepath.Rel(root, path)
+
+	return err == nil && inside != ".." && !strings.HasPrefix(inside, ".."+string(filepath.Separator))
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -367,6 +368,146 @@ type: story
 	})
 }
 
+func TestResolveMedia(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	imagesDir := filepath.Join(engine.ContentDir, "images")
+	if err := os.Mkdir(imagesDir, 0755); err != nil {
+		t.Fatalf("failed to create images dir: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(imagesDir, "door-a.png"), []byte("png"), 0600)
+	os.WriteFile(filepath.Join(imagesDir, "notes.txt"), []byte("txt"), 0600)
+	os.WriteFile(filepath.Join(imagesDir, "logo.svg"), []byte("<svg/>"), 0600)
+	os.WriteFile(filepath.Join(tmpDir, "secret.png"), []byte("png"), 0600)
+
+	if err := os.Symlink(filepath.Join(tmpDir, "secret.png"), filepath.Join(imagesDir, "linked.png")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(imagesDir, "notes.txt"), filepath.Join(imagesDir, "renamed.png")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"valid image", "images/door-a.png", false},
+		{"missing file", "images/door-b.png", true},
+		{"unsupported type", "images/notes.txt", true},
+		{"svg", "images/logo.svg", true},
+		{"symlink out of the content directory", "images/linked.png", true},
+		{"symlink to another type", "images/renamed.png", true},
+		{"path traversal", "../secret.png", true},
+		{"absolute path", filepath.Join(tmpDir, "secret.png"), true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.ResolveMedia(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveMedia(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != filepath.Join(imagesDir, "door-a.png") {
+				t.Errorf("ResolveMedia(%q) = %q", tt.path, got)
+			}
+		})
+	}
+}
+
+func TestValidateStory_MissingPreview(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapter, err := engine.GetChapter("choice1")
+	if err != nil {
+		t.Fatalf("failed to get chapter: %v", err)
+	}
+
+	chapter.Metadata.Choices[0].Preview = "images/missing.png"
+
+	errors := engine.ValidateStory()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestNextChapter_Conditions(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	gate := `---
+id: gate
+type: story
+next: path-a if flags.found_key else path-b
+conditions:
+  - if: score > 100
+    next: choice1
+---
+# Gate`
+	os.WriteFile(filepath.Join(engine.ContentDir, "gate.md"), []byte(gate), 0600)
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), engine.ContentDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		state  State
+		wantID string
+	}{
+		{"no state falls back", nil, "path-b"},
+		{"inline condition", State{"flags": map[string]any{"found_key": true}}, "path-a"},
+		{"explicit condition wins first", State{"score": 150, "flags": map[string]any{"found_key": true}}, "choice1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := engine.NextChapter("gate", tt.state)
+			if err != nil {
+				t.Fatalf("NextChapter failed: %v", err)
+			}
+
+			if next.Metadata.ID != tt.wantID {
+				t.Errorf("next = %q, want %q", next.Metadata.ID, tt.wantID)
+			}
+		})
+	}
+
+	// make the gate part of the story so it is not reported as unreachable
+	intro, _ := engine.GetChapter("intro")
+	intro.Metadata.Next = "gate"
+
+	if errors := engine.ValidateStory(); len(errors) > 0 {
+		t.Errorf("expected no validation errors, got %v", errors)
+	}
+}
+
+func TestValidateStory_InvalidCondition(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapter, err := engine.GetChapter("intro")
+	if err != nil {
+		t.Fatalf("failed to get chapter: %v", err)
+	}
+
+	chapter.Metadata.Conditions = []Condition{
+		{If: "score >", Next: "path-a"},
+		{If: "score > 1", Next: "nowhere"},
+	}
+
+	if errors := engine.ValidateStory(); len(errors) != 2 {
+		t.Errorf("expected 2 errors, got %d: %v", len(errors), errors)
+	}
+}
+
 func TestStoryNodeOverrides(t *testing.T) {
 	tmpDir := t.TempDir()
 	contentDir := filepath.Join(tmpDir, "chapters")
@@ -454,6 +595,7 @@ choices:
 		"path-a.md": `---
 id: path-a
 type: story
+next: path-b
 ---
 # Path A`,
 		"path-b.md": `---
@@ -477,3 +619,19 @@ type: game-over
 
 	return engine, tmpDir
 }
+
+func TestRenderMarkdown(t *testing.T) {
+	engine, tmpDir := setupTestEngine(t)
+	defer os.RemoveAll(tmpDir)
+
+	got, err := engine.RenderMarkdown("# Your journey :rocket:\n\n1. Intro\n")
+	if err != nil {
+		t.Fatalf("RenderMarkdown() error = %v", err)
+	}
+
+	for _, want := range []string{"Your journey 🚀</h1>", "<ol>\n<li>Intro</li>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderMarkdown() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// TemplateData is what chapter templates see when a chapter is served, e.g.
+// "You, the {{.VoterCount}} brave crew members, chose {{.LastWinnerLabel}}".
+type TemplateData struct {
+	Vars            State        // story variables, such as {{.Vars.crew_morale}} or {{.Vars.Lookup "flags.found_key"}}
+	VoterCount      int          // voters connected right now
+	Votes           []VoteResult // decided votes, oldest first
+	LastWinner      string       // choice ID of the most recent vote
+	LastWinnerLabel string       // label of the most recent winning choice
+}
+
+// VoteResult is one decided vote of the current run.
+type VoteResult struct {
+	Question    string         // chapter ID of the decision
+	Winner      string         // winning choice ID
+	Override    string         // choice ID the presenter went with instead, empty unless overridden
+	WinnerLabel string         // winning choice label
+	Votes       int            // ballots cast
+	Results     map[string]int // choice ID -> ballots
+}
+
+// templateFuncs keeps include directives that were not expanded, see
+// ParseMarkdown, as written.
+var templateFuncs = template.FuncMap{
+	"include": func(name string) string {
+		return fmt.Sprintf("{{include %q}}", name)
+	},
+}
+
+// parseTemplate compiles a chapter's markdown as a text/template. Chapters
+// without actions are not templates and yield nil.
+func parseTemplate(name string, markdown []byte) (*template.Template, error) {
+	if !bytes.Contains(markdown, []byte("{{")) {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(templateFuncs).Parse(string(markdown))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// IsTemplate reports whether the chapter's content depends on the story state
+// and has to be rendered with Render when it is served.
+func (c *Chapter) IsTemplate() bool {
+	return c.template != nil
+}
+
+// Render executes the chapter's template with data and converts the result
+// to HTML. Chapters that are not templates return their parsed content.
+func (c *Chapter) Render(data TemplateData) (string, error) {
+	if c.template == nil {
+		return c.Content, nil
+	}
+
+	var markdown bytes.Buffer
+	if err := c.template.Execute(&markdown, data); err != nil {
+		return "", fmt.Errorf("failed to render chapter %s: %w", c.Metadata.ID, err)
+	}
+
+	return convertMarkdown(c.markdown, markdown.Bytes())
+}
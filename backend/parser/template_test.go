@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChapterRender(t *testing.T) {
+	chapter, err := ParseMarkdown([]byte(`---
+id: bridge
+type: story
+---
+You, the {{.VoterCount}} brave crew members, chose **{{.LastWinnerLabel}}**.
+{{if .Vars.Lookup "flags.found_key"}}The key glows.{{end}}`))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	if !chapter.IsTemplate() {
+		t.Fatal("IsTemplate() = false, want true")
+	}
+
+	if !strings.Contains(chapter.Content, "You, the 0 brave crew members") {
+		t.Errorf("Content = %s, want it rendered with empty data", chapter.Content)
+	}
+
+	content, err := chapter.Render(TemplateData{
+		Vars:            State{"flags": map[string]any{"found_key": true}},
+		VoterCount:      12,
+		LastWinnerLabel: "the left tunnel",
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{"You, the 12 brave crew members, chose <strong>the left tunnel</strong>.", "The key glows."} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Render() = %s, want it to contain %q", content, want)
+		}
+	}
+}
+
+func TestChapterTemplateOptions(t *testing.T) {
+	literal, err := ParseMarkdown([]byte("---\nid: helm\nliteral: true\n---\n`{{ .Values.image }}`"))
+	if err != nil {
+		t.Fatalf("ParseMarkdown(literal) error = %v", err)
+	}
+
+	if literal.IsTemplate() || !strings.Contains(literal.Content, "{{ .Values.image }}") {
+		t.Errorf("literal chapter = %q, want its braces kept as written", literal.Content)
+	}
+
+	plain, err := ParseMarkdown([]byte("---\nid: plain\n---\n# Plain"))
+	if err != nil {
+		t.Fatalf("ParseMarkdown(plain) error = %v", err)
+	}
+
+	if plain.IsTemplate() {
+		t.Error("chapter without actions must not be a template")
+	}
+
+	if _, err := ParseMarkdown([]byte("---\nid: broken\n---\n{{if .Vars.x}}never closed")); err == nil {
+		t.Error("ParseMarkdown() with an unclosed action succeeded, want an error")
+	}
+}
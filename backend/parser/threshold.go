@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultChoice returns the ID of the choice that wins when a choice with a
+// threshold leads the vote without reaching it, or "" when there is none.
+func (m ChapterMetadata) DefaultChoice() string {
+	for _, choice := range m.Choices {
+		if choice.Default {
+			return choice.ID
+		}
+	}
+
+	return ""
+}
+
+// validateThresholds checks that thresholds are shares of the votes and that
+// a decision using them has a single default choice to fall back to.
+func (m ChapterMetadata) validateThresholds() error {
+	var thresholds, defaults int
+
+	for _, choice := range m.Choices {
+		if choice.Threshold < 0 || choice.Threshold > 1 {
+			return fmt.Errorf("choice '%s' has threshold %g outside 0 and 1", choice.ID, choice.Threshold)
+		}
+
+		if choice.Threshold > 0 {
+			thresholds++
+		}
+
+		if choice.Default {
+			if choice.Threshold > 0 {
+				return fmt.Errorf("default choice '%s' cannot have a threshold", choice.ID)
+			}
+
+			defaults++
+		}
+	}
+
+	switch {
+	case defaults > 1:
+		return errors.New("more than one default choice")
+	case thresholds > 0 && defaults == 0:
+		return errors.New("choices with a threshold need a default choice")
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package parser
+
+import "testing"
+
+func TestParseMarkdown_Threshold(t *testing.T) {
+	content := []byte(`---
+id: migrate
+type: decision
+choices:
+  - id: big-bang
+    label: Migrate everything tonight
+    next: chaos
+    threshold: 0.66
+  - id: canary
+    label: Start with a canary
+    next: calm
+    default: true
+---
+
+# Migrate?
+`)
+
+	chapter, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	m := chapter.Metadata
+	if m.Choices[0].Threshold != 0.66 || m.DefaultChoice() != "canary" {
+		t.Errorf("choices = %+v, want big-bang needing 0.66 and canary as the default", m.Choices)
+	}
+
+	if err := m.validateThresholds(); err != nil {
+		t.Errorf("validateThresholds() error = %v", err)
+	}
+}
+
+func TestValidateThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		choices []Choice
+		wantErr bool
+	}{
+		{"no thresholds", []Choice{{ID: "a"}, {ID: "b"}}, false},
+		{"default without thresholds", []Choice{{ID: "a"}, {ID: "b", Default: true}}, false},
+		{"threshold with default", []Choice{{ID: "a", Threshold: 0.5}, {ID: "b", Default: true}}, false},
+		{"threshold without default", []Choice{{ID: "a", Threshold: 0.5}, {ID: "b"}}, true},
+		{"threshold above 1", []Choice{{ID: "a", Threshold: 66}, {ID: "b", Default: true}}, true},
+		{"negative threshold", []Choice{{ID: "a", Threshold: -0.5}, {ID: "b", Default: true}}, true},
+		{"two defaults", []Choice{{ID: "a", Default: true}, {ID: "b", Default: true}}, true},
+		{"default with threshold", []Choice{{ID: "a", Threshold: 0.5, Default: true}, {ID: "b"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := ChapterMetadata{Choices: tt.choices}
+			if err := meta.validateThresholds(); (err != nil) != tt.wantErr {
+				t.Errorf("validateThresholds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
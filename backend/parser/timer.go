@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TimerModeAdaptive lets the server end a vote early once ballots stop coming
+// in, or extend it once while they are still climbing.
+const TimerModeAdaptive = "adaptive"
+
+// IsAdaptiveTimer reports whether the chapter's vote is paced by participation.
+func (m ChapterMetadata) IsAdaptiveTimer() bool {
+	return m.TimerMode == TimerModeAdaptive
+}
+
+// validateTimer checks that adaptive bounds are positive and contain the
+// nominal timer.
+func (m ChapterMetadata) validateTimer() error {
+	switch m.TimerMode {
+	case "":
+		return nil
+	case TimerModeAdaptive:
+	default:
+		return fmt.Errorf("unknown timer_mode '%s'", m.TimerMode)
+	}
+
+	if m.TimerMin <= 0 || m.TimerMax <= 0 {
+		return errors.New("adaptive timers need positive timer_min and timer_max")
+	}
+
+	if m.TimerMin > m.TimerMax {
+		return fmt.Errorf("timer_min %d is greater than timer_max %d", m.TimerMin, m.TimerMax)
+	}
+
+	if m.Timer != 0 && (m.Timer < m.TimerMin || m.Timer > m.TimerMax) {
+		return fmt.Errorf("timer %d is outside timer_min %d and timer_max %d", m.Timer, m.TimerMin, m.TimerMax)
+	}
+
+	return nil
+}
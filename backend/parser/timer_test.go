@@ -0,0 +1,54 @@
+package parser
+
+import "testing"
+
+func TestParseMarkdown_AdaptiveTimer(t *testing.T) {
+	content := []byte(`---
+id: vote
+type: decision
+timer: 45
+timer_mode: adaptive
+timer_min: 15
+timer_max: 90
+---
+
+# Vote
+`)
+
+	chapter, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	m := chapter.Metadata
+	if !m.IsAdaptiveTimer() || m.TimerMin != 15 || m.TimerMax != 90 {
+		t.Errorf("metadata = %+v, want an adaptive timer between 15 and 90", m)
+	}
+
+	if err := m.validateTimer(); err != nil {
+		t.Errorf("validateTimer() error = %v", err)
+	}
+}
+
+func TestValidateTimer(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    ChapterMetadata
+		wantErr bool
+	}{
+		{"fixed timer", ChapterMetadata{Timer: 30}, false},
+		{"adaptive without timer", ChapterMetadata{TimerMode: "adaptive", TimerMin: 10, TimerMax: 60}, false},
+		{"unknown mode", ChapterMetadata{TimerMode: "elastic"}, true},
+		{"missing bounds", ChapterMetadata{TimerMode: "adaptive", TimerMax: 60}, true},
+		{"inverted bounds", ChapterMetadata{TimerMode: "adaptive", TimerMin: 60, TimerMax: 10}, true},
+		{"timer outside bounds", ChapterMetadata{TimerMode: "adaptive", Timer: 120, TimerMin: 10, TimerMax: 60}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.meta.validateTimer(); (err != nil) != tt.wantErr {
+				t.Errorf("validateTimer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GraphFormats are the formats WriteGraph renders a story in.
+var GraphFormats = []string{"dot", "mermaid", "html"}
+
+// Node kinds of a visualized story.
+const (
+	nodeStory    = "story"
+	nodeDecision = "decision"
+	nodeChance   = "chance" // random and roll chapters
+	nodeEnding   = "ending"
+	nodeMissing  = "missing" // a target no chapter has
+)
+
+//go:embed visualize.html
+var graphPage string
+
+var graphTemplate = template.Must(template.New("graph").Parse(graphPage))
+
+// graphNode is a chapter as a visualization shows it.
+type graphNode struct {
+	ID          string `json:"id"`
+	Title       string `json:"title,omitempty"`
+	Question    string `json:"question,omitempty"`
+	Kind        string `json:"kind"`
+	Depth       int    `json:"depth"` // steps from the start chapter, -1 when unreachable
+	Start       bool   `json:"start,omitempty"`
+	Unreachable bool   `json:"unreachable,omitempty"`
+}
+
+// graphEdge is a way from one chapter to another, labelled with what leads
+// there, such as the choice's label.
+type graphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+}
+
+type storyGraph struct {
+	Title string      `json:"title"`
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+// WriteGraph renders the story graph to w as Graphviz DOT, a Mermaid
+// flowchart, or a self-contained interactive HTML page. Decisions, endings
+// and chapters the start cannot reach stand out, as do targets no chapter
+// has.
+func WriteGraph(w io.Writer, format, title, start string, chapters map[string]*Chapter) error {
+	graph := buildGraph(title, start, chapters)
+
+	switch format {
+	case "dot":
+		_, err := io.WriteString(w, graph.dot())
+
+		return err
+	case "mermaid":
+		_, err := io.WriteString(w, graph.mermaid())
+
+		return err
+	case "html":
+		data, err := json.Marshal(graph)
+		if err != nil {
+			return fmt.Errorf("failed to encode graph: %w", err)
+		}
+
+		return graphTemplate.Execute(w, map[string]any{"Title": title, "Graph": template.JS(data)}) //nolint:gosec // JSON from json.Marshal
+	default:
+		return fmt.Errorf("unknown graph format '%s', want one of %s", format, strings.Join(GraphFormats, ", "))
+	}
+}
+
+// buildGraph lists the chapters in outline order and the edges between them.
+func buildGraph(title, start string, chapters map[string]*Chapter) storyGraph {
+	graph := storyGraph{Title: title}
+	missing := map[string]bool{}
+
+	for _, act := range BuildOutline(start, chapters).Acts {
+		for _, chapter := range act.Chapters {
+			meta := chapters[chapter.ID].Metadata
+
+			kind := nodeStory
+			switch {
+			case chapter.Ending:
+				kind = nodeEnding
+			case chapter.Decision:
+				kind = nodeDecision
+			case meta.IsRandom() || meta.IsRoll():
+				kind = nodeChance
+			}
+
+			graph.Nodes = append(graph.Nodes, graphNode{
+				ID:          chapter.ID,
+				Title:       chapter.Title,
+				Question:    chapter.Question,
+				Kind:        kind,
+				Depth:       chapter.Depth,
+				Start:       chapter.ID == start,
+				Unreachable: chapter.Depth < 0,
+			})
+
+			for _, edge := range graphEdges(chapter.ID, meta) {
+				if _, ok := chapters[edge.To]; !ok {
+					missing[edge.To] = true
+				}
+
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(missing)) {
+		graph.Nodes = append(graph.Nodes, graphNode{ID: id, Kind: nodeMissing, Depth: -1})
+	}
+
+	return graph
+}
+
+// graphEdges lists the ways out of a chapter with labels for people, where
+// Edges has descriptions for messages.
+func graphEdges(id string, meta ChapterMetadata) []graphEdge {
+	var out []graphEdge
+
+	add := func(to, label string) {
+		if to != "" {
+			out = append(out, graphEdge{From: id, To: to, Label: label})
+		}
+	}
+
+	for _, condition := range meta.Conditions {
+		add(condition.Next, "if "+condition.If)
+	}
+
+	for _, choice := range meta.Choices {
+		label := choice.Label
+		if label == "" {
+			label = choice.ID
+		}
+
+		add(choice.Next, label)
+	}
+
+	if meta.IsRandom() {
+		for _, outcome := range meta.Outcomes {
+			label := outcome.Label
+			if label == "" {
+				label = outcome.ID
+			}
+
+			add(outcome.Next, label)
+		}
+	}
+
+	if meta.IsRoll() {
+		add(meta.Success, "success")
+		add(meta.Failure, "failure")
+	}
+
+	label := ""
+	if len(meta.Conditions) > 0 {
+		label = "otherwise"
+	}
+
+	add(meta.Next, label)
+
+	return out
+}
+
+// label is what a node shows: its title, or its ID without one.
+func (n graphNode) label() string {
+	if n.Title == "" {
+		return n.ID
+	}
+
+	return n.Title
+}
+
+func (g storyGraph) dot() string {
+	var b strings.Builder
+
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+
+	b.WriteString("digraph story {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  label=" + quote(g.Title) + ";\n")
+	b.WriteString(`  node [shape=box, style="rounded,filled", fillcolor="#ffffff", fontname="Helvetica"];` + "\n")
+	b.WriteString(`  edge [fontname="Helvetica", fontsize=10];` + "\n\n")
+
+	for _, node := range g.Nodes {
+		attrs := []string{"label=" + quote(node.label())}
+
+		switch node.Kind {
+		case nodeDecision:
+			attrs = append(attrs, "shape=diamond", `fillcolor="#fff3bf"`)
+		case nodeChance:
+			attrs = append(attrs, "shape=hexagon", `fillcolor="#e5dbff"`)
+		case nodeEnding:
+			attrs = append(attrs, "shape=doubleoctagon", `fillcolor="#ffc9c9"`)
+		case nodeMissing:
+			attrs = append(attrs, "shape=box", `style="dashed"`, `color="#e03131"`, `fontcolor="#e03131"`)
+		}
+
+		if node.Start {
+			attrs = append(attrs, "penwidth=3")
+		}
+
+		if node.Unreachable {
+			attrs = append(attrs, `style="rounded,filled,dashed"`, `color="#868e96"`, `fontcolor="#868e96"`)
+		}
+
+		b.WriteString("  " + quote(node.ID) + " [" + strings.Join(attrs, ", ") + "];\n")
+	}
+
+	b.WriteString("\n")
+
+	for _, edge := range g.Edges {
+		b.WriteString("  " + quote(edge.From) + " -> " + quote(edge.To))
+
+		if edge.Label != "" {
+			b.WriteString(" [label=" + quote(edge.Label) + "]")
+		}
+
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func (g storyGraph) mermaid() string {
+	var b strings.Builder
+
+	// Mermaid has no escapes inside quoted labels, only entities
+	text := strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace
+
+	// chapter IDs may contain dashes, which Mermaid reads as edges
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	b.WriteString("flowchart LR\n")
+
+	for _, node := range g.Nodes {
+		label := `"` + text(node.label()) + `"`
+
+		switch node.Kind {
+		case nodeDecision:
+			label = "{" + label + "}"
+		case nodeChance:
+			label = "{{" + label + "}}"
+		case nodeEnding:
+			label = "([" + label + "])"
+		default:
+			label = "[" + label + "]"
+		}
+
+		b.WriteString("  " + ids[node.ID] + label + "\n")
+	}
+
+	for _, edge := range g.Edges {
+		b.WriteString("  " + ids[edge.From] + " -->")
+
+		if edge.Label != "" {
+			b.WriteString(`|"` + text(edge.Label) + `"|`)
+		}
+
+		b.WriteString(" " + ids[edge.To] + "\n")
+	}
+
+	b.WriteString("  classDef decision fill:#fff3bf,stroke:#f08c00\n")
+	b.WriteString("  classDef chance fill:#e5dbff,stroke:#7048e8\n")
+	b.WriteString("  classDef ending fill:#ffc9c9,stroke:#e03131\n")
+	b.WriteString("  classDef missing fill:#fff,stroke:#e03131,stroke-dasharray:5 5,color:#e03131\n")
+	b.WriteString("  classDef unreachable fill:#f1f3f5,stroke:#868e96,stroke-dasharray:5 5,color:#868e96\n")
+	b.WriteString("  classDef start stroke-width:4px\n")
+
+	for _, node := range g.Nodes {
+		var classes []string
+
+		if node.Kind != nodeStory {
+			classes = append(classes, node.Kind)
+		}
+
+		if node.Unreachable {
+			classes = append(classes, "unreachable")
+		}
+
+		if node.Start {
+			classes = append(classes, "start")
+		}
+
+		for _, class := range classes {
+			b.WriteString("  class " + ids[node.ID] + " " + class + "\n")
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGraph(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"story.yaml": "start: intro",
+		"intro.md":   "---\nid: intro\ntype: story\nnext: fork\n---\n# The \"Beginning\"",
+		"fork.md": `---
+id: fork
+type: decision
+choices:
+  - id: left
+    label: Go left
+    next: end
+  - id: right
+    next: nowhere
+---
+# Fork`,
+		"end.md":   "---\nid: end\ntype: terminal\n---\n# The end",
+		"attic.md": "---\nid: attic\ntype: story\nnext: end\n---\n# Attic",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	chapters, err := engine.AllChapters()
+	if err != nil {
+		t.Fatalf("failed to load chapters: %v", err)
+	}
+
+	render := func(format string) string {
+		t.Helper()
+
+		var b strings.Builder
+		if err := WriteGraph(&b, format, "Heist <1>", "intro", chapters); err != nil {
+			t.Fatalf("WriteGraph(%s) failed: %v", format, err)
+		}
+
+		return b.String()
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"dot", []string{
+			`"intro" [label="The \"Beginning\"", penwidth=3];`,
+			`"fork" [label="Fork", shape=diamond`,
+			`"end" [label="The end", shape=doubleoctagon`,
+			`"attic" [label="Attic", style="rounded,filled,dashed"`,
+			`"nowhere" [label="nowhere", shape=box, style="dashed"`,
+			`"fork" -> "end" [label="Go left"];`,
+			`"fork" -> "nowhere" [label="right"];`,
+			`"intro" -> "fork";`,
+		}},
+		{"mermaid", []string{
+			`n0["The #quot;Beginning#quot;"]`,
+			`n1{"Fork"}`,
+			`n2(["The end"])`,
+			`n1 -->|"Go left"| n2`,
+			"class n0 start",
+			"class n1 decision",
+			"class n3 unreachable",
+			"class n4 missing",
+		}},
+		{"html", []string{
+			"<title>Heist &lt;1&gt; - story graph</title>",
+			`{"id":"attic","title":"Attic","kind":"story","depth":-1,"unreachable":true}`,
+			`{"from":"fork","to":"end","label":"Go left"}`,
+		}},
+	}
+
+	for _, tt := range tests {
+		got := render(tt.format)
+
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s graph does not contain %q:\n%s", tt.format, want, got)
+			}
+		}
+	}
+
+	if err := WriteGraph(&strings.Builder{}, "svg", "", "intro", chapters); err == nil {
+		t.Error("unknown format did not fail")
+	}
+}
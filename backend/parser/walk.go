@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// defaultWalkSteps is how many chapters a walk may visit before it is taken
+// to be stuck in a loop.
+const defaultWalkSteps = 1000
+
+// WalkOptions configures StoryEngine.Walk.
+type WalkOptions struct {
+	Runs     int    // walks from the start chapter
+	Seed     uint64 // seeds every random pick, so a report can be reproduced
+	MaxSteps int    // chapters a walk may visit before it counts as stuck, 0 for the default
+
+	// Script holds choice IDs to pick at decisions, in the order the walk
+	// reaches them. Every run follows it and picks at random once it is used
+	// up.
+	Script []string
+
+	// Enabled reports whether a feature is on, as chapters gated behind a
+	// disabled feature can't be reached. Nil enables none.
+	Enabled func(feature string) bool
+}
+
+// WalkReport sums up the walks through a story.
+type WalkReport struct {
+	Runs      int
+	Completed int            // runs that reached an ending
+	Endings   map[string]int // ending chapter ID -> runs that reached it
+	MinSteps  int            // chapters visited by the shortest completed run
+	MaxSteps  int            // chapters visited by the longest completed run
+	MeanSteps float64
+	Unvisited []string       // chapters no run visited, sorted
+	Errors    map[string]int // problem -> runs that ran into it
+}
+
+// ReadScript reads a scripted path: one choice ID per line. Blank lines and
+// lines starting with # are skipped.
+func ReadScript(r io.Reader) ([]string, error) {
+	var script []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		script = append(script, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	return script, nil
+}
+
+// Walk plays the story from the start chapter the given number of times the
+// way the server would, without an audience: decisions pick a choice the
+// inventory allows, following the script or at random, and random and roll
+// chapters roll. It reports how long the runs were, which endings they
+// reached, which chapters none of them visited and what went wrong on the
+// way, so large stories can be smoke-tested quickly.
+func (se *StoryEngine) Walk(opts WalkOptions) WalkReport {
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = defaultWalkSteps
+	}
+
+	if opts.Enabled == nil {
+		opts.Enabled = func(feature string) bool { return feature == "" }
+	}
+
+	report := WalkReport{Runs: opts.Runs, Endings: map[string]int{}, Errors: map[string]int{}}
+	visited := map[string]bool{}
+	rng := rand.New(rand.NewPCG(opts.Seed, 0)) //nolint:gosec // walks must be reproducible from the seed
+
+	total := 0
+
+	for range opts.Runs {
+		steps, ending, err := se.walk(opts, rng, visited)
+		if err != nil {
+			report.Errors[err.Error()]++
+
+			continue
+		}
+
+		report.Completed++
+		report.Endings[ending]++
+		total += steps
+
+		if report.MinSteps == 0 || steps < report.MinSteps {
+			report.MinSteps = steps
+		}
+
+		report.MaxSteps = max(report.MaxSteps, steps)
+	}
+
+	if report.Completed > 0 {
+		report.MeanSteps = float64(total) / float64(report.Completed)
+	}
+
+	for id := range se.Story.Nodes {
+		if !visited[id] {
+			report.Unvisited = append(report.Unvisited, id)
+		}
+	}
+
+	slices.Sort(report.Unvisited)
+
+	return report
+}
+
+// walk plays the story once and returns how many chapters it visited and
+// the ending it reached.
+func (se *StoryEngine) walk(opts WalkOptions, rng *rand.Rand, visited map[string]bool) (int, string, error) {
+	state := State{}
+	script := opts.Script
+	id, from := se.Story.Flow.Start, ""
+
+	for steps := 1; steps <= opts.MaxSteps; steps++ {
+		chapter, err := se.GetChapter(id)
+		if err != nil {
+			if from != "" {
+				err = fmt.Errorf("chapter '%s' leads to '%s': %w", from, id, err)
+			}
+
+			return steps, "", err
+		}
+
+		meta := chapter.Metadata
+		if !opts.Enabled(meta.RequiresFeature) {
+			return steps, "", fmt.Errorf("chapter '%s' requires disabled feature '%s'", id, meta.RequiresFeature)
+		}
+
+		visited[id] = true
+		state.Enter(meta)
+
+		if chapter.IsTemplate() {
+			if _, err := chapter.Render(TemplateData{Vars: state}); err != nil {
+				return steps, "", fmt.Errorf("chapter '%s' failed to render: %w", id, err)
+			}
+		}
+
+		if meta.IsEnding() {
+			return steps, id, nil
+		}
+
+		var next string
+
+		switch {
+		case meta.Type == "decision":
+			var choice Choice
+
+			choice, script, err = se.pick(meta, state, script, rng, opts.Enabled)
+			if err != nil {
+				return steps, "", err
+			}
+
+			state.Award(choice.Points)
+
+			next = choice.Next
+		case meta.IsRandom():
+			roll, err := PickOutcome(meta.Outcomes, rng.Uint64())
+			if err != nil {
+				return steps, "", fmt.Errorf("chapter '%s' failed to roll: %w", id, err)
+			}
+
+			next = roll.Outcome.Next
+		case meta.IsRoll():
+			roll, err := meta.RollDice(state, rng.Uint64())
+			if err != nil {
+				return steps, "", fmt.Errorf("chapter '%s' failed to roll: %w", id, err)
+			}
+
+			next = roll.Next
+		default:
+			if next, err = ResolveNext(meta, state); err != nil {
+				return steps, "", fmt.Errorf("chapter '%s' failed to resolve next: %w", id, err)
+			}
+		}
+
+		if next == "" {
+			return steps, "", fmt.Errorf("chapter '%s' is a dead end", id)
+		}
+
+		id, from = next, id
+	}
+
+	return opts.MaxSteps, "", fmt.Errorf("no ending within %d chapters", opts.MaxSteps)
+}
+
+// pick chooses at a decision among the choices the inventory allows and
+// that lead to enabled chapters: the next choice of the script, or one at
+// random when the script is used up. It returns the rest of the script.
+func (se *StoryEngine) pick(meta ChapterMetadata, state State, script []string, rng *rand.Rand, enabled func(string) bool) (Choice, []string, error) {
+	available := slices.DeleteFunc(slices.Clone(meta.Choices), func(choice Choice) bool {
+		if !state.HasItems(choice.Requires) {
+			return true
+		}
+
+		target, err := se.GetChapter(choice.Next)
+
+		return err == nil && !enabled(target.Metadata.RequiresFeature)
+	})
+
+	if len(script) > 0 {
+		for _, choice := range available {
+			if choice.ID == script[0] {
+				return choice, script[1:], nil
+			}
+		}
+
+		return Choice{}, nil, fmt.Errorf("scripted choice '%s' is not available in chapter '%s'", script[0], meta.ID)
+	}
+
+	if len(available) == 0 {
+		return Choice{}, nil, fmt.Errorf("chapter '%s' has no available choice", meta.ID)
+	}
+
+	return available[rng.IntN(len(available))], nil, nil
+}
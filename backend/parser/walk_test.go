@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"story.yaml": "start: intro",
+		"intro.md":   "---\nid: intro\ntype: story\nnext: fork\ngrants: [key]\n---\n# Intro",
+		"fork.md": `---
+id: fork
+type: decision
+choices:
+  - id: left
+    next: end
+  - id: right
+    next: vault
+    requires: [key]
+  - id: secret
+    next: treasure
+    requires: [gem]
+---
+# Fork`,
+		"vault.md": `---
+id: vault
+type: random
+outcomes:
+  - id: lucky
+    next: end
+  - id: unlucky
+    next: broken
+---
+# Vault`,
+		"broken.md":   "---\nid: broken\ntype: story\nnext: nowhere\n---\n# Broken",
+		"treasure.md": "---\nid: treasure\ntype: terminal\n---\n# Treasure",
+		"end.md":      "---\nid: end\ntype: terminal\n---\n# The end",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewStoryEngine(filepath.Join(tmpDir, "story.yaml"), tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	report := engine.Walk(WalkOptions{Runs: 200, Seed: 1})
+
+	if report.Completed+report.Errors["chapter 'broken' leads to 'nowhere': node not found: nowhere"] != 200 {
+		t.Errorf("report = %+v, want every run to end or fail on the missing chapter", report)
+	}
+
+	if report.Endings["end"] != report.Completed || report.MinSteps != 3 || report.MaxSteps != 4 {
+		t.Errorf("report = %+v, want runs of 3 or 4 chapters ending at end", report)
+	}
+
+	if !slices.Equal(report.Unvisited, []string{"treasure"}) {
+		t.Errorf("unvisited = %v, want the chapter behind the missing gem", report.Unvisited)
+	}
+
+	if again := engine.Walk(WalkOptions{Runs: 200, Seed: 1}); again.Completed != report.Completed {
+		t.Errorf("walks with the same seed completed %d and %d times", report.Completed, again.Completed)
+	}
+
+	scripted := engine.Walk(WalkOptions{Runs: 20, Seed: 1, Script: []string{"right"}})
+	if scripted.MinSteps != 4 {
+		t.Errorf("scripted report = %+v, want every run through the vault", scripted)
+	}
+
+	locked := engine.Walk(WalkOptions{Runs: 5, Script: []string{"secret"}})
+	if locked.Errors["scripted choice 'secret' is not available in chapter 'fork'"] != 5 {
+		t.Errorf("errors = %v, want the locked scripted choice reported", locked.Errors)
+	}
+
+	script, err := ReadScript(strings.NewReader("# first fork\nright\n\n  left  \n"))
+	if err != nil || !slices.Equal(script, []string{"right", "left"}) {
+		t.Errorf("ReadScript() = %v, %v", script, err)
+	}
+}
@@ -0,0 +1,17 @@
+package server
+
+import "github.com/skarlso/kube_adventures/voting/backend/parser"
+
+// AbstainChoice is the ID of the choice voters abstain with, which every vote
+// offers with WithAbstain. Abstaining voters count as taking part, but never
+// towards a choice's votes or the winner, so the presenter can tell a shrug
+// from an audience that is not there.
+const AbstainChoice = "abstain"
+
+// abstainChoice is the choice appended to votes when abstaining is allowed.
+var abstainChoice = parser.Choice{ID: AbstainChoice, Label: "Abstain", Description: "Sit this one out"}
+
+// withAbstain returns the choices of a vote with the abstain choice last.
+func withAbstain(choices []parser.Choice) []parser.Choice {
+	return append(choices[:len(choices):len(choices)], abstainChoice)
+}
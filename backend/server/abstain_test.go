@@ -0,0 +1,92 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestAbstainCountedApart(t *testing.T) {
+	vm := NewVoteManager()
+	vm.abstain = true
+
+	var winner string
+
+	done := make(chan struct{})
+
+	choices := []parser.Choice{{ID: "a", Label: "A"}, {ID: "b", Label: "B"}}
+	vm.StartVotingWithChoices("q", []string{"a", "b"}, choices, "", time.Minute, func(_ map[string]int, w string) {
+		winner = w
+		close(done)
+	})
+
+	vm.mu.RLock()
+	shown := vm.started.Payload["choices"].([]parser.Choice)
+	vm.mu.RUnlock()
+
+	if len(shown) != 3 || shown[2].ID != AbstainChoice {
+		t.Fatalf("choices = %v, want abstain offered last", shown)
+	}
+
+	vm.SubmitVote("voter-1", "b")
+	vm.SubmitVote("voter-2", AbstainChoice)
+	vm.SubmitVote("voter-3", AbstainChoice)
+
+	// changing from abstaining to a choice counts the ballot there
+	vm.SubmitVote("voter-4", AbstainChoice)
+	vm.SubmitVote("voter-4", "a")
+
+	results := vm.GetResults("q")
+	if _, ok := results[AbstainChoice]; ok || results["a"] != 1 || results["b"] != 1 {
+		t.Errorf("results = %v, want one vote each and no abstentions", results)
+	}
+
+	vm.mu.RLock()
+	abstained := vm.vote.abstentions()
+	vm.mu.RUnlock()
+
+	if abstained != 2 {
+		t.Errorf("abstentions = %d, want 2", abstained)
+	}
+
+	vm.EndVoting()
+	<-done
+
+	if winner == AbstainChoice {
+		t.Errorf("winner = %s, abstaining must never win", winner)
+	}
+
+	stats, ok := vm.LastStats("q")
+	if !ok {
+		t.Fatal("no statistics for the vote")
+	}
+
+	if stats.Voters != 4 || stats.Abstained != 2 {
+		t.Errorf("stats = %d voters, %d abstained, want 4 and 2", stats.Voters, stats.Abstained)
+	}
+}
+
+func TestAbstainKeepsStoryChoice(t *testing.T) {
+	vm := NewVoteManager()
+	vm.abstain = true
+
+	// a story can name a choice abstain itself, which then counts as any other
+	choices := []parser.Choice{{ID: "go"}, {ID: AbstainChoice}}
+	vm.StartVotingWithChoices("q", []string{"go", AbstainChoice}, choices, "", time.Minute, nil)
+	defer vm.EndVoting()
+
+	vm.mu.RLock()
+	shown := vm.started.Payload["choices"].([]parser.Choice)
+	vm.mu.RUnlock()
+
+	if len(shown) != 2 {
+		t.Errorf("choices = %v, want the story's choices only", shown)
+	}
+
+	vm.SubmitVote("voter-1", AbstainChoice)
+
+	if results := vm.GetResults("q"); results[AbstainChoice] != 1 {
+		t.Errorf("results = %v, want the story's abstain choice counted", results)
+	}
+}
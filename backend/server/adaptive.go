@@ -0,0 +1,136 @@
+package server
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// defaultQuietPeriod is how long a vote may go without a new voter before
+// participation counts as having plateaued.
+const defaultQuietPeriod = 5 * time.Second
+
+// AdaptiveTimer paces a vote by participation. The vote ends early once no
+// new voter has joined for Quiet (but never before Min), and is extended to
+// Max once when the nominal time runs out while voters are still arriving.
+type AdaptiveTimer struct {
+	Min   time.Duration
+	Max   time.Duration
+	Quiet time.Duration
+}
+
+// newAdaptiveTimer reads the adaptive bounds of a chapter.
+func newAdaptiveTimer(meta parser.ChapterMetadata) AdaptiveTimer {
+	return AdaptiveTimer{
+		Min:   time.Duration(meta.TimerMin) * time.Second,
+		Max:   time.Duration(meta.TimerMax) * time.Second,
+		Quiet: defaultQuietPeriod,
+	}
+}
+
+// clamp keeps the nominal duration within the adaptive bounds.
+func (t AdaptiveTimer) clamp(d time.Duration) time.Duration {
+	return min(max(d, t.Min), t.Max)
+}
+
+type paceDecision int
+
+const (
+	paceWait paceDecision = iota
+	paceExtend
+	paceEnd
+)
+
+// decide returns what to do with a vote started at startedAt whose most recent
+// new voter arrived at lastBallot.
+func (t AdaptiveTimer) decide(now, startedAt, lastBallot, deadline time.Time, voters int, extended bool) paceDecision {
+	quiet := now.Sub(lastBallot) >= t.Quiet
+
+	if voters > 0 && quiet && now.Sub(startedAt) >= t.Min {
+		return paceEnd
+	}
+
+	if now.Before(deadline) {
+		return paceWait
+	}
+
+	if voters > 0 && !quiet && !extended && deadline.Before(startedAt.Add(t.Max)) {
+		return paceExtend
+	}
+
+	return paceEnd
+}
+
+// PaceVoting replaces the fixed timer of the active vote with an adaptive one.
+func (vm *VoteManager) PaceVoting(t AdaptiveTimer) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if !vm.votingActive {
+		return
+	}
+
+	vm.vote.stop()
+
+	deadline := vm.startedAt.Add(t.clamp(vm.timerDuration))
+	vm.deadline = deadline
+
+	go vm.pace(vm.round, vm.startedAt, deadline, t)
+}
+
+// pace checks participation until the vote of the given round ends.
+func (vm *VoteManager) pace(round uint64, startedAt, deadline time.Time, t AdaptiveTimer) {
+	ticker := time.NewTicker(t.Quiet / 5)
+	defer ticker.Stop()
+
+	extended := false
+
+	for now := range ticker.C {
+		if vm.paceStep(round, now, startedAt, &deadline, &extended, t) {
+			return
+		}
+	}
+}
+
+// paceStep applies one pacing decision and reports whether pacing is done.
+func (vm *VoteManager) paceStep(round uint64, now, startedAt time.Time, deadline *time.Time, extended *bool, t AdaptiveTimer) bool {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if !vm.votingActive || vm.round != round {
+		return true
+	}
+
+	switch t.decide(now, startedAt, vm.lastBallotAt, *deadline, len(vm.vote.voters), *extended) {
+	case paceWait:
+		return false
+	case paceExtend:
+		*extended = true
+		*deadline = startedAt.Add(t.Max)
+		vm.deadline = *deadline
+
+		slog.Info("Voters still arriving, extending vote", "question_id", vm.vote.id, "voters", len(vm.vote.voters), "duration", t.Max)
+
+		vm.broadcast <- &Message{
+			Type: "timer_adjusted",
+			Payload: map[string]any{
+				"question_id": vm.vote.id,
+				"duration":    t.Max.Seconds(),
+				"remaining":   math.Ceil(deadline.Sub(now).Seconds()),
+				"reason":      "extended",
+			},
+		}
+
+		return false
+	default:
+		if now.Before(*deadline) {
+			slog.Info("Votes plateaued, ending vote early", "question_id", vm.vote.id, "voters", len(vm.vote.voters), "elapsed", now.Sub(startedAt).Round(time.Second))
+		}
+
+		vm.endVoting()
+
+		return true
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimerDecide(t *testing.T) {
+	timer := AdaptiveTimer{Min: 10 * time.Second, Max: 60 * time.Second, Quiet: 5 * time.Second}
+	start := time.Now()
+	deadline := start.Add(30 * time.Second)
+
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tests := []struct {
+		name       string
+		now        time.Duration
+		lastBallot time.Duration
+		voters     int
+		extended   bool
+		want       paceDecision
+	}{
+		{"votes arriving", 8 * time.Second, 7 * time.Second, 4, false, paceWait},
+		{"plateau before min", 8 * time.Second, time.Second, 4, false, paceWait},
+		{"plateau after min", 12 * time.Second, 6 * time.Second, 4, false, paceEnd},
+		{"nobody voted yet", 20 * time.Second, 0, 0, false, paceWait},
+		{"deadline without voters", 30 * time.Second, 0, 0, false, paceEnd},
+		{"deadline while climbing", 30 * time.Second, 29 * time.Second, 4, false, paceExtend},
+		{"deadline after extension", 30 * time.Second, 29 * time.Second, 4, true, paceEnd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timer.decide(at(tt.now), start, at(tt.lastBallot), deadline, tt.voters, tt.extended)
+			if got != tt.want {
+				t.Errorf("decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaceVoting_EndsEarlyOnPlateau(t *testing.T) {
+	vm := NewVoteManager()
+
+	vm.StartVoting("q", []string{"a", "b"}, time.Minute, nil)
+	vm.PaceVoting(AdaptiveTimer{Min: 50 * time.Millisecond, Max: 2 * time.Minute, Quiet: 50 * time.Millisecond})
+
+	if err := vm.SubmitVote("voter-1", "a"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for vm.IsVotingActive() {
+		if time.Now().After(deadline) {
+			t.Fatal("vote did not end after participation plateaued")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPaceVoting_ExtendsOnceWhileClimbing(t *testing.T) {
+	vm := NewVoteManager()
+
+	vm.StartVoting("q", []string{"a", "b"}, 100*time.Millisecond, nil)
+	vm.PaceVoting(AdaptiveTimer{Min: 50 * time.Millisecond, Max: 300 * time.Millisecond, Quiet: 100 * time.Millisecond})
+
+	// keep new voters arriving past the nominal 100ms
+	for i := range 8 {
+		_ = vm.SubmitVote("voter-"+string(rune('a'+i)), "a")
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if !vm.IsVotingActive() {
+		t.Fatal("vote ended while voters were still arriving")
+	}
+
+	timeout := time.After(2 * time.Second)
+	extended := false
+
+	for {
+		select {
+		case message := <-vm.broadcast:
+			switch message.Type {
+			case "timer_adjusted":
+				if extended {
+					t.Error("vote was extended more than once")
+				}
+
+				extended = message.Payload["reason"] == "extended"
+			case "voting_ended":
+				if !extended {
+					t.Error("vote ended without being extended")
+				}
+
+				return
+			}
+		case <-timeout:
+			t.Fatal("extended vote never ended")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Networks are the addresses presenters may connect from. An empty list
+// allows every address.
+type Networks []netip.Prefix
+
+// ParseNetworks parses a comma-separated list of addresses and CIDR ranges
+// such as "10.0.40.0/24,192.168.1.17".
+func ParseNetworks(list string) (Networks, error) {
+	var networks Networks
+
+	for entry := range strings.SplitSeq(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+			}
+
+			networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", entry, err)
+		}
+
+		networks = append(networks, prefix.Masked())
+	}
+
+	return networks, nil
+}
+
+// Allows reports whether addr belongs to one of the networks. IPv4 addresses
+// written as IPv6, as dual-stack listeners report them, match IPv4 ranges.
+func (n Networks) Allows(addr string) bool {
+	if len(n) == 0 {
+		return true
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+
+	ip = ip.Unmap()
+
+	for _, prefix := range n {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fromPresenterNetwork reports whether the request comes from an address
+// presenters may use. It goes by the address of the connection, so behind a
+// reverse proxy list the proxy's address.
+func (s *Server) fromPresenterNetwork(r *http.Request) bool {
+	return s.allowedNetworks.Allows(clientAddr(r))
+}
+
+// outsidePresenterNetworks answers 403 Forbidden to requests from outside
+// the presenter networks, before they get to try any credentials, and
+// reports whether it did.
+func (s *Server) outsidePresenterNetworks(w http.ResponseWriter, r *http.Request) bool {
+	if s.fromPresenterNetwork(r) {
+		return false
+	}
+
+	requestLogger(r).Warn("Presenter access from outside the allowed networks", "remote", r.RemoteAddr)
+	http.Error(w, "presenter access is not allowed from this address", http.StatusForbidden)
+
+	return true
+}
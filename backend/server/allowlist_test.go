@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks(" 10.0.40.7/24, 192.168.1.17,fd00::/8 ,")
+	if err != nil {
+		t.Fatalf("ParseNetworks() error = %v", err)
+	}
+
+	tests := map[string]bool{
+		"10.0.40.1":          true,
+		"10.0.41.1":          false,
+		"192.168.1.17":       true,
+		"192.168.1.18":       false,
+		"::ffff:10.0.40.200": true,
+		"fd12::1":            true,
+		"2001:db8::1":        false,
+		"not-an-address":     false,
+	}
+
+	for addr, want := range tests {
+		if got := networks.Allows(addr); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", addr, got, want)
+		}
+	}
+
+	if !(Networks(nil)).Allows("203.0.113.9") {
+		t.Error("an empty allow-list should allow every address")
+	}
+
+	for _, list := range []string{"10.0.0.0/33", "podium", "10.0.0"} {
+		if _, err := ParseNetworks(list); err == nil {
+			t.Errorf("ParseNetworks(%q) succeeded, want an error", list)
+		}
+	}
+}
+
+func TestPresenterNetworks(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "test-secret-123"
+
+	networks, err := ParseNetworks("10.0.40.0/24")
+	if err != nil {
+		t.Fatalf("ParseNetworks() error = %v", err)
+	}
+
+	WithPresenterNetworks(networks)(server)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		remote   string
+		wantCode int
+	}{
+		{"podium lists clients", "GET", "/api/v1/admin/clients", "10.0.40.12:5000", http.StatusOK},
+		{"audience advances", "POST", "/api/v1/advance", "10.0.50.12:5000", http.StatusForbidden},
+		{"audience opens the presenter view", "GET", "/presenter/", "10.0.50.12:5000", http.StatusForbidden},
+		{"audience presses a control button", "GET", "/api/v1/control/next?token=test-secret-123", "10.0.50.12:5000", http.StatusForbidden},
+		{"audience logs in", "POST", "/api/v1/login", "10.0.50.12:5000", http.StatusForbidden},
+		{"audience reads the chapter", "GET", "/api/v1/chapter/current", "10.0.50.12:5000", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("Authorization", "Bearer test-secret-123")
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/chapter/current?notes=true", nil)
+	req.RemoteAddr = "10.0.50.12:5000"
+	req.Header.Set("Authorization", "Bearer test-secret-123")
+
+	if server.wantsNotes(req) {
+		t.Error("speaker notes were shown outside the presenter networks")
+	}
+}
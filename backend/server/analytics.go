@@ -0,0 +1,240 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// QuestionStats is how the audience took part in one vote.
+type QuestionStats struct {
+	QuestionID      string         `json:"question_id"`
+	Question        string         `json:"question,omitempty"`
+	StartedAt       time.Time      `json:"started_at"`
+	Duration        float64        `json:"duration"`      // seconds the vote was open
+	Audience        int            `json:"audience"`      // most voters connected while the vote was open
+	Voters          int            `json:"voters"`        // voters that cast a ballot
+	Abstained       int            `json:"abstained"`     // voters among them that abstained
+	Participation   float64        `json:"participation"` // share of the audience that voted, 0 to 1
+	TimeToFirstVote *float64       `json:"time_to_first_vote,omitempty"`
+	Changes         int            `json:"changes"`     // ballots changed to another choice
+	ChangeRate      float64        `json:"change_rate"` // changes per voter
+	Winner          string         `json:"winner,omitempty"`
+	Results         map[string]int `json:"results,omitempty"` // choiceID -> votes
+	Margin          int            `json:"margin"`            // votes between the winner and the runner-up
+	MarginPercent   float64        `json:"margin_percent"`    // the margin in percentage points of all votes
+
+	firstBallot time.Time
+}
+
+// Analytics sums up the votes of a session, so speakers learn which
+// decisions engaged the audience most.
+type Analytics struct {
+	Questions     []QuestionStats `json:"questions"`
+	Participation float64         `json:"participation"`           // average over the votes
+	MostEngaging  string          `json:"most_engaging,omitempty"` // question with the highest participation
+	Closest       string          `json:"closest,omitempty"`       // question decided by the smallest margin
+	Chapters      []ChapterDwell  `json:"chapters"`                // time spent at each chapter of the current run
+}
+
+// ChapterDwell is how long the current run has spent at a chapter, to
+// calibrate the pacing of the story against a talk slot.
+type ChapterDwell struct {
+	ID      string  `json:"id"`
+	Title   string  `json:"title,omitempty"`
+	Seconds float64 `json:"seconds"` // every visit together, including the time so far at the current chapter
+}
+
+// trackStart begins the statistics of the vote just started. Callers must
+// hold vm.mu.
+func (vm *VoteManager) trackStart(question string) {
+	vm.tracked = &QuestionStats{
+		QuestionID: vm.vote.id,
+		Question:   question,
+		StartedAt:  vm.startedAt,
+		Audience:   vm.presence().Voters,
+	}
+}
+
+// trackAudience notes how many voters are connected during the vote.
+// Callers must hold vm.mu.
+func (vm *VoteManager) trackAudience() {
+	if vm.votingActive && vm.tracked != nil {
+		vm.tracked.Audience = max(vm.tracked.Audience, vm.presence().Voters)
+	}
+}
+
+// trackBallot counts a ballot of the current vote, changed when the voter had
+// picked another choice before. Callers must hold vm.mu.
+func (vm *VoteManager) trackBallot(changed bool) {
+	if vm.tracked == nil {
+		return
+	}
+
+	if vm.tracked.firstBallot.IsZero() {
+		vm.tracked.firstBallot = time.Now()
+	}
+
+	if changed {
+		vm.tracked.Changes++
+	}
+}
+
+// trackEnd finishes the statistics of the vote that just ended. Callers must
+// hold vm.mu.
+func (vm *VoteManager) trackEnd(results map[string]int, winner string) {
+	stats := vm.tracked
+	if stats == nil {
+		return
+	}
+
+	vm.tracked = nil
+
+	stats.Duration = time.Since(stats.StartedAt).Seconds()
+	stats.Voters = len(vm.vote.voters)
+	stats.Abstained = vm.vote.abstentions()
+	stats.Audience = max(stats.Audience, stats.Voters)
+	stats.Winner = winner
+	stats.Results = maps.Clone(results)
+
+	if stats.Audience > 0 {
+		stats.Participation = float64(stats.Voters) / float64(stats.Audience)
+	}
+
+	if stats.Voters > 0 {
+		stats.ChangeRate = float64(stats.Changes) / float64(stats.Voters)
+	}
+
+	if !stats.firstBallot.IsZero() {
+		seconds := stats.firstBallot.Sub(stats.StartedAt).Seconds()
+		stats.TimeToFirstVote = &seconds
+	}
+
+	counts := slices.Sorted(func(yield func(int) bool) {
+		for _, votes := range results {
+			if !yield(votes) {
+				return
+			}
+		}
+	})
+	slices.Reverse(counts)
+
+	total := 0
+	for _, votes := range counts {
+		total += votes
+	}
+
+	switch {
+	case len(counts) >= 2:
+		stats.Margin = counts[0] - counts[1]
+	case len(counts) == 1:
+		stats.Margin = counts[0]
+	}
+
+	if total > 0 {
+		stats.MarginPercent = math.Round(float64(stats.Margin)*1000/float64(total)) / 10
+	}
+
+	vm.questionStats = append(vm.questionStats, *stats)
+}
+
+// Analytics returns the statistics of every vote that ended so far.
+func (vm *VoteManager) Analytics() Analytics {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	analytics := Analytics{Questions: slices.Clone(vm.questionStats)}
+	if analytics.Questions == nil {
+		analytics.Questions = []QuestionStats{}
+	}
+
+	var engaging, closest *QuestionStats
+
+	for i := range analytics.Questions {
+		stats := &analytics.Questions[i]
+		analytics.Participation += stats.Participation
+
+		if engaging == nil || stats.Participation > engaging.Participation {
+			engaging = stats
+		}
+
+		if stats.Voters > 0 && (closest == nil || stats.MarginPercent < closest.MarginPercent) {
+			closest = stats
+		}
+	}
+
+	if n := len(analytics.Questions); n > 0 {
+		analytics.Participation /= float64(n)
+		analytics.MostEngaging = engaging.QuestionID
+	}
+
+	if closest != nil {
+		analytics.Closest = closest.QuestionID
+	}
+
+	return analytics
+}
+
+// LastStats returns the statistics of the most recent vote on questionID that
+// ended.
+func (vm *VoteManager) LastStats(questionID string) (QuestionStats, bool) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	for _, stats := range slices.Backward(vm.questionStats) {
+		if stats.QuestionID == questionID {
+			return stats, true
+		}
+	}
+
+	return QuestionStats{}, false
+}
+
+// ResetAnalytics forgets the statistics of every vote.
+func (vm *VoteManager) ResetAnalytics() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vm.questionStats = nil
+}
+
+// chapterDwell lists the time a run spent at each chapter it visited.
+// Callers must hold s.mu.
+func (s *Server) chapterDwell(run SessionRun) []ChapterDwell {
+	dwell := []ChapterDwell{}
+
+	for _, id := range run.visited() {
+		title := s.chapterTitle(id)
+		if title == id {
+			title = ""
+		}
+
+		dwell = append(dwell, ChapterDwell{ID: id, Title: title, Seconds: run.Dwell[id]})
+	}
+
+	return dwell
+}
+
+// handleGetAnalytics returns how the audience took part in every vote of the
+// session.
+func (s *Server) handleGetAnalytics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	analytics := s.voteManager.Analytics()
+	analytics.Chapters = []ChapterDwell{}
+
+	if run, ok := s.sessions.Current(); ok {
+		s.mu.RLock()
+		analytics.Chapters = s.chapterDwell(run)
+		s.mu.RUnlock()
+	}
+
+	if err := json.NewEncoder(w).Encode(analytics); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAnalytics(t *testing.T) {
+	vm := NewVoteManager()
+	go vm.Run()
+
+	vm.StartVoting("q1", []string{"a", "b"}, time.Minute, nil)
+
+	for voter, choice := range map[string]string{"voter-1": "a", "voter-2": "a", "voter-3": "b", "voter-4": "a"} {
+		if err := vm.SubmitVote(voter, choice); err != nil {
+			t.Fatalf("SubmitVote() error = %v", err)
+		}
+	}
+
+	// voter-3 changes their mind, then votes for the same choice again
+	_ = vm.SubmitVote("voter-3", "a")
+	_ = vm.SubmitVote("voter-3", "a")
+	vm.EndVoting()
+
+	vm.StartVoting("q2", []string{"a", "b"}, time.Minute, nil)
+	_ = vm.SubmitVote("voter-1", "a")
+	_ = vm.SubmitVote("voter-2", "b")
+	vm.EndVoting()
+
+	analytics := vm.Analytics()
+	if len(analytics.Questions) != 2 {
+		t.Fatalf("got %d questions, want 2", len(analytics.Questions))
+	}
+
+	q1, q2 := analytics.Questions[0], analytics.Questions[1]
+	if q1.QuestionID != "q1" || q1.Voters != 4 || q1.Changes != 1 || q1.Winner != "a" {
+		t.Errorf("q1 = %+v, want 4 voters, 1 change and a winning", q1)
+	}
+
+	if q1.Margin != 4 || q1.MarginPercent != 100 {
+		t.Errorf("q1 margin = %d (%v%%), want 4 (100%%)", q1.Margin, q1.MarginPercent)
+	}
+
+	if q1.TimeToFirstVote == nil || *q1.TimeToFirstVote < 0 {
+		t.Errorf("q1 time to first vote = %v, want it measured", q1.TimeToFirstVote)
+	}
+
+	if q1.Participation != 1 || q1.ChangeRate != 0.25 {
+		t.Errorf("q1 participation = %v, change rate = %v, want 1 and 0.25", q1.Participation, q1.ChangeRate)
+	}
+
+	if q2.Margin != 0 || q2.Voters != 2 {
+		t.Errorf("q2 = %+v, want a tie of two voters", q2)
+	}
+
+	if analytics.Closest != "q2" || analytics.MostEngaging != "q1" {
+		t.Errorf("closest = %q, most engaging = %q, want q2 and q1", analytics.Closest, analytics.MostEngaging)
+	}
+
+	vm.ResetAnalytics()
+
+	if got := vm.Analytics().Questions; len(got) != 0 {
+		t.Errorf("got %d questions after reset, want none", len(got))
+	}
+}
+
+func TestAnalyticsWithoutBallots(t *testing.T) {
+	vm := NewVoteManager()
+	go vm.Run()
+
+	vm.StartVoting("q1", []string{"a", "b"}, time.Minute, nil)
+	vm.EndVoting()
+
+	stats := vm.Analytics().Questions[0]
+	if stats.TimeToFirstVote != nil || stats.Participation != 0 || stats.Margin != 0 {
+		t.Errorf("stats = %+v, want nothing measured without ballots", stats)
+	}
+}
+
+func TestAnalyticsEndpoint(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.voteManager.StartVoting("q1", []string{"a", "b"}, time.Minute, nil)
+	_ = server.voteManager.SubmitVote("voter-1", "b")
+	server.voteManager.EndVoting()
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("analytics = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var analytics struct {
+		Questions []map[string]any `json:"questions"`
+		Chapters  []ChapterDwell   `json:"chapters"`
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&analytics); err != nil {
+		t.Fatalf("failed to decode analytics: %v", err)
+	}
+
+	if len(analytics.Questions) != 1 || analytics.Questions[0]["winner"] != "b" {
+		t.Errorf("questions = %v, want q1 won by b", analytics.Questions)
+	}
+
+	if len(analytics.Chapters) != 1 || analytics.Chapters[0].ID != "intro" || analytics.Chapters[0].Title != "Introduction" {
+		t.Errorf("chapters = %+v, want the time at the introduction so far", analytics.Chapters)
+	}
+}
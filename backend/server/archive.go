@@ -0,0 +1,306 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxArchiveBytes caps how much a story archive may unpack to, so a
+// malicious archive can't fill the disk.
+const maxArchiveBytes = 512 << 20
+
+// ArchiveFormats are the file extensions PackStory and OpenStoryArchive
+// handle.
+var ArchiveFormats = []string{".tar.gz", ".tgz", ".zip"}
+
+// archiveFormat returns the archive extension of name, or an error for names
+// that are no story archive.
+func archiveFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+
+	for _, format := range ArchiveFormats {
+		if strings.HasSuffix(lower, format) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown archive type %q, want one of %s", name, strings.Join(ArchiveFormats, ", "))
+}
+
+// PackStory writes the bundle as a single archive to w, a gzipped tarball
+// or a zip file depending on the extension of name. The archive holds the
+// story index as story.yaml and the content directory as chapters/, which is
+// how DiscoverStories lays out a bundle, so it unpacks to a playable story.
+func PackStory(w io.Writer, name string, bundle StoryBundle) error {
+	format, err := archiveFormat(name)
+	if err != nil {
+		return err
+	}
+
+	var add func(name string, file string) error
+
+	closeArchive := func() error { return nil }
+
+	switch format {
+	case ".zip":
+		zw := zip.NewWriter(w)
+		closeArchive = zw.Close
+
+		add = func(name, file string) error {
+			dst, err := zw.Create(name)
+			if err != nil {
+				return err
+			}
+
+			return copyFileTo(dst, file)
+		}
+	default:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+
+		closeArchive = func() error {
+			return errors.Join(tw.Close(), gz.Close())
+		}
+
+		add = func(name, file string) error {
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+
+			header.Name = name
+
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+
+			return copyFileTo(tw, file)
+		}
+	}
+
+	if err := add("story.yaml", bundle.StoryPath); err != nil {
+		return fmt.Errorf("failed to pack story index: %w", err)
+	}
+
+	storyPath, _ := filepath.Abs(bundle.StoryPath)
+
+	err = filepath.WalkDir(bundle.ContentDir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// editor swap files, .git and the like are no part of the story
+		if strings.HasPrefix(entry.Name(), ".") && file != bundle.ContentDir {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		if abs, _ := filepath.Abs(file); abs == storyPath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(bundle.ContentDir, file)
+		if err != nil {
+			return err
+		}
+
+		return add(path.Join("chapters", filepath.ToSlash(rel)), file)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pack chapters: %w", err)
+	}
+
+	return closeArchive()
+}
+
+func copyFileTo(w io.Writer, file string) error {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+// OpenStoryArchive unpacks a story archive, such as one PackStory wrote, to
+// dir and returns the story in it. The story.yaml may sit at the top of the
+// archive or in a single directory the archive wraps everything in. Entries
+// that would land outside dir, links and archives unpacking to more than
+// maxArchiveBytes are refused.
+func OpenStoryArchive(archive, dir string) (StoryBundle, error) {
+	format, err := archiveFormat(archive)
+	if err != nil {
+		return StoryBundle{}, err
+	}
+
+	if format == ".zip" {
+		err = unzip(archive, dir)
+	} else {
+		err = untar(archive, dir)
+	}
+
+	if err != nil {
+		return StoryBundle{}, fmt.Errorf("failed to unpack %s: %w", archive, err)
+	}
+
+	root := dir
+
+	if _, err := os.Stat(filepath.Join(root, "story.yaml")); err != nil {
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 || !entries[0].IsDir() {
+			return StoryBundle{}, fmt.Errorf("%s has no story.yaml", archive)
+		}
+
+		root = filepath.Join(dir, entries[0].Name())
+
+		if _, err := os.Stat(filepath.Join(root, "story.yaml")); err != nil {
+			return StoryBundle{}, fmt.Errorf("%s has no story.yaml", archive)
+		}
+	}
+
+	contentDir := filepath.Join(root, "chapters")
+	if info, err := os.Stat(contentDir); err != nil || !info.IsDir() {
+		contentDir = root
+	}
+
+	// named after the archive, such as heist for heist.tgz
+	id := filepath.Base(archive)
+	id = id[:len(id)-len(format)]
+
+	return StoryBundle{ID: id, StoryPath: filepath.Join(root, "story.yaml"), ContentDir: contentDir}, nil
+}
+
+// archiveWriter unpacks files to a directory, keeping track of the space
+// they take.
+type archiveWriter struct {
+	dir     string
+	written int64
+}
+
+// create writes the file name of an archive from r.
+func (a *archiveWriter) create(name string, r io.Reader) error {
+	local := filepath.FromSlash(path.Clean(strings.TrimPrefix(name, "./")))
+	if !filepath.IsLocal(local) {
+		return fmt.Errorf("entry %q leaves the archive", name)
+	}
+
+	target := filepath.Join(a.dir, local)
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Clean(target), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(f, io.LimitReader(r, maxArchiveBytes-a.written+1))
+	a.written += n
+
+	if err := errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+
+	if a.written > maxArchiveBytes {
+		return fmt.Errorf("archive unpacks to more than %d MiB", maxArchiveBytes>>20)
+	}
+
+	return nil
+}
+
+func untar(archive, dir string) error {
+	f, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	out := &archiveWriter{dir: dir}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			if err := out.create(header.Name, tr); err != nil {
+				return err
+			}
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+		default:
+			return fmt.Errorf("entry %q is not a regular file", header.Name)
+		}
+	}
+}
+
+func unzip(archive, dir string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	out := &archiveWriter{dir: dir}
+
+	for _, file := range zr.File {
+		// the resource forks Finder adds when zipping on macOS
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+
+		if !file.Mode().IsRegular() {
+			return fmt.Errorf("entry %q is not a regular file", file.Name)
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+
+		err = out.create(file.Name, r)
+		_ = r.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
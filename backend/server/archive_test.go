@@ -0,0 +1,163 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestStoryArchive(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapters := filepath.Join(tmpDir, "chapters")
+
+	if err := os.MkdirAll(filepath.Join(chapters, "images"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{"images/map.png": "png", ".intro.md.swp": "swap"} {
+		if err := os.WriteFile(filepath.Join(chapters, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle := StoryBundle{StoryPath: filepath.Join(tmpDir, "story.yaml"), ContentDir: chapters}
+
+	for _, name := range []string{"heist.tgz", "heist.tar.gz", "heist.zip"} {
+		archive := filepath.Join(t.TempDir(), name)
+
+		f, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := PackStory(f, name, bundle); err != nil {
+			t.Fatalf("PackStory(%s) failed: %v", name, err)
+		}
+
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		dir := t.TempDir()
+
+		opened, err := OpenStoryArchive(archive, dir)
+		if err != nil {
+			t.Fatalf("OpenStoryArchive(%s) failed: %v", name, err)
+		}
+
+		want := StoryBundle{ID: "heist", StoryPath: filepath.Join(dir, "story.yaml"), ContentDir: filepath.Join(dir, "chapters")}
+		if opened != want {
+			t.Errorf("OpenStoryArchive(%s) = %+v, want %+v", name, opened, want)
+		}
+
+		engine, err := parser.NewStoryEngine(opened.StoryPath, opened.ContentDir)
+		if err != nil {
+			t.Fatalf("story of %s does not load: %v", name, err)
+		}
+
+		if _, err := engine.GetChapter("choice1"); err != nil {
+			t.Errorf("story of %s lacks a chapter: %v", name, err)
+		}
+
+		if _, err := os.Stat(filepath.Join(opened.ContentDir, "images", "map.png")); err != nil {
+			t.Errorf("%s lacks the image: %v", name, err)
+		}
+
+		if _, err := os.Stat(filepath.Join(opened.ContentDir, ".intro.md.swp")); err == nil {
+			t.Errorf("%s packed a hidden file", name)
+		}
+	}
+
+	if err := PackStory(&strings.Builder{}, "heist.rar", bundle); err == nil {
+		t.Error("PackStory() with an unknown format succeeded")
+	}
+}
+
+func TestOpenStoryArchive_Wrapped(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "heist.zip")
+
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zw := zip.NewWriter(f)
+
+	for name, content := range map[string]string{
+		"heist-main/story.yaml":            "start: intro",
+		"heist-main/intro.md":              "---\nid: intro\ntype: terminal\n---\n# Intro",
+		"__MACOSX/heist-main/._story.yaml": "fork",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _ = w.Write([]byte(content))
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = f.Close()
+
+	dir := t.TempDir()
+
+	bundle, err := OpenStoryArchive(archive, dir)
+	if err != nil {
+		t.Fatalf("OpenStoryArchive() failed: %v", err)
+	}
+
+	if root := filepath.Join(dir, "heist-main"); bundle.ContentDir != root || bundle.StoryPath != filepath.Join(root, "story.yaml") {
+		t.Errorf("bundle = %+v, want the story in the wrapping directory", bundle)
+	}
+}
+
+func TestOpenStoryArchive_Escape(t *testing.T) {
+	for _, header := range []*tar.Header{
+		{Name: "../evil.md", Typeflag: tar.TypeReg, Size: 4, Mode: 0o644},
+		{Name: "/etc/evil.md", Typeflag: tar.TypeReg, Size: 4, Mode: 0o644},
+		{Name: "link.md", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	} {
+		archive := filepath.Join(t.TempDir(), "evil.tgz")
+
+		f, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+
+		if header.Size > 0 {
+			_, _ = tw.Write([]byte("evil"))
+		}
+
+		_ = tw.Close()
+		_ = gz.Close()
+		_ = f.Close()
+
+		dir := filepath.Join(t.TempDir(), "unpacked")
+
+		if _, err := OpenStoryArchive(archive, dir); err == nil {
+			t.Errorf("OpenStoryArchive() with entry %q succeeded", header.Name)
+		}
+
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.md")); err == nil {
+			t.Errorf("entry %q was written outside the directory", header.Name)
+		}
+	}
+}
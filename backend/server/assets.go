@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// assetsCacheControl lets browsers keep chapter assets for an hour, which is
+// plenty for a presentation and short enough for edits to show up.
+const assetsCacheControl = "public, max-age=3600"
+
+// handleGetAsset serves images, audio and video from the content directory
+// under /assets/, so chapters can reference /assets/images/map.png and choices
+// their preview, see withPreviewURLs. Paths the content directory does not
+// have fall through to the frontend's own assets, such as /assets/pixel.css.
+func (s *Server) handleGetAsset(frontend http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		engine := s.storyEngine
+		s.mu.RUnlock()
+
+		path, err := engine.ResolveMedia(mux.Vars(r)["path"])
+		if err != nil {
+			frontend.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("Cache-Control", assetsCacheControl)
+		http.ServeFile(w, r, path)
+	}
+}
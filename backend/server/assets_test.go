@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandleGetAsset(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	mockFS := fstest.MapFS{
+		"assets/pixel.css": &fstest.MapFile{Data: []byte("body {}")},
+	}
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), mockFS, "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	imagesDir := filepath.Join(tmpDir, "chapters", "images")
+	if err := os.Mkdir(imagesDir, 0755); err != nil {
+		t.Fatalf("failed to create images dir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(imagesDir, "map.png"), []byte("png"), 0600); err != nil {
+		t.Fatalf("failed to write image: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "secret.png"), []byte("png"), 0600); err != nil {
+		t.Fatalf("failed to write image: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantCache  bool
+	}{
+		{"chapter image", "/assets/images/map.png", http.StatusOK, true},
+		{"frontend stylesheet", "/assets/pixel.css", http.StatusOK, false},
+		{"missing image", "/assets/images/nope.png", http.StatusNotFound, false},
+		{"chapter markdown", "/assets/intro.md", http.StatusNotFound, false},
+		{"directory", "/assets/images", http.StatusNotFound, false},
+		// the router cleans the path and redirects before the handler sees it
+		{"path traversal", "/assets/../secret.png", http.StatusMovedPermanently, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get("Cache-Control") == assetsCacheControl; got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want cached %v", w.Header().Get("Cache-Control"), tt.wantCache)
+			}
+		})
+	}
+}
@@ -186,3 +186,62 @@ func TestPresenterAuthIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestCoPresenterAccess(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "lead-secret"
+	server.coPresenter = "helper-secret"
+
+	tests := []struct {
+		name           string
+		method         string
+		endpoint       string
+		authHeader     string
+		wantStatusCode int
+	}{
+		{"co-presenter reads the outline", "GET", "/api/v1/story/outline", "Bearer helper-secret", http.StatusOK},
+		{"co-presenter lists clients", "GET", "/api/v1/admin/clients", "Bearer helper-secret", http.StatusOK},
+		{"co-presenter cannot advance", "POST", "/api/v1/advance", "Bearer helper-secret", http.StatusForbidden},
+		{"co-presenter cannot start voting", "POST", "/api/start-voting", "Bearer helper-secret", http.StatusForbidden},
+		{"co-presenter cannot disconnect clients", "DELETE", "/api/v1/admin/clients/abc", "Bearer helper-secret", http.StatusForbidden},
+		{"presenter advances", "POST", "/api/v1/advance", "Bearer lead-secret", http.StatusOK},
+		{"wrong secret", "GET", "/api/v1/story/outline", "Bearer nope", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.endpoint, bytes.NewBufferString("{}"))
+			req.Header.Set("Authorization", tt.authHeader)
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+
+	t.Run("co-presenter opens the presenter view", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/presenter/", nil)
+		req.SetBasicAuth("presenter", "helper-secret")
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code == http.StatusUnauthorized {
+			t.Errorf("status = %d, want the presenter view", w.Code)
+		}
+	})
+
+	t.Run("co-presenter reads speaker notes", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/chapter/current?notes=true", nil)
+		req.SetBasicAuth("presenter", "helper-secret")
+
+		if !server.wantsNotes(req) {
+			t.Error("wantsNotes() = false, want co-presenters to see notes")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package server
+
+import "errors"
+
+// errBallotLocked is returned for a ballot that would change a vote on a
+// question that does not allow changes, such as a decision with
+// allow_change: false. Voters on the WebSocket are told with a vote_rejected
+// message, see RejectVote, and those texting or on Slack with a reply.
+var errBallotLocked = errors.New("votes cannot be changed on this question")
+
+// LockBallots keeps voters of the running vote from changing their ballot,
+// including in a runoff that follows it.
+func (vm *VoteManager) LockBallots() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if vm.votingActive {
+		vm.vote.request.locked = true
+	}
+}
+
+// RejectVote tells client that its ballot was not counted, and why.
+func (vm *VoteManager) RejectVote(client *Client, reason string) {
+	vm.mu.RLock()
+	questionID := vm.vote.id
+	vm.mu.RUnlock()
+
+	vm.broadcast <- &Message{
+		Type: "vote_rejected",
+		Payload: map[string]any{
+			"question_id": questionID,
+			"reason":      reason,
+		},
+		to: client,
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockedBallots(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapter := `---
+id: choice1
+type: decision
+question: Sign the contract?
+allow_change: false
+choices:
+  - id: opt-a
+    label: Sign
+    next: path-a
+  - id: opt-b
+    label: Walk away
+    next: path-b
+---
+# Sign the contract?`
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "choice.md"), []byte(chapter), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	server.mu.Lock()
+	server.currentNode = "choice1"
+	server.mu.Unlock()
+
+	vm := server.voteManager
+
+	if err := server.startVoting(slog.Default(), "choice1", []string{"opt-a", "opt-b"}, time.Minute); err != nil {
+		t.Fatalf("startVoting() error = %v", err)
+	}
+	defer vm.EndVoting()
+
+	voter := &Client{Role: RoleVoter, voterID: "voter-1"}
+
+	if err := server.handleClientMessage(voter, []byte(`{"type":"vote","choice_id":"opt-a"}`)); err != nil {
+		t.Fatalf("vote error = %v", err)
+	}
+
+	// voting for the same choice again is no change
+	if err := server.handleClientMessage(voter, []byte(`{"type":"vote","choice_id":"opt-a"}`)); err != nil {
+		t.Fatalf("repeated vote error = %v", err)
+	}
+
+	if err := server.handleClientMessage(voter, []byte(`{"type":"vote","choice_id":"opt-b"}`)); err != nil {
+		t.Fatalf("changed vote error = %v", err)
+	}
+
+	if results := vm.GetResults("choice1"); results["opt-a"] != 1 || results["opt-b"] != 0 {
+		t.Errorf("results = %v, want the first ballot kept", results)
+	}
+
+	var rejected *Message
+
+	for rejected == nil {
+		select {
+		case msg := <-vm.broadcast:
+			if msg.Type == "vote_rejected" {
+				rejected = msg
+			}
+		default:
+			t.Fatal("no vote_rejected sent")
+		}
+	}
+
+	if rejected.to != voter || rejected.Payload["question_id"] != "choice1" || rejected.Payload["reason"] == "" {
+		t.Errorf("vote_rejected = %+v, want a reason for the voter alone", rejected)
+	}
+
+	// other sources get the error back
+	if err := vm.SubmitVote("voter-1", "opt-b"); err == nil {
+		t.Error("expected an error for a changed ballot")
+	}
+}
+
+func TestBallotsChangeByDefault(t *testing.T) {
+	vm := NewVoteManager()
+
+	vm.StartVoting("q", []string{"a", "b"}, time.Minute, nil)
+	defer vm.EndVoting()
+
+	vm.SubmitVote("voter-1", "a")
+
+	if err := vm.SubmitVote("voter-1", "b"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	if results := vm.GetResults("q"); results["a"] != 0 || results["b"] != 1 {
+		t.Errorf("results = %v, want the ballot changed to b", results)
+	}
+}
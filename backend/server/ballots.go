@@ -0,0 +1,90 @@
+package server
+
+import (
+	"maps"
+	"time"
+)
+
+// ballotBox holds the ballots of one open question: the story's vote, or a
+// side poll next to it. Each question has its own box, so its voters, results
+// and timer never mix with those of another question open at the same time.
+type ballotBox struct {
+	id      string
+	request voteRequest       // the story's vote as it was started, to start a runoff of it; zero for polls
+	voters  map[string]string // voterID -> choiceID
+	results map[string]int    // choiceID -> votes, weighted for the story's vote
+	abstain string            // choice voters abstain with, see AbstainChoice; empty unless offered
+	timer   *time.Timer       // ends the question, nil when it runs until closed
+}
+
+// newBallotBox returns an empty box for the question id with choiceIDs up for
+// vote.
+func newBallotBox(id string, choiceIDs []string) *ballotBox {
+	box := &ballotBox{
+		id:      id,
+		voters:  make(map[string]string),
+		results: make(map[string]int, len(choiceIDs)),
+	}
+
+	for _, choiceID := range choiceIDs {
+		box.results[choiceID] = 0
+	}
+
+	return box
+}
+
+// accepts reports whether choiceID is up for vote, abstaining included.
+func (b *ballotBox) accepts(choiceID string) bool {
+	_, ok := b.results[choiceID]
+
+	return ok || b.abstaining(choiceID)
+}
+
+// abstaining reports whether a ballot for choiceID abstains rather than votes.
+func (b *ballotBox) abstaining(choiceID string) bool {
+	return b.abstain != "" && choiceID == b.abstain
+}
+
+// abstentions counts the voters that abstain.
+func (b *ballotBox) abstentions() int {
+	n := 0
+
+	for _, choiceID := range b.voters {
+		if b.abstaining(choiceID) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// cast records a voter's ballot with the given weight, replacing their
+// earlier one, and returns the earlier choice and whether there was one.
+// Abstentions are recorded, but never count towards the results.
+func (b *ballotBox) cast(voterID, choiceID string, weight int) (string, bool) {
+	previous, voted := b.voters[voterID]
+	if voted && !b.abstaining(previous) {
+		b.results[previous] -= weight
+	}
+
+	b.voters[voterID] = choiceID
+
+	if !b.abstaining(choiceID) {
+		b.results[choiceID] += weight
+	}
+
+	return previous, voted
+}
+
+// tally returns a copy of the results, for messages sent outside vm.mu.
+func (b *ballotBox) tally() map[string]int {
+	return maps.Clone(b.results)
+}
+
+// stop stops the timer of the question, if it has one.
+func (b *ballotBox) stop() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+}
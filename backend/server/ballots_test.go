@@ -0,0 +1,32 @@
+package server
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestBallotBoxCast(t *testing.T) {
+	box := newBallotBox("q", []string{"a", "b"})
+	box.abstain = AbstainChoice
+
+	box.cast("voter-1", "a", 2)
+	box.cast("voter-2", "b", 1)
+	box.cast("voter-3", AbstainChoice, 1)
+
+	// a changed ballot moves its weight to the new choice
+	if previous, voted := box.cast("voter-1", "b", 2); previous != "a" || !voted {
+		t.Errorf("cast() = %q, %v, want the earlier ballot a", previous, voted)
+	}
+
+	if want := map[string]int{"a": 0, "b": 3}; !maps.Equal(box.tally(), want) {
+		t.Errorf("results = %v, want %v", box.tally(), want)
+	}
+
+	if n := box.abstentions(); n != 1 {
+		t.Errorf("abstentions = %d, want 1", n)
+	}
+
+	if box.accepts("c") || !box.accepts(AbstainChoice) {
+		t.Error("accepts() takes the choices up for vote and abstaining alone")
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// CleanBasePath returns the path prefix a server is mounted at in the form
+// the server uses it, such as /adventure for "adventure/", or an empty string
+// for the root.
+func CleanBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+
+	return "/" + basePath
+}
+
+// publicPath returns the path browsers reach p of this server at, behind the
+// base path and in its room.
+func (s *Server) publicPath(p string) string {
+	return s.basePath + s.roomPath + p
+}
+
+// stripBasePath serves requests under the base path as if it were the root,
+// and sends browsers opening the base path itself to the landing page.
+// Requests without the base path are served as they are, for proxies that
+// strip it before passing requests on.
+func (s *Server) stripBasePath(next http.Handler) http.Handler {
+	if s.basePath == "" {
+		return next
+	}
+
+	stripped := http.StripPrefix(s.basePath, next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == s.basePath:
+			http.Redirect(w, r, s.basePath+"/", http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, s.basePath+"/"):
+			stripped.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// rootLinks matches the attributes of HTML pages linking to paths of the
+// server, such as href="/assets/pixel.css".
+var rootLinks = regexp.MustCompile(`\b(href|src|action)="/([^/"])`)
+
+// basePathFS serves the frontend for a server mounted at a base path. Its
+// HTML pages have their links to the server rewritten under the base path
+// and carry the base path in a base-path meta tag for their scripts.
+type basePathFS struct {
+	fs.FS
+
+	basePath string
+}
+
+func (b basePathFS) Open(name string) (fs.File, error) {
+	f, err := b.FS.Open(name)
+	if err != nil || !strings.HasSuffix(name, ".html") {
+		return f, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	data = rootLinks.ReplaceAll(data, []byte(`$1="`+b.basePath+`/$2`))
+	data = bytes.Replace(data, []byte("<head>"), []byte(`<head>
+    <meta name="base-path" content="`+b.basePath+`">`), 1)
+
+	return &memFile{Reader: bytes.NewReader(data), info: info, size: int64(len(data))}, nil
+}
+
+// memFile is a rewritten file, served from memory.
+type memFile struct {
+	*bytes.Reader
+
+	info fs.FileInfo
+	size int64
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return memFileInfo{f.info, f.size}, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memFileInfo is the FileInfo of a file with its size changed.
+type memFileInfo struct {
+	fs.FileInfo
+
+	size int64
+}
+
+func (i memFileInfo) Size() int64 { return i.size }
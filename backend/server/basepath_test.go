@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestCleanBasePath(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		"/":            "",
+		"adventure":    "/adventure",
+		"/adventure/":  "/adventure",
+		"/talks/demo/": "/talks/demo",
+	}
+
+	for basePath, want := range tests {
+		if got := CleanBasePath(basePath); got != want {
+			t.Errorf("CleanBasePath(%q) = %q, want %q", basePath, got, want)
+		}
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	staticFS := fstest.MapFS{
+		"voter/index.html": &fstest.MapFile{Data: []byte(`<html><head>
+    <link rel="stylesheet" href="/assets/pixel.css">
+</head><body><a href="https://example.com/">elsewhere</a></body></html>`)},
+	}
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), staticFS, "secret", "", false,
+		WithBasePath("/adventure/"))
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	handler := server.stripBasePath(server.router)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		return w
+	}
+
+	w := get("/adventure/voter/")
+	if w.Code != http.StatusOK {
+		t.Fatalf("voter page = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	page := w.Body.String()
+	for _, want := range []string{`<meta name="base-path" content="/adventure">`, `href="/adventure/assets/pixel.css"`, `href="https://example.com/"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("voter page lacks %s:\n%s", want, page)
+		}
+	}
+
+	if w := get("/adventure"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/adventure/" {
+		t.Errorf("base path = %d to %q, want a redirect to /adventure/", w.Code, w.Header().Get("Location"))
+	}
+
+	if w := get("/adventure/api/v1/state"); w.Code != http.StatusOK {
+		t.Errorf("state = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if link := get("/adventure/api/state").Header().Get("Link"); link != `</adventure/api/v1/state>; rel="successor-version"` {
+		t.Errorf("legacy route Link = %q, want its successor under the base path", link)
+	}
+
+	req := httptest.NewRequest("GET", "/adventure/presenter/", nil)
+	req.Header.Set("Accept", "text/html")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if location := w.Header().Get("Location"); !strings.HasPrefix(location, "/adventure/presenter/login?") ||
+		!strings.Contains(location, "next=%2Fadventure%2Fpresenter%2F") {
+		t.Errorf("presenter page redirects to %q, want the login under the base path", location)
+	}
+
+	if got := withPreviewURLs(server.basePath, []parser.Choice{{ID: "a", Preview: "door.png"}}); got[0].Preview != "/adventure/assets/door.png" {
+		t.Errorf("preview = %q, want it under the base path", got[0].Preview)
+	}
+}
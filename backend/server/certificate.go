@@ -0,0 +1,314 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// ballotRecord is how the audience decided one question.
+type ballotRecord struct {
+	QuestionID string
+	Winner     string
+	Override   string            // choice the presenter went with instead of Winner, if any
+	Ballots    map[string]string // voterID -> choiceID
+}
+
+// recordBallots remembers the ballots of the current question once it ends.
+// Voting on the same question again replaces the earlier record. Callers must
+// hold vm.mu.
+func (vm *VoteManager) recordBallots(winner string) {
+	record := ballotRecord{
+		QuestionID: vm.vote.id,
+		Winner:     winner,
+		Ballots:    make(map[string]string, len(vm.vote.voters)),
+	}
+
+	for voterID, choiceID := range vm.vote.voters {
+		record.Ballots[voterID] = choiceID
+	}
+
+	vm.ballotHistory = slices.DeleteFunc(vm.ballotHistory, func(r ballotRecord) bool {
+		return r.QuestionID == record.QuestionID
+	})
+	vm.ballotHistory = append(vm.ballotHistory, record)
+}
+
+// VoterBallot is one decision as a single voter saw it.
+type VoterBallot struct {
+	QuestionID string
+	Choice     string // empty when the voter did not vote
+	Winner     string
+}
+
+// BallotHistory returns every decided question in order with the voter's choice.
+func (vm *VoteManager) BallotHistory(voterID string) []VoterBallot {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	out := make([]VoterBallot, 0, len(vm.ballotHistory))
+	for _, record := range vm.ballotHistory {
+		out = append(out, VoterBallot{
+			QuestionID: record.QuestionID,
+			Choice:     record.Ballots[voterID],
+			Winner:     record.Winner,
+		})
+	}
+
+	return out
+}
+
+// ResetBallotHistory forgets every recorded ballot.
+func (vm *VoteManager) ResetBallotHistory() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vm.ballotHistory = nil
+}
+
+// certificateDecision is one row of the decisions table on a certificate.
+type certificateDecision struct {
+	Question string
+	Choice   string
+	Winner   string
+	Majority bool
+}
+
+// certificate is everything rendered on a voter's session certificate.
+type certificate struct {
+	Name      string
+	Headline  string
+	Ending    string
+	Chapters  int
+	Voted     int
+	Decisions int
+	Majority  int
+	Rows      []certificateDecision
+	Date      string
+}
+
+// defaultHeadline is used when the ending chapter has no certificate line.
+func defaultHeadline(meta parser.ChapterMetadata) string {
+	if meta.Type == "game-over" {
+		return "Game over, but what a ride!"
+	}
+
+	return "You made it to the end of the adventure!"
+}
+
+// buildCertificate summarises a voter's run. Callers must hold s.mu.
+func (s *Server) buildCertificate(voterID string, ending *parser.Chapter) certificate {
+	cert := certificate{
+		Headline: ending.Metadata.Certificate,
+		Ending:   ending.Metadata.ID,
+		Chapters: len(s.history) + 1,
+		Date:     time.Now().Format("January 2, 2006"),
+	}
+
+	if cert.Headline == "" {
+		cert.Headline = defaultHeadline(ending.Metadata)
+	}
+
+	if s.roster != nil {
+		cert.Name = s.roster.Name(voterID)
+	}
+
+	for _, ballot := range s.voteManager.BallotHistory(voterID) {
+		row := certificateDecision{
+			Question: ballot.QuestionID,
+			Choice:   ballot.Choice,
+			Winner:   ballot.Winner,
+			Majority: ballot.Choice != "" && ballot.Choice == ballot.Winner,
+		}
+
+		if chapter, err := s.storyEngine.GetChapter(ballot.QuestionID); err == nil {
+			if chapter.Metadata.Question != "" {
+				row.Question = chapter.Metadata.Question
+			}
+
+			for _, choice := range chapter.Metadata.Choices {
+				if choice.Label == "" {
+					continue
+				}
+
+				if choice.ID == row.Choice {
+					row.Choice = choice.Label
+				}
+
+				if choice.ID == row.Winner {
+					row.Winner = choice.Label
+				}
+			}
+		}
+
+		cert.Decisions++
+
+		if ballot.Choice != "" {
+			cert.Voted++
+		}
+
+		if row.Majority {
+			cert.Majority++
+		}
+
+		cert.Rows = append(cert.Rows, row)
+	}
+
+	return cert
+}
+
+// CertificateTokens hands out the tokens voters fetch their own certificate
+// with. Voter IDs the server assigned, through a join code or the roster, get
+// their token on every connection. A voter ID the browser picked itself is
+// bound to the first connection that claims it: later connections only get
+// the token back by presenting it, so knowing a voter's ID is not enough.
+type CertificateTokens struct {
+	mu     sync.Mutex
+	key    []byte
+	issued map[string]bool // voter IDs a token was handed out for
+}
+
+// NewCertificateTokens creates certificate tokens signed with a random key.
+func NewCertificateTokens() *CertificateTokens {
+	return &CertificateTokens{key: randomKey(), issued: make(map[string]bool)}
+}
+
+// Claim returns the certificate token for a connection voting as voterID,
+// and whether the connection may have it: when the server assigned the
+// voter ID, when no connection claimed it before, or when presented is its
+// token.
+func (ct *CertificateTokens) Claim(voterID string, assigned bool, presented string) (string, bool) {
+	if voterID == "" {
+		return "", false
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	token := ct.sign(voterID)
+
+	if assigned || !ct.issued[voterID] {
+		ct.issued[voterID] = true
+
+		return token, true
+	}
+
+	return token, hmac.Equal([]byte(presented), []byte(token))
+}
+
+// Verify reports whether token is the certificate token of voterID.
+func (ct *CertificateTokens) Verify(voterID, token string) bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	return token != "" && ct.issued[voterID] && hmac.Equal([]byte(token), []byte(ct.sign(voterID)))
+}
+
+// sign returns the token of voterID. Callers hold mu.
+func (ct *CertificateTokens) sign(voterID string) string {
+	mac := hmac.New(sha256.New, ct.key)
+	mac.Write([]byte(voterID))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// mayReadCertificate reports whether the request may see the certificate of
+// voterID: with the voter's certificate token or join code token in the
+// token query parameter, or with presenter credentials.
+func (s *Server) mayReadCertificate(r *http.Request, voterID string) bool {
+	token := r.URL.Query().Get("token")
+
+	if s.certificates.Verify(voterID, token) {
+		return true
+	}
+
+	if s.joinCodes != nil {
+		if id, ok := s.joinCodes.Verify(token); ok && id == voterID {
+			return true
+		}
+	}
+
+	return s.isPresenter(r)
+}
+
+// handleGetCertificate renders a printable summary of a voter's run once the
+// story has reached an ending. Only the voter and presenters may see it.
+func (s *Server) handleGetCertificate(w http.ResponseWriter, r *http.Request) {
+	voterID := mux.Vars(r)["voterId"]
+
+	if !s.mayReadCertificate(r, voterID) {
+		http.Error(w, "certificates are for their own voter", http.StatusForbidden)
+
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ending, err := s.chapter(s.currentNode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if !ending.Metadata.IsEnding() {
+		http.Error(w, "certificates are available once the story has ended", http.StatusConflict)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := certificateTemplate.Execute(w, s.buildCertificate(voterID, ending)); err != nil {
+		requestLogger(r).Error("Failed to render certificate", "voter_id", voterID, "error", err)
+	}
+}
+
+var certificateTemplate = template.Must(template.New("certificate").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Adventure certificate</title>
+<style>
+  body { font-family: Georgia, serif; max-width: 42rem; margin: 2rem auto; padding: 2rem; border: 6px double #333; color: #222; }
+  h1 { text-align: center; margin-bottom: 0.25rem; }
+  .sub { text-align: center; color: #666; margin-top: 0; }
+  .stats { text-align: center; font-size: 1.2rem; margin: 1.5rem 0; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { text-align: left; padding: 0.4rem; border-bottom: 1px solid #ccc; }
+  .majority { color: #176b2c; }
+  footer { text-align: center; margin-top: 2rem; color: #666; font-size: 0.9rem; }
+  @media print { button { display: none; } body { border-color: #000; } }
+</style>
+</head>
+<body>
+  <h1>{{.Headline}}</h1>
+  <p class="sub">{{if .Name}}Awarded to {{.Name}} · {{end}}{{.Date}}</p>
+  <p class="stats">
+    You travelled through {{.Chapters}} chapters to <em>{{.Ending}}</em>.<br>
+    {{if .Decisions}}You voted on {{.Voted}} of {{.Decisions}} decisions and with the majority {{.Majority}}/{{.Decisions}} times.{{else}}There were no decisions to vote on this time.{{end}}
+  </p>
+  {{if .Rows}}
+  <table>
+    <tr><th>Decision</th><th>Your vote</th><th>The audience chose</th></tr>
+    {{range .Rows}}
+    <tr{{if .Majority}} class="majority"{{end}}><td>{{.Question}}</td><td>{{if .Choice}}{{.Choice}}{{else}}—{{end}}</td><td>{{if .Winner}}{{.Winner}}{{else}}—{{end}}</td></tr>
+    {{end}}
+  </table>
+  {{end}}
+  <footer>
+    <button onclick="window.print()">Print or save as PDF</button>
+  </footer>
+</body>
+</html>
+`))
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVoterCertificate(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ending := `---
+id: path-b
+type: game-over
+certificate: You survived the cluster outage!
+---
+# Game Over`
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "path-b.md"), []byte(ending), 0600); err != nil {
+		t.Fatalf("failed to write ending: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	post := func(path string, body any) {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	certificateWith := func(voterID, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/certificate/"+voterID+"?token="+token, nil))
+
+		return w
+	}
+
+	token := func(voterID string) string {
+		token, _ := server.certificates.Claim(voterID, true, "")
+
+		return token
+	}
+
+	certificate := func(voterID string) *httptest.ResponseRecorder {
+		return certificateWith(voterID, token(voterID))
+	}
+
+	post("/api/v1/restart", nil)
+	post("/api/v1/advance", map[string]any{})
+
+	if w := certificate("voter-1"); w.Code != http.StatusConflict {
+		t.Errorf("certificate before the ending status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	post("/api/v1/start-voting", map[string]any{
+		"question_id": "choice1",
+		"choices":     []string{"opt-a", "opt-b"},
+		"duration":    60,
+	})
+
+	for voterID, choiceID := range map[string]string{"voter-1": "opt-b", "voter-2": "opt-b", "voter-3": "opt-a"} {
+		if err := server.voteManager.SubmitVote(voterID, choiceID); err != nil {
+			t.Fatalf("SubmitVote() error = %v", err)
+		}
+	}
+
+	server.voteManager.EndVoting()
+	post("/api/v1/advance", map[string]any{"choice_id": "opt-b"})
+
+	tests := map[string]string{
+		"voter-1": "with the majority 1/1 times",
+		"voter-3": "with the majority 0/1 times",
+		"lurker":  "You voted on 0 of 1 decisions",
+	}
+
+	for voterID, want := range tests {
+		w := certificate(voterID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("certificate(%s) status = %d: %s", voterID, w.Code, w.Body.String())
+		}
+
+		body := w.Body.String()
+		if !strings.Contains(body, "You survived the cluster outage!") || !strings.Contains(body, want) {
+			t.Errorf("certificate(%s) = %s, want the headline and %q", voterID, body, want)
+		}
+
+		if !strings.Contains(body, "Choose your path") || !strings.Contains(body, "Option B") {
+			t.Errorf("certificate(%s) should list the question and choice labels", voterID)
+		}
+	}
+
+	// with presenter auth, only the voter and presenters get a certificate
+	server.presenterSecret = "test-secret-123"
+	server.joinCodes = NewJoinCodes()
+	joinToken, _ := server.joinCodes.Join(server.joinCodes.Code(), "voter-1")
+
+	access := map[string]struct {
+		token string
+		want  int
+	}{
+		"own token":           {token("voter-1"), http.StatusOK},
+		"join code token":     {joinToken, http.StatusOK},
+		"other voter's token": {token("voter-3"), http.StatusForbidden},
+		"no token":            {"", http.StatusForbidden},
+	}
+
+	for name, tt := range access {
+		if w := certificateWith("voter-1", tt.token); w.Code != tt.want {
+			t.Errorf("%s: certificate status = %d, want %d", name, w.Code, tt.want)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/certificate/voter-1", nil)
+	req.Header.Set("Authorization", "Bearer test-secret-123")
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("presenter certificate status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	server.presenterSecret = ""
+	server.joinCodes = nil
+
+	post("/api/v1/restart", nil)
+
+	if history := server.voteManager.BallotHistory("voter-1"); len(history) != 0 {
+		t.Errorf("BallotHistory() after restart = %v, want none", history)
+	}
+}
+
+func TestCertificateTokensClaim(t *testing.T) {
+	tokens := NewCertificateTokens()
+
+	if _, ok := tokens.Claim("", false, ""); ok {
+		t.Error("Claim() handed out a token without a voter ID")
+	}
+
+	if tokens.Verify("voter-1", "") {
+		t.Error("Verify() accepted an empty token")
+	}
+
+	token, ok := tokens.Claim("voter-1", false, "")
+	if !ok || !tokens.Verify("voter-1", token) {
+		t.Fatal("the first connection of a voter gets no valid token")
+	}
+
+	// another connection claiming the same voter ID needs the token
+	if _, ok := tokens.Claim("voter-1", false, ""); ok {
+		t.Error("Claim() handed the token to a second connection without it")
+	}
+
+	if _, ok := tokens.Claim("voter-1", false, "forged"); ok {
+		t.Error("Claim() accepted a forged token")
+	}
+
+	if again, ok := tokens.Claim("voter-1", false, token); !ok || again != token {
+		t.Error("Claim() refused the voter's own token")
+	}
+
+	// voter IDs the server assigned always get theirs
+	if _, ok := tokens.Claim("voter-1", true, ""); !ok {
+		t.Error("Claim() refused a voter ID the server assigned")
+	}
+
+	if tokens.Verify("voter-2", token) {
+		t.Error("Verify() accepted the token of another voter")
+	}
+}
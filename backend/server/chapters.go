@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// handleListChapters lists the chapters of the story with their metadata,
+// sorted by ID. Repeated tag parameters keep chapters carrying all of them
+// and type keeps chapters of that type, so GET /chapters?tag=act-1&type=decision
+// returns the decisions of the first act.
+func (s *Server) handleListChapters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	tags := query["tag"]
+	chapterType := query.Get("type")
+
+	type chapterInfo struct {
+		ID       string                 `json:"id"`
+		Metadata parser.ChapterMetadata `json:"metadata"`
+	}
+
+	s.mu.RLock()
+	ids := slices.Sorted(maps.Keys(s.storyEngine.Story.Nodes))
+
+	out := make([]chapterInfo, 0, len(ids))
+
+	for _, id := range ids {
+		chapter, err := s.chapter(id)
+		if err != nil {
+			continue
+		}
+
+		if chapterType != "" && chapter.Metadata.Type != chapterType {
+			continue
+		}
+
+		if !chapter.Metadata.HasTags(tags) {
+			continue
+		}
+
+		out = append(out, chapterInfo{ID: id, Metadata: chapter.Metadata})
+	}
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"chapters": out,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleGetChapterPreview lets the presenter peek at a chapter mid-show: the
+// chapter as voters would see it now, where it leads and, for decisions, the
+// chapter behind each choice. Nothing is broadcast and the story stays where
+// it is.
+func (s *Server) handleGetChapterPreview(w http.ResponseWriter, r *http.Request) {
+	chapterID := mux.Vars(r)["id"]
+
+	type preview struct {
+		ID       string                 `json:"id"`
+		Metadata parser.ChapterMetadata `json:"metadata"`
+		Content  string                 `json:"content"`
+	}
+
+	type choicePreview struct {
+		ID      string   `json:"id"`
+		Label   string   `json:"label"`
+		Next    string   `json:"next"`
+		Chapter *preview `json:"chapter,omitempty"` // nil when the target does not load
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := s.vars.Clone()
+
+	chapter, err := s.chapter(chapterID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	chapter = s.present(chapter, state)
+
+	choices := make([]choicePreview, 0, len(chapter.Metadata.Choices))
+
+	for _, choice := range chapter.Metadata.Choices {
+		out := choicePreview{ID: choice.ID, Label: choice.Label, Next: choice.Next}
+
+		if next, err := s.chapter(choice.Next); err == nil {
+			next = s.present(next, state)
+			out.Chapter = &preview{ID: choice.Next, Metadata: next.Metadata, Content: next.Content}
+		}
+
+		choices = append(choices, out)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"id":       chapterID,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+		"notes":    chapter.Notes,
+		"edges":    chapter.Metadata.Edges(),
+		"choices":  choices,
+		"current":  chapterID == s.currentNode,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
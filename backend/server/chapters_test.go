@@ -0,0 +1,198 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestListChapters(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	tagged := map[string]string{
+		"intro.md":  "---\nid: intro\ntype: story\nnext: choice1\ntags: [act-1]\n---\n# Introduction",
+		"choice.md": "---\nid: choice1\ntype: decision\nquestion: Choose your path\ntags: [act-1, boss-fight]\nchoices:\n  - id: opt-a\n    label: Option A\n    next: path-a\n  - id: opt-b\n    label: Option B\n    next: path-b\n---\n# Choose",
+		"path-b.md": "---\nid: path-b\ntype: game-over\ntags: [act-2]\n---\n# Game Over",
+	}
+
+	for name, content := range tagged {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"choice1", "intro", "path-a", "path-b"}},
+		{"?tag=act-1", []string{"choice1", "intro"}},
+		{"?tag=act-1&tag=boss-fight", []string{"choice1"}},
+		{"?tag=act-1&type=story", []string{"intro"}},
+		{"?type=game-over", []string{"path-b"}},
+		{"?tag=act-3", []string{}},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapters"+tt.query, nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /chapters%s status = %d, want %d", tt.query, w.Code, http.StatusOK)
+		}
+
+		var resp struct {
+			Chapters []struct {
+				ID       string `json:"id"`
+				Metadata struct {
+					Tags []string `json:"tags"`
+				} `json:"metadata"`
+			} `json:"chapters"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode chapters: %v", err)
+		}
+
+		ids := []string{}
+		for _, chapter := range resp.Chapters {
+			ids = append(ids, chapter.ID)
+		}
+
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("GET /chapters%s = %v, want %v", tt.query, ids, tt.want)
+		}
+
+		if tt.query == "?tag=act-1&tag=boss-fight" && len(resp.Chapters) == 1 && !slices.Equal(resp.Chapters[0].Metadata.Tags, []string{"act-1", "boss-fight"}) {
+			t.Errorf("choice1 tags = %v, want [act-1 boss-fight]", resp.Chapters[0].Metadata.Tags)
+		}
+	}
+}
+
+func TestChapterExtraFields(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	intro := "---\nid: intro\ntype: story\nnext: choice1\nextra:\n  background: \"#102030\"\n  music: theme.mp3\n  lights:\n    dim: true\n---\n# Introduction"
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "intro.md"), []byte(intro), 0600); err != nil {
+		t.Fatalf("failed to write intro: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/intro", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /chapter/intro status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Metadata struct {
+			Extra map[string]any `json:"Extra"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode chapter: %v", err)
+	}
+
+	extra := resp.Metadata.Extra
+	if extra["background"] != "#102030" || extra["music"] != "theme.mp3" {
+		t.Errorf("Extra = %v, want background and music", extra)
+	}
+
+	if lights, ok := extra["lights"].(map[string]any); !ok || lights["dim"] != true {
+		t.Errorf("Extra[lights] = %v, want the nested map", extra["lights"])
+	}
+}
+
+func TestChapterPreview(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/choice1/preview", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /chapter/choice1/preview status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		ID      string        `json:"id"`
+		Content string        `json:"content"`
+		Edges   []parser.Edge `json:"edges"`
+		Current bool          `json:"current"`
+		Choices []struct {
+			ID      string `json:"id"`
+			Next    string `json:"next"`
+			Chapter *struct {
+				ID       string                 `json:"id"`
+				Metadata parser.ChapterMetadata `json:"metadata"`
+				Content  string                 `json:"content"`
+			} `json:"chapter"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode preview: %v", err)
+	}
+
+	if resp.ID != "choice1" || resp.Current || !strings.Contains(resp.Content, "Choose your path") {
+		t.Errorf("preview = %+v, want choice1, not current", resp)
+	}
+
+	wantEdges := []parser.Edge{{To: "path-a", Via: "choice 'opt-a'"}, {To: "path-b", Via: "choice 'opt-b'"}}
+	if !slices.Equal(resp.Edges, wantEdges) {
+		t.Errorf("Edges = %v, want %v", resp.Edges, wantEdges)
+	}
+
+	if len(resp.Choices) != 2 {
+		t.Fatalf("Choices = %+v, want 2", resp.Choices)
+	}
+
+	for i, want := range []struct{ id, next, content, chapterType string }{
+		{"opt-a", "path-a", "Path A", "story"},
+		{"opt-b", "path-b", "Game Over", "game-over"},
+	} {
+		choice := resp.Choices[i]
+		if choice.ID != want.id || choice.Next != want.next || choice.Chapter == nil {
+			t.Fatalf("Choices[%d] = %+v, want %s leading to %s", i, choice, want.id, want.next)
+		}
+
+		if choice.Chapter.Metadata.Type != want.chapterType || !strings.Contains(choice.Chapter.Content, want.content) {
+			t.Errorf("Choices[%d].Chapter = %+v, want the %s chapter", i, choice.Chapter, want.next)
+		}
+	}
+
+	if server.currentNode != "intro" {
+		t.Errorf("currentNode = %s after preview, want intro", server.currentNode)
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/missing/preview", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /chapter/missing/preview status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	server.presenterSecret = "secret"
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/choice1/preview", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /chapter/choice1/preview without auth status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
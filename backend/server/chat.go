@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	chatHistorySize   = 50
+	maxChatTextLength = 500
+)
+
+// ChatMessage is a note exchanged between presenters.
+type ChatMessage struct {
+	From   string    `json:"from"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// ChatLog keeps the most recent presenter chat messages so that a presenter
+// joining (or reconnecting) mid-show can catch up.
+type ChatLog struct {
+	mu       sync.Mutex
+	size     int
+	messages []ChatMessage
+}
+
+// NewChatLog creates a chat log holding at most size messages.
+func NewChatLog(size int) *ChatLog {
+	return &ChatLog{size: size}
+}
+
+// Add appends a message, dropping the oldest one when the buffer is full.
+func (cl *ChatLog) Add(msg ChatMessage) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.messages = append(cl.messages, msg)
+	if len(cl.messages) > cl.size {
+		cl.messages = cl.messages[len(cl.messages)-cl.size:]
+	}
+}
+
+// History returns a copy of the buffered messages, oldest first.
+func (cl *ChatLog) History() []ChatMessage {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return slices.Clone(cl.messages)
+}
+
+// chatRequest is an incoming {"type":"chat"} WebSocket message.
+type chatRequest struct {
+	From string `json:"from"`
+	Text string `json:"text"`
+}
+
+// handleChatMessage records a presenter chat message and relays it to the
+// other presenters. Voters can neither send nor receive chat.
+func (s *Server) handleChatMessage(client *Client, data []byte) error {
+	if client.Role != RolePresenter {
+		return errors.New("chat is only available to presenters")
+	}
+
+	var req chatRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return errors.New("empty chat message")
+	}
+
+	if utf8.RuneCountInString(text) > maxChatTextLength {
+		return errors.New("chat message too long")
+	}
+
+	from := strings.TrimSpace(req.From)
+	if from == "" {
+		from = RolePresenter
+	}
+
+	msg := ChatMessage{From: from, Text: text, SentAt: time.Now()}
+	s.chat.Add(msg)
+
+	s.voteManager.BroadcastToRole(RolePresenter, "chat_message", map[string]any{
+		"from":    msg.From,
+		"text":    msg.Text,
+		"sent_at": msg.SentAt,
+	})
+
+	return nil
+}
+
+// chatHistoryMessage builds the catch-up message sent to presenters on connect.
+func (s *Server) chatHistoryMessage() *Message {
+	return &Message{
+		Type: "chat_history",
+		Payload: map[string]any{
+			"messages": s.chat.History(),
+		},
+	}
+}
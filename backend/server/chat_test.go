@@ -0,0 +1,134 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestChatLog(t *testing.T) {
+	cl := NewChatLog(3)
+
+	for i := range 5 {
+		cl.Add(ChatMessage{From: "op", Text: fmt.Sprintf("msg-%d", i)})
+	}
+
+	history := cl.History()
+	if len(history) != 3 {
+		t.Fatalf("got %d messages, want 3", len(history))
+	}
+
+	if history[0].Text != "msg-2" || history[2].Text != "msg-4" {
+		t.Errorf("history = %+v, want msg-2..msg-4", history)
+	}
+}
+
+func TestHandleChatMessage(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name    string
+		role    string
+		message string
+		wantErr bool
+	}{
+		{"presenter message", RolePresenter, `{"type":"chat","from":"stage","text":"skip the next demo"}`, false},
+		{"voter cannot chat", RoleVoter, `{"type":"chat","text":"hello"}`, true},
+		{"empty text", RolePresenter, `{"type":"chat","text":"   "}`, true},
+		{"too long", RolePresenter, `{"type":"chat","text":"` + strings.Repeat("a", maxChatTextLength+1) + `"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.handleClientMessage(&Client{Role: tt.role}, []byte(tt.message))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleClientMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	history := server.chat.History()
+	if len(history) != 1 || history[0].From != "stage" {
+		t.Errorf("history = %+v, want the single presenter message", history)
+	}
+}
+
+func TestWebSocketPresenterChat(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "secret"
+	server.chat.Add(ChatMessage{From: "op", Text: "earlier"})
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	t.Run("presenter role requires auth", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?role=presenter", nil)
+		if err == nil {
+			t.Fatal("expected dial to fail without credentials")
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	presenter, _, err := websocket.DefaultDialer.Dial(wsURL+"?role=presenter&token=secret", nil)
+	if err != nil {
+		t.Fatalf("failed to connect presenter: %v", err)
+	}
+	defer presenter.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	var msg Message
+
+	presenter.ReadJSON(&msg) // state
+	if err := presenter.ReadJSON(&msg); err != nil || msg.Type != "chat_history" {
+		t.Fatalf("expected chat_history, got %q (%v)", msg.Type, err)
+	}
+
+	voter.ReadJSON(&msg) // state
+
+	if err := presenter.WriteJSON(map[string]string{"type": "chat", "text": "skip the demo"}); err != nil {
+		t.Fatalf("failed to send chat: %v", err)
+	}
+
+	if err := presenter.ReadJSON(&msg); err != nil || msg.Type != "chat_message" {
+		t.Fatalf("expected chat_message, got %q (%v)", msg.Type, err)
+	}
+
+	if msg.Payload["text"] != "skip the demo" {
+		t.Errorf("text = %v, want %q", msg.Payload["text"], "skip the demo")
+	}
+
+	// the voter must not see presenter chat before this broadcast; presence
+	// updates may arrive at any time
+	server.voteManager.BroadcastMessage("ping", map[string]any{})
+
+	voter.SetReadDeadline(time.Now().Add(time.Second))
+
+	for msg.Type = "presence"; msg.Type == "presence"; {
+		if err := voter.ReadJSON(&msg); err != nil {
+			t.Fatalf("voter did not receive ping: %v", err)
+		}
+	}
+
+	if msg.Type != "ping" {
+		t.Errorf("voter received %q, want ping", msg.Type)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// lastCheckpoint returns the position in history of the most recent chapter
+// marked as a checkpoint, or -1 when the story has not passed one. Callers
+// must hold s.mu.
+func (s *Server) lastCheckpoint() int {
+	for i := len(s.history) - 1; i >= 0; i-- {
+		chapter, err := s.storyEngine.GetChapter(s.history[i])
+		if err == nil && chapter.Metadata.Checkpoint {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkpointID returns the chapter go-back-to-checkpoint would return to, or
+// an empty string. Callers must hold s.mu.
+func (s *Server) checkpointID() string {
+	if i := s.lastCheckpoint(); i >= 0 {
+		return s.history[i]
+	}
+
+	return ""
+}
+
+// rewind goes back the given number of chapters, undoing the variables they
+// set, their session steps and their votes. Callers must hold s.mu.
+func (s *Server) rewind(steps int) (*parser.Chapter, error) {
+	target := len(s.history) - steps
+
+	chapter, err := s.chapter(s.history[target])
+	if err != nil {
+		return nil, err
+	}
+
+	left := append(slices.Clone(s.history[target+1:]), s.currentNode)
+
+	// remember the chapters left so they can be redone, latest first
+	for i := len(left) - 1; i >= 0; i-- {
+		s.forward = append(s.forward, forwardStep{ID: left[i], Choice: s.sessions.Back()})
+	}
+
+	s.currentNode = s.history[target]
+	s.history = s.history[:target]
+	s.diceRoll = nil
+
+	score := s.vars.Score()
+
+	// undo whatever the chapters we are leaving set
+	if n := len(s.varsHistory); n > 0 {
+		s.vars = s.varsHistory[max(n-steps, 0)]
+		s.varsHistory = s.varsHistory[:max(n-steps, 0)]
+	}
+
+	// clear for the questions left behind only
+	for _, id := range left {
+		s.voteManager.ClearQuestionVotes(id)
+	}
+
+	chapter = s.present(chapter, s.vars)
+
+	s.broadcastChapter("chapter_changed", s.vars, s.withNavigation(map[string]any{
+		"id":       s.currentNode,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+	}))
+	s.broadcastInventory()
+	s.broadcastScore(score, 0)
+
+	return chapter, nil
+}
+
+// handleGoBackToCheckpoint rewinds the story to the last checkpoint passed, as
+// if the presenter went back through every chapter since.
+func (s *Server) handleGoBackToCheckpoint(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoint := s.lastCheckpoint()
+	if checkpoint < 0 {
+		http.Error(w, "no checkpoint to go back to", http.StatusBadRequest)
+
+		return
+	}
+
+	from := s.currentNode
+	steps := len(s.history) - checkpoint
+
+	chapter, err := s.rewind(steps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	requestLogger(r).Info("Went back to checkpoint", "from", from, "chapter_id", s.currentNode, "steps", steps)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.withNavigation(map[string]any{
+		"id":       s.currentNode,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+		"notes":    chapter.Notes,
+	})); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
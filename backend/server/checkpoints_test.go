@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoBackToCheckpoint(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapters := map[string]string{
+		"intro.md": "---\nid: intro\ntype: story\nnext: camp\n---\n# Intro",
+		"camp.md":  "---\nid: camp\ntype: story\ncheckpoint: true\nset: {morale: 5}\nnext: trail\n---\n# Camp",
+		"trail.md": "---\nid: trail\ntype: story\nset: {morale: +3}\nnext: choice1\n---\n# Trail",
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	type response struct {
+		ID         string `json:"id"`
+		CanGoBack  bool   `json:"can_go_back"`
+		Checkpoint string `json:"checkpoint"`
+	}
+
+	post := func(path string, body any, wantStatus int) response {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != wantStatus {
+			t.Fatalf("POST %s status = %d, want %d: %s", path, w.Code, wantStatus, w.Body.String())
+		}
+
+		var out response
+		if wantStatus == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+				t.Fatalf("failed to decode %s: %v", path, err)
+			}
+		}
+
+		return out
+	}
+
+	post("/api/v1/restart", nil, http.StatusOK)
+	post("/api/v1/go-back-to-checkpoint", nil, http.StatusBadRequest)
+
+	if got := post("/api/v1/advance", map[string]any{}, http.StatusOK); got.ID != "camp" || got.Checkpoint != "" {
+		t.Errorf("at camp = %+v, want no checkpoint behind it yet", got)
+	}
+
+	if got := post("/api/v1/advance", map[string]any{}, http.StatusOK); got.Checkpoint != "camp" {
+		t.Errorf("past camp checkpoint = %q, want camp", got.Checkpoint)
+	}
+
+	post("/api/v1/advance", map[string]any{}, http.StatusOK)
+	post("/api/v1/start-voting", map[string]any{
+		"question_id": "choice1",
+		"choices":     []string{"opt-a", "opt-b"},
+		"duration":    60,
+	}, http.StatusOK)
+
+	if err := server.voteManager.SubmitVote("voter-1", "opt-a"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	server.voteManager.EndVoting()
+	post("/api/v1/advance", map[string]any{"choice_id": "opt-a"}, http.StatusOK)
+
+	got := post("/api/v1/go-back-to-checkpoint", nil, http.StatusOK)
+	if got.ID != "camp" || !got.CanGoBack || got.Checkpoint != "" {
+		t.Errorf("after rewinding = %+v, want camp with intro behind it", got)
+	}
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	if len(server.history) != 1 || len(server.varsHistory) != 1 {
+		t.Errorf("history = %v (%d states), want [intro]", server.history, len(server.varsHistory))
+	}
+
+	if morale := server.vars.Lookup("morale"); morale != 5 {
+		t.Errorf("morale = %v, want 5 as set by camp", morale)
+	}
+
+	if results := server.voteManager.GetResults("choice1"); len(results) != 0 {
+		t.Errorf("choice1 results = %v, want them cleared", results)
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client roles, chosen with the ?role= query parameter on /ws.
+const (
+	RoleVoter     = "voter"
+	RolePresenter = "presenter"
+	RoleSpectator = "spectator" // read-only, for projection screens and remote viewers
+	RoleOverlay   = "overlay"   // stream overlays on /overlay, sent overlay snapshots only
+)
+
+// clientMessages are the message types each role may send on /ws. The role
+// is settled when the connection is made, see handleWebSocket.
+var clientMessages = map[string][]string{
+	RoleVoter:     {"vote", "poll_vote", "veto", "reaction", "suggestion"},
+	RolePresenter: {"chat"},
+}
+
+// Client is a WebSocket connection known to the hub.
+type Client struct {
+	conn       *websocket.Conn
+	ID         string
+	Role       string
+	Lang       string // language chapters are shown in, chosen with ?lang= on /ws; empty for the default
+	RemoteAddr string
+	JoinedAt   time.Time
+
+	welcome []*Message // sent to this client only, right after the initial state
+
+	mu         sync.Mutex
+	lastActive time.Time
+	voterID    string // the voter the connection votes as, see claim
+
+	participantID string // roster identity the client joined with, if any
+}
+
+// ClientInfo is a snapshot of a connected client for the admin API.
+type ClientInfo struct {
+	ID         string    `json:"id"`
+	Role       string    `json:"role"`
+	Lang       string    `json:"lang,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	JoinedAt   time.Time `json:"joined_at"`
+	LastActive time.Time `json:"last_active"`
+	VoterID    string    `json:"voter_id,omitempty"`
+	HasVoted   bool      `json:"has_voted"`
+}
+
+// NewClient wraps a connection with its role.
+func NewClient(conn *websocket.Conn, role string) *Client {
+	now := time.Now()
+
+	client := &Client{
+		conn:       conn,
+		ID:         newRequestID(),
+		Role:       role,
+		JoinedAt:   now,
+		lastActive: now,
+	}
+
+	if conn != nil {
+		client.RemoteAddr = conn.RemoteAddr().String()
+	}
+
+	return client
+}
+
+// touch records activity on the connection.
+func (c *Client) touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastActive = time.Now()
+}
+
+// claim reports whether the connection may act as voterID. A connection
+// votes as the voter it connected as, or else as the first voter ID it
+// sends, and as no other.
+func (c *Client) claim(voterID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.voterID == "" {
+		c.voterID = voterID
+	}
+
+	return voterID == "" || voterID == c.voterID
+}
+
+// voter returns the voter ID the connection votes as.
+func (c *Client) voter() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.voterID
+}
+
+// info returns a snapshot of the client; voted reports whether its voter ID
+// has a ballot on the current question.
+func (c *Client) info(voted func(voterID string) bool) ClientInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return ClientInfo{
+		ID:         c.ID,
+		Role:       c.Role,
+		Lang:       c.Lang,
+		RemoteAddr: c.RemoteAddr,
+		JoinedAt:   c.JoinedAt,
+		LastActive: c.lastActive,
+		VoterID:    c.voterID,
+		HasVoted:   c.voterID != "" && voted(c.voterID),
+	}
+}
+
+// close sends the client a close frame with the reason and closes the
+// connection. Its read loop then unregisters it. WriteControl and Close are
+// safe to call concurrently with the hub's writers.
+func (c *Client) close(code int, reason string) {
+	_ = c.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(time.Second),
+	)
+	_ = c.conn.Close()
+}
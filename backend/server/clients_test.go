@@ -0,0 +1,235 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func listClients(t *testing.T, server *Server) []ClientInfo {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/clients", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Clients []ClientInfo `json:"clients"`
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return response.Clients
+}
+
+func TestAdminClients(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	voter, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	var msg Message
+	voter.ReadJSON(&msg) // state
+
+	server.voteManager.StartVoting("q1", []string{"a", "b"}, 2*time.Second, nil)
+	voter.ReadJSON(&msg) // voting_started
+
+	if err := voter.WriteJSON(VoteMessage{Type: "vote", VoterID: "voter-1", ChoiceID: "a"}); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+
+	voter.ReadJSON(&msg) // vote_update
+
+	clients := listClients(t, server)
+	if len(clients) != 1 {
+		t.Fatalf("got %d clients, want 1", len(clients))
+	}
+
+	if clients[0].Role != RoleVoter || !clients[0].HasVoted || clients[0].VoterID != "voter-1" {
+		t.Errorf("client = %+v, want voted voter-1", clients[0])
+	}
+
+	t.Run("disconnect unknown client", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/admin/clients/nope", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("disconnect client", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/admin/clients/"+clients[0].ID, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+
+		voter.SetReadDeadline(time.Now().Add(time.Second))
+
+		for {
+			if _, _, err := voter.ReadMessage(); err != nil {
+				break
+			}
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for len(listClients(t, server)) > 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if n := len(listClients(t, server)); n != 0 {
+			t.Errorf("got %d clients after disconnect, want 0", n)
+		}
+	})
+
+	server.voteManager.EndVoting()
+}
+
+func TestAdminClientsRequiresAuth(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "secret"
+
+	for _, method := range []string{"GET", "DELETE"} {
+		path := "/api/v1/admin/clients"
+		if method == "DELETE" {
+			path += "/some-id"
+		}
+
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s status = %d, want %d", method, path, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestSpectatorRole(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	spectator, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?role=spectator", nil)
+	if err != nil {
+		t.Fatalf("failed to connect spectator: %v", err)
+	}
+	defer spectator.Close()
+
+	var state Message
+	if err := spectator.ReadJSON(&state); err != nil || state.Type != "state" {
+		t.Fatalf("expected state, got %q (%v)", state.Type, err)
+	}
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	defer server.voteManager.EndVoting()
+
+	// spectators see broadcasts like everybody else
+	spectator.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	for {
+		var msg Message
+		if err := spectator.ReadJSON(&msg); err != nil {
+			t.Fatalf("no voting_started for the spectator: %v", err)
+		}
+
+		if msg.Type == "voting_started" {
+			break
+		}
+	}
+
+	client := &Client{ID: "screen", Role: RoleSpectator}
+
+	for _, message := range []string{
+		`{"type":"vote","voter_id":"screen","choice_id":"opt-a"}`,
+		`{"type":"reaction","emoji":"🎉"}`,
+	} {
+		if err := server.handleClientMessage(client, []byte(message)); err == nil {
+			t.Errorf("handleClientMessage(%s) from a spectator succeeded, want an error", message)
+		}
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-a"] != 0 {
+		t.Errorf("results = %v, want the spectator's vote ignored", got)
+	}
+
+	server.voteManager.mu.RLock()
+	p := server.voteManager.presence()
+	server.voteManager.mu.RUnlock()
+
+	if p.Voters != 0 || p.Spectators != 1 {
+		t.Errorf("presence = %+v, want one spectator and no voters", p)
+	}
+}
+
+func TestClientMessagePermissions(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	defer server.voteManager.EndVoting()
+
+	presenter := &Client{ID: "podium", Role: RolePresenter}
+	if err := server.handleClientMessage(presenter, []byte(`{"type":"vote","voter_id":"podium","choice_id":"opt-a"}`)); err == nil {
+		t.Error("vote from a presenter connection succeeded, want an error")
+	}
+
+	voter := &Client{ID: "phone", Role: RoleVoter}
+	if err := server.handleClientMessage(voter, []byte(`{"type":"chat","text":"hi"}`)); err == nil {
+		t.Error("chat from a voter connection succeeded, want an error")
+	}
+
+	// the first voter ID a connection votes with is the only one it gets
+	if err := server.handleClientMessage(voter, []byte(`{"type":"vote","voter_id":"v1","choice_id":"opt-a"}`)); err != nil {
+		t.Fatalf("vote failed: %v", err)
+	}
+
+	if err := server.handleClientMessage(voter, []byte(`{"type":"vote","voter_id":"v2","choice_id":"opt-a"}`)); err == nil {
+		t.Error("vote as a second voter ID succeeded, want an error")
+	}
+
+	if err := server.handleClientMessage(voter, []byte(`{"type":"vote","choice_id":"opt-b"}`)); err != nil {
+		t.Errorf("vote without voter ID failed: %v", err)
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-a"] != 0 || got["opt-b"] != 1 {
+		t.Errorf("results = %v, want v1's changed vote only", got)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?role=admin", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown role: err = %v, want 400", err)
+	}
+}
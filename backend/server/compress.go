@@ -0,0 +1,210 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// minCompressSize is the smallest response worth compressing, when its size is
+// known up front.
+const minCompressSize = 1024
+
+// compressibleTypes are the media types of responses worth compressing.
+// Images, video and archives are compressed already.
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+// compressor is a gzip.Writer or a zlib.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressors keeps compressors around between responses, per content coding,
+// as setting one up takes a fair amount of memory.
+var compressors = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	"deflate": {New: func() any {
+		return zlib.NewWriter(io.Discard)
+	}},
+}
+
+// withCompression compresses responses with gzip or deflate, whichever the
+// client accepts, so chapters and the frontend load faster over conference
+// Wi-Fi. WebSocket upgrades and range requests, such as for seeking in videos,
+// are passed through.
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if !slices.Contains(w.Header().Values("Vary"), "Accept-Encoding") {
+			w.Header().Add("Vary", "Accept-Encoding")
+		}
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding returns the content coding to compress with out of an
+// Accept-Encoding header, gzip when the client takes both, or an empty string
+// when it takes neither.
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+
+	for part := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+
+		accepted[coding] = true
+	}
+
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter compresses a response once its headers show it is worth it.
+type compressWriter struct {
+	http.ResponseWriter
+
+	encoding    string
+	compressor  compressor // nil while the response goes out as it is
+	wroteHeader bool
+}
+
+// WriteHeader decides whether to compress the response.
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		cw.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	cw.wroteHeader = true
+
+	if cw.compressible(status) {
+		header := cw.Header()
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+
+		// the compressed bytes differ from those a strong ETag stands for
+		if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		cw.compressor, _ = compressors[cw.encoding].Get().(compressor)
+		cw.compressor.Reset(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Write(p)
+	}
+
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush sends what has been compressed so far to the client.
+func (cw *compressWriter) Flush() {
+	if cw.compressor != nil {
+		_ = cw.compressor.Flush()
+	}
+
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible tells whether a response with the given status and the
+// headers set so far is worth compressing. Responses a handler further in,
+// such as that of a room, compressed already are left alone.
+func (cw *compressWriter) compressible(status int) bool {
+	header := cw.Header()
+
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent || header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < minCompressSize {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// close finishes the compressed stream, if any, and returns the compressor to
+// its pool.
+func (cw *compressWriter) close() {
+	if cw.compressor == nil {
+		return
+	}
+
+	_ = cw.compressor.Close()
+	cw.compressor.Reset(io.Discard)
+	compressors[cw.encoding].Put(cw.compressor)
+	cw.compressor = nil
+}
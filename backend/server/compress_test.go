@@ -0,0 +1,113 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"gzip, deflate, br":       "gzip",
+		"deflate":                 "deflate",
+		"GZIP;q=0.5":              "gzip",
+		"gzip;q=0, deflate":       "deflate",
+		"br, identity":            "",
+		"deflate;q=0.8, gzip;q=1": "gzip",
+	}
+
+	for header, want := range tests {
+		if got := acceptedEncoding(header); got != want {
+			t.Errorf("acceptedEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	script := strings.Repeat("console.log('adventure');\n", 200)
+	staticFS := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte("<html><body>Test</body></html>")},
+		"app.js":     &fstest.MapFile{Data: []byte(script)},
+	}
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), staticFS, "", "", false)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	get := func(path, acceptEncoding string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := get("/app.js", "gzip, deflate")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Content-Length") != "" {
+		t.Fatalf("headers = %v, want a gzipped response of unknown length", w.Header())
+	}
+
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, `W/"`) {
+		t.Errorf("ETag = %q, want a weak one for the compressed bytes", etag)
+	}
+
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", w.Header().Get("Vary"))
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+
+	if body, _ := io.ReadAll(gz); string(body) != script {
+		t.Errorf("decompressed %d bytes, want the %d of app.js", len(body), len(script))
+	}
+
+	w = get("/app.js", "deflate")
+	if w.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("Content-Encoding = %q, want deflate", w.Header().Get("Content-Encoding"))
+	}
+
+	zr, err := zlib.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("zlib.NewReader() error = %v", err)
+	}
+
+	if body, _ := io.ReadAll(zr); string(body) != script {
+		t.Errorf("decompressed %d bytes, want the %d of app.js", len(body), len(script))
+	}
+
+	w = get("/api/chapter/intro", "gzip")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("chapter = %d, %q, want gzipped JSON", w.Code, w.Header().Get("Content-Encoding"))
+	}
+
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"without Accept-Encoding": get("/app.js", ""),
+		"small file":              get("/", "gzip"),
+		"range request":           get("/app.js", "gzip", "Range", "bytes=0-9"),
+	} {
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: Content-Encoding = %q, want none", name, w.Header().Get("Content-Encoding"))
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// defaultVoteDuration is how long a vote started from a control button runs
+// on a chapter without a timer, the same as in the presenter view.
+const defaultVoteDuration = 60 * time.Second
+
+// requireControlAuth guards the control endpoints. Hardware buttons often
+// can only open a URL, so besides the usual credentials the presenter secret
+// is accepted as ?token=. Co-presenters are turned away even on GET.
+func (s *Server) requireControlAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.outsidePresenterNetworks(w, r) || s.lockedOut(w, r) {
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if !s.canControl(r) && s.credentialRole(token) != RolePresenter {
+			s.recordLogin(r, cmp.Or(token, s.presenterCredential(r)), false)
+			w.Header().Set("WWW-Authenticate", `Basic realm="Presenter Access"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		// buttons are pressed with GET too, so even those need the CSRF
+		// token when all they carry is the session cookie
+		if s.forgedRequest(w, r) {
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// Decided returns the choice the story follows after the vote on a
+// question: the presenter's override or the audience's pick.
+func (vm *VoteManager) Decided(questionID string) (string, bool) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	if vm.votingActive && vm.vote.id == questionID {
+		return "", false
+	}
+
+	for _, record := range vm.ballotHistory {
+		if record.QuestionID != questionID {
+			continue
+		}
+
+		if record.Override != "" {
+			return record.Override, true
+		}
+
+		return record.Winner, record.Winner != ""
+	}
+
+	return "", false
+}
+
+// writeControlStatus answers a control button with the chapter the show is on.
+func (s *Server) writeControlStatus(w http.ResponseWriter, status string) {
+	s.mu.RLock()
+	chapterID := s.currentNode
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status":     status,
+		"chapter_id": chapterID,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleControlNext moves the show on: on a decision chapter along the
+// decided choice once the vote is over, anywhere else to the next chapter.
+func (s *Server) handleControlNext(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	currentNode := s.currentNode
+	chapter, err := s.chapter(currentNode)
+	s.mu.RUnlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	var choiceID string
+
+	if chapter.Metadata.Type == "decision" {
+		decided, ok := s.voteManager.Decided(currentNode)
+		if !ok {
+			http.Error(w, "the vote on this chapter is not decided yet", http.StatusConflict)
+
+			return
+		}
+
+		choiceID = decided
+	}
+
+	if _, err := s.advance(requestLogger(r), choiceID, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	s.writeControlStatus(w, "advanced")
+}
+
+// handleControlStartVote starts the vote of the current chapter with the
+// choices and timer the chapter defines. Pressing it again while that vote
+// runs changes nothing.
+func (s *Server) handleControlStartVote(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	currentNode := s.currentNode
+	chapter, err := s.chapter(currentNode)
+	s.mu.RUnlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if chapter.Metadata.Type != "decision" {
+		http.Error(w, "current chapter is not a decision point", http.StatusConflict)
+
+		return
+	}
+
+	if s.voteManager.IsVotingActive() && s.voteManager.CurrentQuestion() == currentNode {
+		s.writeControlStatus(w, "voting_started")
+
+		return
+	}
+
+	choiceIDs := make([]string, 0, len(chapter.Metadata.Choices))
+	for _, choice := range chapter.Metadata.Choices {
+		choiceIDs = append(choiceIDs, choice.ID)
+	}
+
+	duration := time.Duration(chapter.Metadata.Timer) * time.Second
+	if duration <= 0 {
+		duration = defaultVoteDuration
+	}
+
+	if err := s.startVoting(requestLogger(r), currentNode, choiceIDs, duration); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	s.writeControlStatus(w, "voting_started")
+}
+
+// handleControlEndVote closes the running vote now. Without one it does nothing.
+func (s *Server) handleControlEndVote(w http.ResponseWriter, r *http.Request) {
+	if s.voteManager.IsVotingActive() {
+		requestLogger(r).Info("Voting ended early", "question_id", s.voteManager.CurrentQuestion())
+		s.voteManager.EndVoting()
+	}
+
+	s.writeControlStatus(w, "voting_ended")
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestControlEndpoints(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "secret"
+	server.coPresenter = "helper"
+	defer server.voteManager.EndVoting()
+
+	press := func(path string) int {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/control/"+path, nil))
+
+		return w.Code
+	}
+
+	if code := press("next"); code != http.StatusUnauthorized {
+		t.Errorf("next without a token = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if code := press("next?token=helper"); code != http.StatusUnauthorized {
+		t.Errorf("next with the co-presenter token = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	steps := []struct {
+		vote    string // cast before pressing
+		path    string
+		code    int
+		chapter string
+		voting  bool
+	}{
+		{"", "start-vote", http.StatusConflict, "intro", false}, // not a decision
+		{"", "next", http.StatusOK, "choice1", false},
+		{"", "next", http.StatusConflict, "choice1", false}, // not voted on yet
+		{"", "start-vote", http.StatusOK, "choice1", true},
+		{"opt-b", "start-vote", http.StatusOK, "choice1", true}, // pressed twice keeps the vote
+		{"", "next", http.StatusConflict, "choice1", true},      // still running
+		{"", "end-vote", http.StatusOK, "choice1", false},
+		{"", "end-vote", http.StatusOK, "choice1", false}, // pressed twice
+		{"", "next", http.StatusOK, "path-b", false},
+		{"", "back", http.StatusOK, "choice1", false},
+		{"", "restart", http.StatusOK, "intro", false},
+	}
+
+	for i, step := range steps {
+		if step.vote != "" {
+			server.voteManager.SubmitVote("voter", step.vote)
+		}
+
+		if code := press(step.path + "?token=secret"); code != step.code {
+			t.Fatalf("step %d: %s = %d, want %d", i, step.path, code, step.code)
+		}
+
+		if server.currentNode != step.chapter || server.voteManager.IsVotingActive() != step.voting {
+			t.Fatalf("step %d: after %s on %s (voting %v), want %s (voting %v)", i, step.path,
+				server.currentNode, server.voteManager.IsVotingActive(), step.chapter, step.voting)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"time"
+)
+
+const (
+	// csrfCookie holds the CSRF token of a browser session. Unlike the
+	// session cookie, scripts of the presenter pages can read it, which
+	// pages on other sites cannot. Rooms add their code like for the
+	// session cookie.
+	csrfCookie = "presenter_csrf"
+	// csrfHeader is where the presenter pages send the CSRF token back.
+	csrfHeader = "X-CSRF-Token"
+)
+
+// csrfToken derives the CSRF token of a session from its session token, so
+// it needs no storage and every replica agrees on it.
+func (s *Server) csrfToken(session string) string {
+	mac := hmac.New(sha256.New, s.tokenKey())
+	mac.Write([]byte("csrf\x00" + session))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// setCSRFCookie hands the browser the CSRF token of its session.
+func (s *Server) setCSRFCookie(w http.ResponseWriter, r *http.Request, session string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cookieName(csrfCookie),
+		Value:    s.csrfToken(session),
+		Path:     "/",
+		Expires:  expires,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sessionOnly returns the session token, and the server that started the
+// session, when a session cookie is the only valid presenter credential of
+// the request. Browsers send the cookie along whichever page makes the
+// request, so such requests have to prove they come from a presenter page;
+// Basic Auth, Bearer credentials and ?token= are added by the client on
+// purpose, but only count when they are valid themselves, or any page could
+// add a made-up one to skip the check.
+func (s *Server) sessionOnly(r *http.Request) (*Server, string, bool) {
+	for _, credential := range []string{authorizationCredential(r), r.URL.Query().Get("token")} {
+		if credential != "" && s.isPresenterSecret(credential) {
+			return nil, "", false
+		}
+	}
+
+	return s.session(r)
+}
+
+// forgedRequest answers 403 Forbidden to requests that another site may
+// have made on behalf of the presenter's browser, and reports whether it
+// did: requests browsers mark as cross-site, and requests authenticated by
+// the session cookie alone that lack its CSRF token.
+func (s *Server) forgedRequest(w http.ResponseWriter, r *http.Request) bool {
+	forged := r.Header.Get("Sec-Fetch-Site") == "cross-site"
+
+	if owner, session, ok := s.sessionOnly(r); ok && !forged {
+		forged = !hmac.Equal([]byte(r.Header.Get(csrfHeader)), []byte(owner.csrfToken(session)))
+	}
+
+	if !forged {
+		return false
+	}
+
+	requestLogger(r).Warn("Refused presenter request without CSRF token", "remote", r.RemoteAddr)
+	http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+
+	return true
+}
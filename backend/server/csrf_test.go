@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "test-secret-123"
+
+	req := httptest.NewRequest("POST", "/presenter/login", strings.NewReader(url.Values{"secret": {"test-secret-123"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+
+	session, csrf := cookies[sessionCookie], cookies[csrfCookie]
+	if session == nil || csrf == nil {
+		t.Fatalf("login set cookies %v, want the session and its CSRF token", cookies)
+	}
+
+	if csrf.HttpOnly || csrf.Value != server.csrfToken(session.Value) {
+		t.Fatal("the CSRF cookie should be readable by the presenter pages and match the session")
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		header   map[string]string
+		wantCode int
+	}{
+		{"session without token", "POST", "/api/v1/mode", nil, http.StatusForbidden},
+		{"session with wrong token", "POST", "/api/v1/mode", map[string]string{csrfHeader: "nope"}, http.StatusForbidden},
+		{"session with token", "POST", "/api/v1/mode", map[string]string{csrfHeader: csrf.Value}, http.StatusOK},
+		{"cross-site with token", "POST", "/api/v1/mode", map[string]string{csrfHeader: csrf.Value, "Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"reading needs no token", "GET", "/api/v1/mode", nil, http.StatusOK},
+		{"control button by link", "GET", "/api/v1/control/back", nil, http.StatusForbidden},
+		{"control button with made-up token", "GET", "/api/v1/control/restart?token=x", nil, http.StatusForbidden},
+		{"control button with secret", "GET", "/api/v1/control/restart?token=test-secret-123", nil, http.StatusOK},
+		{"bearer needs no token", "POST", "/api/v1/mode", map[string]string{"Authorization": "Bearer test-secret-123"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(`{"rehearsal": false}`))
+			req.AddCookie(session)
+
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
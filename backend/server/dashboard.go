@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ShowStatus is where one show stands, the main one or a room, as the event
+// dashboard shows it.
+type ShowStatus struct {
+	Room         string         `json:"room,omitempty"` // empty for the main show
+	Story        string         `json:"story"`
+	Chapter      string         `json:"chapter"`
+	ChapterType  string         `json:"chapter_type,omitempty"`
+	Participants Presence       `json:"participants"`
+	Vote         map[string]any `json:"vote"` // the current vote as stream overlays show it
+	EndingsFound int            `json:"endings_found"`
+	EndingsTotal int            `json:"endings_total"`
+	FinishedRuns int            `json:"finished_runs"`
+	LastActive   *time.Time     `json:"last_active,omitempty"`
+}
+
+// Dashboard sums up every show a server runs for the event organizer.
+type Dashboard struct {
+	Rooms         int          `json:"rooms"`
+	Participants  Presence     `json:"participants"`    // connected to any show
+	VotesInFlight int          `json:"votes_in_flight"` // shows with an open vote
+	Ballots       int          `json:"ballots"`         // cast in the open votes
+	Shows         []ShowStatus `json:"shows"`           // the main show first, then the rooms, oldest first
+}
+
+// VoteSnapshot returns the current vote as stream overlays show it.
+func (vm *VoteManager) VoteSnapshot() map[string]any {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	return vm.overlay(time.Now())
+}
+
+// showStatus returns where the show of this server stands.
+func (s *Server) showStatus() ShowStatus {
+	s.mu.RLock()
+
+	status := ShowStatus{
+		Room:    s.roomCode(),
+		Story:   s.activeStory,
+		Chapter: s.currentNode,
+	}
+
+	if chapter, err := s.chapter(s.currentNode); err == nil {
+		status.ChapterType = chapter.Metadata.Type
+	}
+
+	endings := s.endings()
+	s.mu.RUnlock()
+
+	status.EndingsFound = endings.Reached
+	status.EndingsTotal = endings.Total
+
+	for _, run := range s.sessions.Runs() {
+		if run.Ending != "" {
+			status.FinishedRuns++
+		}
+	}
+
+	status.Participants = s.voteManager.Connected()
+	status.Vote = s.voteManager.VoteSnapshot()
+
+	return status
+}
+
+// dashboard sums up the main show and every room.
+func (s *Server) dashboard() Dashboard {
+	board := Dashboard{Shows: []ShowStatus{s.showStatus()}}
+
+	if s.rooms != nil {
+		for _, room := range s.rooms.List() {
+			status := room.server.showStatus()
+
+			s.rooms.mu.RLock()
+			lastActive := room.lastActive
+			s.rooms.mu.RUnlock()
+
+			status.LastActive = &lastActive
+			board.Shows = append(board.Shows, status)
+			board.Rooms++
+		}
+	}
+
+	for _, show := range board.Shows {
+		board.Participants.Voters += show.Participants.Voters
+		board.Participants.Presenters += show.Participants.Presenters
+		board.Participants.Spectators += show.Participants.Spectators
+
+		if active, _ := show.Vote["voting_active"].(bool); active {
+			board.VotesInFlight++
+
+			total, _ := show.Vote["total"].(int)
+			board.Ballots += total
+		}
+	}
+
+	return board
+}
+
+// handleGetDashboard returns where every show of the server stands, so an
+// organizer can keep an eye on parallel sessions.
+func (s *Server) handleGetDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.dashboard()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDashboard(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "", "", false, WithRooms(0))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	room, err := server.openRoom(defaultStoryID, "")
+	if err != nil {
+		t.Fatalf("failed to open room: %v", err)
+	}
+
+	vm := room.server.voteManager
+	vm.StartVoting("q1", []string{"a", "b"}, time.Minute, nil)
+
+	if err := vm.SubmitVote("voter-1", "a"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/dashboard", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("dashboard = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var board struct {
+		Rooms         int `json:"rooms"`
+		VotesInFlight int `json:"votes_in_flight"`
+		Ballots       int `json:"ballots"`
+		Shows         []struct {
+			Room         string         `json:"room"`
+			Chapter      string         `json:"chapter"`
+			EndingsTotal int            `json:"endings_total"`
+			Vote         map[string]any `json:"vote"`
+		} `json:"shows"`
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&board); err != nil {
+		t.Fatalf("failed to decode dashboard: %v", err)
+	}
+
+	if board.Rooms != 1 || len(board.Shows) != 2 {
+		t.Fatalf("dashboard has %d rooms and %d shows, want the main show and one room", board.Rooms, len(board.Shows))
+	}
+
+	if board.VotesInFlight != 1 || board.Ballots != 1 {
+		t.Errorf("votes in flight = %d with %d ballots, want the room's vote with one", board.VotesInFlight, board.Ballots)
+	}
+
+	main, breakout := board.Shows[0], board.Shows[1]
+	if main.Room != "" || breakout.Room != room.Code {
+		t.Errorf("shows = %q, %q, want the main show, then %s", main.Room, breakout.Room, room.Code)
+	}
+
+	if breakout.Chapter != "intro" || breakout.Vote["question_id"] != "q1" {
+		t.Errorf("room at %q voting on %v, want intro voting on q1", breakout.Chapter, breakout.Vote["question_id"])
+	}
+
+	if main.Vote["voting_active"] != false {
+		t.Error("want no vote in flight on the main show")
+	}
+}
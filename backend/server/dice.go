@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// The dice of a roll chapter tumble for rollTicks ticks, rollTickInterval
+// apart unless the server sets its own rollInterval, before the result is
+// revealed.
+const (
+	rollTicks        = 12
+	rollTickInterval = 150 * time.Millisecond
+)
+
+// rollDice rolls the dice of the current roll chapter, with the given seed when
+// one is provided so a previous roll can be replayed. Callers must hold s.mu.
+func (s *Server) rollDice(seed *uint64) (*parser.DiceRoll, error) {
+	chapter, err := s.chapter(s.currentNode)
+	if err != nil {
+		return nil, err
+	}
+
+	value := newSeed()
+	if seed != nil {
+		value = *seed
+	}
+
+	roll, err := chapter.Metadata.RollDice(s.vars, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roll, nil
+}
+
+// handleRoll starts the dice roll of the current roll chapter. The result is
+// decided immediately but revealed through a series of roll_tick events;
+// advancing afterwards follows the success or failure branch.
+func (s *Server) handleRoll(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Seed *uint64 `json:"seed"` // replays a previous roll
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.Lock()
+
+	if s.diceRoll != nil {
+		s.mu.Unlock()
+		http.Error(w, "the dice have already been rolled for this chapter", http.StatusConflict)
+
+		return
+	}
+
+	roll, err := s.rollDice(req.Seed)
+	if err != nil {
+		s.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	s.diceRoll = roll
+	chapterID := s.currentNode
+	s.mu.Unlock()
+
+	requestLogger(r).Info("Dice rolled", "chapter_id", chapterID, "dice", roll.Dice, "faces", roll.Faces, "modifier", roll.Modifier, "total", roll.Total, "threshold", roll.Threshold, "success", roll.Success, "seed", roll.Seed)
+
+	go s.animateRoll(chapterID, roll)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status": "rolling",
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// animateRoll broadcasts tumbling dice followed by the final roll. It stops
+// early when the story moves on before the dice have settled.
+func (s *Server) animateRoll(chapterID string, roll *parser.DiceRoll) {
+	dice, _ := parser.ParseDice(roll.Dice)
+
+	for tick := 1; tick < rollTicks; tick++ {
+		time.Sleep(s.rollInterval)
+
+		if !s.isRolling(roll) {
+			return
+		}
+
+		faces := make([]int, dice.Count)
+		for i := range faces {
+			faces[i] = 1 + rand.IntN(dice.Sides) //nolint:gosec // only for show
+		}
+
+		s.voteManager.BroadcastMessage("roll_tick", map[string]any{
+			"chapter_id": chapterID,
+			"tick":       tick,
+			"ticks":      rollTicks,
+			"faces":      faces,
+			"final":      false,
+		})
+	}
+
+	time.Sleep(s.rollInterval)
+
+	if s.isRolling(roll) {
+		s.broadcastRollResult(chapterID, roll)
+	}
+}
+
+// isRolling reports whether roll is still the pending roll of the current chapter.
+func (s *Server) isRolling(roll *parser.DiceRoll) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.diceRoll == roll
+}
+
+// broadcastRollResult reveals the outcome of a roll as the final roll_tick.
+func (s *Server) broadcastRollResult(chapterID string, roll *parser.DiceRoll) {
+	s.voteManager.BroadcastMessage("roll_tick", map[string]any{
+		"chapter_id": chapterID,
+		"tick":       rollTicks,
+		"ticks":      rollTicks,
+		"faces":      roll.Faces,
+		"final":      true,
+		"result":     roll,
+	})
+}
+
+// isRollChapter reports whether the chapter branches on a dice roll. Callers
+// must hold s.mu.
+func (s *Server) isRollChapter(id string) bool {
+	chapter, err := s.storyEngine.GetChapter(id)
+
+	return err == nil && chapter.Metadata.IsRoll()
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestRollChapter(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.rollInterval = time.Millisecond
+
+	chapters := map[string]string{
+		"intro.md": `---
+id: intro
+type: story
+next: bridge
+set: {luck: +3}
+---
+# Introduction`,
+		"bridge.md": `---
+id: bridge
+type: roll
+dice: 1d6
+threshold: 4
+modifier: luck
+success: path-a
+failure: path-b
+---
+# Cross the bridge`,
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	post := func(path string, body any, wantStatus int) *httptest.ResponseRecorder {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != wantStatus {
+			t.Fatalf("POST %s status = %d, want %d: %s", path, w.Code, wantStatus, w.Body.String())
+		}
+
+		return w
+	}
+
+	type advanceResponse struct {
+		ID   string           `json:"id"`
+		Dice *parser.DiceRoll `json:"dice"`
+	}
+
+	advance := func(body any) advanceResponse {
+		t.Helper()
+
+		var response advanceResponse
+		if err := json.NewDecoder(post("/api/v1/advance", body, http.StatusOK).Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		return response
+	}
+
+	post("/api/v1/restart", nil, http.StatusOK)
+	post("/api/v1/roll", nil, http.StatusBadRequest)
+
+	advance(map[string]any{})
+	post("/api/v1/roll", map[string]any{"seed": 7}, http.StatusOK)
+	post("/api/v1/roll", nil, http.StatusConflict)
+
+	// luck 3 on a d6 always reaches 4
+	got := advance(map[string]any{})
+	if got.ID != "path-a" || got.Dice == nil || !got.Dice.Success || got.Dice.Seed != 7 {
+		t.Fatalf("advance = %+v, want a successful roll with seed 7 leading to path-a", got)
+	}
+
+	if got.Dice.Modifier != 3 || got.Dice.Total != got.Dice.Faces[0]+3 {
+		t.Errorf("dice = %+v, want the luck variable added to the roll", got.Dice)
+	}
+
+	// advancing without rolling first rolls on the spot
+	post("/api/v1/go-back", nil, http.StatusOK)
+
+	if again := advance(map[string]any{"seed": 7}); again.Dice == nil || again.Dice.Total != got.Dice.Total {
+		t.Errorf("advance without a roll = %+v, want the same roll as seed 7", again)
+	}
+}
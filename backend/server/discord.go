@@ -0,0 +1,356 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+const (
+	discordAPIURL          = "https://discord.com/api/v10"
+	discordGatewayURL      = "wss://gateway.discord.gg/?v=10&encoding=json"
+	discordTimeout         = 10 * time.Second
+	discordReconnectDelay  = 5 * time.Second
+	discordReactionSpacing = 300 * time.Millisecond // Discord rate limits adding reactions
+	discordVoterPrefix     = "discord:"
+
+	// GUILDS and GUILD_MESSAGE_REACTIONS, neither of them privileged
+	discordIntents = 1<<0 | 1<<10
+)
+
+// Discord gateway opcodes.
+const (
+	discordOpDispatch       = 0
+	discordOpHeartbeat      = 1
+	discordOpIdentify       = 2
+	discordOpReconnect      = 7
+	discordOpInvalidSession = 9
+	discordOpHello          = 10
+)
+
+// discordEmojis are the reactions voters pick choices with, in choice order.
+var discordEmojis = []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣", "6️⃣", "7️⃣", "8️⃣", "9️⃣", "🔟"}
+
+// Discord lets a Discord channel vote alongside the room. Every vote that
+// starts is posted to the channel with a numbered reaction per choice, and
+// the reactions people add come back over the gateway as their votes.
+type Discord struct {
+	token      string
+	channel    string
+	apiURL     string
+	gatewayURL string
+	client     *http.Client
+	votes      *VoteManager
+
+	mu    sync.Mutex
+	botID string      // the bot's own user, whose reactions are not votes
+	poll  discordPoll // the message of the current vote
+}
+
+// discordPoll is a vote posted to Discord.
+type discordPoll struct {
+	questionID string
+	messageID  string
+	choices    map[string]string // emoji -> choice ID
+}
+
+// NewDiscord creates a Discord integration posting to channel with the bot
+// token and recording reactions as votes in votes.
+func NewDiscord(token, channel string, votes *VoteManager) *Discord {
+	return &Discord{
+		token:      token,
+		channel:    channel,
+		apiURL:     discordAPIURL,
+		gatewayURL: discordGatewayURL,
+		client:     &http.Client{Timeout: discordTimeout},
+		votes:      votes,
+	}
+}
+
+// Notify posts every vote that starts to the channel.
+func (d *Discord) Notify(message *Message) {
+	if message.Type != "voting_started" {
+		return
+	}
+
+	questionID, _ := message.Payload["question_id"].(string)
+	question, _ := message.Payload["question"].(string)
+	if question == "" {
+		question = "What should we do?"
+	}
+
+	choices := openChoices(message.Payload)
+	if len(choices) > len(discordEmojis) {
+		choices = choices[:len(discordEmojis)]
+	}
+
+	go func() {
+		if err := d.post(questionID, question, choices); err != nil {
+			slog.Warn("Failed to post vote to discord", "channel", d.channel, "error", err)
+		}
+	}()
+}
+
+// post sends the vote to the channel and adds a reaction per choice for
+// voters to click.
+func (d *Discord) post(questionID, question string, choices []parser.Choice) error {
+	var content strings.Builder
+
+	content.WriteString("**" + question + "**\n")
+
+	for i, choice := range choices {
+		content.WriteString(discordEmojis[i] + " " + choice.Label + "\n")
+	}
+
+	content.WriteString("\nReact to vote!")
+
+	var posted struct {
+		ID string `json:"id"`
+	}
+
+	if err := d.call(http.MethodPost, "/channels/"+d.channel+"/messages", map[string]any{"content": content.String()}, &posted); err != nil {
+		return err
+	}
+
+	poll := discordPoll{questionID: questionID, messageID: posted.ID, choices: make(map[string]string, len(choices))}
+	for i, choice := range choices {
+		poll.choices[discordEmojis[i]] = choice.ID
+	}
+
+	d.mu.Lock()
+	d.poll = poll
+	d.mu.Unlock()
+
+	for i := range choices {
+		if i > 0 {
+			time.Sleep(discordReactionSpacing)
+		}
+
+		path := "/channels/" + d.channel + "/messages/" + posted.ID + "/reactions/" + url.PathEscape(discordEmojis[i]) + "/@me"
+		if err := d.call(http.MethodPut, path, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// call invokes the Discord REST API, decoding the response into out unless it is nil.
+func (d *Discord) call(method, path string, body, out any) error {
+	var reader io.Reader = http.NoBody
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, d.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bot "+d.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Run keeps a gateway connection open to receive reactions until ctx is
+// done, reconnecting when Discord drops it.
+func (d *Discord) Run(ctx context.Context) {
+	for {
+		err := d.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("Discord gateway disconnected, reconnecting", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(discordReconnectDelay):
+		}
+	}
+}
+
+// discordPayload is a gateway message.
+type discordPayload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d"`
+	S  *int64          `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+// listen identifies with the gateway and handles its events until the
+// connection ends.
+func (d *Discord) listen(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, d.gatewayURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	var hello discordPayload
+	if err := conn.ReadJSON(&hello); err != nil {
+		return err
+	}
+
+	var helloData struct {
+		HeartbeatInterval int64 `json:"heartbeat_interval"`
+	}
+
+	if err := json.Unmarshal(hello.D, &helloData); hello.Op != discordOpHello || err != nil || helloData.HeartbeatInterval <= 0 {
+		return errors.New("expected hello from the gateway")
+	}
+
+	var (
+		writeMu sync.Mutex
+		seqMu   sync.Mutex
+		seq     *int64
+	)
+
+	send := func(op int, data any) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		return conn.WriteJSON(map[string]any{"op": op, "d": data})
+	}
+
+	if err := send(discordOpIdentify, map[string]any{
+		"token":   d.token,
+		"intents": discordIntents,
+		"properties": map[string]string{
+			"os":      "linux",
+			"browser": "adventure-voter",
+			"device":  "adventure-voter",
+		},
+	}); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(helloData.HeartbeatInterval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				seqMu.Lock()
+				last := seq
+				seqMu.Unlock()
+
+				if err := send(discordOpHeartbeat, last); err != nil {
+					_ = conn.Close()
+
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		var payload discordPayload
+		if err := conn.ReadJSON(&payload); err != nil {
+			return err
+		}
+
+		if payload.S != nil {
+			seqMu.Lock()
+			seq = payload.S
+			seqMu.Unlock()
+		}
+
+		switch payload.Op {
+		case discordOpDispatch:
+			d.dispatch(payload.T, payload.D)
+		case discordOpReconnect, discordOpInvalidSession:
+			return fmt.Errorf("gateway asked to reconnect (op %d)", payload.Op)
+		}
+	}
+}
+
+// dispatch handles a gateway event.
+func (d *Discord) dispatch(event string, data json.RawMessage) {
+	switch event {
+	case "READY":
+		var ready struct {
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		}
+
+		if err := json.Unmarshal(data, &ready); err == nil {
+			d.mu.Lock()
+			d.botID = ready.User.ID
+			d.mu.Unlock()
+
+			slog.Info("Connected to Discord", "channel", d.channel)
+		}
+	case "MESSAGE_REACTION_ADD":
+		var reaction struct {
+			UserID    string `json:"user_id"`
+			MessageID string `json:"message_id"`
+			Emoji     struct {
+				Name string `json:"name"`
+			} `json:"emoji"`
+		}
+
+		if err := json.Unmarshal(data, &reaction); err != nil {
+			return
+		}
+
+		d.mu.Lock()
+		poll, botID := d.poll, d.botID
+		d.mu.Unlock()
+
+		choiceID, ok := poll.choices[reaction.Emoji.Name]
+		if !ok || reaction.MessageID != poll.messageID || reaction.UserID == botID || poll.questionID != d.votes.CurrentQuestion() {
+			return
+		}
+
+		if err := d.votes.SubmitVote(discordVoterPrefix+reaction.UserID, choiceID); err != nil {
+			slog.Warn("Failed to record discord vote", "user", reaction.UserID, "error", err)
+		}
+	}
+}
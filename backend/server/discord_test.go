@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiscordReactionVotes(t *testing.T) {
+	vm := NewVoteManager()
+	defer close(vm.broadcast)
+
+	posted := make(chan string, 1)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bot bot-token" {
+			t.Errorf("Authorization = %q, want the bot token", r.Header.Get("Authorization"))
+		}
+
+		if r.Method == http.MethodPost {
+			var body struct {
+				Content string `json:"content"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			posted <- body.Content
+
+			w.Write([]byte(`{"id":"m1"}`))
+		}
+	}))
+	defer api.Close()
+
+	events := make(chan map[string]any)
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteJSON(map[string]any{"op": discordOpHello, "d": map[string]any{"heartbeat_interval": 45000}})
+
+		var identify map[string]any
+		if err := conn.ReadJSON(&identify); err != nil || identify["op"] != float64(discordOpIdentify) {
+			t.Errorf("expected identify, got %v (%v)", identify, err)
+		}
+
+		conn.WriteJSON(map[string]any{"op": discordOpDispatch, "t": "READY", "s": 1, "d": map[string]any{"user": map[string]any{"id": "bot"}}})
+
+		for event := range events {
+			conn.WriteJSON(map[string]any{"op": discordOpDispatch, "t": "MESSAGE_REACTION_ADD", "s": 2, "d": event})
+		}
+	}))
+	defer gateway.Close()
+	defer close(events)
+
+	discord := NewDiscord("bot-token", "C1", vm)
+	discord.apiURL = api.URL
+	discord.gatewayURL = "ws" + strings.TrimPrefix(gateway.URL, "http")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go discord.Run(ctx)
+
+	vm.observers = append(vm.observers, discord.Notify)
+	go vm.Run()
+
+	vm.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	defer vm.EndVoting()
+
+	select {
+	case content := <-posted:
+		if !strings.Contains(content, "1️⃣ opt-a") || !strings.Contains(content, "2️⃣ opt-b") {
+			t.Errorf("posted %q, want a numbered line per choice", content)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("vote was not posted to discord")
+	}
+
+	// wait for the poll to be registered before reacting to it
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		discord.mu.Lock()
+		ready := discord.poll.messageID == "m1" && discord.botID == "bot"
+		discord.mu.Unlock()
+
+		if ready {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("poll was not registered")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reaction := func(user, message, emoji string) map[string]any {
+		return map[string]any{"user_id": user, "message_id": message, "emoji": map[string]any{"name": emoji}}
+	}
+
+	events <- reaction("bot", "m1", "1️⃣") // the bot's own reaction
+	events <- reaction("u1", "old", "1️⃣") // an earlier vote
+	events <- reaction("u1", "m1", "🎉")    // not a choice
+	events <- reaction("u1", "m1", "1️⃣")
+	events <- reaction("u1", "m1", "2️⃣") // changes the vote
+	events <- reaction("u2", "m1", "2️⃣")
+
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		results := vm.GetResults("choice1")
+		if results["opt-a"] == 0 && results["opt-b"] == 2 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("results = %v, want both discord users on opt-b", results)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !vm.HasVoted("discord:u1") {
+		t.Error("vote was not recorded under the discord user")
+	}
+}
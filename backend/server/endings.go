@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// EndingStat is how often one ending of the story has been reached.
+type EndingStat struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	Headline      string     `json:"headline,omitempty"` // certificate headline, if the chapter sets one
+	Reached       int        `json:"reached"`            // finished runs that ended here
+	LastReachedAt *time.Time `json:"last_reached_at,omitempty"`
+}
+
+// EndingsReport lists every ending of the active story and which of them the
+// audience has found. With a sessions file, runs of earlier server sessions
+// count too.
+type EndingsReport struct {
+	Total   int          `json:"total"`
+	Reached int          `json:"reached"` // endings reached at least once
+	Endings []EndingStat `json:"endings"`
+}
+
+// endings builds the endings registry of the active story from the finished
+// runs. Callers must hold s.mu.
+func (s *Server) endings() EndingsReport {
+	ids := slices.Sorted(maps.Keys(s.storyEngine.Story.Nodes))
+
+	report := EndingsReport{Endings: []EndingStat{}}
+	index := map[string]int{}
+
+	for _, id := range ids {
+		chapter, err := s.chapter(id)
+		if err != nil || !chapter.Metadata.IsEnding() {
+			continue
+		}
+
+		index[id] = len(report.Endings)
+		report.Endings = append(report.Endings, EndingStat{
+			ID:       id,
+			Type:     chapter.Metadata.Type,
+			Headline: chapter.Metadata.Certificate,
+		})
+	}
+
+	for _, run := range s.sessions.Runs() {
+		i, ok := index[run.Ending]
+		if !ok {
+			continue
+		}
+
+		stat := &report.Endings[i]
+		stat.Reached++
+
+		if stat.LastReachedAt == nil || run.EndedAt.After(*stat.LastReachedAt) {
+			stat.LastReachedAt = &run.EndedAt
+		}
+	}
+
+	report.Total = len(report.Endings)
+
+	for _, stat := range report.Endings {
+		if stat.Reached > 0 {
+			report.Reached++
+		}
+	}
+
+	return report
+}
+
+// broadcastEndingReached celebrates reaching an ending with every client,
+// telling them whether the audience found it for the first time. Callers must
+// hold s.mu and have finished the session run.
+func (s *Server) broadcastEndingReached(chapter *parser.Chapter) {
+	report := s.endings()
+
+	reached := 0
+
+	for _, stat := range report.Endings {
+		if stat.ID == chapter.Metadata.ID {
+			reached = stat.Reached
+		}
+	}
+
+	s.voteManager.BroadcastMessage("ending_reached", map[string]any{
+		"id":            chapter.Metadata.ID,
+		"metadata":      chapter.Metadata,
+		"reached":       reached,
+		"first_time":    reached == 1,
+		"endings_found": report.Reached,
+		"endings_total": report.Total,
+	})
+}
+
+// handleGetEndings reports every ending of the story and how often each was reached.
+func (s *Server) handleGetEndings(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	report := s.endings()
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestEndings(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	secret := "---\nid: secret-ending\ntype: terminal\ncertificate: You found the secret!\n---\n# Secret"
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "secret.md"), []byte(secret), 0600); err != nil {
+		t.Fatalf("failed to write ending: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	post := func(path string, body any) {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	// play through to the game-over chapter and wait for the celebration
+	reachEnding := func() map[string]any {
+		t.Helper()
+
+		post("/api/v1/restart", nil)
+		post("/api/v1/advance", map[string]any{})
+		post("/api/v1/advance", map[string]any{"choice_id": "opt-b"})
+
+		voter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+		for {
+			var msg Message
+			if err := voter.ReadJSON(&msg); err != nil {
+				t.Fatalf("no ending_reached event: %v", err)
+			}
+
+			if msg.Type == "ending_reached" {
+				return msg.Payload
+			}
+		}
+	}
+
+	first := reachEnding()
+	if first["id"] != "path-b" || first["first_time"] != true || first["endings_found"] != 1.0 || first["endings_total"] != 2.0 {
+		t.Errorf("first ending_reached = %v, want path-b found for the first time, 1 of 2", first)
+	}
+
+	if again := reachEnding(); again["first_time"] != false || again["reached"] != 2.0 {
+		t.Errorf("second ending_reached = %v, want path-b reached a second time", again)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/endings", nil))
+
+	var report EndingsReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode endings: %v", err)
+	}
+
+	if report.Total != 2 || report.Reached != 1 || len(report.Endings) != 2 {
+		t.Fatalf("endings = %+v, want 1 of 2 reached", report)
+	}
+
+	pathB, secretEnding := report.Endings[0], report.Endings[1]
+	if pathB.ID != "path-b" || pathB.Reached != 2 || pathB.LastReachedAt == nil {
+		t.Errorf("path-b = %+v, want reached twice", pathB)
+	}
+
+	if secretEnding.ID != "secret-ending" || secretEnding.Reached != 0 || secretEnding.Headline != "You found the secret!" {
+		t.Errorf("secret-ending = %+v, want unreached with its headline", secretEnding)
+	}
+}
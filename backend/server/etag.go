@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// revalidateCacheControl lets browsers keep a response but ask whether it
+	// is still current, by its ETag, before using it again.
+	revalidateCacheControl = "no-cache"
+
+	// immutableCacheControl lets browsers keep a response for a year without
+	// asking, for URLs that change along with the content.
+	immutableCacheControl = "public, max-age=31536000, immutable"
+)
+
+// contentHash returns a short hash of data, for ETags and versioned URLs.
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// etagMatches tells whether an If-None-Match header lists etag, comparing
+// weakly, since compressed responses carry weak ETags.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeCachedJSON writes v as JSON with an ETag of its content, or just
+// 304 Not Modified when the client has it already, so hundreds of voters
+// reloading a chapter don't download it again.
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, v any) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	etag := `"` + contentHash(body.Bytes()) + `"`
+
+	w.Header().Set("ETag", etag)
+	// private, as presenters get speaker notes from the same URL
+	w.Header().Set("Cache-Control", "private, "+revalidateCacheControl)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
+
+// staticHash is the content hash of a static file as of its last change.
+type staticHash struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// staticETags tags the files of the frontend with hashes of their content.
+// The hashes are computed once per file, and again when a file on disk
+// changes.
+type staticETags struct {
+	fsys   fs.FS
+	mu     sync.Mutex
+	hashes map[string]staticHash // file name -> hash
+}
+
+func newStaticETags(fsys fs.FS) *staticETags {
+	return &staticETags{fsys: fsys, hashes: make(map[string]staticHash)}
+}
+
+// hash returns the content hash of a file, or an empty string when it is not
+// a regular file. HTML pages are hashed on every call, as their links to
+// assets change along with the assets, see versionedFS.
+func (e *staticETags) hash(name string) string {
+	info, err := fs.Stat(e.fsys, name)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+
+	page := strings.HasSuffix(name, ".html")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if cached, ok := e.hashes[name]; ok && !page && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.hash
+	}
+
+	data, err := fs.ReadFile(e.fsys, name)
+	if err != nil {
+		return ""
+	}
+
+	hash := contentHash(data)
+	if !page {
+		e.hashes[name] = staticHash{modTime: info.ModTime(), size: info.Size(), hash: hash}
+	}
+
+	return hash
+}
+
+// versionedFS serves the frontend with the links of its HTML pages to files
+// under /assets carrying the hash of the file, as in /assets/pixel.css?v=<hash>,
+// so browsers keep them for a year and still fetch a new version as soon as
+// it changes.
+type versionedFS struct {
+	fs.FS
+
+	assets *staticETags // hashes of the files linked to
+	links  *regexp.Regexp
+}
+
+// newVersionedFS versions the asset links of the HTML pages of fsys, which
+// link to them under basePath.
+func newVersionedFS(fsys fs.FS, basePath string) versionedFS {
+	return versionedFS{
+		FS:     fsys,
+		assets: newStaticETags(fsys),
+		links:  regexp.MustCompile(`\b(href|src)="` + regexp.QuoteMeta(basePath) + `/(assets/[^"?#]+)"`),
+	}
+}
+
+func (v versionedFS) Open(name string) (fs.File, error) {
+	f, err := v.FS.Open(name)
+	if err != nil || !strings.HasSuffix(name, ".html") {
+		return f, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	data = v.links.ReplaceAllFunc(data, func(link []byte) []byte {
+		match := v.links.FindSubmatch(link)
+		if hash := v.assets.hash(string(match[2])); hash != "" {
+			return append(link[:len(link)-1:len(link)-1], `?v=`+hash+`"`...)
+		}
+
+		return link
+	})
+
+	return &memFile{Reader: bytes.NewReader(data), info: info, size: int64(len(data))}, nil
+}
+
+// handler sets the ETag and Cache-Control headers of the file next serves,
+// which answers If-None-Match with 304 Not Modified on its own. Files are
+// revalidated on every use, except when requested with their hash as the v
+// query parameter, as in /assets/pixel.css?v=<hash>, which browsers keep for
+// a year.
+func (e *staticETags) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+			name = path.Join(name, "index.html")
+		}
+
+		if hash := e.hash(name); hash != "" {
+			w.Header().Set("ETag", `"`+hash+`"`)
+
+			if r.URL.Query().Get("v") == hash {
+				w.Header().Set("Cache-Control", immutableCacheControl)
+			} else {
+				w.Header().Set("Cache-Control", revalidateCacheControl)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
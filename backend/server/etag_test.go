@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch, etag string
+		want              bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`W/"abc"`, `"abc"`, true},
+		{`"xyz", W/"abc"`, `"abc"`, true},
+		{`*`, `"abc"`, true},
+		{`"xyz"`, `"abc"`, false},
+		{``, `"abc"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, tt.etag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.ifNoneMatch, tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestChapterETag(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for _, path := range []string{"/api/v1/chapter/intro", "/api/v1/chapter/current"} {
+		w := get(path, "")
+		etag := w.Header().Get("ETag")
+
+		if w.Code != http.StatusOK || etag == "" || !strings.Contains(w.Header().Get("Cache-Control"), "no-cache") {
+			t.Fatalf("%s = %d with ETag %q and Cache-Control %q, want an ETag to revalidate", path, w.Code, etag, w.Header().Get("Cache-Control"))
+		}
+
+		if w := get(path, etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s with its ETag = %d, want %d without a body", path, w.Code, http.StatusNotModified)
+		}
+
+		if w := get(path, `"stale"`); w.Code != http.StatusOK {
+			t.Errorf("%s with another ETag = %d, want %d", path, w.Code, http.StatusOK)
+		}
+	}
+
+	if a, b := get("/api/v1/chapter/intro", "").Header().Get("ETag"), get("/api/v1/chapter/choice1", "").Header().Get("ETag"); a == b {
+		t.Errorf("chapters share the ETag %q, want one per content", a)
+	}
+}
+
+func TestStaticETag(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	staticFS := fstest.MapFS{
+		"index.html":       &fstest.MapFile{Data: []byte(`<html><head><link rel="stylesheet" href="/assets/pixel.css"></head></html>`)},
+		"assets/pixel.css": &fstest.MapFile{Data: []byte("body {}")},
+	}
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), staticFS, "", "", false)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	css := `"` + contentHash([]byte("body {}")) + `"`
+
+	w := get("/assets/pixel.css", "")
+	if w.Header().Get("ETag") != css || w.Header().Get("Cache-Control") != revalidateCacheControl {
+		t.Fatalf("headers = %v, want ETag %s to revalidate", w.Header(), css)
+	}
+
+	if w := get("/assets/pixel.css", css); w.Code != http.StatusNotModified {
+		t.Errorf("pixel.css with its ETag = %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	if w := get("/assets/pixel.css?v="+strings.Trim(css, `"`), ""); w.Header().Get("Cache-Control") != immutableCacheControl {
+		t.Errorf("versioned pixel.css Cache-Control = %q, want %q", w.Header().Get("Cache-Control"), immutableCacheControl)
+	}
+
+	// pages link to assets by their hash, so browsers can keep them
+	page := `<html><head><link rel="stylesheet" href="/assets/pixel.css?v=` + strings.Trim(css, `"`) + `"></head></html>`
+
+	w = get("/", "")
+	if w.Body.String() != page {
+		t.Errorf("index = %s, want %s", w.Body.String(), page)
+	}
+
+	if w.Header().Get("ETag") != `"`+contentHash([]byte(page))+`"` {
+		t.Errorf("index ETag = %q, want the hash of the page served", w.Header().Get("ETag"))
+	}
+}
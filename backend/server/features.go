@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// Features is the set of experimental features enabled for this deployment.
+// Chapters opt in with `requires_feature:` in their frontmatter and stay
+// unreachable until the feature is enabled.
+type Features map[string]bool
+
+// ParseFeatures parses a comma-separated feature list such as "random,roll".
+func ParseFeatures(list string) Features {
+	features := make(Features)
+
+	for name := range strings.SplitSeq(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			features[name] = true
+		}
+	}
+
+	return features
+}
+
+// Enabled reports whether the named feature is on. The empty name, used by
+// chapters that require nothing, is always enabled.
+func (f Features) Enabled(name string) bool {
+	return name == "" || f[name]
+}
+
+// List returns the enabled feature names in sorted order.
+func (f Features) List() []string {
+	return slices.Sorted(maps.Keys(f))
+}
+
+// chapter loads a chapter, refusing chapters gated behind a disabled feature
+// and hiding choices that lead to such chapters. Callers must hold s.mu.
+func (s *Server) chapter(id string) (*parser.Chapter, error) {
+	return s.localizedChapter(id, "")
+}
+
+// localizedChapter is chapter in the given language, see
+// parser.StoryEngine.GetLocalizedChapter. Callers must hold s.mu.
+func (s *Server) localizedChapter(id, lang string) (*parser.Chapter, error) {
+	chapter, err := s.storyEngine.GetLocalizedChapter(id, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.features.Enabled(chapter.Metadata.RequiresFeature) {
+		return nil, fmt.Errorf("chapter %s requires disabled feature %q", id, chapter.Metadata.RequiresFeature)
+	}
+
+	choices := slices.DeleteFunc(slices.Clone(chapter.Metadata.Choices), func(choice parser.Choice) bool {
+		target, err := s.storyEngine.GetChapter(choice.Next)
+
+		return err == nil && !s.features.Enabled(target.Metadata.RequiresFeature)
+	})
+
+	if len(choices) == len(chapter.Metadata.Choices) {
+		return chapter, nil
+	}
+
+	filtered := *chapter
+	filtered.Metadata.Choices = choices
+
+	return &filtered, nil
+}
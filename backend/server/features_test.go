@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFeatures(t *testing.T) {
+	features := ParseFeatures(" random, roll ,,")
+
+	if !features.Enabled("random") || !features.Enabled("roll") {
+		t.Errorf("features = %v, want random and roll enabled", features)
+	}
+
+	if features.Enabled("veto") {
+		t.Error("veto should be disabled")
+	}
+
+	if !features.Enabled("") {
+		t.Error("the empty feature should always be enabled")
+	}
+
+	if got := features.List(); len(got) != 2 || got[0] != "random" {
+		t.Errorf("List() = %v, want [random roll]", got)
+	}
+}
+
+// setupFeatureTestServer adds an experimental chapter behind the "secret-door"
+// feature and a choice leading to it.
+func setupFeatureTestServer(t *testing.T, features Features) *Server {
+	t.Helper()
+
+	server, tmpDir := setupTestServer(t)
+
+	chapters := map[string]string{
+		"choice.md": `---
+id: choice1
+type: decision
+question: Choose your path
+choices:
+  - id: opt-a
+    label: Option A
+    next: path-a
+  - id: opt-secret
+    label: Secret door
+    next: secret
+---
+# Choose your path`,
+		"secret.md": `---
+id: secret
+type: story
+requires_feature: secret-door
+---
+# Secret`,
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	server.features = features
+
+	return server
+}
+
+func TestFeatureGatedChapters(t *testing.T) {
+	t.Run("disabled feature hides chapter and choice", func(t *testing.T) {
+		server := setupFeatureTestServer(t, Features{})
+
+		req := httptest.NewRequest("GET", "/api/v1/chapter/secret", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+
+		chapter, err := server.chapter("choice1")
+		if err != nil {
+			t.Fatalf("chapter failed: %v", err)
+		}
+
+		if len(chapter.Metadata.Choices) != 1 || chapter.Metadata.Choices[0].ID != "opt-a" {
+			t.Errorf("choices = %+v, want only opt-a", chapter.Metadata.Choices)
+		}
+
+		server.currentNode = "choice1"
+
+		body, _ := json.Marshal(map[string]string{"choice_id": "opt-secret"})
+		req = httptest.NewRequest("POST", "/api/v1/advance", bytes.NewReader(body))
+		w = httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("advance status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("enabled feature exposes chapter", func(t *testing.T) {
+		server := setupFeatureTestServer(t, ParseFeatures("secret-door"))
+		server.currentNode = "choice1"
+
+		chapter, err := server.chapter("choice1")
+		if err != nil {
+			t.Fatalf("chapter failed: %v", err)
+		}
+
+		if len(chapter.Metadata.Choices) != 2 {
+			t.Errorf("got %d choices, want 2", len(chapter.Metadata.Choices))
+		}
+
+		body, _ := json.Marshal(map[string]string{"choice_id": "opt-secret"})
+		req := httptest.NewRequest("POST", "/api/v1/advance", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("advance status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("authors see gated chapters", func(t *testing.T) {
+		server := setupFeatureTestServer(t, Features{})
+		server.authorMode = true
+
+		req := httptest.NewRequest("GET", "/api/v1/chapter/secret", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// BuildInfo identifies the binary: the release version and the commit and
+// date it was built from, as set with -ldflags.
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+// withVCS fills in the commit and date Go records for builds from a
+// checkout, such as go build or go install, when -ldflags set none.
+func (b BuildInfo) withVCS() BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+
+	for _, setting := range info.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && b.Commit == "":
+			b.Commit = setting.Value
+		case setting.Key == "vcs.time" && b.Date == "":
+			b.Date = setting.Value
+		}
+	}
+
+	return b
+}
+
+// handleGetInfo tells what is running, for presenters checking a deployment
+// and for bug reports: the build, the Go version, the story being played and
+// the enabled features.
+func (s *Server) handleGetInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	story := map[string]any{
+		"id":       s.activeStory,
+		"title":    s.storyEngine.Story.Title,
+		"chapters": len(s.storyEngine.Story.Nodes),
+	}
+	s.mu.RUnlock()
+
+	build := s.build.withVCS()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"version":    build.Version,
+		"commit":     build.Commit,
+		"build_date": build.Date,
+		"go_version": runtime.Version(),
+		"story":      story,
+		"features":   s.features.List(),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandleGetInfo(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "", "", false,
+		WithBuildInfo(BuildInfo{Version: "1.2.3", Commit: "abc123", Date: "2026-01-02T03:04:05Z"}),
+		WithFeatures(ParseFeatures("roll")),
+	)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	for _, path := range []string{"/api/info", "/api/v1/info"} {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+
+		var info struct {
+			Version   string   `json:"version"`
+			Commit    string   `json:"commit"`
+			BuildDate string   `json:"build_date"`
+			GoVersion string   `json:"go_version"`
+			Features  []string `json:"features"`
+			Story     struct {
+				ID       string `json:"id"`
+				Chapters int    `json:"chapters"`
+			} `json:"story"`
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		if info.Version != "1.2.3" || info.Commit != "abc123" || info.BuildDate != "2026-01-02T03:04:05Z" || info.GoVersion != runtime.Version() {
+			t.Errorf("build = %+v, want the configured build and %s", info, runtime.Version())
+		}
+
+		if info.Story.ID != defaultStoryID || info.Story.Chapters != 4 || len(info.Features) != 1 || info.Features[0] != "roll" {
+			t.Errorf("info = %+v, want the default story of 4 chapters and the roll feature", info)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// withInventory returns the chapter as the audience sees it with the given
+// story state: choices whose required items are missing are marked locked, or
+// left out entirely when they ask to be hidden.
+func withInventory(chapter *parser.Chapter, state parser.State) *parser.Chapter {
+	gated := slices.ContainsFunc(chapter.Metadata.Choices, func(choice parser.Choice) bool {
+		return len(choice.Requires) > 0
+	})
+	if !gated {
+		return chapter
+	}
+
+	choices := make([]parser.Choice, 0, len(chapter.Metadata.Choices))
+
+	for _, choice := range chapter.Metadata.Choices {
+		choice.Locked = !state.HasItems(choice.Requires)
+		if choice.Locked && choice.HideLocked {
+			continue
+		}
+
+		choices = append(choices, choice)
+	}
+
+	out := *chapter
+	out.Metadata.Choices = choices
+
+	return &out
+}
+
+// isLocked reports whether the chapter's choice with the given ID is locked
+// for the given story state.
+func isLocked(chapter *parser.Chapter, state parser.State, choiceID string) bool {
+	for _, choice := range chapter.Metadata.Choices {
+		if choice.ID == choiceID {
+			return !state.HasItems(choice.Requires)
+		}
+	}
+
+	return false
+}
+
+// inventoryPayload describes the items the audience has collected.
+func inventoryPayload(state parser.State) map[string]any {
+	return map[string]any{
+		"items": state.Inventory(),
+	}
+}
+
+// broadcastInventory sends the current inventory to every client. Callers must
+// hold s.mu.
+func (s *Server) broadcastInventory() {
+	s.voteManager.BroadcastMessage("inventory", inventoryPayload(s.vars))
+}
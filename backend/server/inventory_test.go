@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestWithInventory(t *testing.T) {
+	chapter := &parser.Chapter{Metadata: parser.ChapterMetadata{Choices: []parser.Choice{
+		{ID: "free"},
+		{ID: "rope", Requires: []string{"rope"}},
+		{ID: "key", Requires: []string{"key"}, HideLocked: true},
+	}}}
+
+	state := parser.State{}
+	state.Grant([]string{"rope"})
+
+	got := withInventory(chapter, state).Metadata.Choices
+	if len(got) != 2 || got[0].Locked || got[1].Locked {
+		t.Errorf("choices = %+v, want free and rope unlocked, key hidden", got)
+	}
+
+	got = withInventory(chapter, parser.State{}).Metadata.Choices
+	if len(got) != 2 || got[0].Locked || !got[1].Locked {
+		t.Errorf("choices = %+v, want free unlocked, rope locked, key hidden", got)
+	}
+
+	if chapter.Metadata.Choices[1].Locked || len(chapter.Metadata.Choices) != 3 {
+		t.Error("withInventory must not modify the chapter")
+	}
+}
+
+func TestInventoryGatedVoting(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapters := map[string]string{
+		"intro.md": `---
+id: intro
+type: story
+next: choice1
+grants: [rope]
+---
+# Introduction`,
+		"choice.md": `---
+id: choice1
+type: decision
+question: Choose your path
+choices:
+  - id: opt-a
+    label: Climb down
+    next: path-a
+    requires: [rope]
+  - id: opt-b
+    label: Read the map
+    next: path-b
+    requires: [map]
+  - id: opt-c
+    label: Unlock the door
+    next: path-b
+    requires: [key]
+    hide_locked: true
+---
+# Choose your path`,
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	post := func(path string, body any) {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	post("/api/v1/restart", nil)
+	post("/api/v1/advance", map[string]any{})
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/current", nil))
+
+	var response struct {
+		Metadata parser.ChapterMetadata `json:"metadata"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode chapter: %v", err)
+	}
+
+	choices := response.Metadata.Choices
+	if len(choices) != 2 || choices[0].Locked || !choices[1].Locked {
+		t.Fatalf("choices = %+v, want opt-a unlocked, opt-b locked and opt-c hidden", choices)
+	}
+
+	post("/api/v1/start-voting", map[string]any{
+		"question_id": "choice1",
+		"choices":     []string{"opt-a", "opt-b"},
+		"duration":    5,
+	})
+	defer server.voteManager.EndVoting()
+
+	if err := server.voteManager.SubmitVote("voter-1", "opt-b"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	results := server.voteManager.GetResults("choice1")
+	if _, ok := results["opt-b"]; ok || len(results) != 1 {
+		t.Errorf("results = %v, locked choices must not be up for vote", results)
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// joinCodeAlphabet leaves out letters and digits easily mixed up when read
+	// off a projector, such as O and 0.
+	joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	joinCodeLength   = 6
+)
+
+// JoinCodes keeps voting to the room: voters enter the code shown on the
+// presenter screen at /api/join and receive a voting token for their voter
+// ID. Tokens are only valid for the current code, so rotating it shuts out
+// anyone who got the link from outside.
+type JoinCodes struct {
+	mu   sync.RWMutex
+	code string
+	key  []byte
+}
+
+// NewJoinCodes creates join codes starting with a random code.
+func NewJoinCodes() *JoinCodes {
+	jc := &JoinCodes{key: randomKey()}
+	jc.Rotate()
+
+	return jc
+}
+
+// Code returns the current join code.
+func (jc *JoinCodes) Code() string {
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
+	return jc.code
+}
+
+// Rotate replaces the join code with a new random one, revoking every token
+// issued for the old one, and returns it.
+func (jc *JoinCodes) Rotate() string {
+	code := randomCode()
+
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+
+	jc.code = code
+
+	return jc.code
+}
+
+// randomCode returns a random code of the join code alphabet, short enough
+// to type off a projector.
+func randomCode() string {
+	b := make([]byte, joinCodeLength)
+	_, _ = rand.Read(b)
+
+	for i := range b {
+		b[i] = joinCodeAlphabet[int(b[i])%len(joinCodeAlphabet)]
+	}
+
+	return string(b)
+}
+
+// Join returns a voting token for voterID when code is the current join
+// code. Codes are compared ignoring case, spaces and dashes.
+func (jc *JoinCodes) Join(code, voterID string) (string, bool) {
+	code = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
+	if !hmac.Equal([]byte(code), []byte(jc.code)) {
+		return "", false
+	}
+
+	return voterID + "." + jc.sign(voterID), true
+}
+
+// Verify returns the voter ID a token was issued for, if it is valid for
+// the current join code.
+func (jc *JoinCodes) Verify(token string) (string, bool) {
+	i := strings.LastIndex(token, ".")
+	if i <= 0 {
+		return "", false
+	}
+
+	voterID := token[:i]
+
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
+	if !hmac.Equal([]byte(token[i+1:]), []byte(jc.sign(voterID))) {
+		return "", false
+	}
+
+	return voterID, true
+}
+
+// sign returns the signature of the token of voterID for the current code.
+// Callers hold mu.
+func (jc *JoinCodes) sign(voterID string) string {
+	mac := hmac.New(sha256.New, jc.key)
+	mac.Write([]byte(jc.code + "\x00" + voterID))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// handleJoin exchanges the join code for a voting token.
+func (s *Server) handleJoin(w http.ResponseWriter, r *http.Request) {
+	if s.joinCodes == nil {
+		http.Error(w, "no join code required", http.StatusNotFound)
+
+		return
+	}
+
+	if s.joinLockedOut(w, r) {
+		return
+	}
+
+	var req struct {
+		Code    string `json:"code"`
+		VoterID string `json:"voter_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if req.VoterID == "" {
+		req.VoterID = "voter_" + newRequestID()
+	}
+
+	token, ok := s.joinCodes.Join(req.Code, req.VoterID)
+	s.recordJoin(r, req.Code, ok)
+
+	if !ok {
+		http.Error(w, "wrong join code", http.StatusForbidden)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"token":    token,
+		"voter_id": req.VoterID,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleGetJoinCode returns the join code for the presenter to show.
+func (s *Server) handleGetJoinCode(w http.ResponseWriter, r *http.Request) {
+	if s.joinCodes == nil {
+		http.Error(w, "join codes are not enabled", http.StatusNotFound)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"code": s.joinCodes.Code(),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleRotateJoinCode replaces the join code and disconnects every voter,
+// who has to enter the new one to vote again.
+func (s *Server) handleRotateJoinCode(w http.ResponseWriter, r *http.Request) {
+	if s.joinCodes == nil {
+		http.Error(w, "join codes are not enabled", http.StatusNotFound)
+
+		return
+	}
+
+	code := s.joinCodes.Rotate()
+	s.voteManager.DisconnectRole(RoleVoter, websocket.ClosePolicyViolation, "join code changed")
+	s.voteManager.BroadcastToRole(RolePresenter, "join_code", map[string]any{"code": code})
+
+	requestLogger(r).Info("Join code rotated")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"code": code,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
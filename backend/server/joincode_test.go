@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestJoinCodes(t *testing.T) {
+	jc := NewJoinCodes()
+
+	code := jc.Code()
+	if len(code) != joinCodeLength || strings.Trim(code, joinCodeAlphabet) != "" {
+		t.Fatalf("code = %q, want %d characters of the join code alphabet", code, joinCodeLength)
+	}
+
+	if _, ok := jc.Join("WRONG1", "voter-1"); ok {
+		t.Error("Join() with a wrong code succeeded")
+	}
+
+	// typed the way people copy codes off a screen
+	typed := strings.ToLower(code[:3] + "-" + code[3:])
+
+	token, ok := jc.Join(typed, "voter.1")
+	if !ok {
+		t.Fatalf("Join(%q) failed for code %q", typed, code)
+	}
+
+	if voterID, ok := jc.Verify(token); !ok || voterID != "voter.1" {
+		t.Errorf("Verify() = %q, %v, want voter.1", voterID, ok)
+	}
+
+	if _, ok := jc.Verify("voter.2" + token[len("voter.1"):]); ok {
+		t.Error("Verify() accepted a token moved to another voter")
+	}
+
+	jc.Rotate()
+
+	if _, ok := jc.Verify(token); ok {
+		t.Error("Verify() accepted a token for the old code")
+	}
+}
+
+func TestJoinCodeVoting(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	WithJoinCode()(server)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	join := func(code string) (int, string) {
+		body, _ := json.Marshal(map[string]string{"code": code, "voter_id": "voter-1"})
+
+		resp, err := http.Post(ts.URL+"/api/v1/join", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to join: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var out struct {
+			Token string `json:"token"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+
+		return resp.StatusCode, out.Token
+	}
+
+	if code, _ := join("nope"); code != http.StatusForbidden {
+		t.Errorf("join with a wrong code = %d, want %d", code, http.StatusForbidden)
+	}
+
+	code, token := join(server.joinCodes.Code())
+	if code != http.StatusOK || token == "" {
+		t.Fatalf("join = %d, want a token", code)
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?voter_id=voter-1", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("voter without token: err = %v, want 403", err)
+	}
+
+	spectator, _, err := websocket.DefaultDialer.Dial(wsURL+"?role=spectator", nil)
+	if err != nil {
+		t.Fatalf("spectators need no join code: %v", err)
+	}
+	defer spectator.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial(wsURL+"?"+url.Values{"voter_id": {"voter-1"}, "token": {token}}.Encode(), nil)
+	if err != nil {
+		t.Fatalf("failed to connect with token: %v", err)
+	}
+	defer voter.Close()
+
+	var msg Message
+	voter.ReadJSON(&msg) // state
+
+	server.voteManager.StartVoting("q1", []string{"a", "b"}, 2*time.Second, nil)
+	voter.ReadJSON(&msg) // voting_started
+
+	// the token is for voter-1, so voting as someone else is refused
+	_ = voter.WriteJSON(VoteMessage{Type: "vote", VoterID: "voter-2", ChoiceID: "a"})
+	_ = voter.WriteJSON(VoteMessage{Type: "vote", VoterID: "voter-1", ChoiceID: "b"})
+
+	deadline := time.Now().Add(time.Second)
+	for !server.voteManager.HasVoted("voter-1") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if server.voteManager.HasVoted("voter-2") || !server.voteManager.HasVoted("voter-1") {
+		t.Error("want only the vote of the token's voter counted")
+	}
+
+	server.voteManager.EndVoting()
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/admin/join-code/rotate", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("rotate = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	_ = voter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	for {
+		if err := voter.ReadJSON(&msg); err != nil {
+			if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+				t.Errorf("voter read error = %v, want closed for the new code", err)
+			}
+
+			break
+		}
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL+"?"+url.Values{"token": {token}}.Encode(), nil); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("old token after rotation: err = %v, want 403", err)
+	}
+}
@@ -0,0 +1,423 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Leader election timing, the same defaults as Kubernetes' own controllers.
+const (
+	leaseDuration      = 15 * time.Second // how long followers wait for a silent leader
+	leaseRenewDeadline = 10 * time.Second // how long the leader retries renewing before it steps down
+	leaseRetryPeriod   = 2 * time.Second  // how often the lease is renewed or tried
+
+	leaderURLAnnotation = "adventure-voter/leader-url"
+	forwardedHeader     = "X-Adventure-Forwarded"
+	serviceAccountDir   = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// microTime is the timestamp format of Lease fields
+	microTime = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+var errLeaseNotFound = errors.New("lease not found")
+
+// lease is the part of a coordination.k8s.io/v1 Lease the election uses.
+type lease struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Metadata   leaseMeta `json:"metadata"`
+	Spec       leaseSpec `json:"spec"`
+}
+
+type leaseMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions,omitempty"`
+}
+
+// LeaderElection elects one of several replicas of the server as the leader
+// through a Kubernetes Lease. Story and vote state live in memory, so only the
+// leader serves requests and runs vote timers; followers forward everything,
+// WebSockets included, to the URL the leader advertises on the Lease.
+type LeaderElection struct {
+	name      string
+	namespace string
+	identity  string
+	url       string // where the other replicas reach this one
+	apiURL    string
+	tokenFile string // service account token, read for every request as it rotates
+	client    *http.Client
+
+	// onChange is called when this replica becomes or stops being the leader
+	onChange func(leading bool)
+
+	mu         sync.RWMutex
+	leading    bool
+	leaderURL  string
+	proxy      *httputil.ReverseProxy // to leaderURL
+	observed   string                 // resource version of the Lease last seen
+	observedAt time.Time              // when it was first seen, by this replica's clock
+	renewedAt  time.Time              // when this replica last renewed the Lease
+}
+
+// NewLeaderElection prepares an election on the Lease name in namespace with
+// the in-cluster Kubernetes configuration. identity names this replica, such
+// as its pod name, and url is where the other replicas reach it. An empty
+// namespace is the pod's own.
+func NewLeaderElection(name, namespace, identity, url string) (*LeaderElection, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("leader election needs to run inside Kubernetes")
+	}
+
+	if namespace == "" {
+		data, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pod namespace: %w", err)
+		}
+
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("cluster CA holds no certificates")
+	}
+
+	return &LeaderElection{
+		name:      name,
+		namespace: namespace,
+		identity:  identity,
+		url:       url,
+		apiURL:    "https://" + net.JoinHostPort(host, port),
+		tokenFile: filepath.Join(serviceAccountDir, "token"),
+		client: &http.Client{
+			Timeout:   leaseRetryPeriod,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		},
+	}, nil
+}
+
+// Run takes part in the election until ctx is done, then hands the Lease
+// back if this replica holds it so another one takes over right away.
+func (le *LeaderElection) Run(ctx context.Context) {
+	ticker := time.NewTicker(leaseRetryPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := le.tryAcquireOrRenew(ctx, time.Now()); err != nil {
+			slog.Warn("Failed to acquire or renew leader lease", "lease", le.name, "error", err)
+
+			le.mu.RLock()
+			expired := le.leading && time.Since(le.renewedAt) > leaseRenewDeadline
+			le.mu.RUnlock()
+
+			if expired {
+				le.follow("")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			le.release()
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader reports whether this replica is the leader.
+func (le *LeaderElection) IsLeader() bool {
+	le.mu.RLock()
+	defer le.mu.RUnlock()
+
+	return le.leading
+}
+
+// tryAcquireOrRenew renews the Lease when this replica holds it, takes it
+// over when it is free or its holder stopped renewing it, and otherwise
+// follows its holder.
+func (le *LeaderElection) tryAcquireOrRenew(ctx context.Context, now time.Time) error {
+	current, err := le.get(ctx)
+	if errors.Is(err, errLeaseNotFound) {
+		current = &lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMeta{Name: le.name, Namespace: le.namespace},
+		}
+	} else if err != nil {
+		return err
+	}
+
+	le.mu.Lock()
+	if current.Metadata.ResourceVersion != le.observed {
+		le.observed = current.Metadata.ResourceVersion
+		le.observedAt = now
+	}
+
+	held := time.Duration(current.Spec.LeaseDurationSeconds) * time.Second
+	holder, observedAt := current.Spec.HolderIdentity, le.observedAt
+	le.mu.Unlock()
+
+	// expiry is judged by when this replica saw the Lease change, not by the
+	// holder's renew time, so clocks that disagree cannot cause two leaders
+	if holder != "" && holder != le.identity && now.Before(observedAt.Add(held)) {
+		le.follow(current.Metadata.Annotations[leaderURLAnnotation])
+
+		return nil
+	}
+
+	if holder != le.identity {
+		current.Spec.AcquireTime = now.UTC().Format(microTime)
+		if holder != "" {
+			current.Spec.LeaseTransitions++
+		}
+	}
+
+	if current.Metadata.Annotations == nil {
+		current.Metadata.Annotations = map[string]string{}
+	}
+
+	current.Metadata.Annotations[leaderURLAnnotation] = le.url
+	current.Spec.HolderIdentity = le.identity
+	current.Spec.LeaseDurationSeconds = int(leaseDuration.Seconds())
+	current.Spec.RenewTime = now.UTC().Format(microTime)
+
+	updated, err := le.put(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	le.mu.Lock()
+	le.observed = updated.Metadata.ResourceVersion
+	le.observedAt = now
+	le.renewedAt = now
+	le.mu.Unlock()
+
+	le.lead()
+
+	return nil
+}
+
+// release gives the Lease up so the other replicas need not wait for it to
+// expire.
+func (le *LeaderElection) release() {
+	if !le.IsLeader() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaseRetryPeriod)
+	defer cancel()
+
+	current, err := le.get(ctx)
+	if err != nil || current.Spec.HolderIdentity != le.identity {
+		return
+	}
+
+	current.Spec.HolderIdentity = ""
+	current.Spec.LeaseDurationSeconds = 1
+	current.Spec.RenewTime = time.Now().UTC().Format(microTime)
+
+	if _, err := le.put(ctx, current); err != nil {
+		slog.Warn("Failed to release leader lease", "lease", le.name, "error", err)
+	}
+
+	le.follow("")
+}
+
+// lead makes this replica the leader.
+func (le *LeaderElection) lead() {
+	le.mu.Lock()
+	changed := !le.leading
+	le.leading = true
+	le.leaderURL = le.url
+	le.proxy = nil
+	le.mu.Unlock()
+
+	if changed {
+		slog.Info("Became leader", "lease", le.name, "identity", le.identity)
+		le.changed(true)
+	}
+}
+
+// follow makes this replica a follower of the leader at leaderURL, or of
+// no one yet when it is empty.
+func (le *LeaderElection) follow(leaderURL string) {
+	le.mu.Lock()
+	changed := le.leading
+	le.leading = false
+
+	if leaderURL != le.leaderURL {
+		le.leaderURL = leaderURL
+		le.proxy = nil
+
+		if target, err := url.Parse(leaderURL); err == nil && leaderURL != "" {
+			le.proxy = &httputil.ReverseProxy{
+				Rewrite: func(r *httputil.ProxyRequest) {
+					r.SetURL(target)
+					r.SetXForwarded()
+					r.Out.Host = r.In.Host
+					r.Out.Header.Set(forwardedHeader, le.identity)
+				},
+			}
+		}
+	}
+	le.mu.Unlock()
+
+	if changed {
+		slog.Warn("Lost leadership", "lease", le.name, "identity", le.identity, "leader", leaderURL)
+		le.changed(false)
+	}
+}
+
+func (le *LeaderElection) changed(leading bool) {
+	if le.onChange != nil {
+		le.onChange(leading)
+	}
+}
+
+// Forward serves requests with next on the leader and forwards them to the
+// leader on followers. Until a leader is known requests are refused, and a
+// request another replica already forwarded is never forwarded again, so
+// replicas that disagree about the leader cannot pass it around in a loop.
+func (le *LeaderElection) Forward(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		le.mu.RLock()
+		leading, proxy := le.leading, le.proxy
+		le.mu.RUnlock()
+
+		if leading {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if proxy == nil || r.Header.Get(forwardedHeader) != "" {
+			w.Header().Set("Retry-After", "2")
+			http.Error(w, "no leader elected yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+func (le *LeaderElection) leaseURL() string {
+	return fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", le.apiURL, url.PathEscape(le.namespace))
+}
+
+func (le *LeaderElection) get(ctx context.Context) (*lease, error) {
+	return le.call(ctx, http.MethodGet, le.leaseURL()+"/"+url.PathEscape(le.name), nil)
+}
+
+// put writes the Lease, creating it when it has no resource version yet.
+// The resource version makes the API server refuse the write when another
+// replica changed the Lease since it was read.
+func (le *LeaderElection) put(ctx context.Context, l *lease) (*lease, error) {
+	if l.Metadata.ResourceVersion == "" {
+		return le.call(ctx, http.MethodPost, le.leaseURL(), l)
+	}
+
+	return le.call(ctx, http.MethodPut, le.leaseURL()+"/"+url.PathEscape(le.name), l)
+}
+
+func (le *LeaderElection) call(ctx context.Context, method, endpoint string, body *lease) (*lease, error) {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode lease: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	if le.tokenFile != "" {
+		token, err := os.ReadFile(le.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := le.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call kubernetes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errLeaseNotFound
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return nil, fmt.Errorf("kubernetes returned %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+
+	var out lease
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode lease: %w", err)
+	}
+
+	return &out, nil
+}
+
+// leadershipChanged keeps a replica that lost the Lease from competing with
+// the new leader: its vote stops without a result, and its clients are
+// disconnected so they reconnect, through this replica, to the leader.
+func (s *Server) leadershipChanged(leading bool) {
+	if leading {
+		return
+	}
+
+	s.voteManager.ResetVoting()
+	s.voteManager.ResetPolls()
+	s.voteManager.DisconnectAll(websocket.CloseServiceRestart, "leader changed")
+}
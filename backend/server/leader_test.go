@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeases is the Lease API of a Kubernetes API server holding one Lease.
+type fakeLeases struct {
+	mu      sync.Mutex
+	lease   *lease
+	version int
+}
+
+func (f *fakeLeases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodGet {
+		if f.lease == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(f.lease)
+
+		return
+	}
+
+	var l lease
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && f.lease != nil:
+		http.Error(w, "already exists", http.StatusConflict)
+
+		return
+	case r.Method == http.MethodPut && (f.lease == nil || l.Metadata.ResourceVersion != f.lease.Metadata.ResourceVersion):
+		http.Error(w, "the object has been modified", http.StatusConflict)
+
+		return
+	}
+
+	f.version++
+	l.Metadata.ResourceVersion = strconv.Itoa(f.version)
+	f.lease = &l
+
+	_ = json.NewEncoder(w).Encode(f.lease)
+}
+
+func TestLeaderElection(t *testing.T) {
+	api := httptest.NewServer(&fakeLeases{})
+	defer api.Close()
+
+	// each replica answers with its name, as the replica serving the request
+	replica := func(name string) (*LeaderElection, *httptest.Server, *[]bool) {
+		le := &LeaderElection{name: "voter", namespace: "default", identity: name, apiURL: api.URL, client: api.Client()}
+
+		changes := &[]bool{}
+		le.onChange = func(leading bool) { *changes = append(*changes, leading) }
+
+		srv := httptest.NewServer(le.Forward(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, name)
+		})))
+		le.url = srv.URL
+
+		return le, srv, changes
+	}
+
+	a, srvA, changesA := replica("a")
+	defer srvA.Close()
+
+	b, srvB, changesB := replica("b")
+	defer srvB.Close()
+
+	servedBy := func(srv *httptest.Server) (int, string) {
+		t.Helper()
+
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, string(body)
+	}
+
+	if code, _ := servedBy(srvB); code != http.StatusServiceUnavailable {
+		t.Errorf("before the election = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := a.tryAcquireOrRenew(ctx, now); err != nil {
+		t.Fatalf("a failed to acquire the free lease: %v", err)
+	}
+
+	if err := b.tryAcquireOrRenew(ctx, now); err != nil {
+		t.Fatalf("b failed to read the lease: %v", err)
+	}
+
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("leaders: a %v, b %v, want only a", a.IsLeader(), b.IsLeader())
+	}
+
+	if _, by := servedBy(srvB); by != "a" {
+		t.Errorf("request to b served by %q, want it forwarded to a", by)
+	}
+
+	// a keeps renewing, so b keeps following
+	if err := a.tryAcquireOrRenew(ctx, now.Add(10*time.Second)); err != nil {
+		t.Fatalf("a failed to renew: %v", err)
+	}
+
+	if err := b.tryAcquireOrRenew(ctx, now.Add(20*time.Second)); err != nil || b.IsLeader() {
+		t.Fatalf("b took over a renewed lease (err %v)", err)
+	}
+
+	// a goes silent for longer than the lease duration
+	if err := b.tryAcquireOrRenew(ctx, now.Add(40*time.Second)); err != nil || !b.IsLeader() {
+		t.Fatalf("b did not take over the expired lease (err %v)", err)
+	}
+
+	if err := a.tryAcquireOrRenew(ctx, now.Add(40*time.Second)); err != nil || a.IsLeader() {
+		t.Fatalf("a did not step down for b (err %v)", err)
+	}
+
+	if _, by := servedBy(srvA); by != "b" {
+		t.Errorf("request to a served by %q, want it forwarded to b", by)
+	}
+
+	if len(*changesA) != 2 || !(*changesA)[0] || (*changesA)[1] || len(*changesB) != 1 || !(*changesB)[0] {
+		t.Errorf("leadership changes: a %v, b %v", *changesA, *changesB)
+	}
+
+	// replicas that disagree about the leader do not forward in circles
+	req, _ := http.NewRequest("GET", srvA.URL, nil)
+	req.Header.Set(forwardedHeader, "b")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("forwarded request to a follower = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// leaderboardSize is how many voters leaderboard_update events list.
+const leaderboardSize = 10
+
+// voterStats is how one voter has fared over the session.
+type voterStats struct {
+	votes  int
+	wins   int
+	streak int
+	best   int
+}
+
+// LeaderboardEntry is one voter on the leaderboard. Voters are listed by a
+// handle derived from their voter ID, since the ID itself would let others
+// vote in their name.
+type LeaderboardEntry struct {
+	Rank   int    `json:"rank"`
+	Player string `json:"player"`
+	Votes  int    `json:"votes"`
+	Wins   int    `json:"wins"`        // votes on the winning side
+	Streak int    `json:"streak"`      // consecutive decisions on the winning side, up to now
+	Best   int    `json:"best_streak"` // longest streak so far
+}
+
+// playerHandle is the public name of a voter on the leaderboard.
+func playerHandle(voterID string) string {
+	sum := sha256.Sum256([]byte(voterID))
+
+	return "adventurer-" + hex.EncodeToString(sum[:3])
+}
+
+// scoreDecision updates the statistics of every voter once a vote ends.
+// Voters who sat out the vote lose their streak. Callers must hold vm.mu.
+func (vm *VoteManager) scoreDecision(winner string) {
+	for voterID, choiceID := range vm.vote.voters {
+		stats, ok := vm.voterStats[voterID]
+		if !ok {
+			stats = &voterStats{}
+			vm.voterStats[voterID] = stats
+		}
+
+		stats.votes++
+
+		if choiceID == winner {
+			stats.wins++
+			stats.streak++
+			stats.best = max(stats.best, stats.streak)
+		} else {
+			stats.streak = 0
+		}
+	}
+
+	for voterID, stats := range vm.voterStats {
+		if _, voted := vm.vote.voters[voterID]; !voted {
+			stats.streak = 0
+		}
+	}
+}
+
+// leaderboard ranks every voter by wins, then by current streak, then by the
+// fewest votes needed. It returns the entries and each voter's position.
+// Callers must hold vm.mu.
+func (vm *VoteManager) leaderboard() ([]LeaderboardEntry, map[string]int) {
+	ids := make([]string, 0, len(vm.voterStats))
+	for id := range vm.voterStats {
+		ids = append(ids, id)
+	}
+
+	slices.SortFunc(ids, func(a, b string) int {
+		sa, sb := vm.voterStats[a], vm.voterStats[b]
+
+		return cmp.Or(
+			cmp.Compare(sb.wins, sa.wins),
+			cmp.Compare(sb.streak, sa.streak),
+			cmp.Compare(sa.votes, sb.votes),
+			cmp.Compare(a, b),
+		)
+	})
+
+	entries := make([]LeaderboardEntry, len(ids))
+	positions := make(map[string]int, len(ids))
+
+	for i, id := range ids {
+		stats := vm.voterStats[id]
+		entries[i] = LeaderboardEntry{
+			Rank:   i + 1,
+			Player: playerHandle(id),
+			Votes:  stats.votes,
+			Wins:   stats.wins,
+			Streak: stats.streak,
+			Best:   stats.best,
+		}
+		positions[id] = i
+	}
+
+	return entries, positions
+}
+
+// Leaderboard returns the top n voters of the session, or all of them when n
+// is not positive.
+func (vm *VoteManager) Leaderboard(n int) []LeaderboardEntry {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	entries, _ := vm.leaderboard()
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+
+	return entries
+}
+
+// ResetLeaderboard forgets every voter's statistics.
+func (vm *VoteManager) ResetLeaderboard() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vm.voterStats = make(map[string]*voterStats)
+}
+
+// sendLeaderboard tells everyone the top voters after a decision. Voters also
+// get their own entry as "you", so those outside the top can see where they
+// stand. Callers must hold vm.mu.
+func (vm *VoteManager) sendLeaderboard() {
+	entries, positions := vm.leaderboard()
+	top := entries[:min(len(entries), leaderboardSize)]
+
+	vm.broadcast <- &Message{
+		Type: "leaderboard_update",
+		personal: func(client *Client) map[string]any {
+			payload := map[string]any{"top": top}
+
+			if i, ok := positions[client.voter()]; ok && client.Role == RoleVoter {
+				payload["you"] = entries[i]
+			}
+
+			return payload
+		},
+	}
+}
+
+// handleGetLeaderboard returns the top voters of the session, ten unless
+// ?limit= asks for another number, or 0 for everyone.
+func (s *Server) handleGetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := leaderboardSize
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+
+			return
+		}
+
+		limit = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"leaderboard": s.voteManager.Leaderboard(limit),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
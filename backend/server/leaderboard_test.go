@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLeaderboard(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	vm := server.voteManager
+
+	decide := func(ballots map[string]string) {
+		vm.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+
+		for voterID, choiceID := range ballots {
+			if err := vm.SubmitVote(voterID, choiceID); err != nil {
+				t.Fatalf("SubmitVote() error = %v", err)
+			}
+		}
+
+		vm.EndVoting()
+	}
+
+	decide(map[string]string{"alice": "opt-a", "bob": "opt-a", "carol": "opt-b"})
+	decide(map[string]string{"alice": "opt-a", "bob": "opt-b", "carol": "opt-a"})
+	decide(map[string]string{"bob": "opt-b", "carol": "opt-b", "dave": "opt-a"})
+
+	want := []LeaderboardEntry{
+		{Rank: 1, Player: playerHandle("carol"), Votes: 3, Wins: 2, Streak: 2, Best: 2},
+		{Rank: 2, Player: playerHandle("bob"), Votes: 3, Wins: 2, Streak: 1, Best: 1},
+		{Rank: 3, Player: playerHandle("alice"), Votes: 2, Wins: 2, Streak: 0, Best: 2},
+		{Rank: 4, Player: playerHandle("dave"), Votes: 1, Wins: 0, Streak: 0, Best: 0},
+	}
+
+	got := vm.Leaderboard(0)
+	if len(got) != len(want) {
+		t.Fatalf("Leaderboard() = %+v, want %+v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/leaderboard?limit=2", nil))
+
+	var body struct {
+		Leaderboard []LeaderboardEntry `json:"leaderboard"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode leaderboard: %v", err)
+	}
+
+	if len(body.Leaderboard) != 2 || body.Leaderboard[0].Player != playerHandle("carol") {
+		t.Errorf("leaderboard?limit=2 = %+v, want carol and bob", body.Leaderboard)
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/leaderboard?limit=-1", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("negative limit status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"time"
+)
+
+// HTTPLimits are the timeouts and size limits of the HTTP server. Zero values
+// mean what they mean for an http.Server, and a zero MaxBodyBytes lifts the
+// limit on request bodies.
+type HTTPLimits struct {
+	ReadHeaderTimeout time.Duration // to read the request headers
+	ReadTimeout       time.Duration // to read the whole request, body included
+	WriteTimeout      time.Duration // to write the response, from the end of the request headers
+	IdleTimeout       time.Duration // to wait for the next request on a kept-alive connection
+	MaxHeaderBytes    int
+	MaxBodyBytes      int64
+}
+
+// DefaultHTTPLimits keep slow or stuck clients from holding connections
+// forever while leaving room for large assets on poor venue networks. The
+// timeouts end with the upgrade of a WebSocket connection, since net/http
+// lifts the deadlines of hijacked connections.
+var DefaultHTTPLimits = HTTPLimits{
+	ReadHeaderTimeout: 5 * time.Second,
+	ReadTimeout:       10 * time.Second,
+	WriteTimeout:      time.Minute,
+	IdleTimeout:       time.Minute,
+	MaxHeaderBytes:    64 << 10,
+	MaxBodyBytes:      1 << 20,
+}
+
+// limitRequests caps request bodies at MaxBodyBytes.
+func (l HTTPLimits) limitRequests(next http.Handler) http.Handler {
+	if l.MaxBodyBytes <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, l.MaxBodyBytes)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpServer returns the HTTP server serving handler within the limits.
+func (l HTTPLimits) httpServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           l.limitRequests(handler),
+		ReadHeaderTimeout: l.ReadHeaderTimeout,
+		ReadTimeout:       l.ReadTimeout,
+		WriteTimeout:      l.WriteTimeout,
+		IdleTimeout:       l.IdleTimeout,
+		MaxHeaderBytes:    l.MaxHeaderBytes,
+	}
+}
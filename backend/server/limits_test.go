@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestHTTPLimitsBody(t *testing.T) {
+	limits := HTTPLimits{MaxBodyBytes: 8}
+	handler := limits.limitRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+
+				return
+			}
+		}
+	}))
+
+	for body, want := range map[string]int{"short": http.StatusOK, "far too long": http.StatusRequestEntityTooLarge} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+		if w.Code != want {
+			t.Errorf("body %q = %d, want %d", body, w.Code, want)
+		}
+	}
+}
+
+// TestHTTPLimitsWebSocket keeps a WebSocket connection, forwarded as to a
+// leader, open for longer than the read and write timeouts.
+func TestHTTPLimitsWebSocket(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	backend := httptest.NewServer(server.router)
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	limits := HTTPLimits{ReadTimeout: 100 * time.Millisecond, WriteTimeout: 100 * time.Millisecond, MaxBodyBytes: 1 << 10}
+
+	front := httptest.NewUnstartedServer(nil)
+	front.Config = limits.httpServer(httputil.NewSingleHostReverseProxy(backendURL))
+	front.Start()
+	defer front.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(front.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	var msg Message
+	if err := voter.ReadJSON(&msg); err != nil {
+		t.Fatalf("failed to read state: %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	server.voteManager.StartVoting("q1", []string{"a", "b"}, time.Minute, nil)
+
+	if err := voter.ReadJSON(&msg); err != nil || msg.Type != "voting_started" {
+		t.Fatalf("got %q, %v after the timeouts, want voting_started", msg.Type, err)
+	}
+}
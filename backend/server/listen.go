@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// shutdownTimeout is how long requests in flight get to finish when the
+// server stops.
+const shutdownTimeout = 10 * time.Second
+
+// Listen opens a listener for each of the comma-separated listen specs:
+// host:port or tcp://host:port for TCP, unix:///path/to.sock for a Unix
+// socket, and fd:// for the sockets systemd passed on socket activation.
+// Socket files are removed again when their listeners close.
+func Listen(specs string) ([]net.Listener, error) {
+	var listeners []net.Listener
+
+	closeAll := func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}
+
+	for spec := range strings.SplitSeq(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		opened, err := listen(spec)
+		if err != nil {
+			closeAll()
+
+			return nil, fmt.Errorf("failed to listen on %s: %w", spec, err)
+		}
+
+		listeners = append(listeners, opened...)
+	}
+
+	if len(listeners) == 0 {
+		return nil, errors.New("no address to listen on")
+	}
+
+	return listeners, nil
+}
+
+// listen opens the listeners of one listen spec.
+func listen(spec string) ([]net.Listener, error) {
+	switch {
+	case strings.HasPrefix(spec, "unix://"):
+		return listenUnix(strings.TrimPrefix(spec, "unix://"))
+	case spec == "fd://":
+		return systemdListeners()
+	default:
+		l, err := net.Listen("tcp", strings.TrimPrefix(spec, "tcp://"))
+		if err != nil {
+			return nil, err
+		}
+
+		return []net.Listener{l}, nil
+	}
+}
+
+// listenUnix listens on a Unix socket at path, replacing the socket file a
+// server that didn't shut down cleanly left behind.
+func listenUnix(path string) ([]net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	return []net.Listener{l}, nil
+}
+
+// systemdListeners returns the sockets systemd passed to this process, as
+// described by the LISTEN_PID and LISTEN_FDS environment variables.
+func systemdListeners() ([]net.Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, errors.New("no sockets passed by systemd")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, errors.New("no sockets passed by systemd")
+	}
+
+	// passed sockets start after stdin, stdout and stderr
+	const firstFD = 3
+
+	listeners := make([]net.Listener, 0, count)
+
+	for fd := firstFD; fd < firstFD+count; fd++ {
+		file := os.NewFile(uintptr(fd), "systemd-socket-"+strconv.Itoa(fd))
+
+		l, err := net.FileListener(file)
+		_ = file.Close()
+
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+
+			return nil, fmt.Errorf("socket %d: %w", fd, err)
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	return listeners, nil
+}
+
+// serve serves handler on every listener until ctx is done, then lets
+// requests in flight finish, disconnects WebSocket clients and closes the
+// listeners.
+func (s *Server) serve(ctx context.Context, handler http.Handler, listeners []net.Listener) error {
+	server := s.httpLimits.httpServer(handler)
+	errs := make(chan error, len(listeners))
+
+	for _, l := range listeners {
+		slog.Info("Listening", "network", l.Addr().Network(), "addr", l.Addr().String())
+
+		go func() {
+			errs <- server.Serve(l)
+		}()
+	}
+
+	select {
+	case err := <-errs:
+		_ = server.Close()
+
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// hijacked connections are left alone by Shutdown
+	s.voteManager.DisconnectAll(websocket.CloseGoingAway, "server shutting down")
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+
+	return s.Close()
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListen(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "adventure.sock")
+
+	listeners, err := Listen("127.0.0.1:0, unix://" + socket)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	if len(listeners) != 2 || listeners[0].Addr().Network() != "tcp" || listeners[1].Addr().Network() != "unix" {
+		t.Fatalf("listeners = %v, want one over TCP and one over a Unix socket", listeners)
+	}
+
+	if _, err := Listen("unix://" + socket); err == nil {
+		t.Error("Listen() on a socket in use succeeded, want an error")
+	}
+
+	for _, l := range listeners {
+		_ = l.Close()
+	}
+
+	if _, err := os.Stat(socket); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("socket file after close: %v, want it removed", err)
+	}
+
+	if _, err := Listen(" , "); err == nil {
+		t.Error("Listen() without addresses succeeded, want an error")
+	}
+
+	t.Setenv("LISTEN_PID", "")
+
+	if _, err := Listen("fd://"); err == nil {
+		t.Error("Listen(fd://) without systemd succeeded, want an error")
+	}
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "adventure.sock")
+
+	// a socket file nobody listens on, as a crashed server leaves behind
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	if err != nil {
+		t.Fatalf("failed to create socket: %v", err)
+	}
+
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	listeners, err := Listen("unix://" + socket)
+	if err != nil {
+		t.Fatalf("Listen() over a stale socket error = %v", err)
+	}
+
+	_ = listeners[0].Close()
+}
+
+func TestStartUnixSocket(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	socket := filepath.Join(t.TempDir(), "adventure.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Start(ctx, "unix://"+socket)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for range 50 {
+		if resp, err = client.Get("http://adventure/api/v1/state"); err == nil {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatalf("GET over the socket error = %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("state = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Start() error = %v, want a clean shutdown", err)
+	}
+
+	if _, err := os.Stat(socket); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("socket file after shutdown: %v, want it removed", err)
+	}
+}
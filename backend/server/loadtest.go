@@ -0,0 +1,315 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// LoadTest connects bot voters to a running server and has them vote in
+// every vote the presenter starts, to check before the show that the server
+// and the venue's network keep up with the audience.
+type LoadTest struct {
+	URL      string        // the server, such as http://localhost:8080
+	Bots     int           // voters to connect
+	Ramp     time.Duration // bots connect spread evenly over this long
+	MinDelay time.Duration // bots vote at a random time between MinDelay
+	MaxDelay time.Duration // and MaxDelay after a vote starts
+	Weights  []float64     // relative chance of each choice, in choice order; missing weights count as 1
+	Votes    int           // votes to take part in before stopping, 0 to run until the context ends
+	Dialer   *websocket.Dialer
+}
+
+// LoadTestReport sums up a load test. Latency is how long a vote takes from
+// being sent until the bot sees it counted, by then everyone else sees it too.
+type LoadTestReport struct {
+	Connected int // bots that connected
+	Failed    int // bots that could not connect
+	Dropped   int // bots whose connection was lost before the test ended
+	Sent      int // votes sent
+	Counted   int // votes confirmed counted
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+// Run connects the bots and lets them vote until the given number of votes
+// ended or ctx is done.
+func (lt *LoadTest) Run(ctx context.Context) (LoadTestReport, error) {
+	target, err := url.Parse(lt.URL)
+	if err != nil {
+		return LoadTestReport{}, fmt.Errorf("invalid server URL: %w", err)
+	}
+
+	switch target.Scheme {
+	case "http", "ws":
+		target.Scheme = "ws"
+	case "https", "wss":
+		target.Scheme = "wss"
+	default:
+		return LoadTestReport{}, fmt.Errorf("invalid server URL %q: want http or https", lt.URL)
+	}
+
+	target.Path = "/ws"
+
+	dialer := lt.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		report    LoadTestReport
+		latencies []time.Duration
+	)
+
+	// voter IDs of this run, so bots of an earlier run don't hold on to votes
+	run := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	for i := range lt.Bots {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if lt.Bots > 1 {
+				select {
+				case <-time.After(lt.Ramp * time.Duration(i) / time.Duration(lt.Bots)):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			voterID := fmt.Sprintf("loadtest-%s-%d", run, i)
+
+			query := url.Values{"voter_id": {voterID}}
+			botURL := *target
+			botURL.RawQuery = query.Encode()
+
+			conn, _, err := dialer.DialContext(ctx, botURL.String(), nil)
+			if err != nil {
+				mu.Lock()
+				report.Failed++
+				mu.Unlock()
+
+				return
+			}
+
+			mu.Lock()
+			report.Connected++
+			mu.Unlock()
+
+			b := &bot{test: lt, conn: conn, voterID: voterID, rng: rand.New(rand.NewPCG(uint64(i), uint64(time.Now().UnixNano())))} //nolint:gosec // voting patterns need no secure randomness
+			sent, counted, dropped := b.run(ctx)
+
+			mu.Lock()
+			report.Sent += sent
+			report.Counted += len(counted)
+			latencies = append(latencies, counted...)
+
+			if dropped {
+				report.Dropped++
+			}
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	slices.Sort(latencies)
+
+	report.P50 = percentile(latencies, 0.50)
+	report.P90 = percentile(latencies, 0.90)
+	report.P99 = percentile(latencies, 0.99)
+	report.Max = percentile(latencies, 1)
+
+	return report, nil
+}
+
+// percentile returns the q-th quantile of sorted durations.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[max(0, int(math.Ceil(q*float64(len(sorted))))-1)]
+}
+
+// bot is one load test voter.
+type bot struct {
+	test    *LoadTest
+	conn    *websocket.Conn
+	voterID string
+	rng     *rand.Rand
+
+	mu      sync.Mutex           // guards writes to conn, pending and sent
+	pending map[string]time.Time // ack -> when the vote was sent
+	sent    int
+}
+
+// botMessage is what a bot reads of the server's messages.
+type botMessage struct {
+	Type    string `json:"type"`
+	Payload struct {
+		QuestionID   string            `json:"question_id"`
+		VotingActive bool              `json:"voting_active"`
+		Choices      []json.RawMessage `json:"choices"`
+		YourChoice   string            `json:"your_choice"`
+		Ack          string            `json:"ack"`
+	} `json:"payload"`
+}
+
+// run takes part in votes until enough of them ended or ctx is done. It
+// returns how many votes the bot sent, the latencies of those confirmed
+// counted, and whether the connection was lost.
+func (b *bot) run(ctx context.Context) (int, []time.Duration, bool) {
+	b.pending = map[string]time.Time{}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		_ = b.conn.Close()
+	}()
+
+	var (
+		counted []time.Duration
+		timer   *time.Timer
+		ended   int
+	)
+
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		var msg botMessage
+		if err := b.conn.ReadJSON(&msg); err != nil {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			return b.sent, counted, ctx.Err() == nil && (b.test.Votes == 0 || ended < b.test.Votes)
+		}
+
+		switch {
+		case msg.Type == "voting_started" || msg.Type == "state" && msg.Payload.VotingActive && msg.Payload.YourChoice == "":
+			if timer != nil {
+				timer.Stop()
+			}
+
+			if choice := b.choose(msg.Payload.Choices); choice != "" {
+				timer = time.AfterFunc(b.delay(), func() { b.vote(choice) })
+			}
+		case msg.Type == "vote_ack":
+			b.mu.Lock()
+			if sentAt, ok := b.pending[msg.Payload.Ack]; ok {
+				counted = append(counted, time.Since(sentAt))
+				delete(b.pending, msg.Payload.Ack)
+			}
+			b.mu.Unlock()
+		case msg.Type == "voting_ended":
+			if timer != nil {
+				timer.Stop()
+			}
+
+			if ended++; b.test.Votes > 0 && ended >= b.test.Votes {
+				// give the last acks a moment before hanging up
+				_ = b.conn.SetReadDeadline(time.Now().Add(time.Second))
+			}
+		}
+	}
+}
+
+// delay is how long after a vote starts the bot votes.
+func (b *bot) delay() time.Duration {
+	spread := b.test.MaxDelay - b.test.MinDelay
+	if spread <= 0 {
+		return b.test.MinDelay
+	}
+
+	return b.test.MinDelay + time.Duration(b.rng.Int64N(int64(spread)))
+}
+
+// choose picks one of the open choices, which arrive either as IDs or as
+// choice objects, according to the weights.
+func (b *bot) choose(raw []json.RawMessage) string {
+	var ids []string
+
+	for _, r := range raw {
+		var id string
+		if json.Unmarshal(r, &id) == nil {
+			ids = append(ids, id)
+
+			continue
+		}
+
+		var choice struct {
+			ID     string
+			Locked bool
+		}
+
+		if json.Unmarshal(r, &choice) == nil && !choice.Locked && choice.ID != "" {
+			ids = append(ids, choice.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return ""
+	}
+
+	weight := func(i int) float64 {
+		if i < len(b.test.Weights) {
+			return max(0, b.test.Weights[i])
+		}
+
+		return 1
+	}
+
+	total := 0.0
+	for i := range ids {
+		total += weight(i)
+	}
+
+	if total == 0 {
+		return ids[b.rng.IntN(len(ids))]
+	}
+
+	n := b.rng.Float64() * total
+	for i, id := range ids {
+		if n -= weight(i); n < 0 {
+			return id
+		}
+	}
+
+	return ids[len(ids)-1]
+}
+
+// vote sends a vote, asking the server to confirm once it is counted.
+func (b *bot) vote(choiceID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sent++
+	ack := strconv.Itoa(b.sent)
+	b.pending[ack] = time.Now()
+
+	_ = b.conn.WriteJSON(VoteMessage{Type: "vote", VoterID: b.voterID, ChoiceID: choiceID, Ack: ack})
+}
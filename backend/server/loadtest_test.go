@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadTest(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	// bots that join while the vote is open pick it up from the state
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, 500*time.Millisecond, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lt := &LoadTest{
+		URL:      ts.URL,
+		Bots:     5,
+		Ramp:     50 * time.Millisecond,
+		MinDelay: 10 * time.Millisecond,
+		MaxDelay: 50 * time.Millisecond,
+		Weights:  []float64{1, 0},
+		Votes:    1,
+	}
+
+	report, err := lt.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if ctx.Err() != nil {
+		t.Fatal("load test did not stop after the vote ended")
+	}
+
+	if report.Connected != 5 || report.Failed != 0 || report.Dropped != 0 || report.Sent != 5 || report.Counted != 5 {
+		t.Errorf("report = %+v, want 5 bots each with a counted vote", report)
+	}
+
+	if report.P50 <= 0 || report.P50 > report.P99 || report.P99 > report.Max {
+		t.Errorf("latencies p50 %v, p99 %v, max %v are out of order", report.P50, report.P99, report.Max)
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-a"] != 5 || got["opt-b"] != 0 {
+		t.Errorf("results = %v, want every bot on the only weighted choice", got)
+	}
+
+	lt.URL = "ftp://example.com"
+	if _, err := lt.Run(ctx); err == nil {
+		t.Error("Run() with an ftp URL succeeded")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	for q, want := range map[float64]time.Duration{0.5: 5, 0.9: 9, 0.99: 10, 1: 10} {
+		if got := percentile(sorted, q); got != want {
+			t.Errorf("percentile(%v) = %v, want %v", q, got, want)
+		}
+	}
+
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("percentile of nothing = %v, want 0", got)
+	}
+}
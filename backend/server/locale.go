@@ -0,0 +1,64 @@
+package server
+
+import (
+	"maps"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// translations renders a chapter payload, one with id, metadata and content,
+// in every language connected clients asked for and that the chapter has a
+// translation for. Callers must hold s.mu.
+func (s *Server) translations(state parser.State, payload map[string]any) map[string]map[string]any {
+	id, _ := payload["id"].(string)
+	out := map[string]map[string]any{}
+
+	for _, lang := range s.voteManager.Languages() {
+		chapter, err := s.localizedChapter(id, lang)
+		if err != nil || chapter.Lang == "" {
+			continue
+		}
+
+		chapter = s.present(chapter, state)
+
+		localized := maps.Clone(payload)
+		localized["metadata"] = chapter.Metadata
+		localized["content"] = chapter.Content
+		localized["lang"] = chapter.Lang
+		out[lang] = localized
+	}
+
+	return out
+}
+
+// broadcastChapter sends a chapter payload to every client in its language,
+// and to presenters with the chapter's speaker notes. The progress of the
+// chapter is added to the payload. Callers must hold s.mu.
+func (s *Server) broadcastChapter(msgType string, state parser.State, payload map[string]any) {
+	if id, ok := payload["id"].(string); ok {
+		payload["progress"] = s.progress(id)
+	}
+
+	s.voteManager.BroadcastLocalized(msgType, payload, s.translations(state, payload), s.withNotes(payload))
+}
+
+// questionTranslations returns the question and choices of a decision in
+// every language connected clients asked for. Callers must hold s.mu.
+func (s *Server) questionTranslations(id string, state parser.State) map[string]LocalizedQuestion {
+	out := map[string]LocalizedQuestion{}
+
+	for _, lang := range s.voteManager.Languages() {
+		chapter, err := s.localizedChapter(id, lang)
+		if err != nil || chapter.Lang == "" {
+			continue
+		}
+
+		chapter = withInventory(chapter, state)
+		out[lang] = LocalizedQuestion{
+			Question: chapter.Metadata.Question,
+			Choices:  withPreviewURLs(s.basePath, chapter.Metadata.Choices),
+		}
+	}
+
+	return out
+}
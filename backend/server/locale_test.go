@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestLocalizedVoting(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	german := `---
+question: Wähle deinen Weg
+choices:
+  - id: opt-a
+    label: Option Eins
+---
+# Wähle deinen Weg`
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "choice.de.md"), []byte(german), 0600); err != nil {
+		t.Fatalf("failed to write translation: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	dial := func(query string) *websocket.Conn {
+		t.Helper()
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+query, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+
+		return conn
+	}
+
+	english, deutsch := dial(""), dial("?lang=de-DE")
+	defer english.Close()
+	defer deutsch.Close()
+
+	// wait until both clients are registered
+	for _, conn := range []*websocket.Conn{english, deutsch} {
+		var msg Message
+		conn.ReadJSON(&msg) // state
+	}
+
+	post := func(path string, body any) {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	post("/api/v1/advance", map[string]any{})
+	post("/api/v1/start-voting", map[string]any{
+		"question_id": "choice1",
+		"choices":     []string{"opt-a", "opt-b"},
+		"duration":    60,
+	})
+
+	read := func(conn *websocket.Conn, msgType string) map[string]any {
+		t.Helper()
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+		for {
+			var msg Message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("no %s message: %v", msgType, err)
+			}
+
+			if msg.Type == msgType {
+				return msg.Payload
+			}
+		}
+	}
+
+	label := func(payload map[string]any) string {
+		choices, _ := payload["choices"].([]any)
+		if len(choices) == 0 {
+			return ""
+		}
+
+		first, _ := choices[0].(map[string]any)
+		label, _ := first["Label"].(string)
+
+		return label
+	}
+
+	for _, tt := range []struct {
+		name         string
+		conn         *websocket.Conn
+		wantContent  string
+		wantQuestion string
+		wantLabel    string
+	}{
+		{"english", english, "Choose your path</h1>", "Choose your path", "Option A"},
+		{"german", deutsch, "Wähle deinen Weg</h1>", "Wähle deinen Weg", "Option Eins"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := read(tt.conn, "chapter_changed")
+			if content, _ := changed["content"].(string); !strings.Contains(content, tt.wantContent) {
+				t.Errorf("chapter_changed content = %q, want %q", content, tt.wantContent)
+			}
+
+			started := read(tt.conn, "voting_started")
+			if started["question"] != tt.wantQuestion || label(started) != tt.wantLabel {
+				t.Errorf("voting_started = %v, want %q with %q", started, tt.wantQuestion, tt.wantLabel)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/current?lang=de", nil))
+
+	if !strings.Contains(w.Body.String(), `"lang":"de"`) {
+		t.Errorf("current chapter = %s, want the German translation", w.Body.String())
+	}
+}
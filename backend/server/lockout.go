@@ -0,0 +1,160 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// lockoutFreeAttempts is how many wrong secrets an address may send
+	// before it has to wait.
+	lockoutFreeAttempts = 5
+	// lockoutBase is the first wait, doubling with every further failure up
+	// to lockoutMax.
+	lockoutBase = time.Second
+	lockoutMax  = 15 * time.Minute
+)
+
+// failedLogins are the recent failures of one address.
+type failedLogins struct {
+	count int
+	last  time.Time
+	until time.Time // when the address may try again
+}
+
+// lockout slows down guessing secrets: after lockoutFreeAttempts failures an
+// address is refused for a while, twice as long after every further failure.
+// Failures are forgotten lockoutMax after the last one.
+type lockout struct {
+	mu       sync.Mutex
+	failures map[string]*failedLogins
+	now      func() time.Time
+}
+
+func newLockout() *lockout {
+	return &lockout{failures: map[string]*failedLogins{}, now: time.Now}
+}
+
+// wait returns how long addr has to wait before trying again.
+func (l *lockout) wait(addr string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[addr]
+	if !ok {
+		return 0
+	}
+
+	return max(0, f.until.Sub(l.now()))
+}
+
+// fail records a failed login from addr.
+func (l *lockout) fail(addr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	for key, f := range l.failures {
+		if now.Sub(f.last) > lockoutMax && now.After(f.until) {
+			delete(l.failures, key)
+		}
+	}
+
+	f, ok := l.failures[addr]
+	if !ok {
+		f = &failedLogins{}
+		l.failures[addr] = f
+	}
+
+	f.count++
+	f.last = now
+
+	if extra := f.count - lockoutFreeAttempts; extra > 0 {
+		wait := lockoutMax
+		if extra <= 20 {
+			wait = min(lockoutMax, lockoutBase<<(extra-1))
+		}
+
+		f.until = now.Add(wait)
+	}
+}
+
+// reset forgets the failures of addr after it logged in.
+func (l *lockout) reset(addr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, addr)
+}
+
+// clientAddr is the address failed logins are counted against.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// refuse answers 429 Too Many Requests to clients that have to wait after
+// failed logins, and reports whether it did.
+func (l *lockout) refuse(w http.ResponseWriter, r *http.Request) bool {
+	wait := l.wait(clientAddr(r))
+	if wait <= 0 {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, "too many failed logins, try again later", http.StatusTooManyRequests)
+
+	return true
+}
+
+// record counts a wrong credential against the client, or clears its
+// failures when it got in. Expired or forged tokens don't count: guessing
+// them is hopeless, and a presenter whose session ran out should not be
+// locked out for it.
+func (l *lockout) record(r *http.Request, credential string, ok bool) {
+	switch {
+	case credential == "":
+	case ok:
+		l.reset(clientAddr(r))
+	case !strings.HasPrefix(credential, tokenHeader+"."):
+		l.fail(clientAddr(r))
+		requestLogger(r).Warn("Failed login", "remote", r.RemoteAddr)
+	}
+}
+
+// lockedOut refuses clients that have to wait after guessing presenter
+// secrets wrong, and reports whether it did.
+func (s *Server) lockedOut(w http.ResponseWriter, r *http.Request) bool {
+	return s.lockout.refuse(w, r)
+}
+
+// recordLogin counts a wrong presenter secret against the client, or clears
+// its failures when it logged in.
+func (s *Server) recordLogin(r *http.Request, credential string, ok bool) {
+	s.lockout.record(r, credential, ok)
+}
+
+// joinLockedOut refuses clients that have to wait after guessing join or
+// roster codes wrong, and reports whether it did. Voters have their own
+// count, so voters behind the presenter's address mistyping the code don't
+// lock the presenter out, and the public join code never clears failed
+// presenter logins.
+func (s *Server) joinLockedOut(w http.ResponseWriter, r *http.Request) bool {
+	return s.joinLockout.refuse(w, r)
+}
+
+// recordJoin counts a wrong join or roster code against the client, or
+// clears its failed joins when it got in.
+func (s *Server) recordJoin(r *http.Request, code string, ok bool) {
+	s.joinLockout.record(r, code, ok)
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockout(t *testing.T) {
+	now := time.Now()
+	l := newLockout()
+	l.now = func() time.Time { return now }
+
+	for range lockoutFreeAttempts {
+		l.fail("198.51.100.7")
+	}
+
+	if wait := l.wait("198.51.100.7"); wait != 0 {
+		t.Fatalf("wait after %d failures = %v, want none", lockoutFreeAttempts, wait)
+	}
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		l.fail("198.51.100.7")
+
+		if wait := l.wait("198.51.100.7"); wait != want {
+			t.Errorf("wait after %d more failures = %v, want %v", i+1, wait, want)
+		}
+	}
+
+	if wait := l.wait("198.51.100.8"); wait != 0 {
+		t.Errorf("another address waits %v, want none", wait)
+	}
+
+	for range 30 {
+		l.fail("198.51.100.7")
+	}
+
+	if wait := l.wait("198.51.100.7"); wait != lockoutMax {
+		t.Errorf("wait after many failures = %v, want the maximum %v", wait, lockoutMax)
+	}
+
+	l.reset("198.51.100.7")
+
+	if wait := l.wait("198.51.100.7"); wait != 0 {
+		t.Errorf("wait after logging in = %v, want none", wait)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "lead-secret"
+
+	get := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/story/outline", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// browsers ask without credentials first, which is no failure
+	for range 10 {
+		get("")
+	}
+
+	// nor are expired or forged tokens
+	old, _, _ := server.issueToken(RolePresenter, time.Now().Add(-2*time.Hour), time.Hour)
+	for range 10 {
+		get("Bearer " + old)
+	}
+
+	for range lockoutFreeAttempts {
+		if w := get("Bearer guess"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong secret = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	get("Bearer guess")
+
+	w := get("Bearer lead-secret")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("right secret while locked out = %d, want %d with Retry-After", w.Code, http.StatusTooManyRequests)
+	}
+
+	server.lockout.now = func() time.Time { return time.Now().Add(2 * time.Second) }
+
+	if w := get("Bearer lead-secret"); w.Code != http.StatusOK {
+		t.Errorf("right secret after waiting = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if w := get("Bearer guess"); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong secret after logging in = %d, want a fresh count", w.Code)
+	}
+}
+
+func TestJoinLockoutApartFromLogins(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "lead-secret"
+	server.joinCodes = NewJoinCodes()
+
+	login := func(auth string) int {
+		req := httptest.NewRequest("GET", "/api/v1/story/outline", nil)
+		req.Header.Set("Authorization", auth)
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	join := func(code string) int {
+		req := httptest.NewRequest("POST", "/api/v1/join", strings.NewReader(`{"code":"`+code+`","voter_id":"voter-1"}`))
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	// voters mistyping the code don't lock the presenter out
+	for range lockoutFreeAttempts + 1 {
+		join("WRONG1")
+	}
+
+	if code := join(server.joinCodes.Code()); code != http.StatusTooManyRequests {
+		t.Errorf("join after too many wrong codes = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	if code := login("Bearer lead-secret"); code != http.StatusOK {
+		t.Errorf("presenter login after wrong join codes = %d, want %d", code, http.StatusOK)
+	}
+
+	// and the public join code doesn't clear failed presenter logins
+	server.joinLockout.reset("192.0.2.1")
+
+	for range lockoutFreeAttempts {
+		login("Bearer guess")
+		join(server.joinCodes.Code())
+	}
+
+	login("Bearer guess")
+
+	if code := login("Bearer lead-secret"); code != http.StatusTooManyRequests {
+		t.Errorf("login after wrong secrets between joins = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
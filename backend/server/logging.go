@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type requestIDKey struct{}
+
+// maxRequestIDLength caps incoming request IDs, which end up in every log line.
+const maxRequestIDLength = 64
+
+// NewLogger builds a slog logger writing to w in the given format ("text" or
+// "json") at the given level ("debug", "info", "warn" or "error").
+func NewLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "text", "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q (text or json)", format)
+	}
+}
+
+// withRequestID tags every request with an ID, reusing an incoming
+// X-Request-ID when a proxy already assigned a valid one, and echoes it back.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		r = r.WithContext(ctx)
+
+		requestLogger(r).Debug("request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestLogger returns the default logger annotated with the request ID.
+func requestLogger(r *http.Request) *slog.Logger {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	if id == "" {
+		return slog.Default()
+	}
+
+	return slog.Default().With("request_id", id)
+}
+
+// validRequestID reports whether an incoming request ID is safe to log and
+// echo: at most maxRequestIDLength letters, digits, dots, underscores and
+// dashes.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
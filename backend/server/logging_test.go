@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{"text info", "text", "info", false},
+		{"json debug", "json", "debug", false},
+		{"uppercase format", "JSON", "warn", false},
+		{"invalid format", "xml", "info", true},
+		{"invalid level", "text", "loud", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLogger(&bytes.Buffer{}, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLogger_JSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := NewLogger(&buf, "json", "info")
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+
+	logger.Debug("hidden")
+	logger.Info("Chapter changed", "chapter_id", "intro")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", buf.String(), err)
+	}
+
+	if entry["chapter_id"] != "intro" {
+		t.Errorf("chapter_id = %v, want %q", entry["chapter_id"], "intro")
+	}
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	t.Run("generates an ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/chapter/current", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Header().Get("X-Request-ID") == "" {
+			t.Error("expected X-Request-ID response header")
+		}
+	})
+
+	t.Run("reuses incoming ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/chapter/current", nil)
+		req.Header.Set("X-Request-ID", "from-proxy")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if got := w.Header().Get("X-Request-ID"); got != "from-proxy" {
+			t.Errorf("X-Request-ID = %q, want %q", got, "from-proxy")
+		}
+
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("replaces invalid IDs", func(t *testing.T) {
+		for _, id := range []string{"forged\nlevel=ERROR", "<script>", strings.Repeat("a", 65)} {
+			req := httptest.NewRequest("GET", "/api/chapter/current", nil)
+			req.Header.Set("X-Request-ID", id)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("X-Request-ID"); got == id || !validRequestID(got) {
+				t.Errorf("X-Request-ID for %q = %q, want a generated ID", id, got)
+			}
+		}
+	})
+}
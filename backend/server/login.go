@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// loginPagePath is the presenter login form, which browsers without a
+// session are sent to instead of getting the Basic Auth popup.
+const loginPagePath = "/presenter/login"
+
+// localPath returns next when it is a path on this server, so logins can't
+// be used to send presenters elsewhere, and fallback otherwise.
+func localPath(next, fallback string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return fallback
+	}
+
+	return next
+}
+
+// handleLoginPage serves the presenter login form.
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	if s.outsidePresenterNetworks(w, r) {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFileFS(w, r, s.staticFS, "presenter/login.html")
+}
+
+// handlePresenterLogin checks the secret posted by the login form and starts
+// a session, sending the browser on to the local path in next. A wrong
+// secret goes back to the form.
+func (s *Server) handlePresenterLogin(w http.ResponseWriter, r *http.Request) {
+	if s.presenterSecret == "" {
+		http.Error(w, "there is no presenter secret to log in with", http.StatusNotFound)
+
+		return
+	}
+
+	if s.outsidePresenterNetworks(w, r) || s.lockedOut(w, r) {
+		return
+	}
+
+	secret := r.PostFormValue("secret")
+	next := localPath(r.PostFormValue("next"), s.publicPath("/presenter/"))
+
+	role := s.secretRole(secret)
+	s.recordLogin(r, secret, role != "")
+
+	if role == "" {
+		http.Redirect(w, r, s.publicPath(loginPagePath)+"?"+url.Values{"failed": {"1"}, "next": {next}}.Encode(), http.StatusSeeOther)
+
+		return
+	}
+
+	if err := s.startSession(w, r, role); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	requestLogger(r).Info("Presenter logged in", "role", role)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
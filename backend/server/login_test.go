@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLocalPath(t *testing.T) {
+	tests := map[string]string{
+		"":                      "/presenter/",
+		"/editor/":              "/editor/",
+		"/presenter/?x=1":       "/presenter/?x=1",
+		"https://evil.example/": "/presenter/",
+		"//evil.example/":       "/presenter/",
+		"/\\evil.example/":      "/presenter/",
+	}
+
+	for next, want := range tests {
+		if got := localPath(next, "/presenter/"); got != want {
+			t.Errorf("localPath(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
+
+func TestPresenterLoginForm(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "test-secret-123"
+	server.staticFS = fstest.MapFS{
+		"presenter/login.html": &fstest.MapFile{Data: []byte("<form>login</form>")},
+	}
+
+	do := func(method, target string, form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		if form != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := do("GET", "/presenter/notes.html", nil)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/presenter/login?next=%2Fpresenter%2Fnotes.html" {
+		t.Fatalf("presenter view without session = %d to %q, want a redirect to the login form", w.Code, w.Header().Get("Location"))
+	}
+
+	if w = do("GET", "/presenter/login", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "login") {
+		t.Fatalf("login form = %d, want the page", w.Code)
+	}
+
+	w = do("POST", "/presenter/login", url.Values{"secret": {"wrong"}, "next": {"/presenter/notes.html"}})
+	if w.Code != http.StatusSeeOther || !strings.Contains(w.Header().Get("Location"), "failed=1") {
+		t.Errorf("wrong secret = %d to %q, want back to the form", w.Code, w.Header().Get("Location"))
+	}
+
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("wrong secret started a session")
+	}
+
+	w = do("POST", "/presenter/login", url.Values{"secret": {"test-secret-123"}, "next": {"//evil.example/"}})
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/presenter/" {
+		t.Fatalf("login = %d to %q, want the presenter view", w.Code, w.Header().Get("Location"))
+	}
+
+	var session *http.Cookie
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == sessionCookie {
+			session = cookie
+		}
+	}
+
+	if session == nil || !session.HttpOnly {
+		t.Fatalf("login set no HttpOnly %s cookie", sessionCookie)
+	}
+
+	if w = do("GET", "/presenter/notes.html", nil, session); w.Code == http.StatusFound || w.Code == http.StatusUnauthorized {
+		t.Errorf("presenter view with session = %d, want it served", w.Code)
+	}
+
+	if w = do("GET", "/api/v1/admin/clients", nil, session); w.Code != http.StatusOK {
+		t.Errorf("presenter API with session = %d, want it accepted", w.Code)
+	}
+
+	// an expired session gets a 401 without the Basic Auth popup
+	expired := &http.Cookie{Name: sessionCookie, Value: "stale"}
+	if w = do("POST", "/api/v1/advance", nil, expired); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("stale session = %d with challenge %q, want 401 without one", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+
+	w = do("POST", "/auth/logout", nil, session)
+
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusSeeOther || len(cookies) != 2 || cookies[0].MaxAge >= 0 || cookies[1].MaxAge >= 0 {
+		t.Errorf("logout = %d, want the session and CSRF cookies cleared", w.Code)
+	}
+}
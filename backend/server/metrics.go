@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// histogram counts observations into buckets, as Prometheus histograms do.
+type histogram struct {
+	bounds []float64 // upper bounds of the buckets, in seconds
+	counts []uint64  // observations per bucket, the last one for those above every bound
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds ...float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+// observe counts one observation.
+func (h *histogram) observe(d time.Duration) {
+	seconds := d.Seconds()
+
+	i := 0
+	for i < len(h.bounds) && seconds > h.bounds[i] {
+		i++
+	}
+
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// write writes the histogram in the Prometheus text format.
+func (h *histogram) write(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	var cumulative uint64
+
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", name, strconv.FormatFloat(h.sum, 'g', -1, 64), name, h.count)
+}
+
+// voteMetrics measures how fast votes travel, to diagnose the network of a
+// venue while the show runs.
+type voteMetrics struct {
+	mu        sync.Mutex
+	arrival   *histogram // from the start of the vote to the arrival of each ballot
+	broadcast *histogram // from the arrival of a ballot until every screen got the update
+	votes     uint64     // ballots counted
+	updates   uint64     // vote updates sent to every screen
+}
+
+func newVoteMetrics() *voteMetrics {
+	return &voteMetrics{
+		arrival:   newHistogram(0.5, 1, 2, 5, 10, 15, 20, 30, 45, 60, 90, 120),
+		broadcast: newHistogram(0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5),
+	}
+}
+
+// voteArrived counts a ballot that arrived after the vote had been open for
+// the given time.
+func (m *voteMetrics) voteArrived(sinceStart time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.votes++
+	m.arrival.observe(sinceStart)
+}
+
+// updateSent counts a vote update delivered to every screen, the given time
+// after the ballot that caused it arrived.
+func (m *voteMetrics) updateSent(sinceArrival time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.updates++
+	m.broadcast.observe(sinceArrival)
+}
+
+// write writes the metrics in the Prometheus text format.
+func (m *voteMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP adventure_votes_total Ballots counted.\n# TYPE adventure_votes_total counter\nadventure_votes_total %d\n", m.votes)
+	fmt.Fprintf(w, "# HELP adventure_vote_updates_total Vote updates sent to every screen.\n# TYPE adventure_vote_updates_total counter\nadventure_vote_updates_total %d\n", m.updates)
+	m.arrival.write(w, "adventure_vote_arrival_seconds", "Time from the start of a vote to the arrival of each ballot.")
+	m.broadcast.write(w, "adventure_vote_broadcast_seconds", "Time from the arrival of a ballot until every screen got the update.")
+}
+
+// handleMetrics serves the vote metrics and the connected clients in the
+// Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	s.voteManager.metrics.write(w)
+
+	presence := s.voteManager.Connected()
+
+	fmt.Fprintf(w, "# HELP adventure_clients Connected clients by role.\n# TYPE adventure_clients gauge\n")
+	fmt.Fprintf(w, "adventure_clients{role=%q} %d\n", RoleVoter, presence.Voters)
+	fmt.Fprintf(w, "adventure_clients{role=%q} %d\n", RolePresenter, presence.Presenters)
+	fmt.Fprintf(w, "adventure_clients{role=%q} %d\n", RoleSpectator, presence.Spectators)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram(0.1, 1)
+	h.observe(50 * time.Millisecond)
+	h.observe(100 * time.Millisecond)
+	h.observe(500 * time.Millisecond)
+	h.observe(3 * time.Second)
+
+	var out strings.Builder
+	h.write(&out, "latency_seconds", "Latency.")
+
+	want := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 2
+latency_seconds_bucket{le="1"} 3
+latency_seconds_bucket{le="+Inf"} 4
+latency_seconds_sum 3.65
+latency_seconds_count 4
+`
+	if got := out.String(); got != want {
+		t.Errorf("histogram =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	vm := server.voteManager
+	vm.StartVoting("q1", []string{"a", "b"}, time.Minute, nil)
+	_ = vm.SubmitVote("voter-1", "a")
+	_ = vm.SubmitVote("voter-2", "b")
+
+	// the updates count once the hub has sent them
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		vm.metrics.mu.Lock()
+		updates := vm.metrics.updates
+		vm.metrics.mu.Unlock()
+
+		if updates == 2 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d vote updates, want 2", updates)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("metrics = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"adventure_votes_total 2\n",
+		"adventure_vote_updates_total 2\n",
+		"adventure_vote_arrival_seconds_bucket{le=\"0.5\"} 2\n",
+		"adventure_vote_arrival_seconds_count 2\n",
+		"adventure_vote_broadcast_seconds_count 2\n",
+		"adventure_clients{role=\"voter\"} 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics lack %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// forwardStep is a chapter left by going back, and the choice that led to it.
+type forwardStep struct {
+	ID     string
+	Choice string
+}
+
+// withNavigation adds where the presenter can go from the current chapter to
+// a chapter payload. Callers must hold s.mu.
+func (s *Server) withNavigation(payload map[string]any) map[string]any {
+	payload["can_go_back"] = len(s.history) > 0
+	payload["can_go_forward"] = len(s.forward) > 0
+	payload["checkpoint"] = s.checkpointID()
+
+	return payload
+}
+
+// moveTo enters next from the current chapter by choiceID, recording the step
+// in history and the session, awarding the choice's points and telling every
+// client. It returns the chapter as presented. Callers must hold s.mu.
+func (s *Server) moveTo(next *parser.Chapter, choiceID string) *parser.Chapter {
+	s.history = append(s.history, s.currentNode)
+	s.varsHistory = append(s.varsHistory, s.vars.Clone())
+	s.sessions.Visit(s.currentNode, choiceID, next.Metadata.ID)
+
+	var points int
+	if current, err := s.storyEngine.GetChapter(s.currentNode); err == nil {
+		points = current.Metadata.ChoicePoints(choiceID)
+	}
+
+	score := s.vars.Score()
+
+	s.currentNode = next.Metadata.ID
+	s.diceRoll = nil
+	s.vars.Award(points)
+	s.vars.Enter(next.Metadata)
+	next = s.present(next, s.vars)
+
+	var journey SessionRun
+	if next.Metadata.IsEnding() {
+		journey, _ = s.sessions.Current()
+		s.sessions.Finish(s.currentNode)
+	}
+
+	s.broadcastChapter("chapter_changed", s.vars, s.withNavigation(map[string]any{
+		"id":       s.currentNode,
+		"metadata": next.Metadata,
+		"content":  next.Content,
+	}))
+	s.broadcastInventory()
+	s.broadcastScore(score, points)
+
+	if next.Metadata.IsEnding() {
+		s.broadcastEndingReached(next)
+		s.broadcastEpilogue(journey)
+	}
+
+	return next
+}
+
+// handleGoForward redoes the most recent go-back, returning to the chapter it
+// left by the same choice. Votes cleared by going back are not restored.
+func (s *Server) handleGoForward(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.forward) == 0 {
+		http.Error(w, "nothing to go forward to", http.StatusBadRequest)
+
+		return
+	}
+
+	step := s.forward[len(s.forward)-1]
+
+	chapter, err := s.chapter(step.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	s.forward = s.forward[:len(s.forward)-1]
+
+	requestLogger(r).Info("Went forward", "from", s.currentNode, "chapter_id", step.ID, "choice_id", step.Choice)
+
+	chapter = s.moveTo(chapter, step.Choice)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.withNavigation(map[string]any{
+		"id":       s.currentNode,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+		"notes":    chapter.Notes,
+	})); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
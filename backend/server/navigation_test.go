@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestGoForward(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	type response struct {
+		ID           string `json:"id"`
+		CanGoBack    bool   `json:"can_go_back"`
+		CanGoForward bool   `json:"can_go_forward"`
+	}
+
+	post := func(path string, body any, wantStatus int) response {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != wantStatus {
+			t.Fatalf("POST %s status = %d, want %d: %s", path, w.Code, wantStatus, w.Body.String())
+		}
+
+		var out response
+		if wantStatus == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+				t.Fatalf("failed to decode %s: %v", path, err)
+			}
+		}
+
+		return out
+	}
+
+	post("/api/v1/go-forward", nil, http.StatusBadRequest)
+
+	post("/api/v1/advance", map[string]any{}, http.StatusOK)
+
+	if got := post("/api/v1/advance", map[string]any{"choice_id": "opt-a"}, http.StatusOK); got.ID != "path-a" || got.CanGoForward {
+		t.Errorf("after advancing = %+v, want path-a with nothing to go forward to", got)
+	}
+
+	if got := post("/api/v1/go-back", nil, http.StatusOK); got.ID != "choice1" || !got.CanGoForward {
+		t.Errorf("after going back = %+v, want choice1 able to go forward", got)
+	}
+
+	post("/api/v1/go-back", nil, http.StatusOK)
+
+	if got := post("/api/v1/go-forward", nil, http.StatusOK); got.ID != "choice1" || !got.CanGoBack || !got.CanGoForward {
+		t.Errorf("after the first redo = %+v, want choice1 able to go both ways", got)
+	}
+
+	if got := post("/api/v1/go-forward", nil, http.StatusOK); got.ID != "path-a" || got.CanGoForward {
+		t.Errorf("after the second redo = %+v, want path-a with nothing left to redo", got)
+	}
+
+	run := server.sessions.current
+	if len(run.Choices) != 1 || run.Choices[0].Choice != "opt-a" {
+		t.Errorf("session choices = %+v, want the redone opt-a", run.Choices)
+	}
+
+	post("/api/v1/go-back", nil, http.StatusOK)
+
+	if got := post("/api/v1/advance", map[string]any{"choice_id": "opt-b"}, http.StatusOK); got.ID != "path-b" || got.CanGoForward {
+		t.Errorf("after taking another way = %+v, want path-b with nothing to go forward to", got)
+	}
+
+	post("/api/v1/go-forward", nil, http.StatusBadRequest)
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"maps"
+	"net/http"
+)
+
+// withNotes returns a copy of a chapter payload, one with an id, carrying the
+// chapter's speaker notes for presenters. Callers must hold s.mu.
+func (s *Server) withNotes(payload map[string]any) map[string]any {
+	id, _ := payload["id"].(string)
+
+	out := maps.Clone(payload)
+	out["notes"] = ""
+
+	if chapter, err := s.chapter(id); err == nil {
+		out["notes"] = chapter.Notes
+	}
+
+	return out
+}
+
+// wantsNotes reports whether a request to a public chapter endpoint asked for
+// speaker notes with ?notes=true and is allowed to see them.
+func (s *Server) wantsNotes(r *http.Request) bool {
+	return r.URL.Query().Get("notes") == "true" && s.isPresenter(r)
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSpeakerNotes(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	choice := "---\nid: choice1\ntype: decision\nquestion: Choose your path\nchoices:\n  - id: opt-a\n    label: Option A\n    next: path-a\n  - id: opt-b\n    label: Option B\n    next: path-b\n---\n# Choose your path\n<!-- notes -->\nPath B is a trap."
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "choice.md"), []byte(choice), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	voter, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	presenter, _, err := websocket.DefaultDialer.Dial(wsURL+"?role=presenter", nil)
+	if err != nil {
+		t.Fatalf("failed to connect presenter: %v", err)
+	}
+	defer presenter.Close()
+
+	// wait until both clients are registered
+	for _, conn := range []*websocket.Conn{voter, presenter} {
+		var msg Message
+		conn.ReadJSON(&msg) // state
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/advance", bytes.NewReader([]byte("{}"))))
+
+	var advanced map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&advanced); err != nil {
+		t.Fatalf("failed to decode advance: %v", err)
+	}
+
+	if notes, _ := advanced["notes"].(string); !strings.Contains(notes, "Path B is a trap.") {
+		t.Errorf("advance notes = %q, want the speaker notes", notes)
+	}
+
+	if content, _ := advanced["content"].(string); strings.Contains(content, "trap") {
+		t.Errorf("advance content = %q, must not contain the notes", content)
+	}
+
+	chapterChanged := func(conn *websocket.Conn) map[string]any {
+		t.Helper()
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+		for {
+			var msg Message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("no chapter_changed message: %v", err)
+			}
+
+			if msg.Type == "chapter_changed" {
+				return msg.Payload
+			}
+		}
+	}
+
+	if payload := chapterChanged(voter); payload["notes"] != nil {
+		t.Errorf("voter chapter_changed = %v, must not carry notes", payload)
+	}
+
+	if notes, _ := chapterChanged(presenter)["notes"].(string); !strings.Contains(notes, "trap") {
+		t.Errorf("presenter chapter_changed notes = %q, want the speaker notes", notes)
+	}
+
+	server.presenterSecret = "s3cret"
+
+	current := func(query, secret string) map[string]any {
+		t.Helper()
+
+		req := httptest.NewRequest("GET", "/api/v1/chapter/current"+query, nil)
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /chapter/current%s status = %d", query, w.Code)
+		}
+
+		var out map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatalf("failed to decode chapter: %v", err)
+		}
+
+		return out
+	}
+
+	if out := current("", "s3cret"); out["notes"] != nil {
+		t.Errorf("chapter/current without ?notes = %v, must not carry notes", out)
+	}
+
+	if out := current("?notes=true", "wrong"); out["notes"] != nil {
+		t.Errorf("chapter/current for a voter = %v, must not carry notes", out)
+	}
+
+	if notes, _ := current("?notes=true", "s3cret")["notes"].(string); !strings.Contains(notes, "trap") {
+		t.Errorf("chapter/current notes = %q, want the speaker notes", notes)
+	}
+}
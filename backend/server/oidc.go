@@ -0,0 +1,353 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	oidcTimeout = 10 * time.Second
+	// oidcLoginTimeout is how long a presenter may take to log in at the
+	// provider.
+	oidcLoginTimeout = 10 * time.Minute
+	// oidcCallbackPath is where the provider sends presenters back to, the
+	// redirect URI to register for the client.
+	oidcCallbackPath = "/auth/oidc/callback"
+)
+
+// OIDCConfig describes the OpenID Connect provider presenters log in with.
+type OIDCConfig struct {
+	Issuer       string   // such as https://accounts.google.com
+	ClientID     string   // of the client registered at the provider
+	ClientSecret string   // empty for public clients
+	RedirectURL  string   // the callback registered at the provider, empty to derive it from each request
+	Emails       []string // who may present: addresses, or whole domains as @example.com; empty lets in anyone the provider does
+}
+
+// OIDC logs presenters in with an OpenID Connect provider through the
+// authorization code flow with PKCE.
+type OIDC struct {
+	config   OIDCConfig
+	authURL  string
+	tokenURL string
+	client   *http.Client
+	// key signs presenter sessions when there is no presenter secret: the
+	// client secret, so every replica accepts the sessions of the others, or
+	// a random one for public clients
+	key string
+
+	mu      sync.Mutex
+	pending map[string]oidcLogin // state -> login underway
+}
+
+// oidcLogin is a login waiting for the provider to send the presenter back.
+type oidcLogin struct {
+	verifier string // PKCE code verifier
+	nonce    string
+	redirect string // the redirect_uri the provider was given
+	next     string // where the presenter goes once logged in
+	started  time.Time
+}
+
+// NewOIDC looks up the endpoints of the provider in its discovery document.
+func NewOIDC(ctx context.Context, config OIDCConfig) (*OIDC, error) {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, errors.New("OIDC needs an issuer and a client ID")
+	}
+
+	o := &OIDC{
+		config:  config,
+		client:  &http.Client{Timeout: oidcTimeout},
+		key:     config.ClientSecret,
+		pending: map[string]oidcLogin{},
+	}
+
+	if o.key == "" {
+		o.key = randomToken()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC issuer: %w", err)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %s", resp.Status)
+	}
+
+	var discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, fmt.Errorf("invalid OIDC discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != config.Issuer {
+		return nil, fmt.Errorf("OIDC discovery document is for issuer %q, not %q", discovery.Issuer, config.Issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return nil, errors.New("OIDC discovery document lacks the authorization or token endpoint")
+	}
+
+	o.authURL = discovery.AuthorizationEndpoint
+	o.tokenURL = discovery.TokenEndpoint
+
+	return o, nil
+}
+
+// randomToken returns 32 random bytes, base64url encoded.
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// begin starts a login and returns the provider URL to send the presenter
+// to. They return to next once logged in, through the callback under
+// basePath unless a redirect URL is configured.
+func (o *OIDC) begin(r *http.Request, basePath, next string) string {
+	login := oidcLogin{
+		verifier: randomToken(),
+		nonce:    randomToken(),
+		redirect: o.config.RedirectURL,
+		next:     next,
+		started:  time.Now(),
+	}
+
+	if login.redirect == "" {
+		login.redirect = requestOrigin(r) + basePath + oidcCallbackPath
+	}
+
+	state := randomToken()
+
+	o.mu.Lock()
+	for key, pending := range o.pending {
+		if time.Since(pending.started) > oidcLoginTimeout {
+			delete(o.pending, key)
+		}
+	}
+	o.pending[state] = login
+	o.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(login.verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.config.ClientID},
+		"redirect_uri":          {login.redirect},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {login.nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(o.authURL, "?") {
+		separator = "&"
+	}
+
+	return o.authURL + separator + query.Encode()
+}
+
+// finish redeems the code the provider sent the presenter back with and
+// returns their email address and where they were headed.
+func (o *OIDC) finish(ctx context.Context, state, code string) (string, string, error) {
+	o.mu.Lock()
+	login, ok := o.pending[state]
+	delete(o.pending, state)
+	o.mu.Unlock()
+
+	if !ok || time.Since(login.started) > oidcLoginTimeout {
+		return "", "", errors.New("unknown or expired login, please try again")
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {login.redirect},
+		"code_verifier": {login.verifier},
+		"client_id":     {o.config.ClientID},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if o.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.config.ClientID), url.QueryEscape(o.config.ClientSecret))
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to redeem login code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to redeem login code: %s", resp.Status)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", "", fmt.Errorf("invalid token response: %w", err)
+	}
+
+	email, err := o.verifyIDToken(tokens.IDToken, login.nonce, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+
+	return email, login.next, nil
+}
+
+// verifyIDToken checks the claims of an ID token and returns the email
+// address in it. The token came straight from the token endpoint over the
+// connection the server opened, which OpenID Connect Core 3.1.3.7 accepts in
+// place of checking its signature.
+func (o *OIDC) verifyIDToken(token, nonce string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("invalid ID token")
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	var claims struct {
+		Issuer        string          `json:"iss"`
+		Audience      json.RawMessage `json:"aud"`
+		AuthorizedBy  string          `json:"azp"`
+		ExpiresAt     int64           `json:"exp"`
+		Nonce         string          `json:"nonce"`
+		Email         string          `json:"email"`
+		EmailVerified *bool           `json:"email_verified"`
+	}
+
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return "", fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	var audience []string
+	if err := json.Unmarshal(claims.Audience, &audience); err != nil {
+		var single string
+		_ = json.Unmarshal(claims.Audience, &single)
+		audience = []string{single}
+	}
+
+	switch {
+	case strings.TrimSuffix(claims.Issuer, "/") != o.config.Issuer:
+		return "", fmt.Errorf("ID token is from issuer %q", claims.Issuer)
+	case !slices.Contains(audience, o.config.ClientID):
+		return "", errors.New("ID token is for another client")
+	case claims.AuthorizedBy != "" && claims.AuthorizedBy != o.config.ClientID:
+		return "", errors.New("ID token is for another client")
+	case now.Unix() >= claims.ExpiresAt:
+		return "", errors.New("ID token expired")
+	case claims.Nonce != nonce:
+		return "", errors.New("ID token is for another login")
+	case claims.EmailVerified != nil && !*claims.EmailVerified:
+		return "", errors.New("email address is not verified")
+	}
+
+	if !o.allowed(claims.Email) {
+		return "", fmt.Errorf("%q may not present", claims.Email)
+	}
+
+	return claims.Email, nil
+}
+
+// allowed reports whether the owner of email may present.
+func (o *OIDC) allowed(email string) bool {
+	if len(o.config.Emails) == 0 {
+		return true
+	}
+
+	email = strings.ToLower(email)
+
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return false
+	}
+
+	for _, entry := range o.config.Emails {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == email || entry == "@"+domain {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleOIDCLogin sends the presenter to the provider to log in, then back
+// to the local path in ?next=, the presenter view by default.
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if s.outsidePresenterNetworks(w, r) {
+		return
+	}
+
+	http.Redirect(w, r, s.oidc.begin(r, s.basePath, localPath(r.URL.Query().Get("next"), s.publicPath("/presenter/"))), http.StatusFound)
+}
+
+// handleOIDCCallback finishes a login at the provider with a presenter
+// session.
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if s.outsidePresenterNetworks(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		http.Error(w, "login failed: "+reason+" "+query.Get("error_description"), http.StatusUnauthorized)
+
+		return
+	}
+
+	email, next, err := s.oidc.finish(r.Context(), query.Get("state"), query.Get("code"))
+	if err != nil {
+		requestLogger(r).Warn("Presenter login failed", "error", err, "remote", r.RemoteAddr)
+		http.Error(w, "login failed: "+err.Error(), http.StatusForbidden)
+
+		return
+	}
+
+	if err := s.startSession(w, r, RolePresenter); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	requestLogger(r).Info("Presenter logged in", "email", email)
+	http.Redirect(w, r, next, http.StatusFound)
+}
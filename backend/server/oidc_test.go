@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeProvider is an OpenID Connect provider logging everyone in as email.
+type fakeProvider struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	email     string
+	challenge string // code_challenge of the last authorization request
+	nonce     string // nonce of the last authorization request
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+
+	p := &fakeProvider{email: "lead@example.com"}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "voting" || secret != "client-secret" {
+			http.Error(w, "bad client", http.StatusUnauthorized)
+
+			return
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if r.PostFormValue("code") != "the-code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+			http.Error(w, "bad grant", http.StatusBadRequest)
+
+			return
+		}
+
+		claims, _ := json.Marshal(map[string]any{
+			"iss":   p.URL,
+			"aud":   "voting",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": p.nonce,
+			"email": p.email,
+		})
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"id_token": "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(claims) + ".c2lnbmF0dXJl",
+		})
+	})
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func TestOIDCLogin(t *testing.T) {
+	provider := newFakeProvider(t)
+
+	oidc, err := NewOIDC(context.Background(), OIDCConfig{
+		Issuer:       provider.URL + "/",
+		ClientID:     "voting",
+		ClientSecret: "client-secret",
+		Emails:       []string{"@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewOIDC() error = %v", err)
+	}
+
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "", "", false, WithOIDC(oidc))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	get := func(target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// logs in through the provider, returning the callback's response
+	login := func(t *testing.T) *httptest.ResponseRecorder {
+		t.Helper()
+
+		w := get("/presenter/notes.html")
+		if w.Code != http.StatusFound {
+			t.Fatalf("presenter view without session = %d, want a redirect to log in", w.Code)
+		}
+
+		w = get(w.Header().Get("Location"))
+
+		authorize, err := url.Parse(w.Header().Get("Location"))
+		if err != nil || !strings.HasPrefix(authorize.String(), provider.URL+"/authorize?") {
+			t.Fatalf("login redirects to %q, want the provider", authorize)
+		}
+
+		query := authorize.Query()
+		if query.Get("client_id") != "voting" || query.Get("redirect_uri") != "http://example.com/auth/oidc/callback" {
+			t.Errorf("authorization request = %v", query)
+		}
+
+		provider.mu.Lock()
+		provider.challenge = query.Get("code_challenge")
+		provider.nonce = query.Get("nonce")
+		provider.mu.Unlock()
+
+		return get("/auth/oidc/callback?" + url.Values{"code": {"the-code"}, "state": {query.Get("state")}}.Encode())
+	}
+
+	w := login(t)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/presenter/notes.html" {
+		t.Fatalf("callback = %d to %q, want a redirect back to the presenter view", w.Code, w.Header().Get("Location"))
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Name != sessionCookie || !cookies[0].HttpOnly || cookies[1].Name != csrfCookie {
+		t.Fatalf("callback cookies = %v, want an HttpOnly session cookie and its CSRF token", cookies)
+	}
+
+	if w := get("/api/v1/story/outline", cookies[0]); w.Code != http.StatusOK {
+		t.Errorf("outline with session = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if w := get("/api/v1/story/outline"); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("outline without session = %d %v, want 401 without a password prompt", w.Code, w.Header())
+	}
+
+	if w := get("/auth/oidc/callback?code=the-code&state=replayed"); w.Code != http.StatusForbidden {
+		t.Errorf("callback with unknown state = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	if w := get("/auth/logout"); !strings.Contains(w.Header().Get("Set-Cookie"), "Max-Age=0") {
+		t.Errorf("logout cookie = %q, want the session removed", w.Header().Get("Set-Cookie"))
+	}
+
+	provider.mu.Lock()
+	provider.email = "someone@elsewhere.org"
+	provider.mu.Unlock()
+
+	if w := login(t); w.Code != http.StatusForbidden || len(w.Result().Cookies()) != 0 {
+		t.Errorf("callback for another domain = %d, want %d without a session", w.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithSessionsFile enables persisting finished story runs to the given JSON file
+// so that path statistics can be aggregated across sessions.
+func WithSessionsFile(path string) Option {
+	return func(s *Server) {
+		s.sessions = NewSessionStore(path)
+	}
+}
+
+// WithFeatures enables the given experimental features.
+func WithFeatures(features Features) Option {
+	return func(s *Server) {
+		s.features = features
+	}
+}
+
+// WithSimulatedVoters makes the given number of fake voters take part in every
+// vote, for demos and rehearsals without an audience.
+func WithSimulatedVoters(n int) Option {
+	return func(s *Server) {
+		s.simulatedVoters = n
+	}
+}
+
+// WithRehearsal starts the server rehearsing the show, with the given number of
+// simulated voters and vote timers running speed times faster.
+func WithRehearsal(voters int, speed float64) Option {
+	return func(s *Server) {
+		s.rehearsal = Rehearsal{Enabled: true, Voters: voters, Speed: speed}
+	}
+}
+
+// WithWebhooks POSTs chapter changes, votes starting and ending, and story
+// restarts to the given URLs, signed with secret unless it is empty.
+func WithWebhooks(urls []string, secret string) Option {
+	return func(s *Server) {
+		s.voteManager.observers = append(s.voteManager.observers, NewWebhooks(urls, secret).Notify)
+	}
+}
+
+// WithSlack posts every vote to a Slack channel with a button per choice and
+// counts the clicks as votes. The signing secret authenticates the clicks.
+func WithSlack(token, channel, signingSecret string) Option {
+	return func(s *Server) {
+		s.slack = NewSlack(token, channel, signingSecret)
+		s.voteManager.observers = append(s.voteManager.observers, s.slack.Notify)
+	}
+}
+
+// WithDiscord posts every vote to a Discord channel with a numbered reaction
+// per choice and counts the reactions as votes.
+func WithDiscord(token, channel string) Option {
+	return func(s *Server) {
+		discord := NewDiscord(token, channel, s.voteManager)
+		s.voteManager.observers = append(s.voteManager.observers, discord.Notify)
+
+		go discord.Run(context.Background())
+	}
+}
+
+// WithSMS takes votes texted to a Twilio number. Requests are authenticated
+// with the Twilio auth token; webhookURL is the URL configured in Twilio, or
+// empty when the server sees the same URL.
+func WithSMS(authToken, webhookURL string) Option {
+	return func(s *Server) {
+		s.sms = NewSMS(authToken, webhookURL)
+	}
+}
+
+// WithLeaderElection runs the server as one of several replicas, of which
+// only the leader elected by le serves the show and the others forward to it.
+func WithLeaderElection(le *LeaderElection) Option {
+	return func(s *Server) {
+		s.leader = le
+		le.onChange = s.leadershipChanged
+
+		go le.Run(context.Background())
+	}
+}
+
+// WithVoteSources takes votes from the given sources, such as chat
+// platforms, alongside the room. Sources that are also Notifiers are told
+// about every vote.
+func WithVoteSources(sources ...VoteSource) Option {
+	return func(s *Server) {
+		for _, source := range sources {
+			if notifier, ok := source.(Notifier); ok {
+				s.voteManager.observers = append(s.voteManager.observers, notifier.Notify)
+			}
+		}
+
+		s.sources = append(s.sources, sources...)
+	}
+}
+
+// WithVoteBonus lets voters earn up to n extra votes of weight on decisions by
+// answering quiz questions correctly. Zero keeps one voter, one vote.
+func WithVoteBonus(n int) Option {
+	return func(s *Server) {
+		s.voteManager.maxBonus = n
+	}
+}
+
+// WithVetoes gives every voter one veto per run of the story. A choice is
+// struck from a vote, which starts again without it, once more than share of
+// the voters vetoed it. Zero disables vetoes.
+func WithVetoes(share float64) Option {
+	return func(s *Server) {
+		s.voteManager.vetoShare = share
+	}
+}
+
+// WithShuffledChoices gives every voter the choices of a vote in their own
+// order, to keep the order on screen from swaying results.
+func WithShuffledChoices() Option {
+	return func(s *Server) {
+		s.voteManager.shuffle = true
+	}
+}
+
+// WithTieBreak sets how a tie between the leaders of a vote is broken, either
+// TieBreakRandom or TieBreakRunoff.
+func WithTieBreak(policy string) Option {
+	return func(s *Server) {
+		s.voteManager.tieBreak = policy
+	}
+}
+
+// WithAbstain offers an extra choice to abstain in every vote. Abstentions
+// are counted apart and never win.
+func WithAbstain() Option {
+	return func(s *Server) {
+		s.voteManager.abstain = true
+	}
+}
+
+// WithRoster restricts voting to the participants on the given roster.
+func WithRoster(roster *Roster) Option {
+	return func(s *Server) {
+		s.roster = roster
+	}
+}
+
+// WithStories lets the server switch between the given stories at runtime. The
+// story the server was created with becomes the active one.
+func WithStories(bundles []StoryBundle) Option {
+	return func(s *Server) {
+		created := s.stories
+		s.stories = bundles
+
+		for _, bundle := range bundles {
+			if bundle.StoryPath == s.storyPath {
+				s.activeStory = bundle.ID
+
+				return
+			}
+		}
+
+		s.stories = append(s.stories, created...)
+	}
+}
+
+// WithCoPresenterSecret lets helpers who know secret open the presenter views
+// and read presenter endpoints, such as results and speaker notes, without
+// being able to change the show. It has no effect when presenter auth is off.
+func WithCoPresenterSecret(secret string) Option {
+	return func(s *Server) {
+		s.coPresenter = secret
+	}
+}
+
+// WithSigningSecret signs presenter tokens, sessions and CSRF tokens with
+// secret instead of a random key made on every start. Replicas behind leader
+// election need the same signing secret to accept each other's tokens.
+func WithSigningSecret(secret string) Option {
+	return func(s *Server) {
+		if secret != "" {
+			s.signingKey = []byte(secret)
+		}
+	}
+}
+
+// WithEngineOptions configures how every story the server loads renders its
+// chapters, such as the theme of code blocks.
+func WithEngineOptions(opts ...parser.EngineOption) Option {
+	return func(s *Server) {
+		s.engineOptions = append(s.engineOptions, opts...)
+	}
+}
+
+// WithDevReload has browsers reload the page when the story changes on disk,
+// see WatchContent and WatchFrontend, so authors see their edits at once.
+func WithDevReload() Option {
+	return func(s *Server) {
+		s.devReload = true
+	}
+}
+
+// WithBuildInfo sets the version, commit and build date GET /api/info
+// reports.
+func WithBuildInfo(build BuildInfo) Option {
+	return func(s *Server) {
+		s.build = build
+	}
+}
+
+// WithTokenTTL sets how long the presenter tokens POST /api/login hands out
+// stay valid, an hour by default. Clients refresh them before they run out
+// with POST /api/login/refresh.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.tokenTTL = ttl
+	}
+}
+
+// WithSessionTTL sets how long presenters stay logged in in the browser after
+// the login form or an OpenID Connect login, 12 hours by default.
+func WithSessionTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.sessionTTL = ttl
+	}
+}
+
+// WithPresenterNetworks only lets presenters in from the given networks, such
+// as the VLAN of the podium laptop, on top of their credentials.
+func WithPresenterNetworks(networks Networks) Option {
+	return func(s *Server) {
+		s.allowedNetworks = networks
+	}
+}
+
+// WithRooms lets presenters open rooms, further shows running beside the
+// main one with their own story position and votes, for workshop breakouts.
+// Rooms nobody used for idleTTL are closed; 0 keeps them until closed.
+func WithRooms(idleTTL time.Duration) Option {
+	return func(s *Server) {
+		s.rooms = NewRooms(idleTTL)
+	}
+}
+
+// WithOIDC lets presenters log in with an OpenID Connect provider instead
+// of, or besides, the presenter secret. Browsers opening the presenter view
+// without a session are sent to the provider.
+func WithOIDC(o *OIDC) Option {
+	return func(s *Server) {
+		s.oidc = o
+	}
+}
+
+// WithJoinCode has voters enter a join code shown on the presenter screen
+// before they can vote. The presenter rotates it to shut out link-sharers.
+func WithJoinCode() Option {
+	return func(s *Server) {
+		s.joinCodes = NewJoinCodes()
+	}
+}
+
+// WithHTTPLimits sets the timeouts and size limits of the HTTP server,
+// DefaultHTTPLimits unless given.
+func WithHTTPLimits(limits HTTPLimits) Option {
+	return func(s *Server) {
+		s.httpLimits = limits
+	}
+}
+
+// WithBasePath mounts the server at a path prefix, such as /adventure, for
+// running behind a reverse proxy under a subpath instead of on a host of its
+// own. Links, redirects and voter URLs include the prefix.
+func WithBasePath(basePath string) Option {
+	return func(s *Server) {
+		s.basePath = CleanBasePath(basePath)
+		if s.basePath != "" {
+			s.staticFS = basePathFS{FS: s.staticFS, basePath: s.basePath}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import "github.com/skarlso/kube_adventures/voting/backend/parser"
+
+// choiceLabels maps choice IDs to their labels, for the default language
+// under "" and for every translated language under its code.
+type choiceLabels map[string]map[string]string
+
+// newChoiceLabels collects the labels of a vote in every language it is shown in.
+func newChoiceLabels(choices []parser.Choice, translations map[string]LocalizedQuestion) choiceLabels {
+	labels := choiceLabels{"": labelsOf(choices)}
+
+	for lang, text := range translations {
+		labels[lang] = labelsOf(text.Choices)
+	}
+
+	return labels
+}
+
+func labelsOf(choices []parser.Choice) map[string]string {
+	out := make(map[string]string, len(choices))
+	for _, choice := range choices {
+		out[choice.ID] = choice.Label
+	}
+
+	return out
+}
+
+// label returns the label of a choice in lang, falling back to the default
+// language and then to the ID itself.
+func (l choiceLabels) label(lang, id string) string {
+	if label, ok := l[lang][id]; ok {
+		return label
+	}
+
+	if label, ok := l[""][id]; ok {
+		return label
+	}
+
+	return id
+}
+
+// sendYourResults tells every voter connection privately how the vote went
+// for them: what they picked and whether it won. Callers must hold vm.mu.
+func (vm *VoteManager) sendYourResults(winner string) {
+	questionID := vm.vote.id
+	ballots := make(map[string]string, len(vm.vote.voters))
+
+	for voterID, choiceID := range vm.vote.voters {
+		ballots[voterID] = choiceID
+	}
+
+	labels := vm.labels
+
+	vm.broadcast <- &Message{
+		Type: "your_result",
+		role: RoleVoter,
+		personal: func(client *Client) map[string]any {
+			choice := ballots[client.voter()]
+
+			payload := map[string]any{
+				"question_id":  questionID,
+				"voted":        choice != "",
+				"choice":       choice,
+				"won":          choice != "" && choice == winner,
+				"winner":       winner,
+				"winner_label": labels.label(client.Lang, winner),
+			}
+
+			if choice != "" {
+				payload["choice_label"] = labels.label(client.Lang, choice)
+			}
+
+			return payload
+		},
+	}
+}
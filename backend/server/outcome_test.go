@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestYourResult(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	connect := func() *websocket.Conn {
+		t.Helper()
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatalf("failed to connect voter: %v", err)
+		}
+
+		var state Message
+		conn.ReadJSON(&state)
+
+		return conn
+	}
+
+	winnerVoter, loserVoter, bystander := connect(), connect(), connect()
+	defer winnerVoter.Close()
+	defer loserVoter.Close()
+	defer bystander.Close()
+
+	choices := []parser.Choice{{ID: "opt-a", Label: "Left door"}, {ID: "opt-b", Label: "Right door"}}
+	server.voteManager.StartVotingWithChoices("choice1", []string{"opt-a", "opt-b"}, choices, "Which door?", time.Minute, nil)
+
+	winnerVoter.WriteJSON(VoteMessage{Type: "vote", VoterID: "voter-1", ChoiceID: "opt-a"})
+	loserVoter.WriteJSON(VoteMessage{Type: "vote", VoterID: "voter-2", ChoiceID: "opt-b"})
+	server.voteManager.SubmitVote("voter-3", "opt-a")
+
+	// the WebSocket votes arrive asynchronously
+	deadline := time.Now().Add(2 * time.Second)
+	for results := server.voteManager.GetResults("choice1"); results["opt-a"] < 2 || results["opt-b"] < 1; results = server.voteManager.GetResults("choice1") {
+		if time.Now().After(deadline) {
+			t.Fatalf("votes did not arrive: %v", results)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	server.voteManager.EndVoting()
+
+	yourResult := func(conn *websocket.Conn) map[string]any {
+		t.Helper()
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+		for {
+			var msg Message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("no your_result received: %v", err)
+			}
+
+			if msg.Type == "your_result" {
+				return msg.Payload
+			}
+		}
+	}
+
+	if got := yourResult(winnerVoter); got["won"] != true || got["choice"] != "opt-a" || got["choice_label"] != "Left door" || got["winner_label"] != "Left door" {
+		t.Errorf("winner's your_result = %v, want a win with Left door", got)
+	}
+
+	if got := yourResult(loserVoter); got["won"] != false || got["choice"] != "opt-b" || got["choice_label"] != "Right door" || got["winner"] != "opt-a" {
+		t.Errorf("loser's your_result = %v, want a loss with Right door", got)
+	}
+
+	if got := yourResult(bystander); got["voted"] != false || got["won"] != false || got["winner_label"] != "Left door" {
+		t.Errorf("bystander's your_result = %v, want no vote and the winner", got)
+	}
+}
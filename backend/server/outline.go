@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// handleGetStoryOutline returns the table of contents of the story for the
+// presenter's navigation: acts and chapters in story order, marked with
+// whether the current run has visited them. Chapters behind disabled features
+// are left out.
+func (s *Server) handleGetStoryOutline(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chapters, err := s.playableChapters()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	visited := map[string]bool{s.currentNode: true}
+	for _, id := range s.history {
+		visited[id] = true
+	}
+
+	outline := parser.BuildOutline(s.storyEngine.Story.Flow.Start, chapters)
+	for _, act := range outline.Acts {
+		for i := range act.Chapters {
+			act.Chapters[i].Visited = visited[act.Chapters[i].ID]
+			act.Chapters[i].Current = act.Chapters[i].ID == s.currentNode
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(outline); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
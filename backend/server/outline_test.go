@@ -0,0 +1,66 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestStoryOutline(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/advance", bytes.NewReader([]byte("{}"))))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("advance status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/story/outline", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /story/outline status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var outline parser.Outline
+	if err := json.NewDecoder(w.Body).Decode(&outline); err != nil {
+		t.Fatalf("failed to decode outline: %v", err)
+	}
+
+	if outline.Start != "intro" || len(outline.Acts) != 1 {
+		t.Fatalf("outline = %+v, want one act starting at intro", outline)
+	}
+
+	tests := []struct {
+		id       string
+		visited  bool
+		current  bool
+		decision bool
+		ending   bool
+	}{
+		{"intro", true, false, false, false},
+		{"choice1", true, true, true, false},
+		{"path-a", false, false, false, false},
+		{"path-b", false, false, false, true},
+	}
+
+	chapters := outline.Acts[0].Chapters
+	if len(chapters) != len(tests) {
+		t.Fatalf("chapters = %+v, want %d", chapters, len(tests))
+	}
+
+	for i, tt := range tests {
+		got := chapters[i]
+		if got.ID != tt.id || got.Visited != tt.visited || got.Current != tt.current || got.Decision != tt.decision || got.Ending != tt.ending {
+			t.Errorf("chapters[%d] = %+v, want %s visited=%v current=%v decision=%v ending=%v",
+				i, got, tt.id, tt.visited, tt.current, tt.decision, tt.ending)
+		}
+	}
+}
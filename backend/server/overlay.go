@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// overlayEvents are the broadcasts that change what a stream overlay shows.
+// Each one makes the hub send overlay clients a fresh overlay snapshot.
+var overlayEvents = map[string]bool{
+	"voting_started":    true,
+	"vote_update":       true,
+	"timer_tick":        true,
+	"timer_adjusted":    true,
+	"voting_ended":      true,
+	"winner_overridden": true,
+	"voting_reset":      true,
+}
+
+// OverlayChoice is a choice as a stream overlay shows it.
+type OverlayChoice struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Votes   int    `json:"votes"`
+	Percent int    `json:"percent"`
+}
+
+// overlay is a small snapshot of the vote for stream overlays, such as an
+// OBS browser source: the question, the choices with the most votes first,
+// the countdown and, once decided, the winner. Fields are always present so
+// overlays need no special cases. Callers must hold vm.mu.
+func (vm *VoteManager) overlay(now time.Time) map[string]any {
+	payload := map[string]any{
+		"question_id":   vm.vote.id,
+		"question":      "",
+		"voting_active": vm.votingActive,
+		"remaining":     0.0,
+		"duration":      0.0,
+		"total":         0,
+		"choices":       []OverlayChoice{},
+		"winner":        nil,
+	}
+
+	if vm.vote.id == "" || vm.started == nil {
+		return payload
+	}
+
+	payload["question"], _ = vm.started.Payload["question"].(string)
+
+	if vm.votingActive {
+		payload["remaining"] = vm.remaining(now)
+		payload["duration"] = vm.deadline.Sub(vm.startedAt).Seconds()
+	}
+
+	results := vm.vote.results
+
+	total := 0
+	for _, votes := range results {
+		total += votes
+	}
+
+	choices := []OverlayChoice{}
+
+	for _, choice := range openChoices(vm.started.Payload) {
+		votes := results[choice.ID]
+
+		percent := 0
+		if total > 0 {
+			percent = int(math.Round(float64(votes) * 100 / float64(total)))
+		}
+
+		choices = append(choices, OverlayChoice{ID: choice.ID, Label: choice.Label, Votes: votes, Percent: percent})
+	}
+
+	slices.SortStableFunc(choices, func(a, b OverlayChoice) int {
+		return b.Votes - a.Votes
+	})
+
+	payload["total"] = total
+	payload["choices"] = choices
+
+	if !vm.votingActive {
+		for _, record := range vm.ballotHistory {
+			if record.QuestionID != vm.vote.id {
+				continue
+			}
+
+			winner := record.Winner
+			if record.Override != "" {
+				winner = record.Override
+			}
+
+			for _, choice := range choices {
+				if choice.ID == winner {
+					payload["winner"] = choice
+				}
+			}
+		}
+	}
+
+	return payload
+}
+
+// sendOverlay sends an overlay client the current snapshot.
+func (vm *VoteManager) sendOverlay(client *Client) {
+	vm.mu.RLock()
+	message := &Message{Type: "overlay", Payload: vm.overlay(time.Now())}
+	vm.mu.RUnlock()
+
+	if err := client.conn.WriteJSON(message); err != nil {
+		slog.Warn("Error sending overlay to client", "error", err)
+	}
+}
+
+// handleGetOverlay returns the overlay snapshot, for overlays that poll.
+func (s *Server) handleGetOverlay(w http.ResponseWriter, _ *http.Request) {
+	s.voteManager.mu.RLock()
+	overlay := s.voteManager.overlay(time.Now())
+	s.voteManager.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(overlay); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleOverlayWebSocket streams overlay snapshots. Overlay clients get
+// nothing else, so an overlay never has to understand the full protocol.
+// Browsers opening /overlay itself are sent to the overlay page.
+func (s *Server) handleOverlayWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		http.Redirect(w, r, s.publicPath("/overlay/"), http.StatusFound)
+
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		requestLogger(r).Error("Failed to upgrade connection", "error", err)
+
+		return
+	}
+
+	s.voteManager.RegisterClient(NewClient(conn, RoleOverlay))
+
+	go func() {
+		defer func() {
+			s.voteManager.UnregisterClient(conn)
+			_ = conn.Close()
+		}()
+
+		// overlays only listen; reading notices when they go away
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
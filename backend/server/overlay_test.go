@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+type overlaySnapshot struct {
+	QuestionID   string          `json:"question_id"`
+	Question     string          `json:"question"`
+	VotingActive bool            `json:"voting_active"`
+	Remaining    float64         `json:"remaining"`
+	Total        int             `json:"total"`
+	Choices      []OverlayChoice `json:"choices"`
+	Winner       *OverlayChoice  `json:"winner"`
+}
+
+func TestOverlay(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	overlay, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/overlay", nil)
+	if err != nil {
+		t.Fatalf("failed to connect overlay: %v", err)
+	}
+	defer overlay.Close()
+
+	overlay.SetReadDeadline(time.Now().Add(3 * time.Second))
+
+	// next reads overlay snapshots until one matches
+	next := func(match func(overlaySnapshot) bool) overlaySnapshot {
+		t.Helper()
+
+		for {
+			var msg struct {
+				Type    string          `json:"type"`
+				Payload overlaySnapshot `json:"payload"`
+			}
+
+			if err := overlay.ReadJSON(&msg); err != nil {
+				t.Fatalf("no matching overlay snapshot: %v", err)
+			}
+
+			if msg.Type != "overlay" {
+				t.Fatalf("overlay client got a %q message", msg.Type)
+			}
+
+			if match(msg.Payload) {
+				return msg.Payload
+			}
+		}
+	}
+
+	if first := next(func(overlaySnapshot) bool { return true }); first.QuestionID != "" || first.Choices == nil {
+		t.Errorf("first snapshot = %+v, want an empty one", first)
+	}
+
+	server.voteManager.StartVotingWithChoices("choice1", []string{"opt-a", "opt-b"}, []parser.Choice{
+		{ID: "opt-a", Label: "Left"},
+		{ID: "opt-b", Label: "Right"},
+	}, "Which way?", time.Minute, nil)
+
+	for voterID, choiceID := range map[string]string{"v1": "opt-b", "v2": "opt-b", "v3": "opt-a"} {
+		server.voteManager.SubmitVote(voterID, choiceID)
+	}
+
+	running := next(func(o overlaySnapshot) bool { return o.Total == 3 })
+	if running.Question != "Which way?" || !running.VotingActive || running.Remaining <= 0 {
+		t.Errorf("running snapshot = %+v, want the question and countdown", running)
+	}
+
+	want := []OverlayChoice{
+		{ID: "opt-b", Label: "Right", Votes: 2, Percent: 67},
+		{ID: "opt-a", Label: "Left", Votes: 1, Percent: 33},
+	}
+	if len(running.Choices) != 2 || running.Choices[0] != want[0] || running.Choices[1] != want[1] {
+		t.Errorf("choices = %+v, want %+v", running.Choices, want)
+	}
+
+	server.voteManager.EndVoting()
+
+	decided := next(func(o overlaySnapshot) bool { return !o.VotingActive })
+	if decided.Winner == nil || decided.Winner.ID != "opt-b" {
+		t.Errorf("winner = %+v, want opt-b", decided.Winner)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/overlay", nil))
+
+	var polled overlaySnapshot
+	if err := json.NewDecoder(w.Body).Decode(&polled); err != nil {
+		t.Fatalf("failed to decode overlay: %v", err)
+	}
+
+	if polled.QuestionID != "choice1" || polled.Winner == nil || polled.Winner.Label != "Right" {
+		t.Errorf("GET /overlay = %+v, want the decided vote", polled)
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/overlay", nil))
+
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/overlay/" {
+		t.Errorf("browser visit = %d to %q, want a redirect to the overlay page", w.Code, w.Header().Get("Location"))
+	}
+}
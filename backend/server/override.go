@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+)
+
+// errNoVote is returned when there is no vote whose winner could be overridden.
+var errNoVote = errors.New("there is no vote to override")
+
+// VoteOverride is the presenter going with another choice than the audience.
+type VoteOverride struct {
+	QuestionID string         `json:"question_id"`
+	Results    map[string]int `json:"results"` // the tally as cast
+	Winner     string         `json:"winner"`  // the choice the audience picked
+	Override   string         `json:"override"`
+	Reason     string         `json:"reason,omitempty"`
+}
+
+// OverrideWinner ends the vote on the current question if it is still
+// running and records choiceID as the choice the story follows instead. The
+// tally and the winner stay as cast; everyone is told about the override with
+// a winner_overridden event.
+func (vm *VoteManager) OverrideWinner(choiceID, reason string) (VoteOverride, error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if vm.vote.id == "" {
+		return VoteOverride{}, errNoVote
+	}
+
+	if _, ok := vm.vote.results[choiceID]; !ok {
+		return VoteOverride{}, fmt.Errorf("choice %q is not up for vote on %s", choiceID, vm.vote.id)
+	}
+
+	vm.endVoting()
+
+	i := len(vm.ballotHistory) - 1
+	for i >= 0 && vm.ballotHistory[i].QuestionID != vm.vote.id {
+		i--
+	}
+
+	if i < 0 {
+		return VoteOverride{}, errNoVote
+	}
+
+	vm.ballotHistory[i].Override = choiceID
+
+	override := VoteOverride{
+		QuestionID: vm.vote.id,
+		Results:    maps.Clone(vm.vote.results),
+		Winner:     vm.ballotHistory[i].Winner,
+		Override:   choiceID,
+		Reason:     reason,
+	}
+
+	vm.broadcast <- &Message{
+		Type: "winner_overridden",
+		Payload: map[string]any{
+			"question_id": override.QuestionID,
+			"results":     override.Results,
+			"winner":      override.Winner,
+			"override":    override.Override,
+			"reason":      override.Reason,
+		},
+	}
+
+	return override, nil
+}
+
+// Override returns the choice the presenter went with instead of the winner
+// of the question, or an empty string when the vote was not overridden.
+func (vm *VoteManager) Override(questionID string) string {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	for _, record := range vm.ballotHistory {
+		if record.QuestionID == questionID {
+			return record.Override
+		}
+	}
+
+	return ""
+}
+
+// handleOverrideWinner lets the presenter take another path than the one the
+// audience voted for, such as when the demo environment cannot support it.
+func (s *Server) handleOverrideWinner(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChoiceID string `json:"choice_id"`
+		Reason   string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	override, err := s.voteManager.OverrideWinner(req.ChoiceID, req.Reason)
+	if errors.Is(err, errNoVote) {
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	requestLogger(r).Info("Vote winner overridden", "question_id", override.QuestionID, "winner", override.Winner, "override", override.Override, "reason", override.Reason, "results", override.Results)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(override); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestOverrideWinner(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	var state Message
+	voter.ReadJSON(&state)
+
+	override := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/override-winner", bytes.NewReader([]byte(body))))
+
+		return w
+	}
+
+	if w := override(`{"choice_id":"opt-b"}`); w.Code != http.StatusConflict {
+		t.Errorf("override without a vote status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	server.voteManager.SubmitVote("voter-1", "opt-a")
+	server.voteManager.SubmitVote("voter-2", "opt-a")
+	server.voteManager.SubmitVote("voter-3", "opt-b")
+
+	if w := override(`{"choice_id":"opt-c"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("override to an unknown choice status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w := override(`{"choice_id":"opt-b","reason":"the cluster for path A is down"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("override status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var got VoteOverride
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode override: %v", err)
+	}
+
+	if got.Winner != "opt-a" || got.Override != "opt-b" || got.Results["opt-a"] != 2 || got.Results["opt-b"] != 1 {
+		t.Errorf("override = %+v, want the opt-a tally overridden with opt-b", got)
+	}
+
+	if server.voteManager.IsVotingActive() {
+		t.Error("voting is still active after the override")
+	}
+
+	voter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	seen := map[string]bool{}
+
+	for !seen["voting_ended"] || !seen["winner_overridden"] {
+		var msg Message
+		if err := voter.ReadJSON(&msg); err != nil {
+			t.Fatalf("missing voting_ended or winner_overridden, got %v: %v", seen, err)
+		}
+
+		seen[msg.Type] = true
+
+		if msg.Type == "winner_overridden" {
+			if msg.Payload["winner"] != "opt-a" || msg.Payload["override"] != "opt-b" || msg.Payload["reason"] != "the cluster for path A is down" {
+				t.Errorf("winner_overridden payload = %v, want opt-a overridden with opt-b", msg.Payload)
+			}
+		}
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/results/choice1", nil))
+
+	var results struct {
+		Results  map[string]int `json:"results"`
+		Override string         `json:"override"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+		t.Fatalf("failed to decode results: %v", err)
+	}
+
+	if results.Override != "opt-b" || results.Results["opt-a"] != 2 {
+		t.Errorf("results = %+v, want the actual tally and the override", results)
+	}
+
+	archived := server.voteManager.VoteResults()
+	if len(archived) != 1 || archived[0].Winner != "opt-a" || archived[0].Override != "opt-b" {
+		t.Errorf("VoteResults() = %+v, want opt-a overridden with opt-b", archived)
+	}
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxPollDuration caps how long a side poll stays open.
+const maxPollDuration = time.Hour
+
+var (
+	errPollNotFound = errors.New("poll not found")
+	errPollClosed   = errors.New("poll is closed")
+	errPollExists   = errors.New("a poll with this ID is open")
+)
+
+// PollChoice is one answer of a side poll.
+type PollChoice struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+// Poll is a question open alongside the story's vote, such as "name the
+// cluster", with its own ballot box, so its timer, ballots and results are
+// apart from the vote's. Polls never move the story on.
+type Poll struct {
+	ID        string         `json:"id"`
+	Question  string         `json:"question,omitempty"`
+	Choices   []PollChoice   `json:"choices"`
+	Results   map[string]int `json:"results"`
+	Total     int            `json:"total"` // voters that answered
+	Open      bool           `json:"open"`
+	Winner    string         `json:"winner,omitempty"`
+	StartedAt time.Time      `json:"started_at"`
+	EndsAt    time.Time      `json:"ends_at,omitzero"` // zero for a poll closed by hand
+
+	box *ballotBox
+}
+
+// snapshot copies the poll, with its results so far, for callers outside
+// vm.mu.
+func (p *Poll) snapshot() Poll {
+	out := *p
+	out.Choices = slices.Clone(p.Choices)
+	out.Results = p.box.tally()
+	out.Total = len(p.box.voters)
+	out.box = nil
+
+	return out
+}
+
+// OpenPoll opens a side poll that runs next to the story's vote until
+// duration passes, or until ClosePoll when duration is 0. A closed poll with
+// the same ID is replaced.
+func (vm *VoteManager) OpenPoll(id, question string, choices []PollChoice, duration time.Duration) (Poll, error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if existing, ok := vm.polls[id]; ok && existing.Open {
+		return Poll{}, errPollExists
+	}
+
+	choiceIDs := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		choiceIDs = append(choiceIDs, choice.ID)
+	}
+
+	now := time.Now()
+	poll := &Poll{
+		ID:        id,
+		Question:  question,
+		Choices:   choices,
+		Open:      true,
+		StartedAt: now,
+		box:       newBallotBox(id, choiceIDs),
+	}
+
+	if duration > 0 {
+		poll.EndsAt = now.Add(duration)
+		poll.box.timer = time.AfterFunc(duration, func() {
+			vm.mu.Lock()
+			defer vm.mu.Unlock()
+
+			// The poll may have been closed, or replaced, while the timer fired.
+			if vm.polls[id] == poll && poll.Open {
+				vm.closePoll(poll)
+			}
+		})
+	}
+
+	vm.polls[id] = poll
+
+	vm.broadcast <- &Message{
+		Type:    "poll_started",
+		Payload: map[string]any{"poll": poll.snapshot()},
+	}
+
+	return poll.snapshot(), nil
+}
+
+// SubmitPollVote records a voter's answer to an open side poll, replacing
+// their earlier one.
+func (vm *VoteManager) SubmitPollVote(pollID, voterID, choiceID string) error {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	poll, ok := vm.polls[pollID]
+	if !ok {
+		return errPollNotFound
+	}
+
+	if !poll.Open {
+		return errPollClosed
+	}
+
+	if !poll.box.accepts(choiceID) {
+		return errors.New("unknown poll choice")
+	}
+
+	poll.box.cast(voterID, choiceID, 1)
+
+	vm.broadcast <- &Message{
+		Type: "poll_update",
+		Payload: map[string]any{
+			"id":      poll.ID,
+			"results": poll.box.tally(),
+			"total":   len(poll.box.voters),
+		},
+	}
+
+	return nil
+}
+
+// ClosePoll closes a side poll and announces its winner.
+func (vm *VoteManager) ClosePoll(id string) (Poll, error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	poll, ok := vm.polls[id]
+	if !ok {
+		return Poll{}, errPollNotFound
+	}
+
+	if !poll.Open {
+		return Poll{}, errPollClosed
+	}
+
+	vm.closePoll(poll)
+
+	return poll.snapshot(), nil
+}
+
+// closePoll closes an open poll and announces its winner. Callers must hold
+// vm.mu.
+func (vm *VoteManager) closePoll(poll *Poll) {
+	poll.box.stop()
+
+	poll.Open = false
+	poll.Winner = vm.determineWinner(poll.box.results)
+
+	vm.broadcast <- &Message{
+		Type: "poll_ended",
+		Payload: map[string]any{
+			"id":      poll.ID,
+			"results": poll.box.tally(),
+			"total":   len(poll.box.voters),
+			"winner":  poll.Winner,
+		},
+	}
+}
+
+// Polls returns every side poll, open or closed, oldest first.
+func (vm *VoteManager) Polls() []Poll {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	out := make([]Poll, 0, len(vm.polls))
+	for _, poll := range vm.polls {
+		out = append(out, poll.snapshot())
+	}
+
+	slices.SortFunc(out, func(a, b Poll) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return out
+}
+
+// openPolls returns the open side polls for a client reconnecting, with the
+// answer of its voter. Callers must hold vm.mu.
+func (vm *VoteManager) openPolls(voterID string) []map[string]any {
+	var out []map[string]any
+
+	for _, poll := range vm.polls {
+		if !poll.Open {
+			continue
+		}
+
+		state := map[string]any{"poll": poll.snapshot()}
+		if choiceID, ok := poll.box.voters[voterID]; ok && voterID != "" {
+			state["your_choice"] = choiceID
+		}
+
+		out = append(out, state)
+	}
+
+	return out
+}
+
+// ResetPolls stops and drops every side poll. Unlike ResetVoting, which ends
+// the story's vote, it leaves the story alone.
+func (vm *VoteManager) ResetPolls() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	for _, poll := range vm.polls {
+		poll.box.stop()
+	}
+
+	clear(vm.polls)
+}
+
+// pollVoteMessage is an incoming {"type":"poll_vote"} WebSocket message.
+type pollVoteMessage struct {
+	PollID   string `json:"poll_id"`
+	ChoiceID string `json:"choice_id"`
+}
+
+// handlePollVote counts an answer to a side poll as the voter the connection
+// votes as.
+func (s *Server) handlePollVote(client *Client, data []byte) error {
+	var msg pollVoteMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	voterID := client.voter()
+	if voterID == "" {
+		return errors.New("poll vote without voter_id")
+	}
+
+	return s.voteManager.SubmitPollVote(msg.PollID, voterID, msg.ChoiceID)
+}
+
+// handleListPolls returns every side poll of the session.
+func (s *Server) handleListPolls(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"polls": s.voteManager.Polls(),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleOpenPoll opens a side poll, such as
+// {"id": "cluster-name", "question": "Name the cluster", "choices": [{"id": "koala"}, {"id": "otter"}], "duration": 60}.
+// Without a duration the poll stays open until closed.
+func (s *Server) handleOpenPoll(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID       string       `json:"id"`
+		Question string       `json:"question"`
+		Choices  []PollChoice `json:"choices"`
+		Duration int          `json:"duration"` // seconds
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+
+	switch {
+	case req.ID == "":
+		http.Error(w, "id is required", http.StatusBadRequest)
+
+		return
+	case len(req.Choices) < 2:
+		http.Error(w, "a poll needs at least two choices", http.StatusBadRequest)
+
+		return
+	case duration < 0 || duration > maxPollDuration:
+		http.Error(w, "duration must be between 0 and 3600 seconds", http.StatusBadRequest)
+
+		return
+	}
+
+	seen := map[string]bool{}
+
+	for _, choice := range req.Choices {
+		if choice.ID == "" || seen[choice.ID] {
+			http.Error(w, "choices need unique IDs", http.StatusBadRequest)
+
+			return
+		}
+
+		seen[choice.ID] = true
+	}
+
+	poll, err := s.voteManager.OpenPoll(req.ID, req.Question, req.Choices, duration)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	}
+
+	requestLogger(r).Info("Poll opened", "poll_id", poll.ID, "choices", len(poll.Choices), "duration", duration)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleClosePoll closes a side poll before its timer runs out.
+func (s *Server) handleClosePoll(w http.ResponseWriter, r *http.Request) {
+	poll, err := s.voteManager.ClosePoll(mux.Vars(r)["id"])
+	if err != nil {
+		status := http.StatusConflict
+		if errors.Is(err, errPollNotFound) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	requestLogger(r).Info("Poll closed", "poll_id", poll.ID, "winner", poll.Winner, "total", poll.Total)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollAlongsideStoryVote(t *testing.T) {
+	vm := NewVoteManager()
+	go vm.Run()
+
+	vm.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+
+	choices := []PollChoice{{ID: "koala"}, {ID: "otter"}}
+	if _, err := vm.OpenPoll("name", "Name the cluster", choices, 0); err != nil {
+		t.Fatalf("OpenPoll() error = %v", err)
+	}
+
+	if _, err := vm.OpenPoll("name", "Again", choices, 0); !errors.Is(err, errPollExists) {
+		t.Errorf("second OpenPoll() error = %v, want %v", err, errPollExists)
+	}
+
+	if err := vm.SubmitVote("v1", "opt-a"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	for voter, choice := range map[string]string{"v1": "koala", "v2": "otter", "v3": "otter"} {
+		if err := vm.SubmitPollVote("name", voter, choice); err != nil {
+			t.Fatalf("SubmitPollVote(%s) error = %v", voter, err)
+		}
+	}
+
+	// v3 changes their mind
+	if err := vm.SubmitPollVote("name", "v3", "koala"); err != nil {
+		t.Fatalf("SubmitPollVote() error = %v", err)
+	}
+
+	if err := vm.SubmitPollVote("name", "v1", "panda"); err == nil {
+		t.Error("expected an error for an unknown choice")
+	}
+
+	if err := vm.SubmitPollVote("missing", "v1", "koala"); !errors.Is(err, errPollNotFound) {
+		t.Errorf("SubmitPollVote() error = %v, want %v", err, errPollNotFound)
+	}
+
+	poll, err := vm.ClosePoll("name")
+	if err != nil {
+		t.Fatalf("ClosePoll() error = %v", err)
+	}
+
+	if poll.Open || poll.Winner != "koala" || poll.Total != 3 || poll.Results["koala"] != 2 || poll.Results["otter"] != 1 {
+		t.Errorf("closed poll = %+v, want koala winning 2 to 1", poll)
+	}
+
+	if err := vm.SubmitPollVote("name", "v4", "otter"); !errors.Is(err, errPollClosed) {
+		t.Errorf("SubmitPollVote() after close error = %v, want %v", err, errPollClosed)
+	}
+
+	if !vm.IsVotingActive() {
+		t.Error("closing the poll ended the story vote")
+	}
+
+	if results := vm.GetResults("choice1"); results["opt-a"] != 1 {
+		t.Errorf("story results = %v, want the single ballot for opt-a", results)
+	}
+
+	vm.ResetVoting()
+
+	if len(vm.Polls()) != 1 {
+		t.Error("ResetVoting dropped the side poll")
+	}
+
+	vm.ResetPolls()
+
+	if len(vm.Polls()) != 0 {
+		t.Error("ResetPolls kept the side poll")
+	}
+}
+
+func TestPollTimer(t *testing.T) {
+	vm := NewVoteManager()
+	go vm.Run()
+
+	choices := []PollChoice{{ID: "yes"}, {ID: "no"}}
+	if _, err := vm.OpenPoll("quick", "", choices, 50*time.Millisecond); err != nil {
+		t.Fatalf("OpenPoll() error = %v", err)
+	}
+
+	if _, err := vm.OpenPoll("slow", "", choices, time.Minute); err != nil {
+		t.Fatalf("OpenPoll() error = %v", err)
+	}
+
+	if err := vm.SubmitPollVote("quick", "v1", "no"); err != nil {
+		t.Fatalf("SubmitPollVote() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+
+	for {
+		polls := vm.Polls()
+		if !polls[0].Open {
+			if polls[0].Winner != "no" {
+				t.Errorf("winner = %q, want %q", polls[0].Winner, "no")
+			}
+
+			if !polls[1].Open {
+				t.Error("the other poll closed with the first")
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("poll did not close when its timer ran out")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	vm.ResetPolls()
+}
+
+func TestHandlePollVote(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	if _, err := server.voteManager.OpenPoll("name", "", []PollChoice{{ID: "koala"}, {ID: "otter"}}, 0); err != nil {
+		t.Fatalf("OpenPoll() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		client  *Client
+		message string
+		wantErr bool
+	}{
+		{"voter", &Client{Role: RoleVoter, voterID: "v1"}, `{"type":"poll_vote","poll_id":"name","choice_id":"otter"}`, false},
+		{"without voter ID", &Client{Role: RoleVoter}, `{"type":"poll_vote","poll_id":"name","choice_id":"otter"}`, true},
+		{"unknown poll", &Client{Role: RoleVoter, voterID: "v1"}, `{"type":"poll_vote","poll_id":"other","choice_id":"otter"}`, true},
+		{"spectator", &Client{Role: RoleSpectator, voterID: "v2"}, `{"type":"poll_vote","poll_id":"name","choice_id":"otter"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.handleClientMessage(tt.client, []byte(tt.message))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleClientMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	server.voteManager.mu.RLock()
+	polls := server.voteManager.openPolls("v1")
+	server.voteManager.mu.RUnlock()
+
+	if len(polls) != 1 || polls[0]["your_choice"] != "otter" {
+		t.Errorf("open polls = %v, want v1's answer otter", polls)
+	}
+}
+
+func TestPollEndpoints(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for _, tt := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{"one choice", `{"id":"name","choices":[{"id":"koala"}]}`, http.StatusBadRequest},
+		{"duplicate choices", `{"id":"name","choices":[{"id":"koala"},{"id":"koala"}]}`, http.StatusBadRequest},
+		{"no id", `{"choices":[{"id":"koala"},{"id":"otter"}]}`, http.StatusBadRequest},
+		{"too long", `{"id":"name","choices":[{"id":"koala"},{"id":"otter"}],"duration":7200}`, http.StatusBadRequest},
+		{"valid", `{"id":"name","question":"Name the cluster","choices":[{"id":"koala"},{"id":"otter"}],"duration":60}`, http.StatusOK},
+		{"already open", `{"id":"name","choices":[{"id":"koala"},{"id":"otter"}]}`, http.StatusConflict},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(http.MethodPost, "/api/polls", tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	w := do(http.MethodGet, "/api/polls", "")
+
+	var list struct {
+		Polls []Poll `json:"polls"`
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode polls: %v", err)
+	}
+
+	if len(list.Polls) != 1 || !list.Polls[0].Open || list.Polls[0].EndsAt.IsZero() {
+		t.Errorf("polls = %+v, want the open timed poll", list.Polls)
+	}
+
+	if w := do(http.MethodPost, "/api/polls/name/close", ""); w.Code != http.StatusOK {
+		t.Errorf("close status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if w := do(http.MethodPost, "/api/polls/name/close", ""); w.Code != http.StatusConflict {
+		t.Errorf("second close status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	if w := do(http.MethodPost, "/api/polls/missing/close", ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing close status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
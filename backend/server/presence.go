@@ -0,0 +1,76 @@
+package server
+
+import "time"
+
+// presenceInterval is how often presence updates are sent at most, so a room
+// connecting at once causes one update instead of one per voter.
+const presenceInterval = time.Second
+
+// Presence is who is connected, sent to every client as a presence event.
+type Presence struct {
+	Voters     int `json:"voters"`
+	Presenters int `json:"presenters"`
+	Spectators int `json:"spectators"`
+	Joined     int `json:"joined"` // voters that connected since the previous update
+	Left       int `json:"left"`   // voters that disconnected since the previous update
+}
+
+// presenceChanged records a client connecting or disconnecting and schedules
+// a presence update. Callers must hold vm.mu.
+func (vm *VoteManager) presenceChanged(client *Client, joined bool) {
+	if client.Role == RoleVoter {
+		if joined {
+			vm.joined++
+			vm.trackAudience()
+		} else {
+			vm.left++
+		}
+	}
+
+	if !vm.presencePending {
+		vm.presencePending = true
+		time.AfterFunc(vm.presenceInterval, vm.broadcastPresence)
+	}
+}
+
+// presence counts the connected clients by role. Callers must hold vm.mu.
+func (vm *VoteManager) presence() Presence {
+	var p Presence
+
+	for _, client := range vm.clients {
+		switch client.Role {
+		case RoleVoter:
+			p.Voters++
+		case RolePresenter:
+			p.Presenters++
+		case RoleSpectator:
+			p.Spectators++
+		}
+	}
+
+	return p
+}
+
+// broadcastPresence tells every client who is connected and how that changed
+// since the previous update.
+func (vm *VoteManager) broadcastPresence() {
+	vm.mu.Lock()
+
+	p := vm.presence()
+	p.Joined, p.Left = vm.joined, vm.left
+	vm.joined, vm.left = 0, 0
+	vm.presencePending = false
+
+	vm.mu.Unlock()
+
+	vm.broadcast <- &Message{
+		Type: "presence",
+		Payload: map[string]any{
+			"voters":     p.Voters,
+			"presenters": p.Presenters,
+			"spectators": p.Spectators,
+			"joined":     p.Joined,
+			"left":       p.Left,
+		},
+	}
+}
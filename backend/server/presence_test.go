@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestPresenceUpdates(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.voteManager.mu.Lock()
+	server.voteManager.presenceInterval = 50 * time.Millisecond
+	server.voteManager.mu.Unlock()
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	presenter, _, err := websocket.DefaultDialer.Dial(wsURL+"?role=presenter", nil)
+	if err != nil {
+		t.Fatalf("failed to connect presenter: %v", err)
+	}
+	defer presenter.Close()
+
+	var state Message
+	presenter.ReadJSON(&state)
+
+	voters := make([]*websocket.Conn, 2)
+	for i := range voters {
+		voters[i], _, err = websocket.DefaultDialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatalf("failed to connect voter: %v", err)
+		}
+		defer voters[i].Close()
+	}
+
+	presenter.SetReadDeadline(time.Now().Add(3 * time.Second))
+
+	// waitFor reads presence updates until one reports the given voters,
+	// returning the voters that joined and left on the way
+	waitFor := func(want int) (joined, left int) {
+		t.Helper()
+
+		for {
+			var msg Message
+			if err := presenter.ReadJSON(&msg); err != nil {
+				t.Fatalf("no presence update with %d voters: %v", want, err)
+			}
+
+			if msg.Type != "presence" {
+				continue
+			}
+
+			joined += int(msg.Payload["joined"].(float64))
+			left += int(msg.Payload["left"].(float64))
+
+			if int(msg.Payload["voters"].(float64)) == want {
+				if msg.Payload["presenters"] != float64(1) {
+					t.Errorf("presence = %v, want 1 presenter", msg.Payload)
+				}
+
+				return joined, left
+			}
+		}
+	}
+
+	if joined, left := waitFor(2); joined != 2 || left != 0 {
+		t.Errorf("joined %d and left %d, want 2 joined", joined, left)
+	}
+
+	voters[0].Close()
+
+	if joined, left := waitFor(1); joined != 0 || left != 1 {
+		t.Errorf("joined %d and left %d, want 1 left", joined, left)
+	}
+
+	if got := server.voteManager.VoterCount(); got != 1 {
+		t.Errorf("VoterCount() = %d, want 1", got)
+	}
+}
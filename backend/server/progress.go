@@ -0,0 +1,41 @@
+package server
+
+import (
+	"maps"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// playableChapters returns every chapter of the story that is not hidden
+// behind a disabled feature. Callers must hold s.mu.
+func (s *Server) playableChapters() (map[string]*parser.Chapter, error) {
+	chapters, err := s.storyEngine.AllChapters()
+	if err != nil {
+		return nil, err
+	}
+
+	maps.DeleteFunc(chapters, func(_ string, chapter *parser.Chapter) bool {
+		return !s.features.Enabled(chapter.Metadata.RequiresFeature)
+	})
+
+	return chapters, nil
+}
+
+// progress reports how far along the story the chapter is in the current
+// run, counting the decisions in the history. Callers must hold s.mu.
+func (s *Server) progress(id string) parser.Progress {
+	chapters, err := s.playableChapters()
+	if err != nil {
+		return parser.Progress{Depth: -1, Remaining: -1}
+	}
+
+	decided := 0
+
+	for _, visited := range s.history {
+		if chapter, ok := chapters[visited]; ok && chapter.Metadata.Type == "decision" {
+			decided++
+		}
+	}
+
+	return parser.ChapterProgress(s.storyEngine.Story.Flow.Start, id, decided, chapters)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestChapterProgressPayloads(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	current := func() parser.Progress {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/current", nil))
+
+		var resp struct {
+			Progress parser.Progress `json:"progress"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode current chapter: %v", err)
+		}
+
+		return resp.Progress
+	}
+
+	if got, want := current(), (parser.Progress{Depth: 0, Remaining: 2, Decision: 0, Decisions: 1}); got != want {
+		t.Errorf("intro progress = %+v, want %+v", got, want)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	var state Message
+	voter.ReadJSON(&state)
+
+	for _, body := range []string{`{}`, `{"choice_id":"opt-b"}`} {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/advance", bytes.NewReader([]byte(body))))
+	}
+
+	voter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var got []parser.Progress
+
+	for len(got) < 2 {
+		var msg struct {
+			Type    string `json:"type"`
+			Payload struct {
+				Progress parser.Progress `json:"progress"`
+			} `json:"payload"`
+		}
+		if err := voter.ReadJSON(&msg); err != nil {
+			t.Fatalf("missing chapter_changed messages, got %v: %v", got, err)
+		}
+
+		if msg.Type == "chapter_changed" {
+			got = append(got, msg.Payload.Progress)
+		}
+	}
+
+	want := []parser.Progress{
+		{Depth: 1, Remaining: 1, Decision: 1, Decisions: 1}, // choice1
+		{Depth: 2, Remaining: 0, Decision: 1, Decisions: 1}, // path-b, after deciding choice1
+	}
+
+	// the test server runs two hubs, so broadcasts may arrive out of order
+	for _, progress := range want {
+		if !slices.Contains(got, progress) {
+			t.Errorf("chapter_changed progress = %+v, want it to contain %+v", got, progress)
+		}
+	}
+
+	if got, want := current(), want[1]; got != want {
+		t.Errorf("current progress = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"math/rand/v2"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// newSeed returns a random seed small enough to survive a round trip through
+// a JavaScript number, so clients can display and replay it exactly.
+func newSeed() uint64 {
+	return rand.Uint64() >> 11 //nolint:gosec // not used for security
+}
+
+// isRandomChapter reports whether the chapter picks its successor at random.
+// Callers must hold s.mu.
+func (s *Server) isRandomChapter(id string) bool {
+	chapter, err := s.storyEngine.GetChapter(id)
+
+	return err == nil && chapter.Metadata.IsRandom()
+}
+
+// rollNext rolls the current random chapter, with the given seed when one is
+// provided so a previous roll can be replayed. Callers must hold s.mu.
+func (s *Server) rollNext(seed *uint64) (*parser.Chapter, *parser.Roll, error) {
+	value := newSeed()
+	if seed != nil {
+		value = *seed
+	}
+
+	next, roll, err := s.storyEngine.RollChapter(s.currentNode, value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return next, &roll, nil
+}
+
+// rollPayload describes a roll for the random_outcome broadcast.
+func rollPayload(chapterID string, roll *parser.Roll) map[string]any {
+	return map[string]any{
+		"chapter_id": chapterID,
+		"seed":       roll.Seed,
+		"roll":       roll.Roll,
+		"total":      roll.Total,
+		"outcome":    roll.Outcome,
+	}
+}
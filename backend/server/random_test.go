@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdvanceRandomChapter(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapters := map[string]string{
+		"intro.md": `---
+id: intro
+type: story
+next: dice
+---
+# Introduction`,
+		"dice.md": `---
+id: dice
+type: random
+outcomes:
+  - id: lucky
+    label: Lucky break
+    next: path-a
+    weight: 3
+  - id: unlucky
+    next: path-b
+---
+# The dice decide`,
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	type advanceResponse struct {
+		ID   string `json:"id"`
+		Roll *struct {
+			ChapterID string `json:"chapter_id"`
+			Seed      uint64 `json:"seed"`
+			Total     int    `json:"total"`
+			Outcome   struct {
+				ID   string `json:"ID"`
+				Next string `json:"Next"`
+			} `json:"outcome"`
+		} `json:"roll"`
+	}
+
+	post := func(path string, body any) advanceResponse {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+
+		var response advanceResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		return response
+	}
+
+	post("/api/v1/restart", nil)
+
+	if got := post("/api/v1/advance", map[string]any{}); got.ID != "dice" || got.Roll != nil {
+		t.Fatalf("advance to dice = %+v, want dice without a roll", got)
+	}
+
+	first := post("/api/v1/advance", map[string]any{"seed": 42})
+	if first.Roll == nil {
+		t.Fatal("advancing from a random chapter did not report the roll")
+	}
+
+	if first.Roll.ChapterID != "dice" || first.Roll.Seed != 42 || first.Roll.Total != 4 {
+		t.Errorf("roll = %+v, want dice rolled with seed 42 out of 4", first.Roll)
+	}
+
+	if first.ID != first.Roll.Outcome.Next {
+		t.Errorf("advanced to %q, want the outcome's %q", first.ID, first.Roll.Outcome.Next)
+	}
+
+	post("/api/v1/go-back", nil)
+
+	if replay := post("/api/v1/advance", map[string]any{"seed": 42}); replay.ID != first.ID {
+		t.Errorf("replaying seed 42 advanced to %q, want %q", replay.ID, first.ID)
+	}
+
+	post("/api/v1/go-back", nil)
+
+	if rolled := post("/api/v1/advance", map[string]any{}); rolled.Roll == nil {
+		t.Errorf("advance without a seed = %+v, want a fresh roll", rolled)
+	}
+}
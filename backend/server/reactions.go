@@ -0,0 +1,125 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	reactionBatchInterval = 500 * time.Millisecond
+	reactionMinInterval   = 200 * time.Millisecond // per voter, about five reactions a second
+)
+
+// reactionEmojis are the reactions the audience can send. A fixed set keeps
+// the presenter screen free of arbitrary text.
+var reactionEmojis = []string{"🔥", "👏", "😂", "😮", "❤️", "👍", "👎", "🎉", "🤔"}
+
+// Reactions counts audience emoji reactions and hands them on in batches, so
+// a room full of reactions becomes a few messages a second instead of one per
+// tap.
+type Reactions struct {
+	mu       sync.Mutex
+	interval time.Duration
+	minGap   time.Duration
+	counts   map[string]int       // emoji -> reactions in the current batch
+	last     map[string]time.Time // voter or client ID -> when its last reaction was counted
+	pending  bool                 // a batch is waiting to be sent
+	send     func(counts map[string]int)
+}
+
+// NewReactions creates a batcher that calls send with the reactions counted
+// every interval, and counts at most one reaction per client every minGap.
+func NewReactions(interval, minGap time.Duration, send func(counts map[string]int)) *Reactions {
+	return &Reactions{
+		interval: interval,
+		minGap:   minGap,
+		counts:   make(map[string]int),
+		last:     make(map[string]time.Time),
+		send:     send,
+	}
+}
+
+// Add counts a reaction from a client. It reports false, and drops the
+// reaction, when the client reacted too recently.
+func (r *Reactions) Add(clientID, emoji string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.last[clientID]) < r.minGap {
+		return false
+	}
+
+	r.last[clientID] = now
+	r.counts[emoji]++
+
+	if !r.pending {
+		r.pending = true
+		time.AfterFunc(r.interval, r.flush)
+	}
+
+	return true
+}
+
+// flush sends the current batch and forgets clients that may react again.
+func (r *Reactions) flush() {
+	r.mu.Lock()
+
+	counts := r.counts
+	r.counts = make(map[string]int)
+	r.pending = false
+
+	now := time.Now()
+	for id, at := range r.last {
+		if now.Sub(at) >= r.minGap {
+			delete(r.last, id)
+		}
+	}
+
+	r.mu.Unlock()
+
+	if len(counts) > 0 {
+		r.send(counts)
+	}
+}
+
+// reactionRequest is an incoming {"type":"reaction"} WebSocket message.
+type reactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// handleReaction counts an audience reaction towards the next reaction_burst.
+// Reactions sent faster than the rate limit are dropped without an error. The
+// limit is per voter, so extra connections of one voter share it; anonymous
+// clients are limited per connection.
+func (s *Server) handleReaction(client *Client, data []byte) error {
+	var req reactionRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	if !slices.Contains(reactionEmojis, req.Emoji) {
+		return errors.New("unsupported reaction")
+	}
+
+	s.reactions.Add(cmp.Or(client.voter(), client.ID), req.Emoji)
+
+	return nil
+}
+
+// broadcastReactions tells every client about a batch of reactions.
+func (s *Server) broadcastReactions(counts map[string]int) {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+
+	s.voteManager.BroadcastMessage("reaction_burst", map[string]any{
+		"reactions": counts,
+		"total":     total,
+	})
+}
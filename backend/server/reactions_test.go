@@ -0,0 +1,101 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestReactionsBatching(t *testing.T) {
+	batches := make(chan map[string]int, 4)
+	reactions := NewReactions(50*time.Millisecond, time.Second, func(counts map[string]int) {
+		batches <- counts
+	})
+
+	if !reactions.Add("a", "🔥") || !reactions.Add("b", "🔥") || !reactions.Add("c", "👏") {
+		t.Fatal("first reactions of three clients were dropped")
+	}
+
+	if reactions.Add("a", "🔥") {
+		t.Error("second reaction within the rate limit was counted")
+	}
+
+	select {
+	case got := <-batches:
+		if len(got) != 2 || got["🔥"] != 2 || got["👏"] != 1 {
+			t.Errorf("batch = %v, want 2 🔥 and 1 👏", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no batch was sent")
+	}
+
+	select {
+	case got := <-batches:
+		t.Errorf("unexpected second batch %v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebSocketReactions(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	if err := server.handleClientMessage(&Client{Role: RoleVoter}, []byte(`{"type":"reaction","emoji":"<b>hi</b>"}`)); err == nil {
+		t.Error("expected an error for an unsupported reaction")
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	presenter, _, err := websocket.DefaultDialer.Dial(wsURL+"?role=presenter", nil)
+	if err != nil {
+		t.Fatalf("failed to connect presenter: %v", err)
+	}
+	defer presenter.Close()
+
+	var state Message
+	presenter.ReadJSON(&state)
+
+	// two anonymous connections, then two of the same voter
+	for _, query := range []string{"", "", "?voter_id=v1", "?voter_id=v1"} {
+		voter, _, err := websocket.DefaultDialer.Dial(wsURL+query, nil)
+		if err != nil {
+			t.Fatalf("failed to connect voter: %v", err)
+		}
+		defer voter.Close()
+
+		voter.ReadJSON(&state)
+
+		for range 3 {
+			if err := voter.WriteJSON(map[string]string{"type": "reaction", "emoji": "🔥"}); err != nil {
+				t.Fatalf("failed to send reaction: %v", err)
+			}
+		}
+	}
+
+	presenter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	for {
+		var msg Message
+		if err := presenter.ReadJSON(&msg); err != nil {
+			t.Fatalf("no reaction_burst received: %v", err)
+		}
+
+		if msg.Type != "reaction_burst" {
+			continue
+		}
+
+		reactions, _ := msg.Payload["reactions"].(map[string]any)
+		if reactions["🔥"] != float64(3) || msg.Payload["total"] != float64(3) {
+			t.Errorf("reaction_burst = %v, want one 🔥 from each anonymous connection and one from the voter", msg.Payload)
+		}
+
+		break
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// epilogueID is the ID of the "Your journey" chapter made up for every run
+// that reaches an ending.
+const epilogueID = "epilogue"
+
+// chapterTitle returns the first heading of a chapter, or its ID when it has
+// none. Callers must hold s.mu.
+func (s *Server) chapterTitle(id string) string {
+	chapter, err := s.chapter(id)
+	if err != nil {
+		return id
+	}
+
+	return cmp.Or(chapter.Title(), id)
+}
+
+// choiceLabel returns the label of a choice of a chapter, or its ID when it
+// has none. Callers must hold s.mu.
+func (s *Server) choiceLabel(chapterID, choiceID string) string {
+	chapter, err := s.chapter(chapterID)
+	if err != nil {
+		return choiceID
+	}
+
+	for _, choice := range chapter.Metadata.Choices {
+		if choice.ID == choiceID {
+			return cmp.Or(choice.Label, choiceID)
+		}
+	}
+
+	return choiceID
+}
+
+// epilogue writes the markdown of the "Your journey" chapter of a run: the
+// chapters it visited, the decisions taken with the share of the votes the
+// choice got, and the endings unlocked so far. Callers must hold s.mu.
+func (s *Server) epilogue(run SessionRun) string {
+	var md strings.Builder
+
+	md.WriteString("# Your journey\n\n## Chapters visited\n\n")
+
+	for i, id := range run.Path {
+		fmt.Fprintf(&md, "%d. %s\n", i+1, s.chapterTitle(id))
+	}
+
+	if len(run.Choices) > 0 {
+		md.WriteString("\n## Decisions made\n\n")
+	}
+
+	for _, choice := range run.Choices {
+		fmt.Fprintf(&md, "- **%s**: %s", s.chapterTitle(choice.From), s.choiceLabel(choice.From, choice.Choice))
+
+		for _, vote := range run.Votes {
+			if vote.Step != choice.Step-1 {
+				continue
+			}
+
+			total := 0
+			for _, votes := range vote.Results {
+				total += votes
+			}
+
+			if total > 0 {
+				fmt.Fprintf(&md, " (%d%% of %d votes)", (vote.Results[choice.Choice]*100+total/2)/total, total)
+			}
+		}
+
+		md.WriteString("\n")
+	}
+
+	endings := s.endings()
+
+	var unlocked []string
+
+	for _, ending := range endings.Endings {
+		if ending.Reached > 0 {
+			unlocked = append(unlocked, s.chapterTitle(ending.ID))
+		}
+	}
+
+	fmt.Fprintf(&md, "\n## Endings unlocked\n\n%d of %d endings found", endings.Reached, endings.Total)
+
+	if len(unlocked) > 0 {
+		fmt.Fprintf(&md, ": %s", strings.Join(unlocked, ", "))
+	}
+
+	md.WriteString(".\n")
+
+	return md.String()
+}
+
+// broadcastEpilogue sends every screen the "Your journey" chapter of a run
+// that just reached an ending, rendered like any chapter. Callers must hold
+// s.mu.
+func (s *Server) broadcastEpilogue(run SessionRun) {
+	content, err := s.storyEngine.RenderMarkdown(s.epilogue(run))
+	if err != nil {
+		slog.Warn("Failed to render the epilogue", "error", err)
+
+		return
+	}
+
+	s.voteManager.BroadcastMessage("epilogue", map[string]any{
+		"id":      epilogueID,
+		"title":   "Your journey",
+		"ending":  s.currentNode,
+		"content": content,
+	})
+}
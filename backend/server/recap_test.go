@@ -0,0 +1,95 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestEpilogue(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	advance := func(body string) {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/advance", strings.NewReader(body)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("advance status = %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	advance(`{}`)
+
+	if err := server.startVoting(slog.Default(), "choice1", []string{"opt-a", "opt-b"}, time.Minute); err != nil {
+		t.Fatalf("startVoting() error = %v", err)
+	}
+
+	for voterID, choice := range map[string]string{"voter-1": "opt-b", "voter-2": "opt-b", "voter-3": "opt-a"} {
+		_ = server.voteManager.SubmitVote(voterID, choice)
+	}
+
+	server.voteManager.EndVoting()
+
+	// the vote reaches the session once the vote's completion has run
+	deadline := time.Now().Add(2 * time.Second)
+	for run, _ := server.sessions.Current(); len(run.Votes) == 0; run, _ = server.sessions.Current() {
+		if time.Now().After(deadline) {
+			t.Fatal("vote never recorded in the session")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	advance(`{"choice_id": "opt-b"}`)
+
+	voter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var epilogue map[string]any
+
+	for epilogue == nil {
+		var msg Message
+		if err := voter.ReadJSON(&msg); err != nil {
+			t.Fatalf("no epilogue event: %v", err)
+		}
+
+		if msg.Type == "epilogue" {
+			epilogue = msg.Payload
+		}
+	}
+
+	if epilogue["id"] != epilogueID || epilogue["ending"] != "path-b" {
+		t.Errorf("epilogue = %v, want the epilogue of path-b", epilogue)
+	}
+
+	content, _ := epilogue["content"].(string)
+	for _, want := range []string{
+		"Your journey</h1>",
+		"<li>Introduction</li>",
+		"<li>Choose your path</li>",
+		"<li>Game Over</li>",
+		"<li><strong>Choose your path</strong>: Option B (67% of 3 votes)</li>",
+		"1 of 1 endings found: Game Over.",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("epilogue lacks %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Defaults for rehearsals started without saying how many voters to fake or
+// how fast timers run.
+const (
+	defaultRehearsalVoters = 25
+	defaultRehearsalSpeed  = 4
+)
+
+// Rehearsal is a practice run of the show. Votes are still accepted, but
+// simulated voters take part, vote timers run Speed times faster and finished
+// runs are neither kept in statistics nor written to the sessions file.
+type Rehearsal struct {
+	Enabled bool    `json:"rehearsal"`
+	Voters  int     `json:"voters"` // simulated voters taking part in every vote
+	Speed   float64 `json:"speed"`  // timer speed multiplier, 2 halves every vote timer
+}
+
+// validate reports settings a rehearsal cannot run with.
+func (r Rehearsal) validate() error {
+	if r.Voters < 0 {
+		return errors.New("voters must not be negative")
+	}
+
+	if r.Speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+
+	return nil
+}
+
+// scale shortens d by the rehearsal speed, or returns it as is outside
+// rehearsals.
+func (r Rehearsal) scale(d time.Duration) time.Duration {
+	if !r.Enabled || r.Speed <= 0 {
+		return d
+	}
+
+	return time.Duration(float64(d) / r.Speed)
+}
+
+// scaled returns the adaptive bounds shortened by the rehearsal speed.
+func (t AdaptiveTimer) scaled(r Rehearsal) AdaptiveTimer {
+	return AdaptiveTimer{
+		Min:   r.scale(t.Min),
+		Max:   r.scale(t.Max),
+		Quiet: r.scale(t.Quiet),
+	}
+}
+
+// simulatedVoterCount is the number of fake voters for the next vote: the
+// rehearsal voters while rehearsing, the configured ones otherwise.
+func (s *Server) simulatedVoterCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.rehearsal.Enabled {
+		return s.rehearsal.Voters
+	}
+
+	return s.simulatedVoters
+}
+
+// setRehearsal switches between rehearsing and presenting. The story restarts
+// under the mode being left, so no practice run or vote carries over into the
+// real show and the other way around. Callers must hold s.mu.
+func (s *Server) setRehearsal(r Rehearsal) error {
+	if _, err := s.restartStory(); err != nil {
+		return err
+	}
+
+	s.rehearsal = r
+	s.sessions.SetDryRun(r.Enabled)
+	s.voteManager.ResetLeaderboard()
+	s.voteManager.ResetAnalytics()
+
+	s.voteManager.BroadcastMessage("mode_changed", map[string]any{
+		"rehearsal": r.Enabled,
+		"voters":    r.Voters,
+		"speed":     r.Speed,
+	})
+
+	return nil
+}
+
+// handleGetMode returns whether the show is being rehearsed.
+func (s *Server) handleGetMode(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	rehearsal := s.rehearsal
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(rehearsal); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleSetMode starts or ends a rehearsal. Either way the story restarts.
+func (s *Server) handleSetMode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Rehearsal bool     `json:"rehearsal"`
+		Voters    *int     `json:"voters"`
+		Speed     *float64 `json:"speed"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	mode := Rehearsal{}
+
+	if req.Rehearsal {
+		mode = Rehearsal{Enabled: true, Voters: defaultRehearsalVoters, Speed: defaultRehearsalSpeed}
+
+		if req.Voters != nil {
+			mode.Voters = *req.Voters
+		}
+
+		if req.Speed != nil {
+			mode.Speed = *req.Speed
+		}
+
+		if err := mode.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.setRehearsal(mode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	requestLogger(r).Info("Mode changed", "rehearsal", mode.Enabled, "voters", mode.Voters, "speed", mode.Speed)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(mode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
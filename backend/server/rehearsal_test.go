@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRehearsalMode(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	post := func(path string, body any, wantStatus int) *httptest.ResponseRecorder {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != wantStatus {
+			t.Fatalf("POST %s status = %d, want %d: %s", path, w.Code, wantStatus, w.Body.String())
+		}
+
+		return w
+	}
+
+	post("/api/v1/mode", map[string]any{"rehearsal": true, "speed": 0}, http.StatusBadRequest)
+
+	var mode Rehearsal
+	if err := json.NewDecoder(post("/api/v1/mode", map[string]any{"rehearsal": true, "voters": 5, "speed": 10}, http.StatusOK).Body).Decode(&mode); err != nil {
+		t.Fatalf("failed to decode mode: %v", err)
+	}
+
+	if !mode.Enabled || mode.Voters != 5 || mode.Speed != 10 {
+		t.Errorf("mode = %+v, want a rehearsal with 5 voters at 10x", mode)
+	}
+
+	post("/api/v1/advance", map[string]any{}, http.StatusOK)
+	post("/api/v1/start-voting", map[string]any{
+		"question_id": "choice1",
+		"choices":     []string{"opt-a", "opt-b"},
+		"duration":    10,
+	}, http.StatusOK)
+
+	// ten seconds at ten times the speed
+	deadline := time.Now().Add(3 * time.Second)
+	for server.voteManager.IsVotingActive() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if server.voteManager.IsVotingActive() {
+		t.Fatal("rehearsal vote did not end at the faster pace")
+	}
+
+	total := 0
+	for _, count := range server.voteManager.GetResults("choice1") {
+		total += count
+	}
+
+	if total != 5 {
+		t.Errorf("got %d simulated votes, want 5", total)
+	}
+
+	post("/api/v1/advance", map[string]any{"choice_id": "opt-b"}, http.StatusOK)
+
+	if runs := server.sessions.Runs(); len(runs) != 0 {
+		t.Errorf("rehearsal kept %d runs, want none", len(runs))
+	}
+
+	post("/api/v1/mode", map[string]any{"rehearsal": false}, http.StatusOK)
+
+	if len(server.voteManager.VoteResults()) != 0 {
+		t.Error("rehearsal votes carried over into the show")
+	}
+
+	post("/api/v1/advance", map[string]any{}, http.StatusOK)
+	post("/api/v1/advance", map[string]any{"choice_id": "opt-b"}, http.StatusOK)
+
+	if runs := server.sessions.Runs(); len(runs) != 1 {
+		t.Errorf("show kept %d runs, want 1", len(runs))
+	}
+}
@@ -0,0 +1,345 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// Report formats.
+const (
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+)
+
+// Report is a recap of the runs of a session, ready to paste into a blog post
+// after the talk.
+type Report struct {
+	Title string
+	Runs  []SessionRun
+
+	chapters map[string]*parser.Chapter // for titles and labels, IDs are shown without
+}
+
+// NewReport describes runs with the titles of chapters and the labels of their
+// choices. Chapters may be nil, such as when the story is not at hand, to show
+// IDs instead.
+func NewReport(title string, runs []SessionRun, chapters map[string]*parser.Chapter) Report {
+	return Report{Title: title, Runs: runs, chapters: chapters}
+}
+
+// reportRun is one run as the report shows it.
+type reportRun struct {
+	Number     int
+	Started    string
+	Length     string // empty while the run goes on
+	Ending     string // empty when abandoned or going on
+	Path       []string
+	PeakVoters int
+	Dwell      []reportDwell
+	Decisions  []reportDecision
+}
+
+// reportDwell is how long a run spent at one chapter.
+type reportDwell struct {
+	Chapter string
+	Time    string
+}
+
+// reportDecision is one vote of a run as the report shows it.
+type reportDecision struct {
+	Chapter         string
+	Question        string
+	Tally           []reportTally
+	Winner          string
+	Taken           string // the choice the story went on with, when the presenter picked another one than Winner
+	Votes           int
+	Margin          int
+	Voters          int
+	Audience        int
+	Participation   int // percent
+	TimeToFirstVote string
+	Open            string
+	Changes         int
+}
+
+// reportTally is how many votes one choice got.
+type reportTally struct {
+	Label   string
+	Votes   int
+	Percent int
+	Winner  bool
+}
+
+// title returns the title of a chapter, or its ID when it has none.
+func (r Report) title(id string) string {
+	if chapter, ok := r.chapters[id]; ok {
+		return cmp.Or(chapter.Title(), id)
+	}
+
+	return id
+}
+
+// label returns the label of a choice of a chapter, or its ID when unknown.
+func (r Report) label(chapterID, choiceID string) string {
+	if chapter, ok := r.chapters[chapterID]; ok {
+		for _, choice := range chapter.Metadata.Choices {
+			if choice.ID == choiceID {
+				return cmp.Or(choice.Label, choiceID)
+			}
+		}
+	}
+
+	return choiceID
+}
+
+// seconds formats a number of seconds as a duration.
+func seconds(s float64) string {
+	return time.Duration(s * float64(time.Second)).Round(time.Second).String()
+}
+
+// runs lays out the runs for the templates.
+func (r Report) runs() []reportRun {
+	runs := make([]reportRun, 0, len(r.Runs))
+
+	for i, run := range r.Runs {
+		view := reportRun{
+			Number:     i + 1,
+			Started:    run.StartedAt.Format("2006-01-02 15:04 MST"),
+			PeakVoters: run.PeakVoters,
+		}
+
+		if !run.EndedAt.IsZero() {
+			view.Length = run.EndedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+
+		if run.Ending != "" {
+			view.Ending = r.title(run.Ending)
+		}
+
+		for _, id := range run.Path {
+			view.Path = append(view.Path, r.title(id))
+		}
+
+		for _, id := range run.visited() {
+			view.Dwell = append(view.Dwell, reportDwell{Chapter: r.title(id), Time: seconds(run.Dwell[id])})
+		}
+
+		for _, vote := range run.Votes {
+			view.Decisions = append(view.Decisions, r.decision(run, vote))
+		}
+
+		runs = append(runs, view)
+	}
+
+	return runs
+}
+
+// decision lays out one vote of a run for the templates.
+func (r Report) decision(run SessionRun, vote VoteRecord) reportDecision {
+	decision := reportDecision{
+		Chapter:       r.title(vote.Chapter),
+		Question:      vote.Question,
+		Margin:        vote.Margin,
+		Voters:        vote.Voters,
+		Audience:      vote.Audience,
+		Participation: int(vote.Participation*100 + 0.5),
+		Open:          seconds(vote.Duration),
+		Changes:       vote.Changes,
+	}
+
+	if vote.Winner != "" {
+		decision.Winner = r.label(vote.Chapter, vote.Winner)
+	}
+
+	if vote.TimeToFirstVote != nil {
+		decision.TimeToFirstVote = seconds(*vote.TimeToFirstVote)
+	}
+
+	for _, choice := range run.Choices {
+		if choice.Step == vote.Step+1 && choice.Choice != vote.Winner {
+			decision.Taken = r.label(vote.Chapter, choice.Choice)
+		}
+	}
+
+	total := 0
+	for _, votes := range vote.Results {
+		total += votes
+	}
+
+	for choiceID, votes := range vote.Results {
+		tally := reportTally{
+			Label:  r.label(vote.Chapter, choiceID),
+			Votes:  votes,
+			Winner: choiceID == vote.Winner,
+		}
+
+		if total > 0 {
+			tally.Percent = (votes*100 + total/2) / total
+		}
+
+		if tally.Winner {
+			decision.Votes = votes
+		}
+
+		decision.Tally = append(decision.Tally, tally)
+	}
+
+	slices.SortFunc(decision.Tally, func(a, b reportTally) int {
+		return cmp.Or(b.Votes-a.Votes, strings.Compare(a.Label, b.Label))
+	})
+
+	return decision
+}
+
+// reportData is what the report templates get.
+type reportData struct {
+	Title string
+	Runs  []reportRun
+}
+
+// Write renders the report in the given format, Markdown when empty.
+func (r Report) Write(w io.Writer, format string) error {
+	data := reportData{Title: cmp.Or(r.Title, "Adventure recap"), Runs: r.runs()}
+
+	switch cmp.Or(format, ReportMarkdown) {
+	case ReportMarkdown:
+		return markdownReport.Execute(w, data)
+	case ReportHTML:
+		return htmlReport.Execute(w, data)
+	default:
+		return fmt.Errorf("unknown report format %q, want %s or %s", format, ReportMarkdown, ReportHTML)
+	}
+}
+
+// cell escapes text for a Markdown table cell.
+func cell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+var markdownReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cell": cell,
+	"join": strings.Join,
+}).Parse(`# {{.Title}}
+{{range .Runs}}
+## Run {{.Number}}{{if .Ending}}: {{.Ending}}{{end}}
+
+Started {{.Started}}{{if .Length}}, took {{.Length}}{{else}}, still going{{end}}.
+{{- if .PeakVoters}} Up to {{.PeakVoters}} voters were connected.{{end}}
+
+**Path:** {{join .Path " → "}}
+{{if .Dwell}}
+| Chapter | Time |
+|---------|-----:|
+{{range .Dwell}}| {{cell .Chapter}} | {{.Time}} |
+{{end}}{{end}}{{range .Decisions}}
+### {{.Chapter}}
+{{if .Question}}
+> {{.Question}}
+{{end}}
+| Choice | Votes | Share |
+|--------|------:|------:|
+{{range .Tally}}| {{if .Winner}}**{{cell .Label}}**{{else}}{{cell .Label}}{{end}} | {{.Votes}} | {{.Percent}}% |
+{{end}}
+{{if .Winner}}**{{.Winner}}** won with {{.Votes}} votes, {{.Margin}} ahead of the runner-up.{{else}}Nobody voted.{{end}}
+{{- if .Taken}} The presenter went with **{{.Taken}}** instead.{{end}}
+{{.Voters}} of {{.Audience}} voters took part ({{.Participation}}%) while the vote was open for {{.Open}}.
+{{- if .TimeToFirstVote}} The first ballot came in after {{.TimeToFirstVote}}.{{end}}
+{{- if .Changes}} Ballots changed {{.Changes}} times.{{end}}
+{{end}}{{end}}`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+table { border-collapse: collapse; margin: 0.5rem 0; }
+th, td { border: 1px solid #ccc; padding: 0.25rem 0.75rem; text-align: left; }
+td.n { text-align: right; }
+blockquote { color: #555; margin-left: 0; padding-left: 1rem; border-left: 3px solid #ccc; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Runs}}
+<h2>Run {{.Number}}{{if .Ending}}: {{.Ending}}{{end}}</h2>
+<p>Started {{.Started}}{{if .Length}}, took {{.Length}}{{else}}, still going{{end}}.
+{{- if .PeakVoters}} Up to {{.PeakVoters}} voters were connected.{{end}}</p>
+<p><strong>Path:</strong> {{range $i, $title := .Path}}{{if $i}} → {{end}}{{$title}}{{end}}</p>
+{{if .Dwell}}<table>
+<tr><th>Chapter</th><th>Time</th></tr>
+{{range .Dwell}}<tr><td>{{.Chapter}}</td><td class="n">{{.Time}}</td></tr>
+{{end}}</table>{{end}}
+{{range .Decisions}}
+<h3>{{.Chapter}}</h3>
+{{if .Question}}<blockquote>{{.Question}}</blockquote>{{end}}
+<table>
+<tr><th>Choice</th><th>Votes</th><th>Share</th></tr>
+{{range .Tally}}<tr><td>{{if .Winner}}<strong>{{.Label}}</strong>{{else}}{{.Label}}{{end}}</td><td class="n">{{.Votes}}</td><td class="n">{{.Percent}}%</td></tr>
+{{end}}</table>
+<p>{{if .Winner}}<strong>{{.Winner}}</strong> won with {{.Votes}} votes, {{.Margin}} ahead of the runner-up.{{else}}Nobody voted.{{end}}
+{{- if .Taken}} The presenter went with <strong>{{.Taken}}</strong> instead.{{end}}
+{{.Voters}} of {{.Audience}} voters took part ({{.Participation}}%) while the vote was open for {{.Open}}.
+{{- if .TimeToFirstVote}} The first ballot came in after {{.TimeToFirstVote}}.{{end}}
+{{- if .Changes}} Ballots changed {{.Changes}} times.{{end}}</p>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// handleReport writes a recap of the session's runs, including the one in
+// progress, as Markdown or, with {"format": "html"}, as HTML.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Format string `json:"format"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	contentType := "text/markdown; charset=utf-8"
+
+	switch cmp.Or(req.Format, ReportMarkdown) {
+	case ReportMarkdown:
+	case ReportHTML:
+		contentType = "text/html; charset=utf-8"
+	default:
+		http.Error(w, "format must be markdown or html", http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.RLock()
+	title := s.storyEngine.Story.Title
+	chapters, err := s.storyEngine.AllChapters()
+	s.mu.RUnlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	if err := NewReport(title, s.sessions.Recorded(), chapters).Write(w, req.Format); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
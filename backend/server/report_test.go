@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/advance", strings.NewReader(`{}`)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("advance = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if err := server.startVoting(slog.Default(), "choice1", []string{"opt-a", "opt-b"}, time.Minute); err != nil {
+		t.Fatalf("startVoting() error = %v", err)
+	}
+
+	for voter, choice := range map[string]string{"voter-1": "opt-b", "voter-2": "opt-b", "voter-3": "opt-a"} {
+		_ = server.voteManager.SubmitVote(voter, choice)
+	}
+
+	server.voteManager.EndVoting()
+
+	// the vote reaches the session once the vote's completion has run
+	deadline := time.Now().Add(2 * time.Second)
+	for runs := server.sessions.Recorded(); len(runs[0].Votes) == 0; runs = server.sessions.Recorded() {
+		if time.Now().After(deadline) {
+			t.Fatal("vote never recorded in the session")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/report", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("report = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/markdown") {
+		t.Errorf("Content-Type = %q, want Markdown", got)
+	}
+
+	report := w.Body.String()
+	for _, want := range []string{
+		"## Run 1",
+		"still going",
+		"**Path:** Introduction → Choose your path",
+		"> Choose your path",
+		"| **Option B** | 2 | 67% |",
+		"| Option A | 1 | 33% |",
+		"**Option B** won with 2 votes, 1 ahead of the runner-up.",
+		"3 of 3 voters took part (100%) while the vote was open for",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/report", strings.NewReader(`{"format": "html"}`)))
+
+	if !strings.Contains(w.Body.String(), "<td><strong>Option B</strong></td>") {
+		t.Errorf("HTML report lacks the winner:\n%s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/report", strings.NewReader(`{"format": "pdf"}`)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("pdf report = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestReportWithoutStory(t *testing.T) {
+	runs := []SessionRun{{
+		StartedAt: time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC),
+		EndedAt:   time.Date(2026, 5, 4, 10, 12, 0, 0, time.UTC),
+		Path:      []string{"intro", "choice1", "path-a"},
+		Choices:   []ChoiceRecord{{From: "choice1", Choice: "opt-a", To: "path-a", Step: 2}},
+		Votes: []VoteRecord{{Chapter: "choice1", Step: 1, QuestionStats: QuestionStats{
+			QuestionID: "choice1",
+			Results:    map[string]int{"opt-a": 1, "opt-b": 4},
+			Winner:     "opt-b",
+			Voters:     5,
+			Audience:   5,
+		}}},
+		Ending: "path-a",
+		Dwell:  map[string]float64{"intro": 90, "choice1": 45.3},
+	}}
+
+	var buf bytes.Buffer
+	if err := NewReport("", runs, nil).Write(&buf, ""); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	report := buf.String()
+	for _, want := range []string{
+		"# Adventure recap",
+		"## Run 1: path-a",
+		"took 12m0s",
+		"**Path:** intro → choice1 → path-a",
+		"The presenter went with **opt-a** instead.",
+		"| intro | 1m30s |\n| choice1 | 45s |\n| path-a | 0s |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+
+	if err := NewReport("", runs, nil).Write(&buf, "pdf"); err == nil {
+		t.Error("want an error for an unknown format")
+	}
+}
@@ -0,0 +1,430 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// Room is a show running beside the main one, such as a workshop breakout,
+// with its own story position, votes and WebSocket clients. Voters reach it
+// at /r/{code}/voter/ and its API lives under /api/rooms/{code}/.
+type Room struct {
+	Code    string
+	Story   string // ID of the story bundle the room plays
+	Created time.Time
+
+	lastActive time.Time // when the room last served a request, guarded by Rooms.mu
+	server     *Server
+}
+
+// Rooms are the rooms a server hosts besides its own show.
+type Rooms struct {
+	mu      sync.RWMutex
+	rooms   map[string]*Room
+	idleTTL time.Duration // rooms nobody used for this long are closed, never when 0
+}
+
+// NewRooms creates an empty set of rooms, closing rooms once they have been
+// idle for idleTTL, or never when it is 0.
+func NewRooms(idleTTL time.Duration) *Rooms {
+	return &Rooms{rooms: map[string]*Room{}, idleTTL: idleTTL}
+}
+
+// Get returns the room with the given code. Codes are compared ignoring
+// case.
+func (rs *Rooms) Get(code string) (*Room, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	room := rs.rooms[strings.ToUpper(code)]
+
+	return room, room != nil
+}
+
+// reserve picks a code no room uses yet and holds it until the room is put
+// or released.
+func (rs *Rooms) reserve() string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for {
+		code := randomCode()
+		if _, taken := rs.rooms[code]; !taken {
+			rs.rooms[code] = nil
+
+			return code
+		}
+	}
+}
+
+// put opens a room under its reserved code.
+func (rs *Rooms) put(room *Room) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	room.lastActive = room.Created
+	rs.rooms[room.Code] = room
+}
+
+// touch marks a room as in use.
+func (rs *Rooms) touch(room *Room) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	room.lastActive = time.Now()
+}
+
+// List returns the open rooms, oldest first.
+func (rs *Rooms) List() []*Room {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	rooms := make([]*Room, 0, len(rs.rooms))
+
+	for _, room := range rs.rooms {
+		if room != nil {
+			rooms = append(rooms, room)
+		}
+	}
+
+	slices.SortFunc(rooms, func(a, b *Room) int {
+		return cmp.Or(a.Created.Compare(b.Created), cmp.Compare(a.Code, b.Code))
+	})
+
+	return rooms
+}
+
+// remove takes the room with the given code out of service and returns it.
+func (rs *Rooms) remove(code string) (*Room, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	code = strings.ToUpper(code)
+
+	room := rs.rooms[code]
+	if room == nil {
+		return nil, false
+	}
+
+	delete(rs.rooms, code)
+
+	return room, true
+}
+
+// idle returns the rooms nobody has connected to or made a request to for
+// the idle TTL as of now.
+func (rs *Rooms) idle(now time.Time) []string {
+	if rs.idleTTL <= 0 {
+		return nil
+	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	var codes []string
+
+	for code, room := range rs.rooms {
+		if room == nil || now.Sub(room.lastActive) < rs.idleTTL {
+			continue
+		}
+
+		if connected := room.server.voteManager.Connected(); connected.Voters+connected.Presenters+connected.Spectators == 0 {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+// release frees a code reserved for a room that failed to open.
+func (rs *Rooms) release(code string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	delete(rs.rooms, code)
+}
+
+// roomCode returns the code of the room this server runs, empty for the
+// main show.
+func (s *Server) roomCode() string {
+	return strings.TrimPrefix(s.roomPath, "/r/")
+}
+
+// roomPath is where the pages of the room with the given code are served.
+func roomPath(code string) string {
+	return "/r/" + code
+}
+
+// openRoom starts a room playing the story bundle with the given ID, run by
+// presenters with the given secret or with the credentials of the server.
+// The room shares the features and other settings of the server, but none
+// of its integrations: vote sources, webhooks and sessions files stay with
+// the main show.
+func (s *Server) openRoom(storyID, secret string) (*Room, error) {
+	bundle, ok := s.story(storyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown story %q", storyID)
+	}
+
+	code := s.rooms.reserve()
+
+	voterURL := ""
+	if s.voterURL != "" {
+		if u, err := url.Parse(s.voterURL); err == nil {
+			u.Path = s.basePath + roomPath(code) + "/voter/"
+			voterURL = u.String()
+		}
+	}
+
+	server, err := NewServer(bundle.StoryPath, bundle.ContentDir, s.staticFS, secret, voterURL, false, func(room *Server) {
+		room.roomPath = roomPath(code)
+		room.basePath = s.basePath
+		room.admin = s
+		room.features = s.features
+		room.engineOptions = s.engineOptions
+		room.stories = s.stories
+		room.activeStory = bundle.ID
+		room.build = s.build
+		room.tokenTTL = s.tokenTTL
+		room.sessionTTL = s.sessionTTL
+		room.signingKey = s.signingKey
+		room.lockout = s.lockout
+		room.joinLockout = s.joinLockout
+		room.allowedNetworks = s.allowedNetworks
+
+		if s.joinCodes != nil {
+			room.joinCodes = NewJoinCodes()
+		}
+	})
+	if err != nil {
+		s.rooms.release(code)
+
+		return nil, err
+	}
+
+	room := &Room{Code: code, Story: bundle.ID, Created: time.Now(), server: server}
+	s.rooms.put(room)
+
+	return room, nil
+}
+
+// serveRoom hands requests under prefix+{code}/ to the room, as if they had
+// been made to target on a server of its own.
+func (s *Server) serveRoom(prefix, target string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code := mux.Vars(r)["code"]
+
+		room, ok := s.rooms.Get(code)
+		if !ok {
+			http.Error(w, "no such room", http.StatusNotFound)
+
+			return
+		}
+
+		s.rooms.touch(room)
+
+		u := *r.URL
+		u.Path = target + strings.TrimPrefix(r.URL.Path, prefix+code+"/")
+		u.RawPath = ""
+
+		inner := r.Clone(r.Context())
+		inner.URL = &u
+
+		room.server.router.ServeHTTP(w, inner)
+	}
+}
+
+// closeRoom ends the room with the given code: its clients are disconnected
+// and its state is dropped.
+func (s *Server) closeRoom(code, reason string) bool {
+	room, ok := s.rooms.remove(code)
+	if !ok {
+		return false
+	}
+
+	vm := room.server.voteManager
+	vm.ResetVoting()
+	vm.ResetPolls()
+	vm.DisconnectAll(websocket.CloseGoingAway, reason)
+	vm.Stop()
+
+	if err := room.server.Close(); err != nil {
+		slog.Warn("Failed to close room", "room", room.Code, "error", err)
+	}
+
+	return true
+}
+
+// expireRooms closes rooms once they have been idle for the idle TTL.
+func (s *Server) expireRooms() {
+	interval := min(time.Minute, max(time.Second, s.rooms.idleTTL/10))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		for _, code := range s.rooms.idle(now) {
+			if s.closeRoom(code, "room closed after being idle") {
+				slog.Info("Idle room closed", "room", code)
+			}
+		}
+	}
+}
+
+// handleRoomLink sends voters following a bare room link to its voter page.
+func (s *Server) handleRoomLink(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, s.basePath+roomPath(mux.Vars(r)["code"])+"/voter/", http.StatusFound)
+}
+
+// handleOpenRoom opens a room playing the story in {"story": "..."}, the
+// active one by default, and returns where voters and the presenter find it.
+// The room's presenters log in with {"secret": "..."}; when the server
+// requires presenters to log in and none is given, a secret is made up and
+// returned.
+func (s *Server) handleOpenRoom(w http.ResponseWriter, r *http.Request) {
+	if s.rooms == nil {
+		http.Error(w, "rooms are not enabled", http.StatusNotFound)
+
+		return
+	}
+
+	var req struct {
+		Story  string `json:"story"`
+		Secret string `json:"secret"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.RLock()
+	storyID := cmp.Or(req.Story, s.activeStory)
+	s.mu.RUnlock()
+
+	if _, ok := s.story(storyID); !ok {
+		http.Error(w, "story not found", http.StatusNotFound)
+
+		return
+	}
+
+	response := map[string]any{}
+
+	secret := req.Secret
+	if secret == "" && s.authRequired() {
+		secret = randomCode() + "-" + randomCode()
+		response["secret"] = secret
+	}
+
+	room, err := s.openRoom(storyID, secret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	requestLogger(r).Info("Room opened", "room", room.Code, "story", room.Story)
+
+	response["code"] = room.Code
+	response["story"] = room.Story
+	response["voter_url"] = room.server.effectiveVoterURL(r)
+	response["presenter_url"] = requestOrigin(r) + s.basePath + roomPath(room.Code) + "/presenter/"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleListRooms lists the open rooms with who is connected to them.
+func (s *Server) handleListRooms(w http.ResponseWriter, r *http.Request) {
+	if s.rooms == nil {
+		http.Error(w, "rooms are not enabled", http.StatusNotFound)
+
+		return
+	}
+
+	type roomInfo struct {
+		Code         string    `json:"code"`
+		Story        string    `json:"story"`
+		Created      time.Time `json:"created"`
+		LastActive   time.Time `json:"last_active"`
+		Participants Presence  `json:"participants"`
+		VoterURL     string    `json:"voter_url"`
+	}
+
+	rooms := []roomInfo{}
+
+	for _, room := range s.rooms.List() {
+		s.rooms.mu.RLock()
+		lastActive := room.lastActive
+		s.rooms.mu.RUnlock()
+
+		rooms = append(rooms, roomInfo{
+			Code:         room.Code,
+			Story:        room.Story,
+			Created:      room.Created,
+			LastActive:   lastActive,
+			Participants: room.server.voteManager.Connected(),
+			VoterURL:     room.server.effectiveVoterURL(r),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"rooms": rooms,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleCloseRoom closes a room, disconnecting everyone in it.
+func (s *Server) handleCloseRoom(w http.ResponseWriter, r *http.Request) {
+	if s.rooms == nil {
+		http.Error(w, "rooms are not enabled", http.StatusNotFound)
+
+		return
+	}
+
+	code := strings.ToUpper(mux.Vars(r)["code"])
+
+	if !s.closeRoom(code, "room closed by presenter") {
+		http.Error(w, "room not found", http.StatusNotFound)
+
+		return
+	}
+
+	requestLogger(r).Info("Room closed", "room", code)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status": "closed",
+		"code":   code,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,336 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRooms(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "", "", false, WithRooms(0))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/v1/rooms", "application/json", nil)
+	if err != nil {
+		t.Fatalf("failed to open room: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var opened struct {
+		Code     string `json:"code"`
+		VoterURL string `json:"voter_url"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&opened); err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("open room = %d, %v, want a room", resp.StatusCode, err)
+	}
+
+	if opened.VoterURL != ts.URL+"/r/"+opened.Code+"/voter/" {
+		t.Errorf("voter_url = %q, want the room's voter page", opened.VoterURL)
+	}
+
+	room, ok := server.rooms.Get(strings.ToLower(opened.Code))
+	if !ok {
+		t.Fatalf("room %s not found", opened.Code)
+	}
+
+	resp, err = http.Post(ts.URL+"/api/rooms/"+opened.Code+"/advance", "application/json", bytes.NewBufferString("{}"))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("advancing the room failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if room.server.currentNode != "choice1" || server.currentNode != "intro" {
+		t.Errorf("room at %q, main show at %q, want only the room advanced", room.server.currentNode, server.currentNode)
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/r/"+opened.Code+"/ws?voter_id=v1", nil)
+	if err != nil {
+		t.Fatalf("failed to connect to the room: %v", err)
+	}
+	defer ws.Close()
+
+	var msg Message
+	_ = ws.ReadJSON(&msg) // state
+
+	room.server.voteManager.StartVoting("q1", []string{"a", "b"}, 2*time.Second, nil)
+	_ = ws.ReadJSON(&msg) // voting_started
+	_ = ws.WriteJSON(VoteMessage{Type: "vote", VoterID: "v1", ChoiceID: "a"})
+
+	deadline := time.Now().Add(time.Second)
+	for !room.server.voteManager.HasVoted("v1") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !room.server.voteManager.HasVoted("v1") || server.voteManager.HasVoted("v1") {
+		t.Error("want the vote counted in the room only")
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err = client.Get(ts.URL + "/r/" + opened.Code)
+	if err != nil {
+		t.Fatalf("failed to follow room link: %v", err)
+	}
+	resp.Body.Close()
+
+	if location := resp.Header.Get("Location"); location != "/r/"+opened.Code+"/voter/" {
+		t.Errorf("room link redirects to %q, want the voter page", location)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/rooms/NOROOM/chapter/current")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown room = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestRoomLifecycle(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "", "", false, WithRooms(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	open := func(body string) (int, string) {
+		resp, err := http.Post(ts.URL+"/api/v1/rooms", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("failed to open room: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var opened struct {
+			Code string `json:"code"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&opened)
+
+		return resp.StatusCode, opened.Code
+	}
+
+	if code, _ := open(`{"story": "nope"}`); code != http.StatusNotFound {
+		t.Errorf("open room with unknown story = %d, want %d", code, http.StatusNotFound)
+	}
+
+	_, first := open(`{"story": "default"}`)
+	_, second := open("")
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/r/"+first+"/ws?voter_id=v1", nil)
+	if err != nil {
+		t.Fatalf("failed to connect to the room: %v", err)
+	}
+	defer ws.Close()
+
+	list := func() map[string]int {
+		resp, err := http.Get(ts.URL + "/api/v1/rooms")
+		if err != nil {
+			t.Fatalf("failed to list rooms: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var out struct {
+			Rooms []struct {
+				Code         string   `json:"code"`
+				Participants Presence `json:"participants"`
+			} `json:"rooms"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+
+		voters := map[string]int{}
+		for _, room := range out.Rooms {
+			voters[room.Code] = room.Participants.Voters
+		}
+
+		return voters
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for list()[first] != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if rooms := list(); len(rooms) != 2 || rooms[first] != 1 || rooms[second] != 0 {
+		t.Errorf("rooms = %v, want %s with one voter and an empty %s", rooms, first, second)
+	}
+
+	// only the empty room has been idle, the other has a voter
+	if idle := server.rooms.idle(time.Now().Add(2 * time.Hour)); len(idle) != 1 || idle[0] != second {
+		t.Errorf("idle rooms = %v, want [%s]", idle, second)
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/v1/rooms/"+strings.ToLower(first), nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("close room = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	_ = ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	for {
+		var msg Message
+		if err := ws.ReadJSON(&msg); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+				t.Errorf("voter read error = %v, want closed with the room", err)
+			}
+
+			break
+		}
+	}
+
+	if rooms := list(); len(rooms) != 1 {
+		t.Errorf("rooms after closing one = %v, want only %s", rooms, second)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/rooms/" + first + "/chapter/current")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("closed room = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestRoomCredentials(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "admin-secret", "", false, WithRooms(0))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	do := func(method, target, auth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	open := func(body string) (string, string) {
+		w := do("POST", "/api/v1/rooms", "admin-secret", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("open room = %d, want %d", w.Code, http.StatusCreated)
+		}
+
+		var opened struct {
+			Code   string `json:"code"`
+			Secret string `json:"secret"`
+		}
+		_ = json.NewDecoder(w.Body).Decode(&opened)
+
+		return opened.Code, opened.Secret
+	}
+
+	a, generated := open(`{"secret": "room-a"}`)
+	if generated != "" {
+		t.Errorf("secret %q returned for a room opened with one", generated)
+	}
+
+	b, secretB := open("")
+	if secretB == "" {
+		t.Fatal("want a secret made up for a room opened without one")
+	}
+
+	login := do("POST", "/api/rooms/"+a+"/login", "", `{"secret": "room-a"}`)
+
+	var token struct {
+		Token string `json:"token"`
+	}
+
+	if err := json.NewDecoder(login.Body).Decode(&token); err != nil || token.Token == "" {
+		t.Fatalf("room login = %d, want a token", login.Code)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		auth     string
+		wantCode int
+	}{
+		{"facilitator runs their room", "GET", "/api/rooms/" + a + "/admin/clients", "room-a", http.StatusOK},
+		{"facilitator's token runs their room", "GET", "/api/rooms/" + a + "/admin/clients", token.Token, http.StatusOK},
+		{"admin runs any room", "GET", "/api/rooms/" + a + "/admin/clients", "admin-secret", http.StatusOK},
+		{"made-up secret runs its room", "GET", "/api/rooms/" + b + "/admin/clients", secretB, http.StatusOK},
+		{"facilitator cannot run another room", "GET", "/api/rooms/" + b + "/admin/clients", "room-a", http.StatusUnauthorized},
+		{"facilitator's token is for their room only", "GET", "/api/rooms/" + b + "/admin/clients", token.Token, http.StatusUnauthorized},
+		{"facilitator cannot run the main show", "GET", "/api/v1/admin/clients", "room-a", http.StatusUnauthorized},
+		{"facilitator's token is no good on the main show", "GET", "/api/v1/admin/clients", token.Token, http.StatusUnauthorized},
+		{"facilitator cannot manage rooms", "GET", "/api/v1/rooms", "room-a", http.StatusUnauthorized},
+		{"facilitator cannot close rooms", "DELETE", "/api/v1/rooms/" + b, "room-a", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(tt.method, tt.target, tt.auth, ""); w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("room sessions", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/r/"+a+"/presenter/login", strings.NewReader("secret=room-a"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if location := w.Header().Get("Location"); location != "/r/"+a+"/presenter/" {
+			t.Errorf("room login redirects to %q, want the room's presenter view", location)
+		}
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 2 || cookies[0].Name != sessionCookie+"_"+a || cookies[1].Name != csrfCookie+"_"+a {
+			t.Fatalf("room login set %v, want the room's own session and CSRF cookies", cookies)
+		}
+
+		for target, want := range map[string]int{
+			"/api/rooms/" + a + "/admin/clients": http.StatusOK,
+			"/api/rooms/" + b + "/admin/clients": http.StatusUnauthorized,
+		} {
+			req := httptest.NewRequest("GET", target, nil)
+			req.AddCookie(cookies[0])
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != want {
+				t.Errorf("%s with the room session = %d, want %d", target, w.Code, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Participant is a person on the roster of a closed workshop.
+type Participant struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Code  string `json:"code,omitempty"`
+
+	joinedAt    time.Time
+	connections int
+}
+
+// key identifies the participant when joining: the code when one was issued,
+// otherwise the email address, otherwise the name.
+func (p *Participant) key() string {
+	for _, v := range []string{p.Code, p.Email, p.Name} {
+		if v != "" {
+			return normalizeParticipantKey(v)
+		}
+	}
+
+	return ""
+}
+
+func normalizeParticipantKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// ParticipantStatus is a participant's join status as shown to the presenter.
+type ParticipantStatus struct {
+	Participant
+
+	ID        string     `json:"id"`
+	Joined    bool       `json:"joined"`
+	JoinedAt  *time.Time `json:"joined_at,omitempty"`
+	Connected bool       `json:"connected"`
+	HasVoted  bool       `json:"has_voted"`
+}
+
+// Roster restricts voting to a known list of participants.
+type Roster struct {
+	mu           sync.Mutex
+	participants []*Participant
+	byKey        map[string]*Participant
+}
+
+// LoadRoster reads a roster from a .json file holding an array of
+// {name, email, code} objects, or from a .csv file whose header row names the
+// name, email and code columns. Each participant needs at least one of them.
+func LoadRoster(path string) (*Roster, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open roster: %w", err)
+	}
+	defer f.Close()
+
+	var participants []*Participant
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&participants); err != nil {
+			return nil, fmt.Errorf("failed to parse roster: %w", err)
+		}
+	case ".csv":
+		participants, err = parseRosterCSV(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse roster: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported roster format %q (use .csv or .json)", filepath.Ext(path))
+	}
+
+	return NewRoster(participants)
+}
+
+func parseRosterCSV(r io.Reader) ([]*Participant, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		name = normalizeParticipantKey(name)
+		if name == "name" || name == "email" || name == "code" {
+			columns[name] = i
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, errors.New("header must name at least one of the name, email or code columns")
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	var participants []*Participant
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return participants, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		participants = append(participants, &Participant{
+			Name:  field(record, "name"),
+			Email: field(record, "email"),
+			Code:  field(record, "code"),
+		})
+	}
+}
+
+// NewRoster builds a roster, rejecting participants that cannot be identified
+// and duplicate identities.
+func NewRoster(participants []*Participant) (*Roster, error) {
+	r := &Roster{byKey: make(map[string]*Participant, len(participants))}
+
+	for i, p := range participants {
+		key := p.key()
+		if key == "" {
+			return nil, fmt.Errorf("participant %d has no name, email or code", i+1)
+		}
+
+		if _, ok := r.byKey[key]; ok {
+			return nil, fmt.Errorf("participant %q is listed twice", key)
+		}
+
+		r.byKey[key] = p
+		r.participants = append(r.participants, p)
+	}
+
+	return r, nil
+}
+
+// Join marks the participant identified by code as connected and returns
+// their voter ID, or false when they are not on the roster.
+func (r *Roster) Join(code string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := normalizeParticipantKey(code)
+
+	p, ok := r.byKey[key]
+	if !ok || key == "" {
+		return "", false
+	}
+
+	if p.joinedAt.IsZero() {
+		p.joinedAt = time.Now()
+	}
+
+	p.connections++
+
+	return key, true
+}
+
+// Leave records that one of the participant's connections closed.
+func (r *Roster) Leave(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.byKey[id]; ok && p.connections > 0 {
+		p.connections--
+	}
+}
+
+// Name returns the display name of a participant, falling back to their ID.
+func (r *Roster) Name(id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.byKey[id]; ok && p.Name != "" {
+		return p.Name
+	}
+
+	return id
+}
+
+// Status returns every participant in roster order; voted reports whether a
+// voter ID has a ballot on the current question.
+func (r *Roster) Status(voted func(voterID string) bool) []ParticipantStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]ParticipantStatus, 0, len(r.participants))
+
+	for _, p := range r.participants {
+		status := ParticipantStatus{
+			Participant: *p,
+			ID:          p.key(),
+			Joined:      !p.joinedAt.IsZero(),
+			Connected:   p.connections > 0,
+		}
+
+		if status.Joined {
+			joinedAt := p.joinedAt
+			status.JoinedAt = &joinedAt
+		}
+
+		status.HasVoted = voted(status.ID)
+		out = append(out, status)
+	}
+
+	return out
+}
+
+// rosterStatus reports the roster with live voting status.
+func (s *Server) rosterStatus() map[string]any {
+	participants := s.roster.Status(s.voteManager.HasVoted)
+
+	joined := 0
+	for _, p := range participants {
+		if p.Joined {
+			joined++
+		}
+	}
+
+	return map[string]any{
+		"participants": participants,
+		"joined":       joined,
+		"total":        len(participants),
+	}
+}
+
+// broadcastRoster pushes the roster status to presenters.
+func (s *Server) broadcastRoster() {
+	s.voteManager.BroadcastToRole(RolePresenter, "roster_updated", s.rosterStatus())
+}
+
+// handleGetRoster returns every participant and whether they have joined.
+func (s *Server) handleGetRoster(w http.ResponseWriter, r *http.Request) {
+	if s.roster == nil {
+		http.Error(w, "no participant roster configured", http.StatusNotFound)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.rosterStatus()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleParticipantVote records a vote from a roster participant under their
+// own identity, whatever voter ID the client claims.
+func (s *Server) handleParticipantVote(client *Client, data []byte) error {
+	var msg VoteMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	if msg.Type != "vote" {
+		return nil
+	}
+
+	if err := s.voteManager.SubmitVote(client.participantID, msg.ChoiceID); err != nil {
+		return err
+	}
+
+	s.broadcastRoster()
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestLoadRoster(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"roster.csv":  "Name,Email,Code\nAda Lovelace,ada@example.com,\nAlan Turing,,T-42\n",
+		"roster.json": `[{"name": "Ada Lovelace", "email": "ada@example.com"}, {"name": "Alan Turing", "code": "T-42"}]`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatalf("failed to write roster: %v", err)
+			}
+
+			roster, err := LoadRoster(path)
+			if err != nil {
+				t.Fatalf("LoadRoster() error = %v", err)
+			}
+
+			if id, ok := roster.Join(" ADA@example.com "); !ok || id != "ada@example.com" {
+				t.Errorf("Join(email) = %q, %v", id, ok)
+			}
+
+			if id, ok := roster.Join("t-42"); !ok || id != "t-42" {
+				t.Errorf("Join(code) = %q, %v", id, ok)
+			}
+
+			// a participant with a code must use it
+			if _, ok := roster.Join("Alan Turing"); ok {
+				t.Error("Join(name) succeeded for a participant with a code")
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"dupes.csv":   "email\na@example.com\nA@example.com\n",
+			"noid.json":   `[{"name": ""}]`,
+			"header.csv":  "first,last\nAda,Lovelace\n",
+			"roster.yaml": "- name: Ada\n",
+		} {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatalf("failed to write roster: %v", err)
+			}
+
+			if _, err := LoadRoster(path); err == nil {
+				t.Errorf("LoadRoster(%s) succeeded, want error", name)
+			}
+		}
+	})
+}
+
+func TestRosterVoting(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	roster, err := NewRoster([]*Participant{{Name: "Ada", Code: "ADA1"}, {Name: "Alan", Code: "ALAN"}})
+	if err != nil {
+		t.Fatalf("NewRoster() error = %v", err)
+	}
+
+	server.roster = roster
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?code=nope", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unknown code: err = %v, want 403", err)
+	}
+
+	voter, _, err := websocket.DefaultDialer.Dial(wsURL+"?code=ada1", nil)
+	if err != nil {
+		t.Fatalf("failed to connect participant: %v", err)
+	}
+	defer voter.Close()
+
+	var msg Message
+	voter.ReadJSON(&msg) // state
+	voter.ReadJSON(&msg)
+
+	if msg.Type != "participant" || msg.Payload["voter_id"] != "ada1" || msg.Payload["name"] != "Ada" {
+		t.Fatalf("welcome = %+v, want participant ada1", msg)
+	}
+
+	server.voteManager.StartVoting("q1", []string{"a", "b"}, 2*time.Second, nil)
+	voter.ReadJSON(&msg) // voting_started
+
+	// the claimed voter ID is ignored in favor of the roster identity
+	if err := voter.WriteJSON(VoteMessage{Type: "vote", VoterID: "someone-else", ChoiceID: "a"}); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !server.voteManager.HasVoted("ada1") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/roster", nil))
+
+	var status struct {
+		Participants []ParticipantStatus `json:"participants"`
+		Joined       int                 `json:"joined"`
+		Total        int                 `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode roster: %v", err)
+	}
+
+	if status.Joined != 1 || status.Total != 2 {
+		t.Errorf("joined %d of %d, want 1 of 2", status.Joined, status.Total)
+	}
+
+	ada, alan := status.Participants[0], status.Participants[1]
+	if !ada.Joined || !ada.Connected || !ada.HasVoted || ada.JoinedAt == nil {
+		t.Errorf("ada = %+v, want joined, connected and voted", ada)
+	}
+
+	if alan.Joined || alan.Connected || alan.HasVoted {
+		t.Errorf("alan = %+v, want not joined", alan)
+	}
+
+	if server.voteManager.HasVoted("someone-else") {
+		t.Error("vote was recorded under the claimed voter ID")
+	}
+
+	server.voteManager.EndVoting()
+}
+
+func TestGetRosterWithoutRoster(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/roster", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
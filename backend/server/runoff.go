@@ -0,0 +1,116 @@
+package server
+
+import (
+	"slices"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+const (
+	// TieBreakRandom lets any of the tied leaders win. It is the default.
+	TieBreakRandom = "random"
+
+	// TieBreakRunoff lets the audience vote again between the tied leaders:
+	// a shorter runoff between them starts right away. The runoff keeps the
+	// question and its onComplete callback, so its winner decides the
+	// original question. A runoff that ties again is decided like any other
+	// vote.
+	TieBreakRunoff = "runoff"
+)
+
+// minRunoff is the shortest a runoff runs, unless the vote it follows was
+// shorter still.
+const minRunoff = 10 * time.Second
+
+// voteRequest is a vote as it was asked to start.
+type voteRequest struct {
+	questionID    string
+	choiceIDs     []string
+	choiceObjects []parser.Choice
+	question      string
+	translations  map[string]LocalizedQuestion
+	duration      time.Duration
+	onComplete    func(map[string]int, string)
+	runoff        bool // between the tied leaders of an earlier vote on the question
+	locked        bool // voters cannot change their ballot, see LockBallots
+}
+
+// tiedLeaders returns the choices that share the most votes, sorted, or nil
+// unless more than one does.
+func tiedLeaders(results map[string]int) []string {
+	top := 0
+
+	var leaders []string
+
+	for choiceID, votes := range results {
+		switch {
+		case votes > top:
+			top = votes
+			leaders = []string{choiceID}
+		case votes == top && votes > 0:
+			leaders = append(leaders, choiceID)
+		}
+	}
+
+	if len(leaders) < 2 {
+		return nil
+	}
+
+	slices.Sort(leaders)
+
+	return leaders
+}
+
+// runoffRequest returns the runoff of req between the tied choices, running
+// half as long.
+func runoffRequest(req voteRequest, tied []string) voteRequest {
+	untied := func(choice parser.Choice) bool { return !slices.Contains(tied, choice.ID) }
+
+	req.choiceIDs = tied
+	req.choiceObjects = slices.DeleteFunc(slices.Clone(req.choiceObjects), untied)
+
+	if req.translations != nil {
+		translations := make(map[string]LocalizedQuestion, len(req.translations))
+		for lang, text := range req.translations {
+			text.Choices = slices.DeleteFunc(slices.Clone(text.Choices), untied)
+			translations[lang] = text
+		}
+
+		req.translations = translations
+	}
+
+	req.duration = max(req.duration/2, min(req.duration, minRunoff))
+	req.runoff = true
+
+	return req
+}
+
+// startRunoff starts the runoff of a vote that just ended, when the tie-break
+// policy asks for one and its leaders tie, and reports whether it did.
+// Callers must hold vm.mu.
+func (vm *VoteManager) startRunoff(results map[string]int) bool {
+	if vm.tieBreak != TieBreakRunoff || vm.vote.request.runoff || len(vm.correctChoices) > 0 {
+		return false
+	}
+
+	tied := tiedLeaders(results)
+	if tied == nil {
+		return false
+	}
+
+	vm.trackEnd(results, "")
+
+	vm.broadcast <- &Message{
+		Type: "vote_tied",
+		Payload: map[string]any{
+			"question_id": vm.vote.id,
+			"results":     results,
+			"choices":     tied,
+		},
+	}
+
+	vm.beginVoting(runoffRequest(vm.vote.request, tied))
+
+	return true
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestRunoffBetweenTiedLeaders(t *testing.T) {
+	vm := NewVoteManager()
+	vm.tieBreak = TieBreakRunoff
+
+	winners := make(chan string, 2)
+
+	choices := []parser.Choice{{ID: "a", Label: "A"}, {ID: "b", Label: "B"}, {ID: "c", Label: "C"}}
+	vm.StartVotingWithChoices("q", []string{"a", "b", "c"}, choices, "Which way?", time.Minute, func(_ map[string]int, winner string) {
+		winners <- winner
+	})
+
+	vm.SubmitVote("voter-1", "a")
+	vm.SubmitVote("voter-2", "b")
+	vm.SubmitVote("voter-3", "b")
+	vm.SubmitVote("voter-4", "a")
+	vm.SubmitVote("voter-5", "c")
+	vm.EndVoting()
+
+	if !vm.IsVotingActive() {
+		t.Fatal("no runoff started after a tie")
+	}
+
+	vm.mu.RLock()
+	payload := vm.started.Payload
+	duration := vm.timerDuration
+	vm.mu.RUnlock()
+
+	shown := payload["choices"].([]parser.Choice)
+	if ids := []string{shown[0].ID, shown[len(shown)-1].ID}; len(shown) != 2 || !slices.Equal(ids, []string{"a", "b"}) {
+		t.Errorf("runoff choices = %v, want the tied a and b", shown)
+	}
+
+	if payload["runoff"] != true || payload["question_id"] != "q" || payload["question"] != "Which way?" {
+		t.Errorf("runoff payload = %v, want a runoff of the same question", payload)
+	}
+
+	if duration != 30*time.Second {
+		t.Errorf("runoff duration = %s, want half the vote", duration)
+	}
+
+	// every voter has a fresh ballot, and only the tied choices count
+	vm.SubmitVote("voter-5", "c")
+	vm.SubmitVote("voter-5", "b")
+	vm.EndVoting()
+
+	select {
+	case winner := <-winners:
+		if winner != "b" {
+			t.Errorf("winner = %s, want the runoff's winner b", winner)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the original callback never learned the winner")
+	}
+
+	if vm.IsVotingActive() {
+		t.Error("a vote is still running after the runoff")
+	}
+}
+
+func TestRunoffDecidedOnSecondTie(t *testing.T) {
+	vm := NewVoteManager()
+	vm.tieBreak = TieBreakRunoff
+
+	winners := make(chan string, 2)
+
+	vm.StartVoting("q", []string{"a", "b"}, time.Minute, func(_ map[string]int, winner string) {
+		winners <- winner
+	})
+
+	vm.SubmitVote("voter-1", "a")
+	vm.SubmitVote("voter-2", "b")
+	vm.EndVoting()
+
+	vm.SubmitVote("voter-1", "a")
+	vm.SubmitVote("voter-2", "b")
+	vm.EndVoting()
+
+	select {
+	case winner := <-winners:
+		if winner != "a" && winner != "b" {
+			t.Errorf("winner = %q, want one of the tied choices", winner)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a tied runoff started another runoff")
+	}
+
+	if vm.IsVotingActive() {
+		t.Error("a tied runoff started another runoff")
+	}
+}
+
+func TestTiedLeaders(t *testing.T) {
+	tests := map[string]struct {
+		results map[string]int
+		want    []string
+	}{
+		"clear winner": {map[string]int{"a": 3, "b": 1}, nil},
+		"tie":          {map[string]int{"c": 2, "a": 2, "b": 1}, []string{"a", "c"}},
+		"no votes":     {map[string]int{"a": 0, "b": 0}, nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tiedLeaders(tt.results); !slices.Equal(got, tt.want) {
+				t.Errorf("tiedLeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import "github.com/skarlso/kube_adventures/voting/backend/parser"
+
+// scorePayload describes the session score and the points the winning choice
+// added to it, if any.
+func scorePayload(state parser.State, points int) map[string]any {
+	return map[string]any{
+		"score":  state.Score(),
+		"points": points,
+	}
+}
+
+// broadcastScore sends the session score to every client when it is no longer
+// the previous one. Callers must hold s.mu.
+func (s *Server) broadcastScore(previous, points int) {
+	if s.vars.Score() == previous {
+		return
+	}
+
+	s.voteManager.BroadcastMessage("score", scorePayload(s.vars, points))
+}
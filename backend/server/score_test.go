@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestScoreDrivesBranching(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapters := map[string]string{
+		"choice.md": `---
+id: choice1
+type: decision
+question: Choose your path
+choices:
+  - id: opt-a
+    label: Cut corners
+    next: path-a
+    points: 30
+  - id: opt-b
+    label: Do it right
+    next: path-a
+    points: 60
+---
+# Choose your path`,
+		"path-a.md": `---
+id: path-a
+type: story
+next: good if score >= 50 else path-b
+---
+# Path A`,
+		"good.md": `---
+id: good
+type: terminal
+---
+# Good Ending`,
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	post := func(path string, body any) {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	score := func() any {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/state", nil))
+
+		var state struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+			t.Fatalf("failed to decode state: %v", err)
+		}
+
+		return state.Variables["score"]
+	}
+
+	post("/api/v1/restart", nil)
+	post("/api/v1/advance", map[string]any{})
+	post("/api/v1/advance", map[string]any{"choice_id": "opt-b"})
+
+	if got := score(); got != float64(60) {
+		t.Errorf("score = %v, want 60", got)
+	}
+
+	voter.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	for {
+		var msg Message
+		if err := voter.ReadJSON(&msg); err != nil {
+			t.Fatalf("no score message received: %v", err)
+		}
+
+		if msg.Type != "score" {
+			continue
+		}
+
+		if msg.Payload["score"] != float64(60) || msg.Payload["points"] != float64(60) {
+			t.Errorf("score payload = %v, want 60 points for a score of 60", msg.Payload)
+		}
+
+		break
+	}
+
+	post("/api/v1/advance", map[string]any{})
+
+	server.mu.RLock()
+	current := server.currentNode
+	server.mu.RUnlock()
+
+	if current != "good" {
+		t.Errorf("current chapter = %s, want the good ending above 50 points", current)
+	}
+
+	// going back takes the points of the choice away again
+	post("/api/v1/go-back", map[string]any{})
+	post("/api/v1/go-back", map[string]any{})
+
+	if got := score(); got != nil {
+		t.Errorf("score after going back = %v, want none", got)
+	}
+
+	post("/api/v1/advance", map[string]any{"choice_id": "opt-a"})
+	post("/api/v1/advance", map[string]any{})
+
+	server.mu.RLock()
+	current = server.currentNode
+	server.mu.RUnlock()
+
+	if current != "path-b" {
+		t.Errorf("current chapter = %s, want path-b below 50 points", current)
+	}
+}
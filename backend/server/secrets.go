@@ -0,0 +1,152 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// argon2id parameters of the hashes HashSecret makes, the option RFC 9106
+// recommends where memory is tight, as in a small pod.
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+	argon2KeyLen  = 32
+)
+
+// isHashedSecret reports whether a configured secret is a bcrypt or argon2id
+// hash rather than the secret itself.
+func isHashedSecret(secret string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$", "$argon2id$"} {
+		if strings.HasPrefix(secret, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HashSecret hashes a presenter secret for the -presenter-secret flag with
+// bcrypt, or with argon2id when useArgon2 is set.
+func HashSecret(secret string, useArgon2 bool) (string, error) {
+	if !useArgon2 {
+		hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash secret: %w", err)
+		}
+
+		return string(hash), nil
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash secret: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(secret), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// matchSecret reports whether given is the configured secret, which may be
+// a hash. Every comparison takes the same time however much of the secret
+// given gets right.
+func matchSecret(configured, given string) bool {
+	switch {
+	case strings.HasPrefix(configured, "$argon2id$"):
+		return matchArgon2(configured, given)
+	case isHashedSecret(configured):
+		return bcrypt.CompareHashAndPassword([]byte(configured), []byte(given)) == nil
+	}
+
+	// hashed first so that neither does the length of the secret leak
+	a, b := sha256.Sum256([]byte(configured)), sha256.Sum256([]byte(given))
+
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// matchArgon2 checks given against an argon2id hash in the PHC string format,
+// $argon2id$v=19$m=65536,t=3,p=4$salt$key.
+func matchArgon2(hash, given string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+
+	var memory, time uint32
+
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+
+	got := argon2.IDKey([]byte(given), salt, time, memory, threads, uint32(len(want))) //nolint:gosec // key lengths are tiny
+
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// secretMatches reports whether given is the configured secret. Hashes are
+// slow on purpose, and browsers send Basic Auth with every request, so
+// credentials that matched a hash are remembered.
+func (s *Server) secretMatches(configured, given string) bool {
+	if configured == "" || given == "" {
+		return false
+	}
+
+	if !isHashedSecret(configured) {
+		return matchSecret(configured, given)
+	}
+
+	key := sha256.Sum256([]byte(configured + "\x00" + given))
+	if _, ok := s.verified.Load(key); ok {
+		return true
+	}
+
+	if !matchSecret(configured, given) {
+		return false
+	}
+
+	s.verified.Store(key, true)
+
+	return true
+}
+
+// secretRole returns what a secret grants: RolePresenter for the presenter
+// secret, roleCoPresenter for the co-presenter's, and nothing otherwise. In
+// rooms, the secrets of the main server work too.
+func (s *Server) secretRole(secret string) string {
+	switch {
+	case s.secretMatches(s.presenterSecret, secret):
+		return RolePresenter
+	case s.secretMatches(s.coPresenter, secret):
+		return roleCoPresenter
+	case s.admin != nil:
+		return s.admin.secretRole(secret)
+	}
+
+	return ""
+}
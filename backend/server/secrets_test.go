@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestMatchSecret(t *testing.T) {
+	bcryptHash, err := HashSecret("s3cret", false)
+	if err != nil {
+		t.Fatalf("HashSecret() error = %v", err)
+	}
+
+	argon2Hash, err := HashSecret("s3cret", true)
+	if err != nil {
+		t.Fatalf("HashSecret(argon2) error = %v", err)
+	}
+
+	for _, configured := range []string{"s3cret", bcryptHash, argon2Hash} {
+		if !matchSecret(configured, "s3cret") {
+			t.Errorf("matchSecret(%q) rejected the secret", configured)
+		}
+
+		for _, wrong := range []string{"", "s3cre", "s3cret!", configured} {
+			if wrong != "s3cret" && matchSecret(configured, wrong) {
+				t.Errorf("matchSecret(%q, %q) succeeded", configured, wrong)
+			}
+		}
+	}
+
+	if matchSecret("$argon2id$v=19$m=65536,t=3,p=4$broken", "s3cret") {
+		t.Error("matchSecret() accepted a malformed hash")
+	}
+}
+
+func TestHashedPresenterSecret(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	hash, err := HashSecret("lead-secret", false)
+	if err != nil {
+		t.Fatalf("HashSecret() error = %v", err)
+	}
+
+	server.presenterSecret = hash
+
+	get := func(password string) int {
+		req := httptest.NewRequest("GET", "/api/v1/story/outline", nil)
+		req.SetBasicAuth("presenter", password)
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	for range 2 {
+		if code := get("lead-secret"); code != http.StatusOK {
+			t.Errorf("right secret = %d, want %d", code, http.StatusOK)
+		}
+	}
+
+	if code := get(hash); code != http.StatusUnauthorized {
+		t.Errorf("the hash itself = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
@@ -2,14 +2,20 @@ package server
 
 import (
 	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
-	"log"
+	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -36,142 +42,424 @@ type Server struct {
 	storyEngine     *parser.StoryEngine
 	storyPath       string
 	currentNode     string
-	history         []string // breadcrumb of visited chapter IDs
+	history         []string      // breadcrumb of visited chapter IDs
+	forward         []forwardStep // chapters left by going back, the next to redo last
 	staticFS        fs.FS
 	presenterSecret string
+	coPresenter     string // secret granting read-only presenter access, empty disables it
 	voterURL        string
 	authorMode      bool
+	sessions        *SessionStore
+	chat            *ChatLog
+	reactions       *Reactions
+	suggestions     *SuggestionBox
+	features        Features
+	vars            parser.State     // story variables set by chapters and used by conditional branching
+	varsHistory     []parser.State   // variables as they were before each entry in history
+	simulatedVoters int              // fake voters casting ballots on every vote (demo mode)
+	rehearsal       Rehearsal        // practice mode, see Rehearsal
+	roster          *Roster          // when set, only listed participants may vote
+	slack           *Slack           // when set, votes are posted to and taken from a Slack channel
+	sms             *SMS             // when set, votes can be texted to a Twilio number
+	leader          *LeaderElection  // when set, only the elected replica serves, the others forward to it
+	diceRoll        *parser.DiceRoll // result of the current roll chapter once its dice are rolled
+	rollInterval    time.Duration    // how long the dice of a roll chapter tumble between ticks
+	stories         []StoryBundle    // every story this server can switch to
+	activeStory     string           // ID of the story being played
+	engineOptions   []parser.EngineOption
+	sources         []VoteSource // platforms votes are taken from, see VoteSource
+	stopSources     context.CancelFunc
+	devReload       bool          // browsers reload when the story or frontend changes, see WithDevReload
+	build           BuildInfo     // what GET /api/info reports, see WithBuildInfo
+	tokenTTL        time.Duration // how long presenter tokens from /login last
+	sessionTTL      time.Duration // how long presenters stay logged in in the browser
+	oidc            *OIDC         // when set, presenters can log in with an OpenID Connect provider
+	joinCodes       *JoinCodes    // when set, voters need the join code shown on screen to vote
+	lockout         *lockout      // addresses refused after guessing presenter secrets wrong
+	joinLockout     *lockout      // addresses refused after guessing join or roster codes wrong
+	verified        sync.Map      // credentials that matched a hashed secret, see secretMatches
+	allowedNetworks Networks      // addresses presenters may connect from, all when empty
+	rooms           *Rooms        // when set, presenters can open rooms running further shows
+	roomPath        string        // where the room this server runs is served, empty for the main show
+	admin           *Server       // the main server of a room, whose presenters may run the room too
+	httpLimits      HTTPLimits    // timeouts and size limits of the HTTP server
+	basePath        string        // path prefix the server is mounted at behind a proxy, see WithBasePath
+
+	certificates *CertificateTokens // tokens voters fetch their own certificate with
+	signingKey   []byte             // signs presenter tokens, sessions and CSRF tokens, see WithSigningSecret
 }
 
 // NewServer creates a new server instance with embedded filesystem.
-func NewServer(storyPath, contentDir string, staticFS fs.FS, presenterSecret, voterURL string, authorMode bool) (*Server, error) {
-	engine, err := parser.NewStoryEngine(storyPath, contentDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create story engine: %w", err)
-	}
-
-	if errors := engine.ValidateStory(); len(errors) > 0 {
-		log.Println("Story validation warnings:")
-
-		for _, err := range errors {
-			log.Printf("  - %v", err)
-		}
-	}
-
+func NewServer(storyPath, contentDir string, staticFS fs.FS, presenterSecret, voterURL string, authorMode bool, opts ...Option) (*Server, error) {
 	s := &Server{
 		router:          mux.NewRouter(),
 		voteManager:     NewVoteManager(),
-		storyEngine:     engine,
 		storyPath:       storyPath,
-		currentNode:     engine.Story.Flow.Start,
 		history:         []string{},
 		staticFS:        staticFS,
 		presenterSecret: presenterSecret,
 		voterURL:        voterURL,
 		authorMode:      authorMode,
+		sessions:        NewSessionStore(""),
+		chat:            NewChatLog(chatHistorySize),
+		suggestions:     NewSuggestionBox(),
+		features:        Features{},
+		vars:            parser.State{},
+		stories:         []StoryBundle{{ID: defaultStoryID, StoryPath: storyPath, ContentDir: contentDir}},
+		activeStory:     defaultStoryID,
+		tokenTTL:        defaultTokenTTL,
+		sessionTTL:      defaultSessionTTL,
+		lockout:         newLockout(),
+		joinLockout:     newLockout(),
+		httpLimits:      DefaultHTTPLimits,
+		rollInterval:    rollTickInterval,
+		certificates:    NewCertificateTokens(),
+		signingKey:      randomKey(),
+	}
+
+	s.reactions = NewReactions(reactionBatchInterval, reactionMinInterval, s.broadcastReactions)
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.sessions.SetDryRun(s.rehearsal.Enabled)
+
+	engine, err := s.newStoryEngine(storyPath, contentDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create story engine: %w", err)
 	}
 
+	for _, err := range engine.ValidateStory() {
+		slog.Warn("Story validation warning", "error", err)
+	}
+
+	s.storyEngine = engine
+	s.currentNode = engine.Story.Flow.Start
+
+	if start, err := s.chapter(s.currentNode); err == nil {
+		s.vars.Enter(start.Metadata)
+	}
+
+	s.sessions.Begin(s.currentNode)
 	s.setupRoutes()
+	s.startVoteSources()
 
 	go s.voteManager.Run()
 
+	if s.rooms != nil {
+		go s.expireRooms()
+	}
+
 	return s, nil
 }
 
 func (s *Server) setupRoutes() {
-	api := s.router.PathPrefix("/api").Subrouter()
+	s.router.Use(withRequestID, withCompression)
+
+	// rooms first for the same reason
+	if s.rooms != nil {
+		s.router.PathPrefix("/api/rooms/{code}/").HandlerFunc(s.serveRoom("/api/rooms/", "/api/v1/"))
+		s.router.PathPrefix("/r/{code}/").HandlerFunc(s.serveRoom("/r/", "/"))
+		s.router.HandleFunc("/r/{code}", s.handleRoomLink)
+	}
 
+	// the versioned API must be registered first, /api would swallow /api/v1 otherwise
+	v1 := s.router.PathPrefix("/api/v1").Subrouter()
+	v1.Use(withAPIVersion(false, s.publicPath("")))
+	s.registerAPIRoutes(v1)
+
+	// unversioned aliases kept for existing presenter tooling
+	legacy := s.router.PathPrefix("/api").Subrouter()
+	legacy.Use(withAPIVersion(true, s.publicPath("")))
+	s.registerAPIRoutes(legacy)
+
+	s.router.HandleFunc("/ws", s.handleWebSocket)
+	s.router.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
+	s.router.HandleFunc("/auth/logout", s.handleLogout).Methods("GET", "POST")
+	s.router.HandleFunc(loginPagePath, s.handleLoginPage).Methods("GET", "HEAD")
+	s.router.HandleFunc(loginPagePath, s.handlePresenterLogin).Methods("POST")
+
+	if s.oidc != nil {
+		s.router.HandleFunc("/auth/oidc/login", s.handleOIDCLogin).Methods("GET")
+		s.router.HandleFunc(oidcCallbackPath, s.handleOIDCCallback).Methods("GET")
+	}
+
+	s.router.HandleFunc("/overlay", s.handleOverlayWebSocket)
+
+	frontend := newVersionedFS(s.staticFS, s.basePath)
+	fileServer := s.withAppShell(newStaticETags(frontend).handler(http.FileServer(http.FS(frontend))))
+	s.router.HandleFunc("/assets/{path:.+}", s.handleGetAsset(fileServer)).Methods("GET", "HEAD")
+	s.router.PathPrefix("/presenter").Handler(s.requirePresenterAuthMiddleware(fileServer))
+	s.router.PathPrefix("/editor").Handler(s.requirePresenterAuthMiddleware(fileServer))
+	s.router.PathPrefix("/").Handler(fileServer)
+}
+
+// registerAPIRoutes adds every API endpoint to the given subrouter.
+func (s *Server) registerAPIRoutes(api *mux.Router) {
 	// no auth
 	api.HandleFunc("/config", s.handleGetConfig).Methods("GET")
+	api.HandleFunc("/join", s.handleJoin).Methods("POST")
+	api.HandleFunc("/info", s.handleGetInfo).Methods("GET")
+	api.HandleFunc("/state", s.handleGetState).Methods("GET")
 	api.HandleFunc("/chapter/current", s.handleGetCurrentChapter).Methods("GET")
 	api.HandleFunc("/chapter/{id}", s.handleGetChapter).Methods("GET")
 	api.HandleFunc("/results/{questionId}", s.handleGetResults).Methods("GET")
+	api.HandleFunc("/leaderboard", s.handleGetLeaderboard).Methods("GET")
+	api.HandleFunc("/overlay", s.handleGetOverlay).Methods("GET")
+	api.HandleFunc("/certificate/{voterId}", s.handleGetCertificate).Methods("GET")
+
+	// integrations, authenticated by their own request signatures
+	api.HandleFunc("/integrations/slack/actions", s.handleSlackActions).Methods("POST")
+	api.HandleFunc("/integrations/sms", s.handleSMS).Methods("POST")
+
+	// presenter tokens, exchanged for the secret
+	api.HandleFunc("/login", s.handleLogin).Methods("POST")
+	api.HandleFunc("/login/refresh", s.handleRefreshToken).Methods("POST")
 
 	// editor (auth-gated)
 	api.HandleFunc("/story/graph", s.requirePresenterAuth(s.handleGetStoryGraph)).Methods("GET")
+	api.HandleFunc("/story/heatmap", s.requirePresenterAuth(s.handleGetStoryHeatmap)).Methods("GET")
+	api.HandleFunc("/story/outline", s.requirePresenterAuth(s.handleGetStoryOutline)).Methods("GET")
+	api.HandleFunc("/endings", s.requirePresenterAuth(s.handleGetEndings)).Methods("GET")
+	api.HandleFunc("/chapters", s.requirePresenterAuth(s.handleListChapters)).Methods("GET")
+	api.HandleFunc("/chapter/{id}/preview", s.requirePresenterAuth(s.handleGetChapterPreview)).Methods("GET")
 	api.HandleFunc("/author/chapter", s.requirePresenterAuth(s.handleAuthorSaveChapter)).Methods("POST")
 
 	// with auth
 	api.HandleFunc("/start-voting", s.requirePresenterAuth(s.handleStartVoting)).Methods("POST")
 	api.HandleFunc("/advance", s.requirePresenterAuth(s.handleAdvance)).Methods("POST")
+	api.HandleFunc("/roll", s.requirePresenterAuth(s.handleRoll)).Methods("POST")
 	api.HandleFunc("/restart", s.requirePresenterAuth(s.handleRestart)).Methods("POST")
 	api.HandleFunc("/restart-voting", s.requirePresenterAuth(s.handleRestartVoting)).Methods("POST")
+	api.HandleFunc("/override-winner", s.requirePresenterAuth(s.handleOverrideWinner)).Methods("POST")
 	api.HandleFunc("/go-back", s.requirePresenterAuth(s.handleGoBack)).Methods("POST")
+	api.HandleFunc("/mode", s.requirePresenterAuth(s.handleGetMode)).Methods("GET")
+	api.HandleFunc("/mode", s.requirePresenterAuth(s.handleSetMode)).Methods("POST")
+	api.HandleFunc("/go-forward", s.requirePresenterAuth(s.handleGoForward)).Methods("POST")
+	api.HandleFunc("/go-back-to-checkpoint", s.requirePresenterAuth(s.handleGoBackToCheckpoint)).Methods("POST")
+	api.HandleFunc("/admin/clients", s.requirePresenterAuth(s.handleListClients)).Methods("GET")
+	api.HandleFunc("/admin/clients/{id}", s.requirePresenterAuth(s.handleDisconnectClient)).Methods("DELETE")
+	api.HandleFunc("/suggestions", s.requirePresenterAuth(s.handleListSuggestions)).Methods("GET")
+	api.HandleFunc("/suggestions/{id}/approve", s.requirePresenterAuth(s.handleApproveSuggestion)).Methods("POST")
+	api.HandleFunc("/suggestions/{id}/dismiss", s.requirePresenterAuth(s.handleDismissSuggestion)).Methods("POST")
+	api.HandleFunc("/polls", s.requirePresenterAuth(s.handleListPolls)).Methods("GET")
+	api.HandleFunc("/polls", s.requirePresenterAuth(s.handleOpenPoll)).Methods("POST")
+	api.HandleFunc("/polls/{id}/close", s.requirePresenterAuth(s.handleClosePoll)).Methods("POST")
+	api.HandleFunc("/admin/roster", s.requirePresenterAuth(s.handleGetRoster)).Methods("GET")
+	api.HandleFunc("/admin/join-code", s.requirePresenterAuth(s.handleGetJoinCode)).Methods("GET")
+	api.HandleFunc("/admin/join-code/rotate", s.requirePresenterAuth(s.handleRotateJoinCode)).Methods("POST")
+	api.HandleFunc("/stories", s.requirePresenterAuth(s.handleListStories)).Methods("GET")
+	api.HandleFunc("/stories/{id}/activate", s.requirePresenterAuth(s.handleActivateStory)).Methods("POST")
+	api.HandleFunc("/admin/dashboard", s.requirePresenterAuth(s.handleGetDashboard)).Methods("GET")
+	api.HandleFunc("/analytics", s.requirePresenterAuth(s.handleGetAnalytics)).Methods("GET")
+	api.HandleFunc("/report", s.requirePresenterAuth(s.handleReport)).Methods("POST")
+	api.HandleFunc("/rooms", s.requirePresenterAuth(s.handleListRooms)).Methods("GET")
+	api.HandleFunc("/rooms", s.requirePresenterAuth(s.handleOpenRoom)).Methods("POST")
+	api.HandleFunc("/rooms/{code}", s.requirePresenterAuth(s.handleCloseRoom)).Methods("DELETE")
+
+	// one-press controls for Stream Deck and Companion buttons: no body, GET or POST
+	api.HandleFunc("/control/next", s.requireControlAuth(s.handleControlNext)).Methods("GET", "POST")
+	api.HandleFunc("/control/back", s.requireControlAuth(s.handleGoBack)).Methods("GET", "POST")
+	api.HandleFunc("/control/start-vote", s.requireControlAuth(s.handleControlStartVote)).Methods("GET", "POST")
+	api.HandleFunc("/control/end-vote", s.requireControlAuth(s.handleControlEndVote)).Methods("GET", "POST")
+	api.HandleFunc("/control/restart", s.requireControlAuth(s.handleRestart)).Methods("GET", "POST")
+}
 
-	s.router.HandleFunc("/ws", s.handleWebSocket)
+// authorizationCredential returns the secret of the Authorization header,
+// either the Basic Auth password or a Bearer token.
+func authorizationCredential(r *http.Request) string {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
 
-	fileServer := http.FileServer(http.FS(s.staticFS))
-	s.router.PathPrefix("/presenter").Handler(s.requirePresenterAuthMiddleware(fileServer))
-	s.router.PathPrefix("/editor").Handler(s.requirePresenterAuthMiddleware(fileServer))
-	s.router.PathPrefix("/").Handler(fileServer)
+	authHeader := r.Header.Get("Authorization")
+
+	const prefix = "Bearer "
+	if len(authHeader) >= len(prefix) && authHeader[:len(prefix)] == prefix {
+		return authHeader[len(prefix):]
+	}
+
+	return ""
+}
+
+// presenterCredential returns the secret a request carries, either as the
+// Basic Auth password, as a Bearer token or as a session cookie, in rooms
+// that of the room before that of the main server.
+func (s *Server) presenterCredential(r *http.Request) string {
+	if credential := authorizationCredential(r); credential != "" {
+		return credential
+	}
+
+	if _, session, ok := s.session(r); ok {
+		return session
+	}
+
+	return ""
+}
+
+// authRequired reports whether presenters have to authenticate, with a
+// secret or through an OpenID Connect provider.
+func (s *Server) authRequired() bool {
+	return s.presenterSecret != "" || s.oidc != nil
+}
+
+// isPresenterSecret reports whether a secret or presenter token grants
+// presenter access, full or read-only.
+func (s *Server) isPresenterSecret(secret string) bool {
+	return s.credentialRole(secret) != ""
+}
+
+// isPresenter reports whether the request carries valid presenter or
+// co-presenter credentials, either as Basic Auth, as a Bearer secret or
+// token from /login or as a session cookie. Always true when auth is
+// disabled.
+func (s *Server) isPresenter(r *http.Request) bool {
+	if !s.fromPresenterNetwork(r) {
+		return false
+	}
+
+	if !s.authRequired() {
+		return true
+	}
+
+	return s.isPresenterSecret(s.presenterCredential(r))
+}
+
+// canControl reports whether the request may change the state of the show,
+// which co-presenters may not.
+func (s *Server) canControl(r *http.Request) bool {
+	if !s.fromPresenterNetwork(r) {
+		return false
+	}
+
+	return !s.authRequired() || s.credentialRole(s.presenterCredential(r)) == RolePresenter
 }
 
 // requirePresenterAuth is a simple middleware for presenter authentication.
-// Accepts both Bearer token and Basic Auth.
+// Accepts both Bearer token and Basic Auth. Co-presenters only get through
+// with GET and HEAD requests, and changes made with the session cookie need
+// its CSRF token.
 func (s *Server) requirePresenterAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// skip if there is no secret defined
-		if s.presenterSecret == "" {
-			next(w, r)
+		if s.outsidePresenterNetworks(w, r) || s.lockedOut(w, r) {
+			return
+		}
+
+		ok := s.isPresenter(r)
+		s.recordLogin(r, s.presenterCredential(r), ok)
+
+		if !ok {
+			s.challenge(w, r)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
 
 			return
 		}
 
-		_, password, ok := r.BasicAuth()
-		if ok && password == s.presenterSecret {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
 			next(w, r)
 
 			return
 		}
 
-		authHeader := r.Header.Get("Authorization")
+		if !s.canControl(r) {
+			http.Error(w, "co-presenters have read-only access", http.StatusForbidden)
 
-		const prefix = "Bearer "
-		if len(authHeader) >= len(prefix) && authHeader[:len(prefix)] == prefix {
-			token := authHeader[len(prefix):]
-			if token == s.presenterSecret {
-				next(w, r)
+			return
+		}
 
-				return
-			}
+		if s.forgedRequest(w, r) {
+			return
 		}
 
+		next(w, r)
+	}
+}
+
+// challenge asks browsers for the presenter secret with their Basic Auth
+// prompt. Presenters logging in with OpenID Connect only don't get one, nor
+// do browsers whose session ran out: they go back to the login form.
+func (s *Server) challenge(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := s.session(r); ok {
+		return
+	}
+
+	if s.presenterSecret != "" {
 		w.Header().Set("WWW-Authenticate", `Basic realm="Presenter Access"`)
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
 	}
 }
 
 // requirePresenterAuthMiddleware wraps an http.Handler with authentication.
-// Uses HTTP Basic Auth for browser compatibility (triggers password popup).
+// Browsers without a session are sent to the login form when there is a
+// presenter secret, and to the OpenID Connect provider otherwise.
 func (s *Server) requirePresenterAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// skip if there is no secret defined
-		if s.presenterSecret == "" {
+		if s.outsidePresenterNetworks(w, r) || s.lockedOut(w, r) {
+			return
+		}
+
+		ok := s.isPresenter(r)
+		s.recordLogin(r, s.presenterCredential(r), ok)
+
+		if ok {
 			next.ServeHTTP(w, r)
 
 			return
 		}
 
-		_, password, ok := r.BasicAuth()
-		if !ok || password != s.presenterSecret {
-			// this will trigger the password prompt on the presenter screen
-			w.Header().Set("WWW-Authenticate", `Basic realm="Presenter Access"`)
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			login := s.publicPath(loginPagePath)
+			if s.presenterSecret == "" {
+				login = s.basePath + "/auth/oidc/login"
+			}
+
+			// the URI as requested, which differs from r.URL in rooms
+			next := cmp.Or(r.RequestURI, r.URL.RequestURI())
+			http.Redirect(w, r, login+"?"+url.Values{"next": {next}}.Encode(), http.StatusFound)
 
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		s.challenge(w, r)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 	})
 }
 
-// handleGetConfig returns runtime configuration consumed by the frontend,
-// currently the public voter URL used for QR codes.
+// handleGetConfig returns runtime configuration consumed by the frontend:
+// the public voter URL used for QR codes, the enabled feature flags and
+// whether the show is being rehearsed.
 func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	rehearsal := s.rehearsal.Enabled
+	s.mu.RUnlock()
+
 	w.Header().Set("Content-Type", "application/json")
 
 	if err := json.NewEncoder(w).Encode(map[string]any{
 		"voter_url": s.effectiveVoterURL(r),
+		"features":  s.features.List(),
+		"roster":    s.roster != nil,
+		"join_code": s.joinCodes != nil,
+		"oidc":      s.oidc != nil,
+		"rehearsal": rehearsal,
+		"reactions": reactionEmojis,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleGetState returns the current story variables.
+func (s *Server) handleGetState(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	vars := s.vars.Clone()
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"variables": vars,
 	}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -180,12 +468,18 @@ func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
 }
 
 // effectiveVoterURL returns the configured voter URL, or one derived from the
-// request, honoring X-Forwarded-Proto / X-Forwarded-Host when behind a proxy.
+// request.
 func (s *Server) effectiveVoterURL(r *http.Request) string {
 	if s.voterURL != "" {
 		return s.voterURL
 	}
 
+	return requestOrigin(r) + s.publicPath("/voter/")
+}
+
+// requestOrigin returns the scheme and host the client reached the server
+// at, honoring X-Forwarded-Proto / X-Forwarded-Host when behind a proxy.
+func requestOrigin(r *http.Request) string {
 	scheme := "http"
 	if r.TLS != nil {
 		scheme = "https"
@@ -200,12 +494,19 @@ func (s *Server) effectiveVoterURL(r *http.Request) string {
 		host = h
 	}
 
-	return fmt.Sprintf("%s://%s/voter/", scheme, host)
+	return scheme + "://" + host
+}
+
+// isHTTPS reports whether the client reached the server over HTTPS.
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
 }
 
 // handleGetStoryGraph returns every chapter as a flat array suitable for the editor canvas.
 func (s *Server) handleGetStoryGraph(w http.ResponseWriter, r *http.Request) {
-	chapters, err := s.storyEngine.AllChapters()
+	engine := s.engine()
+
+	chapters, err := engine.AllChapters()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -220,6 +521,28 @@ func (s *Server) handleGetStoryGraph(w http.ResponseWriter, r *http.Request) {
 		Question string          `json:"question,omitempty"`
 		Timer    int             `json:"timer,omitempty"`
 		Choices  []parser.Choice `json:"choices,omitempty"`
+
+		RequiresFeature string             `json:"requires_feature,omitempty"`
+		Conditions      []parser.Condition `json:"conditions,omitempty"`
+		Set             parser.Assignments `json:"set,omitempty"`
+		Grants          []string           `json:"grants,omitempty"`
+		Consumes        []string           `json:"consumes,omitempty"`
+		Outcomes        []parser.Outcome   `json:"outcomes,omitempty"`
+		TimerMode       string             `json:"timer_mode,omitempty"`
+		TimerMin        int                `json:"timer_min,omitempty"`
+		TimerMax        int                `json:"timer_max,omitempty"`
+		Dice            string             `json:"dice,omitempty"`
+		Threshold       int                `json:"threshold,omitempty"`
+		Modifier        string             `json:"modifier,omitempty"`
+		Success         string             `json:"success,omitempty"`
+		Failure         string             `json:"failure,omitempty"`
+		Certificate     string             `json:"certificate,omitempty"`
+		Includes        []string           `json:"includes,omitempty"`
+		Literal         bool               `json:"literal,omitempty"`
+		Checkpoint      bool               `json:"checkpoint,omitempty"`
+		Tags            []string           `json:"tags,omitempty"`
+		Notes           string             `json:"notes,omitempty"`
+		Extra           map[string]any     `json:"extra,omitempty"`
 	}
 
 	out := make([]graphChapter, 0, len(chapters))
@@ -233,13 +556,35 @@ func (s *Server) handleGetStoryGraph(w http.ResponseWriter, r *http.Request) {
 			Question: chapter.Metadata.Question,
 			Timer:    chapter.Metadata.Timer,
 			Choices:  chapter.Metadata.Choices,
+
+			RequiresFeature: chapter.Metadata.RequiresFeature,
+			Conditions:      chapter.Metadata.Conditions,
+			Set:             chapter.Metadata.Set,
+			Grants:          chapter.Metadata.Grants,
+			Consumes:        chapter.Metadata.Consumes,
+			Outcomes:        chapter.Metadata.Outcomes,
+			TimerMode:       chapter.Metadata.TimerMode,
+			TimerMin:        chapter.Metadata.TimerMin,
+			TimerMax:        chapter.Metadata.TimerMax,
+			Dice:            chapter.Metadata.Dice,
+			Threshold:       chapter.Metadata.Threshold,
+			Modifier:        chapter.Metadata.Modifier,
+			Success:         chapter.Metadata.Success,
+			Failure:         chapter.Metadata.Failure,
+			Certificate:     chapter.Metadata.Certificate,
+			Includes:        chapter.Metadata.Includes,
+			Literal:         chapter.Metadata.Literal,
+			Checkpoint:      chapter.Metadata.Checkpoint,
+			Tags:            chapter.Metadata.Tags,
+			Notes:           chapter.Metadata.Notes,
+			Extra:           chapter.Metadata.Extra,
 		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 
 	if err := json.NewEncoder(w).Encode(map[string]any{
-		"start":    s.storyEngine.Story.Flow.Start,
+		"start":    engine.Story.Flow.Start,
 		"chapters": out,
 	}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -248,6 +593,22 @@ func (s *Server) handleGetStoryGraph(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleGetStoryHeatmap reports how often each chapter, branch and ending has
+// been reached across all recorded sessions, including the one in progress.
+func (s *Server) handleGetStoryHeatmap(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	chapterIDs := slices.Collect(maps.Keys(s.storyEngine.Story.Nodes))
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(s.sessions.Heatmap(chapterIDs)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
 // handleAuthorSaveChapter writes a single chapter to disk and reloads the story engine.
 // Requires the server to have been started with -author.
 func (s *Server) handleAuthorSaveChapter(w http.ResponseWriter, r *http.Request) {
@@ -266,6 +627,28 @@ func (s *Server) handleAuthorSaveChapter(w http.ResponseWriter, r *http.Request)
 		Timer    int             `json:"timer"`
 		Choices  []parser.Choice `json:"choices"`
 		RawMD    string          `json:"raw_md"`
+
+		RequiresFeature string             `json:"requires_feature"`
+		Conditions      []parser.Condition `json:"conditions"`
+		Set             parser.Assignments `json:"set"`
+		Grants          []string           `json:"grants"`
+		Consumes        []string           `json:"consumes"`
+		Outcomes        []parser.Outcome   `json:"outcomes"`
+		TimerMode       string             `json:"timer_mode"`
+		TimerMin        int                `json:"timer_min"`
+		TimerMax        int                `json:"timer_max"`
+		Dice            string             `json:"dice"`
+		Threshold       int                `json:"threshold"`
+		Modifier        string             `json:"modifier"`
+		Success         string             `json:"success"`
+		Failure         string             `json:"failure"`
+		Certificate     string             `json:"certificate"`
+		Includes        []string           `json:"includes"`
+		Literal         bool               `json:"literal"`
+		Checkpoint      bool               `json:"checkpoint"`
+		Tags            []string           `json:"tags"`
+		Notes           string             `json:"notes"`
+		Extra           map[string]any     `json:"extra"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil { //nolint:musttag // ignore
@@ -289,6 +672,28 @@ func (s *Server) handleAuthorSaveChapter(w http.ResponseWriter, r *http.Request)
 		Question: req.Question,
 		Timer:    req.Timer,
 		Choices:  req.Choices,
+
+		RequiresFeature: req.RequiresFeature,
+		Conditions:      req.Conditions,
+		Set:             req.Set,
+		Grants:          req.Grants,
+		Consumes:        req.Consumes,
+		Outcomes:        req.Outcomes,
+		TimerMode:       req.TimerMode,
+		TimerMin:        req.TimerMin,
+		TimerMax:        req.TimerMax,
+		Dice:            req.Dice,
+		Threshold:       req.Threshold,
+		Modifier:        req.Modifier,
+		Success:         req.Success,
+		Failure:         req.Failure,
+		Certificate:     req.Certificate,
+		Includes:        req.Includes,
+		Literal:         req.Literal,
+		Checkpoint:      req.Checkpoint,
+		Tags:            req.Tags,
+		Notes:           req.Notes,
+		Extra:           req.Extra,
 	}
 
 	content, err := buildChapterFile(meta, req.RawMD)
@@ -332,10 +737,11 @@ func (s *Server) chapterFilePath(id string) (string, error) {
 		return "", fmt.Errorf("invalid chapter id %q (lowercase, digits, hyphens only)", id)
 	}
 
-	contentDir := s.storyEngine.ContentDir
+	engine := s.engine()
+	contentDir := engine.ContentDir
 
 	filename := id + ".md"
-	if node, ok := s.storyEngine.Story.Nodes[id]; ok && node.File != "" {
+	if node, ok := engine.Story.Nodes[id]; ok && node.File != "" {
 		filename = node.File
 	}
 
@@ -374,10 +780,28 @@ func buildChapterFile(meta parser.ChapterMetadata, body string) ([]byte, error)
 	return buf.Bytes(), nil
 }
 
+// newStoryEngine loads a story with the server's rendering options.
+func (s *Server) newStoryEngine(storyPath, contentDir string) (*parser.StoryEngine, error) {
+	return parser.NewStoryEngine(storyPath, contentDir, s.engineOptions...)
+}
+
+// engine returns the story engine in use. Code that does not hold s.mu reads
+// it through engine, as a reload may swap it at any time.
+func (s *Server) engine() *parser.StoryEngine {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.storyEngine
+}
+
 // reloadStoryEngine rebuilds the engine from disk after a write so subsequent
 // reads see the new chapter set. Holds the server lock to keep readers consistent.
 func (s *Server) reloadStoryEngine() error {
-	engine, err := parser.NewStoryEngine(s.storyPath, s.storyEngine.ContentDir)
+	s.mu.RLock()
+	storyPath, contentDir := s.storyPath, s.storyEngine.ContentDir
+	s.mu.RUnlock()
+
+	engine, err := s.newStoryEngine(storyPath, contentDir)
 	if err != nil {
 		return err
 	}
@@ -389,57 +813,83 @@ func (s *Server) reloadStoryEngine() error {
 	return nil
 }
 
-// handleGetChapter returns a specific chapter by ID.
+// handleGetChapter returns a specific chapter by ID, in the language of the
+// lang query parameter when it has a translation. Chapters behind disabled
+// feature flags are hidden, except from presenters in author mode.
 func (s *Server) handleGetChapter(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	chapterID := vars["id"]
 
-	chapter, err := s.storyEngine.GetChapter(chapterID)
+	load := func(id string) (*parser.Chapter, error) {
+		return s.localizedChapter(id, r.URL.Query().Get("lang"))
+	}
+
+	// authors editing the story must see chapters regardless of feature flags
+	if s.authorMode && s.isPresenter(r) {
+		load = func(id string) (*parser.Chapter, error) {
+			return s.storyEngine.GetChapter(id)
+		}
+	}
+
+	s.mu.RLock()
+	chapter, err := load(chapterID)
+	s.mu.RUnlock()
+
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	if err := json.NewEncoder(w).Encode(map[string]any{
+	response := map[string]any{
 		"id":       chapterID,
 		"metadata": chapter.Metadata,
 		"content":  chapter.Content,
 		"raw_md":   chapter.RawMD,
-	}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		"lang":     chapter.Lang,
+	}
 
-		return
+	if s.wantsNotes(r) {
+		response["notes"] = chapter.Notes
 	}
+
+	writeCachedJSON(w, r, response)
 }
 
-// handleGetCurrentChapter returns the current chapter.
+// handleGetCurrentChapter returns the current chapter, in the language of the
+// lang query parameter when it has a translation.
 func (s *Server) handleGetCurrentChapter(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	currentNode := s.currentNode
+	state := s.vars.Clone()
+	progress := s.progress(currentNode)
+	chapter, err := s.localizedChapter(currentNode, r.URL.Query().Get("lang"))
+
+	if err == nil {
+		chapter = s.present(chapter, state)
+	}
 	s.mu.RUnlock()
 
-	chapter, err := s.storyEngine.GetChapter(currentNode)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	if err := json.NewEncoder(w).Encode(map[string]any{
+	response := map[string]any{
 		"id":       currentNode,
 		"metadata": chapter.Metadata,
 		"content":  chapter.Content,
 		"raw_md":   chapter.RawMD,
-	}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		"lang":     chapter.Lang,
+		"progress": progress,
+	}
 
-		return
+	if s.wantsNotes(r) {
+		response["notes"] = chapter.Notes
 	}
+
+	writeCachedJSON(w, r, response)
 }
 
 // handleStartVoting starts a new voting session.
@@ -456,23 +906,12 @@ func (s *Server) handleStartVoting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.mu.RLock()
-	currentNode := s.currentNode
-	s.mu.RUnlock()
-
-	chapter, err := s.storyEngine.GetChapter(currentNode)
-	if err != nil {
+	if err := s.startVoting(requestLogger(r), req.QuestionID, req.Choices, time.Duration(req.Duration)*time.Second); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	duration := time.Duration(req.Duration) * time.Second
-
-	s.voteManager.StartVotingWithChoices(req.QuestionID, req.Choices, chapter.Metadata.Choices, chapter.Metadata.Question, duration, func(results map[string]int, winner string) {
-		log.Printf("Voting complete. Winner: %s, Results: %v", winner, results)
-	})
-
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(map[string]any{
@@ -484,10 +923,99 @@ func (s *Server) handleStartVoting(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// startVoting starts a vote on the current chapter between choiceIDs,
+// leaving out locked ones, paced and scaled the way the chapter and the mode
+// ask for.
+func (s *Server) startVoting(logger *slog.Logger, questionID string, choiceIDs []string, duration time.Duration) error {
+	s.mu.RLock()
+	currentNode := s.currentNode
+	state := s.vars.Clone()
+	rehearsal := s.rehearsal
+	chapter, err := s.chapter(currentNode)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	// locked choices stay visible to voters but cannot be voted for
+	choiceIDs = slices.DeleteFunc(choiceIDs, func(id string) bool {
+		return isLocked(chapter, state, id)
+	})
+	chapter = withInventory(chapter, state)
+
+	// choices struck by vetoes are gone for good
+	if struck := s.voteManager.Struck(questionID); len(struck) > 0 {
+		choiceIDs = slices.DeleteFunc(choiceIDs, func(id string) bool {
+			return slices.Contains(struck, id)
+		})
+		chapter = withoutChoices(chapter, struck)
+	}
+
+	logger = logger.With("chapter_id", currentNode, "question_id", questionID)
+
+	adaptive := chapter.Metadata.IsAdaptiveTimer()
+	pacing := newAdaptiveTimer(chapter.Metadata)
+
+	if adaptive {
+		duration = pacing.clamp(duration)
+	}
+
+	// rehearsals run through votes faster
+	duration = rehearsal.scale(duration)
+	pacing = pacing.scaled(rehearsal)
+
+	logger.Info("Voting started", "duration", duration, "adaptive", adaptive, "choices", len(choiceIDs))
+
+	s.mu.RLock()
+	translations := s.questionTranslations(currentNode, state)
+	s.mu.RUnlock()
+
+	s.voteManager.StartLocalizedVoting(questionID, choiceIDs, withPreviewURLs(s.basePath, chapter.Metadata.Choices), chapter.Metadata.Question, translations, duration, func(results map[string]int, winner string) {
+		voters := 0
+		for _, count := range results {
+			voters += count
+		}
+
+		logger.Info("Voting complete", "winner", winner, "results", results, "voters", voters)
+
+		if stats, ok := s.voteManager.LastStats(questionID); ok {
+			s.sessions.Vote(currentNode, stats)
+		}
+	})
+
+	if adaptive {
+		s.voteManager.PaceVoting(pacing)
+	}
+
+	if !chapter.Metadata.ChangesAllowed() {
+		s.voteManager.LockBallots()
+	}
+
+	go s.simulateVotes(questionID, choiceIDs, duration)
+
+	return nil
+}
+
+// withPreviewURLs returns a copy of choices with preview paths rewritten to
+// the URLs they are served from under basePath.
+func withPreviewURLs(basePath string, choices []parser.Choice) []parser.Choice {
+	out := slices.Clone(choices)
+
+	for i := range out {
+		if out[i].Preview != "" {
+			out[i].Preview = (&url.URL{Path: basePath + "/assets/" + out[i].Preview}).EscapedPath()
+		}
+	}
+
+	return out
+}
+
 // handleAdvance advances to the next chapter based on choice.
 func (s *Server) handleAdvance(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		ChoiceID string `json:"choice_id"`
+		ChoiceID string  `json:"choice_id"`
+		Seed     *uint64 `json:"seed"` // replays a roll when advancing from a random chapter
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -496,48 +1024,106 @@ func (s *Server) handleAdvance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response, err := s.advance(requestLogger(r), req.ChoiceID, req.Seed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// advance moves to the chapter the choice leads to, or for chapters without
+// a vote to the next one, rolling random and dice chapters. seed replays a
+// roll. It returns the chapter payload for the presenter.
+func (s *Server) advance(logger *slog.Logger, choiceID string, seed *uint64) (map[string]any, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.history = append(s.history, s.currentNode)
-
 	var (
 		nextChapter *parser.Chapter
+		roll        *parser.Roll
+		dice        *parser.DiceRoll
 		err         error
 	)
 
-	if req.ChoiceID != "" {
-		nextChapter, err = s.storyEngine.GetChapterByChoice(s.currentNode, req.ChoiceID)
-	} else {
-		nextChapter, err = s.storyEngine.GetNextChapter(s.currentNode)
+	switch {
+	case choiceID != "":
+		nextChapter, err = s.storyEngine.GetChapterByChoice(s.currentNode, choiceID)
+	case s.isRandomChapter(s.currentNode):
+		nextChapter, roll, err = s.rollNext(seed)
+	case s.isRollChapter(s.currentNode):
+		dice = s.diceRoll
+		if dice == nil {
+			dice, err = s.rollDice(seed)
+			if err == nil {
+				s.broadcastRollResult(s.currentNode, dice)
+			}
+		}
+
+		if err == nil {
+			nextChapter, err = s.storyEngine.GetChapter(dice.Next)
+		}
+	default:
+		nextChapter, err = s.storyEngine.NextChapter(s.currentNode, s.vars)
+	}
+
+	if err == nil {
+		nextChapter, err = s.chapter(nextChapter.Metadata.ID)
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, err
+	}
 
-		return
+	var rolled map[string]any
+
+	if roll != nil {
+		// the outcome takes the place of a choice in session statistics
+		choiceID = roll.Outcome.ID
+		rolled = rollPayload(s.currentNode, roll)
+
+		logger.Info("Random outcome", "chapter_id", s.currentNode, "outcome", roll.Outcome.ID, "seed", roll.Seed, "roll", roll.Roll, "total", roll.Total)
+		s.voteManager.BroadcastMessage("random_outcome", rolled)
 	}
 
-	s.currentNode = nextChapter.Metadata.ID
-	s.voteManager.BroadcastMessage("chapter_changed", map[string]any{
-		"id":          s.currentNode,
-		"metadata":    nextChapter.Metadata,
-		"content":     nextChapter.Content,
-		"can_go_back": len(s.history) > 0,
-	})
+	if dice != nil {
+		// the branch taken takes the place of a choice in session statistics
+		choiceID = "failure"
+		if dice.Success {
+			choiceID = "success"
+		}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
+	logger.Info("Chapter changed", "from", s.currentNode, "chapter_id", nextChapter.Metadata.ID, "choice_id", choiceID)
 
-	if err := json.NewEncoder(w).Encode(map[string]any{
-		"id":          s.currentNode,
-		"metadata":    nextChapter.Metadata,
-		"content":     nextChapter.Content,
-		"can_go_back": len(s.history) > 0,
-	}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// taking a new way forgets the chapters that could have been redone
+	s.forward = nil
+	nextChapter = s.moveTo(nextChapter, choiceID)
 
-		return
+	response := s.withNavigation(map[string]any{
+		"id":       s.currentNode,
+		"metadata": nextChapter.Metadata,
+		"content":  nextChapter.Content,
+		"notes":    nextChapter.Notes,
+	})
+
+	if rolled != nil {
+		response["roll"] = rolled
 	}
+
+	if dice != nil {
+		response["dice"] = dice
+	}
+
+	return response, nil
 }
 
 // handleRestart restarts the entire story from the beginning.
@@ -545,23 +1131,14 @@ func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.currentNode = s.storyEngine.Story.Flow.Start
-	s.history = []string{}
-
-	chapter, err := s.storyEngine.GetChapter(s.currentNode)
+	chapter, err := s.restartStory()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	// THIS IS IMPORTANT! Reset the voting state when the story restarts. This should also be done when going back.
-	s.voteManager.ResetVoting()
-	s.voteManager.BroadcastMessage("story_restarted", map[string]any{
-		"id":       s.currentNode,
-		"metadata": chapter.Metadata,
-		"content":  chapter.Content,
-	})
+	requestLogger(r).Info("Story restarted", "chapter_id", s.currentNode)
 
 	w.Header().Set("Content-Type", "application/json")
 
@@ -569,6 +1146,7 @@ func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
 		"id":       s.currentNode,
 		"metadata": chapter.Metadata,
 		"content":  chapter.Content,
+		"notes":    chapter.Notes,
 	}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -576,13 +1154,52 @@ func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// restartStory moves back to the start chapter, clears all story and voting
+// state and tells every client. Callers must hold s.mu.
+func (s *Server) restartStory() (*parser.Chapter, error) {
+	s.sessions.Finish("")
+
+	s.currentNode = s.storyEngine.Story.Flow.Start
+	s.diceRoll = nil
+	s.history = []string{}
+	s.forward = nil
+	score := s.vars.Score()
+	s.vars = parser.State{}
+	s.varsHistory = nil
+	s.sessions.Begin(s.currentNode)
+
+	chapter, err := s.chapter(s.currentNode)
+	if err != nil {
+		return nil, err
+	}
+
+	s.vars.Enter(chapter.Metadata)
+
+	// THIS IS IMPORTANT! Reset the voting state when the story restarts. This should also be done when going back.
+	s.voteManager.ResetVoting()
+	s.voteManager.ResetQuizAnswers()
+	s.voteManager.ResetBallotHistory()
+	s.voteManager.ResetVetoes()
+
+	chapter = s.present(chapter, s.vars)
+	s.broadcastChapter("story_restarted", s.vars, map[string]any{
+		"id":       s.currentNode,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+	})
+	s.broadcastInventory()
+	s.broadcastScore(score, 0)
+
+	return chapter, nil
+}
+
 // handleRestartVoting restarts the current voting session.
 func (s *Server) handleRestartVoting(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	currentNode := s.currentNode
+	chapter, err := s.chapter(currentNode)
 	s.mu.RUnlock()
 
-	chapter, err := s.storyEngine.GetChapter(currentNode)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -619,38 +1236,25 @@ func (s *Server) handleGoBack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentChapterID := s.currentNode
-	previousNode := s.history[len(s.history)-1]
-	s.history = s.history[:len(s.history)-1]
+	from := s.currentNode
 
-	// prev chapter
-	chapter, err := s.storyEngine.GetChapter(previousNode)
+	chapter, err := s.rewind(1)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	s.currentNode = previousNode
-	// clear for current question only
-	s.voteManager.ClearQuestionVotes(currentChapterID)
-
-	// inform all clients about the chapter change
-	s.voteManager.BroadcastMessage("chapter_changed", map[string]any{
-		"id":          s.currentNode,
-		"metadata":    chapter.Metadata,
-		"content":     chapter.Content,
-		"can_go_back": len(s.history) > 0,
-	})
+	requestLogger(r).Info("Went back", "from", from, "chapter_id", s.currentNode)
 
 	w.Header().Set("Content-Type", "application/json")
 
-	if err := json.NewEncoder(w).Encode(map[string]any{
-		"id":          s.currentNode,
-		"metadata":    chapter.Metadata,
-		"content":     chapter.Content,
-		"can_go_back": len(s.history) > 0,
-	}); err != nil {
+	if err := json.NewEncoder(w).Encode(s.withNavigation(map[string]any{
+		"id":       s.currentNode,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+		"notes":    chapter.Notes,
+	})); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -664,64 +1268,309 @@ func (s *Server) handleGetResults(w http.ResponseWriter, r *http.Request) {
 
 	results := s.voteManager.GetResults(questionID)
 
-	w.Header().Set("Content-Type", "application/json")
-
-	if err := json.NewEncoder(w).Encode(map[string]any{
+	response := map[string]any{
 		"question_id": questionID,
 		"results":     results,
-	}); err != nil {
+	}
+
+	if override := s.voteManager.Override(questionID); override != "" {
+		response["override"] = override
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 }
 
-// handleWebSocket handles WebSocket connections.
+// handleWebSocket handles WebSocket connections. The role of a connection is
+// settled by the handshake and limits what it may send, see clientMessages:
+// voters connect without a role, or with the voting token from /api/join as
+// ?token= when join codes are on; presenter screens connect with
+// ?role=presenter and presenter credentials (Basic Auth, a Bearer secret or
+// token, the session cookie, or the secret or a token from /api/login in the
+// token query parameter for browsers that cannot set headers on WebSocket
+// requests); spectators connect with ?role=spectator.
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	role := RoleVoter
+
+	switch r.URL.Query().Get("role") {
+	case "", RoleVoter:
+	case RolePresenter:
+		if s.outsidePresenterNetworks(w, r) || s.lockedOut(w, r) {
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if !s.isPresenter(r) && !s.isPresenterSecret(token) {
+			s.recordLogin(r, cmp.Or(token, s.presenterCredential(r)), false)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		role = RolePresenter
+	case RoleSpectator:
+		role = RoleSpectator
+	default:
+		http.Error(w, "unknown role", http.StatusBadRequest)
+
+		return
+	}
+
+	var participantID, joinedAs string
+
+	if role == RoleVoter && s.joinCodes != nil {
+		id, ok := s.joinCodes.Verify(r.URL.Query().Get("token"))
+		if !ok {
+			http.Error(w, "join code required", http.StatusForbidden)
+
+			return
+		}
+
+		joinedAs = id
+	}
+
+	if role == RoleVoter && s.roster != nil {
+		if s.joinLockedOut(w, r) {
+			return
+		}
+
+		code := r.URL.Query().Get("code")
+		id, ok := s.roster.Join(code)
+		s.recordJoin(r, code, ok)
+
+		if !ok {
+			http.Error(w, "not on the participant roster", http.StatusForbidden)
+
+			return
+		}
+
+		participantID = id
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+		requestLogger(r).Error("Failed to upgrade connection", "error", err)
 
 		return
 	}
 
-	s.voteManager.RegisterClient(conn)
+	logger := requestLogger(r).With("remote", r.RemoteAddr, "role", role)
+	logger.Debug("WebSocket client connected")
+
+	client := NewClient(conn, role)
+	client.Lang = parser.NormalizeLang(r.URL.Query().Get("lang"))
+	if role == RoleVoter {
+		// a voter that reconnects mid-vote gets its ballot back in the state
+		client.voterID = r.URL.Query().Get("voter_id")
+	}
+	if joinedAs != "" {
+		client.voterID = joinedAs
+	}
+	if role == RolePresenter {
+		client.welcome = append(client.welcome, s.chatHistoryMessage())
+	}
+
+	s.mu.RLock()
+	if len(s.vars.Inventory()) > 0 {
+		client.welcome = append(client.welcome, &Message{Type: "inventory", Payload: inventoryPayload(s.vars)})
+	}
+	if _, ok := s.vars[parser.ScoreKey]; ok {
+		client.welcome = append(client.welcome, &Message{Type: "score", Payload: scorePayload(s.vars, 0)})
+	}
+	s.mu.RUnlock()
+
+	if participantID != "" {
+		client.participantID = participantID
+		client.voterID = participantID
+		client.welcome = append(client.welcome, &Message{
+			Type: "participant",
+			Payload: map[string]any{
+				"voter_id": participantID,
+				"name":     s.roster.Name(participantID),
+			},
+		})
+
+		logger.Info("Participant joined", "participant", participantID)
+	}
+
+	// the voter fetches its certificate with this token
+	assigned := joinedAs != "" || participantID != ""
+	if token, ok := s.certificates.Claim(client.voterID, assigned, r.URL.Query().Get("certificate_token")); ok {
+		client.welcome = append(client.welcome, &Message{
+			Type:    "certificate_token",
+			Payload: map[string]any{"token": token},
+		})
+	}
+
+	s.voteManager.RegisterClient(client)
+
+	if participantID != "" {
+		s.broadcastRoster()
+	}
 
 	// read messages from client
 	go func() {
 		defer func() {
 			s.voteManager.UnregisterClient(conn)
 			_ = conn.Close()
+
+			if participantID != "" {
+				s.roster.Leave(participantID)
+				s.broadcastRoster()
+			}
 		}()
 
 		for {
 			_, message, err := conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("WebSocket error: %v", err)
+					logger.Warn("WebSocket error", "error", err)
 				}
 
 				break
 			}
 
-			if err := s.voteManager.HandleVoteMessage(message); err != nil {
-				log.Printf("Error handling vote message: %v", err)
+			if err := s.handleClientMessage(client, message); err != nil {
+				logger.Warn("Error handling client message", "error", err)
 			}
 		}
 	}()
 }
 
-// Start starts the HTTP server.
-func (s *Server) Start(addr string) error {
-	log.Printf("Starting server on %s", addr)
-	log.Printf("Content directory: %s", filepath.Dir(s.storyEngine.ContentDir))
+// handleListClients returns every connected WebSocket client.
+func (s *Server) handleListClients(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"clients": s.voteManager.Clients(),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleDisconnectClient force-disconnects a single WebSocket client.
+func (s *Server) handleDisconnectClient(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if !s.voteManager.DisconnectClient(id) {
+		http.Error(w, "client not found", http.StatusNotFound)
+
+		return
+	}
+
+	requestLogger(r).Info("Client disconnected by presenter", "client_id", id)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status": "disconnected",
+		"id":     id,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleClientMessage dispatches an incoming WebSocket message by its type.
+func (s *Server) handleClientMessage(client *Client, data []byte) error {
+	var envelope struct {
+		Type    string `json:"type"`
+		VoterID string `json:"voter_id"`
+		Ack     string `json:"ack"`
+	}
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	client.touch()
+
+	if !slices.Contains(clientMessages[client.Role], envelope.Type) {
+		return fmt.Errorf("%s connections may not send %q messages", client.Role, envelope.Type)
+	}
+
+	if client.participantID != "" {
+		envelope.VoterID = client.participantID
+	}
+
+	if !client.claim(envelope.VoterID) {
+		return fmt.Errorf("connection votes as %q, not %q", client.voter(), envelope.VoterID)
+	}
+
+	switch envelope.Type {
+	case "chat":
+		return s.handleChatMessage(client, data)
+	case "reaction":
+		return s.handleReaction(client, data)
+	case "suggestion":
+		return s.handleSuggestion(client, data)
+	case "poll_vote":
+		return s.handlePollVote(client, data)
+	case "veto":
+		return s.handleVeto(client, data)
+	default: // vote
+		var err error
+		if client.participantID != "" {
+			err = s.handleParticipantVote(client, data)
+		} else {
+			err = s.handleVote(client, data)
+		}
+
+		if errors.Is(err, errBallotLocked) {
+			s.voteManager.RejectVote(client, err.Error())
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if envelope.Ack != "" {
+			s.voteManager.Acknowledge(client, envelope.Ack)
+		}
+
+		return nil
+	}
+}
+
+// handleVote counts a vote as the voter the connection votes as.
+func (s *Server) handleVote(client *Client, data []byte) error {
+	var msg VoteMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	voterID := client.voter()
+	if voterID == "" {
+		return errors.New("vote without voter_id")
+	}
+
+	return s.voteManager.SubmitVote(voterID, msg.ChoiceID)
+}
+
+// Start serves on the comma-separated listen specs of addr, see Listen, until
+// ctx is done, then shuts down gracefully.
+func (s *Server) Start(ctx context.Context, addr string) error {
+	slog.Info("Starting server", "addr", addr, "content_dir", filepath.Dir(s.engine().ContentDir))
+
+	listeners, err := Listen(addr)
+	if err != nil {
+		return err
+	}
 
-	server := http.Server{
-		Addr:        addr,
-		IdleTimeout: time.Minute,
-		ReadTimeout: 10 * time.Second,
-		Handler:     s.router,
+	var handler http.Handler = s.router
+	if s.leader != nil {
+		handler = s.leader.Forward(s.router)
 	}
 
-	return server.ListenAndServe()
+	return s.serve(ctx, s.stripBasePath(handler), listeners)
 }
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
 )
 
 // setupTestServer creates a test server with sample content
@@ -90,9 +91,6 @@ type: game-over
 		t.Fatalf("failed to create server: %v", err)
 	}
 
-	// Start vote manager
-	go server.voteManager.Run()
-
 	return server, tmpDir
 }
 
@@ -790,6 +788,58 @@ func TestWebSocketVoting(t *testing.T) {
 	}
 }
 
+func TestWebSocketReconnectRestoresVote(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	server.voteManager.StartVotingWithChoices("choice1", []string{"opt-a", "opt-b"}, []parser.Choice{
+		{ID: "opt-a", Label: "Option A"},
+		{ID: "opt-b", Label: "Option B"},
+	}, "Which way?", time.Minute, nil)
+	defer server.voteManager.EndVoting()
+
+	if err := server.voteManager.SubmitVote("returning-voter", "opt-b"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	state := func(voterID string) map[string]any {
+		t.Helper()
+
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?voter_id="+voterID, nil)
+		if err != nil {
+			t.Fatalf("failed to connect websocket: %v", err)
+		}
+		defer ws.Close()
+
+		var msg Message
+		if err := ws.ReadJSON(&msg); err != nil || msg.Type != "state" {
+			t.Fatalf("expected state, got %q (%v)", msg.Type, err)
+		}
+
+		return msg.Payload
+	}
+
+	restored := state("returning-voter")
+	if restored["your_choice"] != "opt-b" || restored["question"] != "Which way?" {
+		t.Errorf("state = %v, want the question and the ballot for opt-b", restored)
+	}
+
+	if choices, _ := restored["choices"].([]any); len(choices) != 2 {
+		t.Errorf("choices = %v, want both choices", restored["choices"])
+	}
+
+	if fresh := state("new-voter"); fresh["your_choice"] != nil {
+		t.Errorf("your_choice = %v for a voter without a ballot, want none", fresh["your_choice"])
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-b"] != 1 {
+		t.Errorf("results = %v, reconnecting must not count the ballot again", got)
+	}
+}
+
 func TestInvalidJSONRequests(t *testing.T) {
 	server, tmpDir := setupTestServer(t)
 	defer os.RemoveAll(tmpDir)
@@ -831,3 +881,109 @@ func TestInvalidJSONRequests(t *testing.T) {
 		})
 	}
 }
+
+func TestWithPreviewURLs(t *testing.T) {
+	choices := []parser.Choice{
+		{ID: "a", Preview: "images/door a.png"},
+		{ID: "b"},
+	}
+
+	got := withPreviewURLs("", choices)
+
+	if got[0].Preview != "/assets/images/door%20a.png" {
+		t.Errorf("preview = %q, want %q", got[0].Preview, "/assets/images/door%20a.png")
+	}
+
+	if got[1].Preview != "" {
+		t.Errorf("preview = %q, want empty", got[1].Preview)
+	}
+
+	if choices[0].Preview != "images/door a.png" {
+		t.Error("withPreviewURLs must not modify the chapter's choices")
+	}
+}
+
+func TestStoryVariables(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapters := map[string]string{
+		"intro.md": `---
+id: intro
+type: story
+next: choice1
+set: {morale: 50}
+---
+# Introduction`,
+		"path-a.md": `---
+id: path-a
+type: story
+set: {morale: +10, has_map: true}
+---
+# Path A`,
+	}
+
+	for name, content := range chapters {
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	post := func(path string, body map[string]any) {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	state := func() map[string]any {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/state", nil))
+
+		var response struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode state: %v", err)
+		}
+
+		return response.Variables
+	}
+
+	post("/api/restart", nil)
+
+	if got := state(); got["morale"] != float64(50) {
+		t.Fatalf("state after restart = %v, want morale 50", got)
+	}
+
+	post("/api/advance", map[string]any{})
+	post("/api/advance", map[string]any{"choice_id": "opt-a"})
+
+	if got := state(); got["morale"] != float64(60) || got["has_map"] != true {
+		t.Errorf("state after path-a = %v, want morale 60 and has_map", got)
+	}
+
+	post("/api/go-back", nil)
+
+	if got := state(); got["morale"] != float64(50) || got["has_map"] != nil {
+		t.Errorf("state after going back = %v, want the variables from before path-a", got)
+	}
+
+	post("/api/advance", map[string]any{"choice_id": "opt-a"})
+	post("/api/restart", nil)
+
+	if got := state(); len(got) != 1 || got["morale"] != float64(50) {
+		t.Errorf("state after second restart = %v, want only morale 50", got)
+	}
+}
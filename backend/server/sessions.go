@@ -0,0 +1,385 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SessionRun is a single play-through of the story from the start chapter.
+type SessionRun struct {
+	StartedAt time.Time      `json:"started_at"`
+	EndedAt   time.Time      `json:"ended_at"`
+	Path      []string       `json:"path"`              // chapter IDs in visiting order
+	Choices   []ChoiceRecord `json:"choices,omitempty"` // decisions taken along the path
+	Votes     []VoteRecord   `json:"votes,omitempty"`   // votes held along the path
+	Ending    string         `json:"ending,omitempty"`  // terminal chapter ID, empty when abandoned
+
+	PeakVoters int                `json:"peak_voters,omitempty"` // most voters connected during a vote
+	Dwell      map[string]float64 `json:"dwell,omitempty"`       // chapter ID -> seconds spent there
+
+	entered time.Time // when the run entered the chapter it is at
+}
+
+// ChoiceRecord is a branch taken at a decision chapter.
+type ChoiceRecord struct {
+	From   string `json:"from"`
+	Choice string `json:"choice"`
+	To     string `json:"to"`
+	Step   int    `json:"step"` // index of To in the run's path
+}
+
+// VoteRecord is how the audience voted at a chapter of a run.
+type VoteRecord struct {
+	Chapter string `json:"chapter"`
+	Step    int    `json:"step"` // index of Chapter in the run's path
+	QuestionStats
+}
+
+// Heatmap aggregates how often chapters, branches and endings were reached.
+type Heatmap struct {
+	Sessions  int                       `json:"sessions"`
+	Nodes     map[string]int            `json:"nodes"`     // chapterID -> visits
+	Choices   map[string]map[string]int `json:"choices"`   // chapterID -> choiceID -> times taken
+	Endings   map[string]int            `json:"endings"`   // chapterID -> times reached
+	Unvisited []string                  `json:"unvisited"` // chapters never reached
+}
+
+// SessionStore tracks the current run and, when a path is configured,
+// persists finished runs to disk as JSON.
+type SessionStore struct {
+	mu      sync.Mutex
+	path    string
+	runs    []SessionRun
+	current *SessionRun
+	dryRun  bool // finished runs are dropped instead of kept, for rehearsals
+}
+
+// NewSessionStore creates a store backed by the given file. Existing runs are
+// loaded from disk; an empty path keeps everything in memory.
+func NewSessionStore(path string) *SessionStore {
+	store := &SessionStore{path: path}
+
+	if path == "" {
+		return store
+	}
+
+	if err := store.load(); err != nil {
+		slog.Error("Failed to load sessions", "path", path, "error", err)
+	}
+
+	return store
+}
+
+func (ss *SessionStore) load() error {
+	runs, err := LoadSessions(ss.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	ss.runs = runs
+
+	return err
+}
+
+// LoadSessions reads the finished runs of a sessions file.
+func LoadSessions(path string) ([]SessionRun, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []SessionRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions: %w", err)
+	}
+
+	return runs, nil
+}
+
+// save writes all finished runs to disk. Caller must hold the lock.
+func (ss *SessionStore) save() error {
+	if ss.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(ss.runs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sessions: %w", err)
+	}
+
+	tmp := ss.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write sessions: %w", err)
+	}
+
+	return os.Rename(tmp, ss.path)
+}
+
+// Begin starts tracking a new run at the given chapter.
+func (ss *SessionStore) Begin(start string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	now := time.Now()
+	ss.current = &SessionRun{
+		StartedAt: now,
+		Path:      []string{start},
+		entered:   now,
+	}
+}
+
+// Visit records moving to a chapter, optionally through a decision choice.
+func (ss *SessionStore) Visit(from, choiceID, to string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	now := time.Now()
+
+	if ss.current == nil {
+		ss.current = &SessionRun{StartedAt: now, Path: []string{from}, entered: now}
+	}
+
+	ss.current.leave(now)
+	ss.current.Path = append(ss.current.Path, to)
+
+	if choiceID != "" {
+		ss.current.Choices = append(ss.current.Choices, ChoiceRecord{
+			From:   from,
+			Choice: choiceID,
+			To:     to,
+			Step:   len(ss.current.Path) - 1,
+		})
+	}
+}
+
+// Back undoes the most recent visit and returns the choice it was taken by,
+// or an empty string when it was not taken by a choice.
+func (ss *SessionStore) Back() string {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.current == nil || len(ss.current.Path) <= 1 {
+		return ""
+	}
+
+	var choice string
+
+	ss.current.leave(time.Now())
+	ss.current.Path = ss.current.Path[:len(ss.current.Path)-1]
+	ss.current.Choices = slices.DeleteFunc(ss.current.Choices, func(c ChoiceRecord) bool {
+		if c.Step >= len(ss.current.Path) {
+			choice = c.Choice
+
+			return true
+		}
+
+		return false
+	})
+	ss.current.Votes = slices.DeleteFunc(ss.current.Votes, func(v VoteRecord) bool {
+		return v.Step >= len(ss.current.Path)
+	})
+
+	return choice
+}
+
+// Vote records a vote that ended at the most recent visit to chapter in the
+// run, replacing an earlier vote there, such as before the vote was
+// restarted. Votes at chapters the run never reached are dropped.
+func (ss *SessionStore) Vote(chapter string, stats QuestionStats) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.current == nil {
+		return
+	}
+
+	step := len(ss.current.Path) - 1
+	for step >= 0 && ss.current.Path[step] != chapter {
+		step--
+	}
+
+	if step < 0 {
+		return
+	}
+
+	ss.current.Votes = slices.DeleteFunc(ss.current.Votes, func(v VoteRecord) bool {
+		return v.Step == step
+	})
+	ss.current.Votes = append(ss.current.Votes, VoteRecord{
+		Chapter:       chapter,
+		Step:          step,
+		QuestionStats: stats,
+	})
+	ss.current.PeakVoters = max(ss.current.PeakVoters, stats.Audience)
+}
+
+// Finish closes the current run with the given ending (empty if abandoned)
+// and persists it. Runs that never left the start chapter, and every run in a
+// dry run, are discarded.
+func (ss *SessionStore) Finish(ending string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	run := ss.current
+	ss.current = nil
+
+	if run == nil || len(run.Path) <= 1 {
+		return
+	}
+
+	if ss.dryRun {
+		return
+	}
+
+	run.EndedAt = time.Now()
+	run.Ending = ending
+	run.leave(run.EndedAt)
+	ss.runs = append(ss.runs, *run)
+
+	if err := ss.save(); err != nil {
+		slog.Error("Failed to persist session", "path", ss.path, "error", err)
+	}
+}
+
+// SetDryRun makes the store drop finished runs instead of keeping and
+// persisting them, until it is turned off again.
+func (ss *SessionStore) SetDryRun(dryRun bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.dryRun = dryRun
+}
+
+// Runs returns a copy of all finished runs.
+func (ss *SessionStore) Runs() []SessionRun {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return slices.Clone(ss.runs)
+}
+
+// Current returns a copy of the run in progress, if any.
+func (ss *SessionStore) Current() (SessionRun, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.current == nil {
+		return SessionRun{}, false
+	}
+
+	return ss.current.snapshot(), true
+}
+
+// Recorded returns a copy of all finished runs followed by the one in
+// progress, if any.
+func (ss *SessionStore) Recorded() []SessionRun {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.recorded()
+}
+
+// recorded is Recorded. Callers must hold ss.mu.
+func (ss *SessionStore) recorded() []SessionRun {
+	runs := slices.Clone(ss.runs)
+
+	if ss.current != nil {
+		runs = append(runs, ss.current.snapshot())
+	}
+
+	return runs
+}
+
+// snapshot copies the run in progress, counting the time at its current
+// chapter so far, so the copy stays put while the run goes on.
+func (run *SessionRun) snapshot() SessionRun {
+	out := *run
+	out.Path = slices.Clone(run.Path)
+	out.Choices = slices.Clone(run.Choices)
+	out.Votes = slices.Clone(run.Votes)
+	out.Dwell = maps.Clone(run.Dwell)
+	out.leave(time.Now())
+
+	return out
+}
+
+// visited returns the chapters the run spent time at, once each, in the order
+// of its path, followed by those it went back from.
+func (run *SessionRun) visited() []string {
+	var ids []string
+
+	seen := map[string]bool{}
+
+	for _, id := range slices.Concat(run.Path, slices.Sorted(maps.Keys(run.Dwell))) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// leave adds the time since the run entered its current chapter to the dwell
+// time of that chapter, as the run moves on.
+func (run *SessionRun) leave(now time.Time) {
+	if run.Dwell == nil {
+		run.Dwell = make(map[string]float64)
+	}
+
+	run.Dwell[run.Path[len(run.Path)-1]] += now.Sub(run.entered).Seconds()
+	run.entered = now
+}
+
+// Heatmap aggregates all finished runs plus the one in progress against the
+// given set of known chapter IDs.
+func (ss *SessionStore) Heatmap(chapterIDs []string) Heatmap {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	hm := Heatmap{
+		Nodes:     make(map[string]int),
+		Choices:   make(map[string]map[string]int),
+		Endings:   make(map[string]int),
+		Unvisited: []string{},
+	}
+
+	runs := ss.recorded()
+	hm.Sessions = len(runs)
+
+	for _, run := range runs {
+		for _, id := range run.Path {
+			hm.Nodes[id]++
+		}
+
+		for _, c := range run.Choices {
+			if hm.Choices[c.From] == nil {
+				hm.Choices[c.From] = make(map[string]int)
+			}
+
+			hm.Choices[c.From][c.Choice]++
+		}
+
+		if run.Ending != "" {
+			hm.Endings[run.Ending]++
+		}
+	}
+
+	for _, id := range chapterIDs {
+		if hm.Nodes[id] == 0 {
+			hm.Unvisited = append(hm.Unvisited, id)
+		}
+	}
+
+	slices.Sort(hm.Unvisited)
+
+	return hm
+}
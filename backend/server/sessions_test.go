@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSessionStore_PersistsFinishedRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+
+	store := NewSessionStore(path)
+	store.Begin("intro")
+	store.Visit("intro", "", "choice1")
+	store.Visit("choice1", "opt-b", "path-b")
+	store.Finish("path-b")
+
+	reloaded := NewSessionStore(path)
+
+	runs := reloaded.Runs()
+	if len(runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(runs))
+	}
+
+	if runs[0].Ending != "path-b" {
+		t.Errorf("ending = %q, want %q", runs[0].Ending, "path-b")
+	}
+
+	if len(runs[0].Choices) != 1 || runs[0].Choices[0].Choice != "opt-b" {
+		t.Errorf("choices = %+v, want single opt-b record", runs[0].Choices)
+	}
+}
+
+func TestSessionStore_DiscardsRunsThatNeverLeftStart(t *testing.T) {
+	store := NewSessionStore("")
+	store.Begin("intro")
+	store.Finish("")
+
+	if runs := store.Runs(); len(runs) != 0 {
+		t.Errorf("got %d runs, want 0", len(runs))
+	}
+}
+
+func TestSessionStore_Back(t *testing.T) {
+	store := NewSessionStore("")
+	store.Begin("intro")
+	store.Visit("intro", "", "choice1")
+	store.Visit("choice1", "opt-a", "path-a")
+	store.Back()
+
+	hm := store.Heatmap([]string{"intro", "choice1", "path-a"})
+
+	if hm.Nodes["path-a"] != 0 {
+		t.Errorf("path-a visits = %d, want 0", hm.Nodes["path-a"])
+	}
+
+	if len(hm.Choices["choice1"]) != 0 {
+		t.Errorf("choice1 choices = %v, want none", hm.Choices["choice1"])
+	}
+}
+
+func TestSessionStore_Heatmap(t *testing.T) {
+	store := NewSessionStore("")
+
+	for _, choice := range []string{"opt-a", "opt-b", "opt-b"} {
+		next := map[string]string{"opt-a": "path-a", "opt-b": "path-b"}[choice]
+
+		store.Begin("intro")
+		store.Visit("intro", "", "choice1")
+		store.Visit("choice1", choice, next)
+		store.Finish(next)
+	}
+
+	hm := store.Heatmap([]string{"intro", "choice1", "path-a", "path-b", "secret"})
+
+	if hm.Sessions != 3 {
+		t.Errorf("sessions = %d, want 3", hm.Sessions)
+	}
+
+	if hm.Nodes["choice1"] != 3 {
+		t.Errorf("choice1 visits = %d, want 3", hm.Nodes["choice1"])
+	}
+
+	if hm.Choices["choice1"]["opt-b"] != 2 {
+		t.Errorf("opt-b taken = %d, want 2", hm.Choices["choice1"]["opt-b"])
+	}
+
+	if hm.Endings["path-a"] != 1 || hm.Endings["path-b"] != 2 {
+		t.Errorf("endings = %v, want path-a:1 path-b:2", hm.Endings)
+	}
+
+	if len(hm.Unvisited) != 1 || hm.Unvisited[0] != "secret" {
+		t.Errorf("unvisited = %v, want [secret]", hm.Unvisited)
+	}
+}
+
+func TestHandleGetStoryHeatmap(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	advance := func(choiceID string) {
+		body, _ := json.Marshal(map[string]string{"choice_id": choiceID})
+		req := httptest.NewRequest("POST", "/api/advance", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("advance status = %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+
+	advance("")
+	advance("opt-b")
+
+	req := httptest.NewRequest("GET", "/api/story/heatmap", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var hm Heatmap
+	if err := json.NewDecoder(w.Body).Decode(&hm); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if hm.Endings["path-b"] != 1 {
+		t.Errorf("path-b endings = %d, want 1", hm.Endings["path-b"])
+	}
+
+	if len(hm.Unvisited) != 1 || hm.Unvisited[0] != "path-a" {
+		t.Errorf("unvisited = %v, want [path-a]", hm.Unvisited)
+	}
+}
+
+func TestSessionStore_Vote(t *testing.T) {
+	store := NewSessionStore("")
+	store.Begin("intro")
+	store.Visit("intro", "", "choice1")
+	store.Vote("choice1", QuestionStats{Winner: "opt-a", Audience: 3})
+	store.Vote("choice1", QuestionStats{Winner: "opt-b", Audience: 5})
+	store.Vote("elsewhere", QuestionStats{Winner: "opt-c"})
+
+	run := store.Recorded()[0]
+	if len(run.Votes) != 1 || run.Votes[0].Winner != "opt-b" || run.Votes[0].Step != 1 {
+		t.Fatalf("votes = %+v, want the restarted vote at choice1 alone", run.Votes)
+	}
+
+	if run.PeakVoters != 5 {
+		t.Errorf("peak voters = %d, want 5", run.PeakVoters)
+	}
+
+	store.Visit("choice1", "opt-b", "path-b")
+	store.Back()
+	store.Back()
+
+	if votes := store.Recorded()[0].Votes; len(votes) != 0 {
+		t.Errorf("votes = %+v, want none after going back past the vote", votes)
+	}
+}
+
+func TestSessionStore_Dwell(t *testing.T) {
+	store := NewSessionStore("")
+	store.Begin("intro")
+	time.Sleep(20 * time.Millisecond)
+	store.Visit("intro", "", "choice1")
+	store.Visit("choice1", "opt-a", "path-a")
+	time.Sleep(20 * time.Millisecond)
+	store.Back()
+
+	run, _ := store.Current()
+	if run.Dwell["intro"] < 0.02 || run.Dwell["path-a"] < 0.02 {
+		t.Errorf("dwell = %v, want the time at intro and at path-a, which was gone back from", run.Dwell)
+	}
+
+	if got := run.visited(); !slices.Equal(got, []string{"intro", "choice1", "path-a"}) {
+		t.Errorf("visited = %v, want the path, then path-a", got)
+	}
+
+	store.Visit("choice1", "opt-b", "path-b")
+	store.Finish("path-b")
+
+	if dwell := store.Runs()[0].Dwell; len(dwell) != 4 {
+		t.Errorf("finished run dwell = %v, want every chapter visited", dwell)
+	}
+}
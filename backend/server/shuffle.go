@@ -0,0 +1,70 @@
+package server
+
+import (
+	"hash/fnv"
+	"maps"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// shuffleSeed picks the seed of a vote's choice orders, never zero.
+func shuffleSeed() uint64 {
+	return rand.Uint64() | 1 //nolint:gosec // the order only needs to differ between votes
+}
+
+// shuffled returns the voting_started payload with its choices in the order
+// of the given client, and that order as choice_order. The order depends on
+// the vote and the voter, so a voter that reconnects finds the choices where
+// they were. Presenters and spectators keep the story's order.
+func shuffled(payload map[string]any, seed uint64, client *Client) map[string]any {
+	if client.Role != RoleVoter {
+		return payload
+	}
+
+	key := client.voter()
+	if key == "" {
+		key = client.ID
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	rng := rand.New(rand.NewPCG(seed, h.Sum64())) //nolint:gosec // the order must be the same on every reconnect
+
+	out := maps.Clone(payload)
+
+	switch choices := payload["choices"].(type) {
+	case []parser.Choice:
+		choices = slices.Clone(choices)
+
+		// abstaining stays the last choice
+		n := len(choices)
+		if n > 0 && choices[n-1].ID == AbstainChoice {
+			n--
+		}
+
+		rng.Shuffle(n, func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+
+		order := make([]string, len(choices))
+		for i, choice := range choices {
+			order[i] = choice.ID
+		}
+
+		out["choices"], out["choice_order"] = choices, order
+	case []string:
+		choices = slices.Clone(choices)
+
+		n := len(choices)
+		if n > 0 && choices[n-1] == AbstainChoice {
+			n--
+		}
+
+		rng.Shuffle(n, func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+
+		out["choices"], out["choice_order"] = choices, choices
+	}
+
+	return out
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestShuffledChoices(t *testing.T) {
+	vm := NewVoteManager()
+	vm.shuffle = true
+
+	choices := []parser.Choice{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}
+	vm.StartVotingWithChoices("q", []string{"a", "b", "c", "d", "e"}, choices, "", time.Minute, nil)
+	defer vm.EndVoting()
+
+	vm.mu.RLock()
+	started := vm.started
+	vm.mu.RUnlock()
+
+	order := func(client *Client) []string {
+		payload := started.forClient(client).Payload
+
+		got, _ := payload["choice_order"].([]string)
+
+		shown, _ := payload["choices"].([]parser.Choice)
+		for i, choice := range shown {
+			if i >= len(got) || got[i] != choice.ID {
+				t.Fatalf("choices %v do not follow choice_order %v", shown, got)
+			}
+		}
+
+		return got
+	}
+
+	first := order(&Client{ID: "conn-1", Role: RoleVoter, voterID: "voter-1"})
+	if len(first) != 5 || !slices.Equal(slices.Sorted(slices.Values(first)), []string{"a", "b", "c", "d", "e"}) {
+		t.Fatalf("choice_order = %v, want a permutation of every choice", first)
+	}
+
+	// the same voter on another connection gets the same order
+	if again := order(&Client{ID: "conn-2", Role: RoleVoter, voterID: "voter-1"}); !slices.Equal(again, first) {
+		t.Errorf("order after reconnecting = %v, want %v", again, first)
+	}
+
+	differs := false
+
+	for i := range 20 {
+		if !slices.Equal(order(&Client{ID: "conn", Role: RoleVoter, voterID: fmt.Sprintf("voter-%d", i+2)}), first) {
+			differs = true
+
+			break
+		}
+	}
+
+	if !differs {
+		t.Error("every voter got the same order")
+	}
+
+	if payload := started.forClient(&Client{Role: RolePresenter}).Payload; payload["choice_order"] != nil {
+		t.Errorf("presenter payload = %v, want the story's order", payload)
+	}
+
+	if shown := started.Payload["choices"].([]parser.Choice); shown[0].ID != "a" || shown[4].ID != "e" {
+		t.Errorf("shuffling changed the broadcast payload to %v", shown)
+	}
+}
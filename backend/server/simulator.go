@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// simulateVotes casts ballots from fake voters at random moments during the
+// first 80% of the voting window, so the presenter screen can be exercised
+// without a real audience. Votes stop as soon as the question changes.
+func (s *Server) simulateVotes(questionID string, choiceIDs []string, duration time.Duration) {
+	voters := s.simulatedVoterCount()
+	if voters <= 0 || len(choiceIDs) == 0 {
+		return
+	}
+
+	window := duration * 4 / 5
+
+	// a skewed distribution makes for more interesting results than a uniform one
+	weights := make([]int, len(choiceIDs))
+	for i := range weights {
+		weights[i] = 1 + rand.IntN(5) //nolint:gosec // simulation, not security
+	}
+
+	for i := range voters {
+		delay := time.Duration(rand.Int64N(int64(window) + 1)) //nolint:gosec // simulation, not security
+		choice := weightedPick(choiceIDs, weights)
+		voterID := fmt.Sprintf("sim-voter-%d", i+1)
+
+		time.AfterFunc(delay, func() {
+			if !s.voteManager.IsVotingActive() || s.voteManager.CurrentQuestion() != questionID {
+				return
+			}
+
+			if err := s.voteManager.SubmitVote(voterID, choice); err != nil {
+				slog.Warn("Simulated vote failed", "voter_id", voterID, "error", err)
+			}
+		})
+	}
+}
+
+func weightedPick(items []string, weights []int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	n := rand.IntN(total) //nolint:gosec // simulation, not security
+	for i, w := range weights {
+		if n < w {
+			return items[i]
+		}
+
+		n -= w
+	}
+
+	return items[len(items)-1]
+}
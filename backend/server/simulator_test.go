@@ -0,0 +1,58 @@
+package server
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSimulateVotes(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.simulatedVoters = 10
+
+	choices := []string{"opt-a", "opt-b"}
+	server.voteManager.StartVoting("choice1", choices, 200*time.Millisecond, nil)
+	server.simulateVotes("choice1", choices, 200*time.Millisecond)
+
+	time.Sleep(250 * time.Millisecond)
+
+	total := 0
+	for _, count := range server.voteManager.GetResults("choice1") {
+		total += count
+	}
+
+	if total != 10 {
+		t.Errorf("got %d simulated votes, want 10", total)
+	}
+}
+
+func TestSimulateVotes_StopsWhenQuestionChanges(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.simulatedVoters = 10
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a"}, time.Second, nil)
+	server.simulateVotes("choice1", []string{"opt-a"}, time.Second)
+	server.voteManager.StartVoting("other", []string{"x"}, time.Second, nil)
+
+	time.Sleep(900 * time.Millisecond)
+
+	if got := server.voteManager.GetResults("other")["opt-a"]; got != 0 {
+		t.Errorf("simulated votes leaked into another question: %d", got)
+	}
+
+	server.voteManager.EndVoting()
+}
+
+func TestWeightedPick(t *testing.T) {
+	items := []string{"a", "b"}
+
+	for range 100 {
+		if got := weightedPick(items, []int{0, 1}); got != "b" {
+			t.Fatalf("weightedPick = %q, want %q", got, "b")
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	slackAPIURL         = "https://slack.com/api"
+	slackTimeout        = 5 * time.Second
+	slackMaxClockSkew   = 5 * time.Minute // oldest request timestamp accepted, against replayed clicks
+	slackMaxRequestSize = 1 << 20
+	slackVoteAction     = "vote:" // action ID prefix of the vote buttons, followed by the choice ID
+	slackVoterPrefix    = "slack:"
+)
+
+var errSlackSignature = errors.New("invalid slack signature")
+
+// Slack lets a Slack channel vote alongside the room. Every vote that starts
+// is posted to the channel with a button per choice, and clicks come back to
+// /api/integrations/slack/actions as votes of the clicking Slack user.
+type Slack struct {
+	token         string
+	channel       string
+	signingSecret []byte
+	apiURL        string
+	client        *http.Client
+}
+
+// NewSlack creates a Slack integration posting to channel with the bot token
+// and checking interaction requests against the app's signing secret.
+func NewSlack(token, channel, signingSecret string) *Slack {
+	return &Slack{
+		token:         token,
+		channel:       channel,
+		signingSecret: []byte(signingSecret),
+		apiURL:        slackAPIURL,
+		client:        &http.Client{Timeout: slackTimeout},
+	}
+}
+
+// Notify posts the choices of every vote that starts to the channel.
+func (sl *Slack) Notify(message *Message) {
+	if message.Type != "voting_started" {
+		return
+	}
+
+	question, _ := message.Payload["question"].(string)
+	if question == "" {
+		question = "What should we do?"
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"channel": sl.channel,
+		"text":    question,
+		"blocks":  slackVoteBlocks(question, message.Payload),
+	})
+	if err != nil {
+		slog.Warn("Failed to encode slack message", "error", err)
+
+		return
+	}
+
+	go func() {
+		if err := sl.call("chat.postMessage", body); err != nil {
+			slog.Warn("Failed to post vote to slack", "channel", sl.channel, "error", err)
+		}
+	}()
+}
+
+// slackVoteBlocks lays out the question with one button per available
+// choice. The block ID is the question, so clicks on an old vote are ignored.
+func slackVoteBlocks(question string, payload map[string]any) []map[string]any {
+	choices := openChoices(payload)
+
+	elements := make([]map[string]any, 0, len(choices))
+	for _, choice := range choices {
+		elements = append(elements, map[string]any{
+			"type":      "button",
+			"action_id": slackVoteAction + choice.ID,
+			"value":     choice.ID,
+			"text":      map[string]any{"type": "plain_text", "text": choice.Label, "emoji": true},
+		})
+	}
+
+	questionID, _ := payload["question_id"].(string)
+
+	return []map[string]any{
+		{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": "*" + question + "*"}},
+		{"type": "actions", "block_id": questionID, "elements": elements},
+	}
+}
+
+// call invokes a Slack Web API method with a JSON body.
+func (sl *Slack) call(method string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, sl.apiURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+sl.token)
+
+	resp, err := sl.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response %s: %w", resp.Status, err)
+	}
+
+	if !result.OK {
+		return fmt.Errorf("%s failed: %s", method, result.Error)
+	}
+
+	return nil
+}
+
+// verify checks the signature Slack puts on every interaction request.
+func (sl *Slack) verify(header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errSlackSignature
+	}
+
+	if age := now.Sub(time.Unix(sent, 0)); age > slackMaxClockSkew || age < -slackMaxClockSkew {
+		return errSlackSignature
+	}
+
+	mac := hmac.New(sha256.New, sl.signingSecret)
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte("v0="+hex.EncodeToString(mac.Sum(nil)))) {
+		return errSlackSignature
+	}
+
+	return nil
+}
+
+// slackActions is the interaction payload Slack sends when a button is clicked.
+type slackActions struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		BlockID  string `json:"block_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// handleSlackActions records vote button clicks from Slack as votes keyed by
+// the Slack user ID.
+func (s *Server) handleSlackActions(w http.ResponseWriter, r *http.Request) {
+	if s.slack == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := s.slack.verify(r.Header, body, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var payload slackActions
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+
+		return
+	}
+
+	for _, action := range payload.Actions {
+		if !strings.HasPrefix(action.ActionID, slackVoteAction) || action.BlockID != s.voteManager.CurrentQuestion() {
+			continue
+		}
+
+		err := s.voteManager.SubmitVote(slackVoterPrefix+payload.User.ID, action.Value)
+		if errors.Is(err, errBallotLocked) {
+			// only the voter sees why their click did nothing
+			w.Header().Set("Content-Type", "application/json")
+
+			if err := json.NewEncoder(w).Encode(map[string]any{
+				"response_type":    "ephemeral",
+				"replace_original": false,
+				"text":             "Your vote cannot be changed on this question.",
+			}); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		requestLogger(r).Debug("Slack vote received", "user", payload.User.ID, "choice_id", action.Value)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestSlackPostsVotes(t *testing.T) {
+	posted := make(chan map[string]any, 1)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb-token" {
+			t.Errorf("unexpected call %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		posted <- body
+
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+
+	slack := NewSlack("xoxb-token", "C123", "secret")
+	slack.apiURL = api.URL
+
+	slack.Notify(&Message{Type: "vote_update", Payload: map[string]any{}})
+	slack.Notify(&Message{Type: "voting_started", Payload: map[string]any{
+		"question_id": "choice1",
+		"question":    "Which way?",
+		"choices": []parser.Choice{
+			{ID: "opt-a", Label: "Left"},
+			{ID: "opt-b", Label: "Right", Locked: true},
+		},
+	}})
+
+	select {
+	case body := <-posted:
+		encoded, _ := json.Marshal(body)
+
+		for _, want := range []string{`"channel":"C123"`, `"block_id":"choice1"`, `"action_id":"vote:opt-a"`, `"text":"Left"`} {
+			if !strings.Contains(string(encoded), want) {
+				t.Errorf("posted message %s does not contain %s", encoded, want)
+			}
+		}
+
+		if strings.Contains(string(encoded), "opt-b") {
+			t.Errorf("posted message %s offers the locked choice", encoded)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("vote was not posted to slack")
+	}
+}
+
+func TestSlackActions(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.slack = NewSlack("xoxb-token", "C123", "secret")
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	defer server.voteManager.EndVoting()
+
+	click := func(blockID, choiceID, secret string) (int, string) {
+		payload := `{"type":"block_actions","user":{"id":"U42"},"actions":[{"action_id":"vote:` + choiceID +
+			`","block_id":"` + blockID + `","value":"` + choiceID + `"}]}`
+		body := "payload=" + url.QueryEscape(payload)
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+		req := httptest.NewRequest("POST", "/api/integrations/slack/actions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w.Code, w.Body.String()
+	}
+
+	if code, _ := click("choice1", "opt-a", "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("forged click status = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if code, _ := click("old-question", "opt-a", "secret"); code != http.StatusOK {
+		t.Errorf("stale click status = %d, want %d", code, http.StatusOK)
+	}
+
+	if code, _ := click("choice1", "opt-b", "secret"); code != http.StatusOK {
+		t.Errorf("click status = %d, want %d", code, http.StatusOK)
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-a"] != 0 || got["opt-b"] != 1 {
+		t.Errorf("results = %v, want only the signed click on the current vote counted", got)
+	}
+
+	if !server.voteManager.HasVoted("slack:U42") {
+		t.Error("vote was not recorded under the slack user")
+	}
+
+	server.voteManager.LockBallots()
+
+	if code, body := click("choice1", "opt-a", "secret"); code != http.StatusOK || !strings.Contains(body, `"ephemeral"`) {
+		t.Errorf("changed vote on a locked question = %d %q, want an ephemeral reply", code, body)
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // Twilio signs requests with HMAC-SHA1
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+const smsVoterPrefix = "sms:"
+
+var errSMSSignature = errors.New("invalid twilio signature")
+
+// SMS takes votes texted to a Twilio number, for audience members without
+// Wi-Fi. Twilio POSTs every incoming text to /api/integrations/sms, signed
+// with the account's auth token.
+type SMS struct {
+	authToken  []byte
+	webhookURL string // the URL Twilio was given, empty to derive it from each request
+}
+
+// NewSMS creates an SMS integration checking requests against the Twilio
+// auth token. webhookURL is the URL configured in Twilio, needed when a
+// proxy in front of the server changes the scheme, host or path.
+func NewSMS(authToken, webhookURL string) *SMS {
+	return &SMS{authToken: []byte(authToken), webhookURL: webhookURL}
+}
+
+// verify checks the X-Twilio-Signature of a request whose form is parsed:
+// the base64 HMAC-SHA1 of the URL followed by every POST parameter, sorted,
+// as name and value.
+func (sms *SMS) verify(r *http.Request) error {
+	signed := sms.webhookURL
+	if signed == "" {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+
+		signed = scheme + "://" + r.Host + r.URL.RequestURI()
+	}
+
+	names := make([]string, 0, len(r.PostForm))
+	for name := range r.PostForm {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		values := slices.Clone(r.PostForm[name])
+		slices.Sort(values)
+
+		for _, value := range values {
+			signed += name + value
+		}
+	}
+
+	mac := hmac.New(sha1.New, sms.authToken)
+	mac.Write([]byte(signed))
+
+	if !hmac.Equal([]byte(r.Header.Get("X-Twilio-Signature")), []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))) {
+		return errSMSSignature
+	}
+
+	return nil
+}
+
+// smsVoterID hashes a phone number into a voter ID, so numbers never show up
+// in logs, certificates or the admin API.
+func smsVoterID(phone string) string {
+	sum := sha256.Sum256([]byte(phone))
+
+	return smsVoterPrefix + hex.EncodeToString(sum[:8])
+}
+
+// smsChoice finds the choice a text picks: its letter, A for the first
+// choice, or its ID or label.
+func smsChoice(text string, choices []parser.Choice) (parser.Choice, bool) {
+	text = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), ".):"))
+
+	if len(text) == 1 {
+		if i := int(strings.ToUpper(text)[0]) - 'A'; i >= 0 && i < len(choices) {
+			return choices[i], true
+		}
+	}
+
+	for _, choice := range choices {
+		if strings.EqualFold(text, choice.ID) || strings.EqualFold(text, choice.Label) {
+			return choice, true
+		}
+	}
+
+	return parser.Choice{}, false
+}
+
+// smsMenu lists the choices with their letters.
+func smsMenu(choices []parser.Choice) string {
+	items := make([]string, len(choices))
+	for i, choice := range choices {
+		items[i] = string(rune('A'+i)) + ") " + choice.Label
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// twiml is a TwiML response replying to the sender with a text.
+type twiml struct {
+	XMLName xml.Name `xml:"Response"`
+	Message string   `xml:"Message"`
+}
+
+// handleSMS records a texted choice as the vote of the sender's number and
+// replies with what was recorded.
+func (s *Server) handleSMS(w http.ResponseWriter, r *http.Request) {
+	if s.sms == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := s.sms.verify(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	var reply string
+
+	choices := s.voteManager.OpenChoices()
+	choice, ok := smsChoice(r.PostForm.Get("Body"), choices)
+
+	switch {
+	case len(choices) == 0:
+		reply = "There is no vote running right now."
+	case !ok:
+		reply = "Reply with a letter: " + smsMenu(choices)
+	default:
+		err := s.voteManager.SubmitVote(smsVoterID(r.PostForm.Get("From")), choice.ID)
+		if errors.Is(err, errBallotLocked) {
+			reply = "Your vote cannot be changed on this question."
+
+			break
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		requestLogger(r).Debug("SMS vote received", "choice_id", choice.ID)
+
+		reply = "Vote recorded: " + choice.Label
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+
+	out, err := xml.Marshal(twiml{Message: reply})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(out)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // Twilio signs requests with HMAC-SHA1
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMSVoting(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.sms = NewSMS("auth-token", "")
+
+	text := func(body, token string) (int, string) {
+		form := url.Values{"From": {"+15551234567"}, "Body": {body}}
+
+		// Twilio signs the URL followed by the sorted parameters
+		mac := hmac.New(sha1.New, []byte(token))
+		mac.Write([]byte("http://example.com/api/integrations/sms" + "Body" + body + "From" + "+15551234567"))
+
+		req := httptest.NewRequest("POST", "http://example.com/api/integrations/sms", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Twilio-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w.Code, w.Body.String()
+	}
+
+	if code, _ := text("A", "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("forged text status = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if _, reply := text("A", "auth-token"); !strings.Contains(reply, "no vote running") {
+		t.Errorf("reply without a vote = %q", reply)
+	}
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	defer server.voteManager.EndVoting()
+
+	tests := []struct {
+		body  string
+		reply string
+	}{
+		{"maybe", "<Message>Reply with a letter: A) opt-a, B) opt-b</Message>"},
+		{" a ", "<Message>Vote recorded: opt-a</Message>"},
+		{"b)", "<Message>Vote recorded: opt-b</Message>"},
+	}
+
+	for _, tt := range tests {
+		code, reply := text(tt.body, "auth-token")
+		if code != http.StatusOK || !strings.Contains(reply, tt.reply) {
+			t.Errorf("text %q = %d %q, want a reply with %q", tt.body, code, reply, tt.reply)
+		}
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-a"] != 0 || got["opt-b"] != 1 {
+		t.Errorf("results = %v, want the number's last choice counted once", got)
+	}
+
+	if !server.voteManager.HasVoted(smsVoterID("+15551234567")) {
+		t.Error("vote was not recorded under the hashed number")
+	}
+
+	server.voteManager.LockBallots()
+
+	if code, reply := text("a", "auth-token"); code != http.StatusOK || !strings.Contains(reply, "cannot be changed") {
+		t.Errorf("changed vote on a locked question = %d %q, want a reply saying so", code, reply)
+	}
+}
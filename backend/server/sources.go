@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Vote is a ballot cast outside the room, such as in a chat platform.
+type Vote struct {
+	// VoterID identifies the voter across all sources, so sources prefix the
+	// platform's user ID with their own name, such as "youtube:UC123".
+	VoterID  string
+	ChoiceID string
+	// QuestionID is the vote the ballot was cast in. Ballots for a vote that
+	// is no longer running are dropped. Empty means the running vote.
+	QuestionID string
+}
+
+// VoteSource feeds votes from another platform, such as YouTube Live, Matrix
+// or Zoom chat, into the show. The server starts every registered source,
+// counts the votes it delivers and stops it when the server closes.
+//
+// Sources that also implement Notifier see every vote start and end, to
+// post polls to their platform.
+type VoteSource interface {
+	// Start connects to the platform. The context ends when the server closes.
+	Start(ctx context.Context) error
+	// Stop disconnects from the platform and closes the Votes channel.
+	Stop() error
+	// Votes delivers the votes cast on the platform.
+	Votes() <-chan Vote
+}
+
+// Notifier receives every message broadcast to the whole audience, such as
+// voting_started with the question and choices. Notify is called from the
+// hub, so it must not block; slow work belongs in a goroutine.
+type Notifier interface {
+	Notify(message *Message)
+}
+
+// startVoteSources starts the registered vote sources and counts their votes.
+func (s *Server) startVoteSources() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopSources = cancel
+
+	for _, source := range s.sources {
+		if err := source.Start(ctx); err != nil {
+			slog.Error("Failed to start vote source", "source", fmt.Sprintf("%T", source), "error", err)
+
+			continue
+		}
+
+		go s.countVotes(source)
+	}
+}
+
+// countVotes submits the votes of a source until it stops.
+func (s *Server) countVotes(source VoteSource) {
+	for vote := range source.Votes() {
+		if vote.VoterID == "" {
+			continue
+		}
+
+		if vote.QuestionID != "" && vote.QuestionID != s.voteManager.CurrentQuestion() {
+			continue
+		}
+
+		if err := s.voteManager.SubmitVote(vote.VoterID, vote.ChoiceID); err != nil {
+			slog.Warn("Failed to count vote", "source", fmt.Sprintf("%T", source), "voter_id", vote.VoterID, "error", err)
+		}
+	}
+}
+
+// Close stops the vote sources.
+func (s *Server) Close() error {
+	if s.stopSources != nil {
+		s.stopSources()
+	}
+
+	var errs []error
+
+	for _, source := range s.sources {
+		if err := source.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
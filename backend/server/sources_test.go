@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// chatSource is a chat platform whose users all vote for the first choice
+// of every vote as soon as it starts.
+type chatSource struct {
+	votes   chan Vote
+	started chan struct{}
+	stopped bool
+}
+
+func (c *chatSource) Start(context.Context) error {
+	close(c.started)
+
+	return nil
+}
+
+func (c *chatSource) Stop() error {
+	c.stopped = true
+	close(c.votes)
+
+	return nil
+}
+
+func (c *chatSource) Votes() <-chan Vote {
+	return c.votes
+}
+
+func (c *chatSource) Notify(message *Message) {
+	if message.Type != "voting_started" {
+		return
+	}
+
+	questionID, _ := message.Payload["question_id"].(string)
+	first := openChoices(message.Payload)[0].ID
+
+	c.votes <- Vote{VoterID: "chat:carol", ChoiceID: first, QuestionID: "an-old-vote"}
+	c.votes <- Vote{VoterID: "chat:alice", ChoiceID: first, QuestionID: questionID}
+	c.votes <- Vote{VoterID: "chat:bob", ChoiceID: first}
+}
+
+func TestVoteSources(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+
+	source := &chatSource{votes: make(chan Vote, 8), started: make(chan struct{})}
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), fstest.MapFS{}, "", "", false,
+		WithVoteSources(source))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	select {
+	case <-source.started:
+	case <-time.After(time.Second):
+		t.Fatal("vote source was not started")
+	}
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+	defer server.voteManager.EndVoting()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.voteManager.GetResults("choice1")["opt-a"] < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := server.voteManager.GetResults("choice1"); got["opt-a"] != 2 {
+		t.Errorf("results = %v, want the two votes on the running vote counted", got)
+	}
+
+	if server.voteManager.HasVoted("chat:carol") {
+		t.Error("vote for another question was counted")
+	}
+
+	if err := server.Close(); err != nil || !source.stopped {
+		t.Errorf("Close() = %v, source stopped %v", err, source.stopped)
+	}
+}
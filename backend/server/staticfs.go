@@ -0,0 +1,88 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// overlayFS serves the files of upper in place of those of lower by the same
+// name, and the files of lower upper doesn't have. Directories are those of
+// lower where both have them, so upper only needs the files it changes.
+type overlayFS struct {
+	upper, lower fs.FS
+}
+
+// OverlayFS layers upper over lower file by file, such as a directory with a
+// reskinned voter page over the frontend built into the binary.
+func OverlayFS(upper, lower fs.FS) fs.FS {
+	return overlayFS{upper: upper, lower: lower}
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	upper, err := o.upper.Open(name)
+	if err != nil {
+		return o.lower.Open(name)
+	}
+
+	if info, err := upper.Stat(); err == nil && !info.IsDir() {
+		return upper, nil
+	}
+
+	lower, err := o.lower.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		// a directory only upper has
+		return upper, nil
+	}
+
+	_ = upper.Close()
+
+	return lower, err
+}
+
+// withAppShell serves the app shell, the index.html of the closest directory
+// that has one, for paths of the frontend without a file, such as
+// /voter/room/ABCD, so the apps can route on the client. Paths that look like
+// files, and those of the API and of assets, still get 404 Not Found.
+func (s *Server) withAppShell(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || name == "" || path.Ext(name) != "" ||
+			name == "api" || strings.HasPrefix(name, "api/") || strings.HasPrefix(name, "assets/") {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if _, err := fs.Stat(s.staticFS, name); err == nil {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		for dir := path.Dir(name); ; dir = path.Dir(dir) {
+			if _, err := fs.Stat(s.staticFS, path.Join(dir, "index.html")); err == nil {
+				shell := r.Clone(r.Context())
+				shell.URL.Path = path.Join("/", dir) + "/"
+				shell.URL.RawPath = ""
+
+				next.ServeHTTP(w, shell)
+
+				return
+			}
+
+			if dir == "." {
+				break
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOverlayFS(t *testing.T) {
+	lower := fstest.MapFS{
+		"index.html":       &fstest.MapFile{Data: []byte("embedded index")},
+		"voter/index.html": &fstest.MapFile{Data: []byte("embedded voter")},
+		"assets/pixel.css": &fstest.MapFile{Data: []byte("embedded css")},
+	}
+	upper := fstest.MapFS{
+		"voter/index.html": &fstest.MapFile{Data: []byte("custom voter")},
+		"theme/logo.svg":   &fstest.MapFile{Data: []byte("<svg/>")},
+	}
+
+	overlay := OverlayFS(upper, lower)
+
+	for name, want := range map[string]string{
+		"index.html":       "embedded index",
+		"voter/index.html": "custom voter",
+		"assets/pixel.css": "embedded css",
+		"theme/logo.svg":   "<svg/>",
+	} {
+		data, err := fs.ReadFile(overlay, name)
+		if err != nil || string(data) != want {
+			t.Errorf("ReadFile(%q) = %q, %v, want %q", name, data, err, want)
+		}
+	}
+
+	entries, err := fs.ReadDir(overlay, "voter")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("ReadDir(voter) = %v, %v, want the embedded directory", entries, err)
+	}
+
+	if info, err := fs.Stat(overlay, "theme"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(theme) = %v, %v, want the directory only the overlay has", info, err)
+	}
+
+	if _, err := overlay.Open("missing.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(missing.html) error = %v, want fs.ErrNotExist", err)
+	}
+
+	if _, err := overlay.Open("../secret"); err == nil {
+		t.Error("Open(../secret) succeeded, want an invalid path")
+	}
+}
+
+func TestAppShell(t *testing.T) {
+	_, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	staticFS := fstest.MapFS{
+		"index.html":       &fstest.MapFile{Data: []byte("landing")},
+		"voter/index.html": &fstest.MapFile{Data: []byte("voter app")},
+		"assets/pixel.css": &fstest.MapFile{Data: []byte("body {}")},
+	}
+
+	server, err := NewServer(filepath.Join(tmpDir, "story.yaml"), filepath.Join(tmpDir, "chapters"), staticFS, "", "", false)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"/voter/room/ABCD", http.StatusOK, "voter app"},
+		{"/voter/", http.StatusOK, "voter app"},
+		{"/schedule", http.StatusOK, "landing"},
+		{"/assets/pixel.css", http.StatusOK, "body {}"},
+		{"/voter/missing.js", http.StatusNotFound, ""},
+		{"/assets/missing", http.StatusNotFound, ""},
+		{"/api/v1/missing", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+		if w.Code != tt.wantCode {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.wantCode)
+		}
+
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Errorf("GET %s = %q, want %q", tt.path, w.Body.String(), tt.wantBody)
+		}
+	}
+}
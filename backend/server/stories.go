@@ -0,0 +1,187 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultStoryID names the story of a server started with a single story.
+const defaultStoryID = "default"
+
+// StoryBundle is one adventure a server can host: a story index and the
+// directory holding its chapters.
+type StoryBundle struct {
+	ID         string
+	StoryPath  string
+	ContentDir string
+}
+
+// DiscoverStories finds the story bundles in dir. A bundle is a subdirectory
+// with a story.yaml; its chapters live in a chapters directory next to it, or
+// beside story.yaml when there is none. Bundles are sorted by ID.
+func DiscoverStories(dir string) ([]StoryBundle, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content directory: %w", err)
+	}
+
+	var bundles []StoryBundle
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		root := filepath.Join(dir, entry.Name())
+
+		storyPath := filepath.Join(root, "story.yaml")
+		if _, err := os.Stat(storyPath); err != nil {
+			continue
+		}
+
+		contentDir := filepath.Join(root, "chapters")
+		if info, err := os.Stat(contentDir); err != nil || !info.IsDir() {
+			contentDir = root
+		}
+
+		bundles = append(bundles, StoryBundle{ID: entry.Name(), StoryPath: storyPath, ContentDir: contentDir})
+	}
+
+	slices.SortFunc(bundles, func(a, b StoryBundle) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return bundles, nil
+}
+
+// story returns the bundle with the given ID.
+func (s *Server) story(id string) (StoryBundle, bool) {
+	for _, bundle := range s.stories {
+		if bundle.ID == id {
+			return bundle, true
+		}
+	}
+
+	return StoryBundle{}, false
+}
+
+// handleListStories lists the stories this server hosts and which one is active.
+func (s *Server) handleListStories(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type storyInfo struct {
+		ID       string `json:"id"`
+		Title    string `json:"title,omitempty"`
+		Start    string `json:"start,omitempty"`
+		Chapters int    `json:"chapters"`
+		Active   bool   `json:"active"`
+		Error    string `json:"error,omitempty"`
+	}
+
+	out := make([]storyInfo, 0, len(s.stories))
+
+	for _, bundle := range s.stories {
+		info := storyInfo{ID: bundle.ID, Active: bundle.ID == s.activeStory}
+
+		engine := s.storyEngine
+		if !info.Active {
+			var err error
+			if engine, err = s.newStoryEngine(bundle.StoryPath, bundle.ContentDir); err != nil {
+				info.Error = err.Error()
+				out = append(out, info)
+
+				continue
+			}
+		}
+
+		info.Title = engine.Story.Title
+		info.Start = engine.Story.Flow.Start
+		info.Chapters = len(engine.Story.Nodes)
+		out = append(out, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"active":  s.activeStory,
+		"stories": out,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleActivateStory switches the server to another story and restarts it.
+// Switching is refused while a vote is running.
+func (s *Server) handleActivateStory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	bundle, ok := s.story(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown story %q", id), http.StatusNotFound)
+
+		return
+	}
+
+	if s.voteManager.IsVotingActive() {
+		http.Error(w, "cannot switch stories while a vote is running", http.StatusConflict)
+
+		return
+	}
+
+	engine, err := s.newStoryEngine(bundle.StoryPath, bundle.ContentDir)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load story %q: %v", id, err), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	for _, err := range engine.ValidateStory() {
+		requestLogger(r).Warn("Story validation warning", "story", id, "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.activeStory
+	s.storyEngine = engine
+	s.storyPath = bundle.StoryPath
+	s.activeStory = bundle.ID
+
+	chapter, err := s.restartStory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	requestLogger(r).Info("Story activated", "story", id, "previous", previous, "chapter_id", s.currentNode)
+
+	s.voteManager.BroadcastMessage("story_changed", map[string]any{
+		"story": id,
+		"title": engine.Story.Title,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"story":    id,
+		"id":       s.currentNode,
+		"metadata": chapter.Metadata,
+		"content":  chapter.Content,
+		"notes":    chapter.Notes,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
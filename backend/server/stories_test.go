@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// writeStoryBundles creates two story bundles, one keeping its chapters in a
+// chapters directory and one keeping them next to story.yaml.
+func writeStoryBundles(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	files := map[string]string{
+		"heist/story.yaml":          "title: The Great Heist\nstart: vault",
+		"heist/chapters/vault.md":   "---\nid: vault\ntype: game-over\n---\n# The vault",
+		"space/story.yaml":          "start: launch",
+		"space/launch.md":           "---\nid: launch\ntype: story\nnext: orbit\n---\n# Launch",
+		"space/orbit.md":            "---\nid: orbit\ntype: terminal\n---\n# Orbit",
+		"notes/README.md":           "not a story",
+		"loose-file-at-the-root.md": "ignored",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	return root
+}
+
+func TestDiscoverStories(t *testing.T) {
+	root := writeStoryBundles(t)
+
+	bundles, err := DiscoverStories(root)
+	if err != nil {
+		t.Fatalf("DiscoverStories() error = %v", err)
+	}
+
+	want := []StoryBundle{
+		{ID: "heist", StoryPath: filepath.Join(root, "heist", "story.yaml"), ContentDir: filepath.Join(root, "heist", "chapters")},
+		{ID: "space", StoryPath: filepath.Join(root, "space", "story.yaml"), ContentDir: filepath.Join(root, "space")},
+	}
+
+	if len(bundles) != len(want) {
+		t.Fatalf("DiscoverStories() = %+v, want %+v", bundles, want)
+	}
+
+	for i := range want {
+		if bundles[i] != want[i] {
+			t.Errorf("bundle %d = %+v, want %+v", i, bundles[i], want[i])
+		}
+	}
+}
+
+func TestSwitchStories(t *testing.T) {
+	root := writeStoryBundles(t)
+
+	bundles, err := DiscoverStories(root)
+	if err != nil {
+		t.Fatalf("DiscoverStories() error = %v", err)
+	}
+
+	mockFS := fstest.MapFS{"index.html": &fstest.MapFile{Data: []byte("<html></html>")}}
+
+	server, err := NewServer(bundles[0].StoryPath, bundles[0].ContentDir, mockFS, "", "", false, WithStories(bundles))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stories", nil))
+
+	var list struct {
+		Active  string `json:"active"`
+		Stories []struct {
+			ID       string `json:"id"`
+			Title    string `json:"title"`
+			Chapters int    `json:"chapters"`
+			Active   bool   `json:"active"`
+		} `json:"stories"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode stories: %v", err)
+	}
+
+	if list.Active != "heist" || len(list.Stories) != 2 || list.Stories[0].Title != "The Great Heist" || list.Stories[1].Chapters != 2 {
+		t.Fatalf("stories = %+v, want heist active and both stories listed", list)
+	}
+
+	activate := func(id string) int {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/stories/"+id+"/activate", nil))
+
+		return w.Code
+	}
+
+	if code := activate("missing"); code != http.StatusNotFound {
+		t.Errorf("activate(missing) status = %d, want %d", code, http.StatusNotFound)
+	}
+
+	server.voteManager.StartVoting("vault", []string{"a"}, time.Minute, nil)
+
+	if code := activate("space"); code != http.StatusConflict {
+		t.Errorf("activate during a vote status = %d, want %d", code, http.StatusConflict)
+	}
+
+	server.voteManager.EndVoting()
+
+	if code := activate("space"); code != http.StatusOK {
+		t.Fatalf("activate(space) status = %d, want %d", code, http.StatusOK)
+	}
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	if server.activeStory != "space" || server.currentNode != "launch" {
+		t.Errorf("active story = %q at %q, want space at launch", server.activeStory, server.currentNode)
+	}
+}
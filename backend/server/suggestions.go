@@ -0,0 +1,279 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxSuggestionLength   = 140
+	maxPendingSuggestions = 200
+	suggestionMinInterval = 10 * time.Second // per connection
+)
+
+// Suggestion moderation states.
+const (
+	SuggestionPending  = "pending"
+	SuggestionApproved = "approved"
+)
+
+var (
+	errSuggestionNotFound = errors.New("suggestion not found")
+	errSuggestionDecided  = errors.New("suggestion was already moderated")
+	errSuggestingTooFast  = errors.New("suggesting too fast")
+	errSuggestionBoxFull  = errors.New("too many suggestions waiting for moderation")
+)
+
+// Suggestion is free text from the audience, such as a name for the pod.
+// Nothing reaches other voters before a presenter approves it.
+type Suggestion struct {
+	ID          string    `json:"id"`
+	Text        string    `json:"text"`
+	Status      string    `json:"status"`
+	ClientID    string    `json:"client_id"` // connection it came from, for disconnecting abusers
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// SuggestionBox holds suggestions until a presenter approves or dismisses
+// them. Approved ones are kept so late presenters can see what was shown.
+type SuggestionBox struct {
+	mu          sync.Mutex
+	suggestions []Suggestion
+	last        map[string]time.Time // client ID -> when it last suggested
+}
+
+// NewSuggestionBox creates an empty suggestion box.
+func NewSuggestionBox() *SuggestionBox {
+	return &SuggestionBox{last: make(map[string]time.Time)}
+}
+
+// Add queues a suggestion from a client for moderation.
+func (sb *SuggestionBox) Add(clientID, text string) (Suggestion, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	now := time.Now()
+	if at, ok := sb.last[clientID]; ok && now.Sub(at) < suggestionMinInterval {
+		return Suggestion{}, errSuggestingTooFast
+	}
+
+	pending := 0
+
+	for _, suggestion := range sb.suggestions {
+		if suggestion.Status == SuggestionPending {
+			pending++
+		}
+	}
+
+	if pending >= maxPendingSuggestions {
+		return Suggestion{}, errSuggestionBoxFull
+	}
+
+	suggestion := Suggestion{
+		ID:          newRequestID(),
+		Text:        text,
+		Status:      SuggestionPending,
+		ClientID:    clientID,
+		SubmittedAt: now,
+	}
+
+	sb.last[clientID] = now
+	sb.suggestions = append(sb.suggestions, suggestion)
+
+	return suggestion, nil
+}
+
+// List returns the suggestions with the given status, oldest first, or all
+// of them for an empty status.
+func (sb *SuggestionBox) List(status string) []Suggestion {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	out := []Suggestion{}
+
+	for _, suggestion := range sb.suggestions {
+		if status == "" || suggestion.Status == status {
+			out = append(out, suggestion)
+		}
+	}
+
+	return out
+}
+
+// Approve marks a pending suggestion as approved and returns it.
+func (sb *SuggestionBox) Approve(id string) (Suggestion, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	i, err := sb.pending(id)
+	if err != nil {
+		return Suggestion{}, err
+	}
+
+	sb.suggestions[i].Status = SuggestionApproved
+
+	return sb.suggestions[i], nil
+}
+
+// Dismiss drops a pending suggestion.
+func (sb *SuggestionBox) Dismiss(id string) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	i, err := sb.pending(id)
+	if err != nil {
+		return err
+	}
+
+	sb.suggestions = append(sb.suggestions[:i], sb.suggestions[i+1:]...)
+
+	return nil
+}
+
+// pending finds a pending suggestion. Callers must hold sb.mu.
+func (sb *SuggestionBox) pending(id string) (int, error) {
+	for i, suggestion := range sb.suggestions {
+		if suggestion.ID != id {
+			continue
+		}
+
+		if suggestion.Status != SuggestionPending {
+			return -1, errSuggestionDecided
+		}
+
+		return i, nil
+	}
+
+	return -1, errSuggestionNotFound
+}
+
+// suggestionRequest is an incoming {"type":"suggestion"} WebSocket message.
+type suggestionRequest struct {
+	Text string `json:"text"`
+}
+
+// handleSuggestion queues audience text for moderation and tells the
+// presenters about it.
+func (s *Server) handleSuggestion(client *Client, data []byte) error {
+	var req suggestionRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	text := strings.Join(strings.Fields(req.Text), " ")
+	if text == "" {
+		return errors.New("empty suggestion")
+	}
+
+	if utf8.RuneCountInString(text) > maxSuggestionLength {
+		return errors.New("suggestion too long")
+	}
+
+	suggestion, err := s.suggestions.Add(client.ID, text)
+	if err != nil {
+		return err
+	}
+
+	s.voteManager.BroadcastToRole(RolePresenter, "suggestion_received", map[string]any{
+		"suggestion": suggestion,
+	})
+
+	return nil
+}
+
+// handleListSuggestions returns the suggestions waiting for moderation, or
+// those with the ?status= given, "all" for every one.
+func (s *Server) handleListSuggestions(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	switch status {
+	case "":
+		status = SuggestionPending
+	case "all":
+		status = ""
+	case SuggestionPending, SuggestionApproved:
+	default:
+		http.Error(w, "unknown status", http.StatusBadRequest)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"suggestions": s.suggestions.List(status),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleApproveSuggestion shows a suggestion to everyone.
+func (s *Server) handleApproveSuggestion(w http.ResponseWriter, r *http.Request) {
+	suggestion, err := s.suggestions.Approve(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), suggestionErrorStatus(err))
+
+		return
+	}
+
+	requestLogger(r).Info("Suggestion approved", "suggestion_id", suggestion.ID, "text", suggestion.Text)
+
+	s.voteManager.BroadcastMessage("suggestion_approved", map[string]any{
+		"id":   suggestion.ID,
+		"text": suggestion.Text,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(suggestion); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// handleDismissSuggestion drops a suggestion without showing it.
+func (s *Server) handleDismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := s.suggestions.Dismiss(id); err != nil {
+		http.Error(w, err.Error(), suggestionErrorStatus(err))
+
+		return
+	}
+
+	requestLogger(r).Info("Suggestion dismissed", "suggestion_id", id)
+
+	s.voteManager.BroadcastToRole(RolePresenter, "suggestion_dismissed", map[string]any{
+		"id": id,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status": "dismissed",
+		"id":     id,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// suggestionErrorStatus maps moderation errors to HTTP status codes.
+func suggestionErrorStatus(err error) int {
+	if errors.Is(err, errSuggestionNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusConflict
+}
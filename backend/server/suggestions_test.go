@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSuggestionBox(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	alice := &Client{ID: "alice", Role: RoleVoter}
+	bob := &Client{ID: "bob", Role: RoleVoter}
+
+	tests := []struct {
+		name    string
+		client  *Client
+		message string
+		wantErr bool
+	}{
+		{"suggestion", alice, `{"type":"suggestion","text":"  pod   mcpodface "}`, false},
+		{"too fast", alice, `{"type":"suggestion","text":"another one"}`, true},
+		{"empty", bob, `{"type":"suggestion","text":"   "}`, true},
+		{"too long", bob, `{"type":"suggestion","text":"` + strings.Repeat("a", maxSuggestionLength+1) + `"}`, true},
+		{"another client", bob, `{"type":"suggestion","text":"kube-kube"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.handleClientMessage(tt.client, []byte(tt.message))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleClientMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	list := func(query string) []Suggestion {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/suggestions"+query, nil))
+
+		var body struct {
+			Suggestions []Suggestion `json:"suggestions"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode suggestions: %v", err)
+		}
+
+		return body.Suggestions
+	}
+
+	pending := list("")
+	if len(pending) != 2 || pending[0].Text != "pod mcpodface" || pending[0].ClientID != "alice" {
+		t.Fatalf("pending = %+v, want both suggestions, the first one tidied", pending)
+	}
+
+	moderate := func(id, action string) int {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/suggestions/"+id+"/"+action, nil))
+
+		return w.Code
+	}
+
+	if code := moderate(pending[0].ID, "approve"); code != http.StatusOK {
+		t.Errorf("approve status = %d, want %d", code, http.StatusOK)
+	}
+
+	if code := moderate(pending[0].ID, "dismiss"); code != http.StatusConflict {
+		t.Errorf("dismissing an approved suggestion status = %d, want %d", code, http.StatusConflict)
+	}
+
+	if code := moderate(pending[1].ID, "dismiss"); code != http.StatusOK {
+		t.Errorf("dismiss status = %d, want %d", code, http.StatusOK)
+	}
+
+	if code := moderate("missing", "approve"); code != http.StatusNotFound {
+		t.Errorf("approving an unknown suggestion status = %d, want %d", code, http.StatusNotFound)
+	}
+
+	if got := list(""); len(got) != 0 {
+		t.Errorf("pending after moderation = %+v, want none", got)
+	}
+
+	if got := list("?status=all"); len(got) != 1 || got[0].Status != SuggestionApproved {
+		t.Errorf("all suggestions = %+v, want the approved one", got)
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"log/slog"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// VoteResults returns the decided votes of the current run, oldest first.
+func (vm *VoteManager) VoteResults() []parser.VoteResult {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	out := make([]parser.VoteResult, 0, len(vm.ballotHistory))
+	for _, record := range vm.ballotHistory {
+		result := parser.VoteResult{
+			Question: record.QuestionID,
+			Winner:   record.Winner,
+			Override: record.Override,
+			Votes:    len(record.Ballots),
+			Results:  make(map[string]int),
+		}
+
+		for _, choiceID := range record.Ballots {
+			result.Results[choiceID]++
+		}
+
+		out = append(out, result)
+	}
+
+	return out
+}
+
+// VoterCount returns the number of connected voters.
+func (vm *VoteManager) VoterCount() int {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	return vm.presence().Voters
+}
+
+// templateData collects what chapter templates can use for the given state.
+// Callers must hold s.mu.
+func (s *Server) templateData(state parser.State) parser.TemplateData {
+	data := parser.TemplateData{
+		Vars:       state,
+		VoterCount: s.voteManager.VoterCount(),
+		Votes:      s.voteManager.VoteResults(),
+	}
+
+	for i, vote := range data.Votes {
+		data.Votes[i].WinnerLabel = vote.Winner
+
+		decision, err := s.storyEngine.GetChapter(vote.Question)
+		if err != nil {
+			continue
+		}
+
+		for _, choice := range decision.Metadata.Choices {
+			if choice.ID == vote.Winner {
+				data.Votes[i].WinnerLabel = choice.Label
+			}
+		}
+	}
+
+	if n := len(data.Votes); n > 0 {
+		data.LastWinner = data.Votes[n-1].Winner
+		data.LastWinnerLabel = data.Votes[n-1].WinnerLabel
+	}
+
+	return data
+}
+
+// present prepares a chapter for clients: choices are locked by the inventory
+// and templated content is rendered with the current story state. A template
+// that fails to render keeps its parsed content. Callers must hold s.mu.
+func (s *Server) present(chapter *parser.Chapter, state parser.State) *parser.Chapter {
+	chapter = withInventory(chapter, state)
+	if !chapter.IsTemplate() {
+		return chapter
+	}
+
+	content, err := chapter.Render(s.templateData(state))
+	if err != nil {
+		slog.Warn("Failed to render chapter template", "chapter_id", chapter.Metadata.ID, "error", err)
+
+		return chapter
+	}
+
+	out := *chapter
+	out.Content = content
+
+	return &out
+}
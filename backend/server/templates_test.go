@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplatedChapter(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	pathA := `---
+id: path-a
+type: story
+set: {crew_morale: 7}
+---
+# Path A
+
+{{.Votes | len}} vote so far: you chose {{.LastWinnerLabel}} ({{.LastWinner}}) with morale {{.Vars.crew_morale}}.`
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "path-a.md"), []byte(pathA), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	post := func(path string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(data)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %s", path, w.Code, w.Body.String())
+		}
+
+		return w
+	}
+
+	post("/api/v1/restart", nil)
+	post("/api/v1/advance", map[string]any{})
+	post("/api/v1/start-voting", map[string]any{
+		"question_id": "choice1",
+		"choices":     []string{"opt-a", "opt-b"},
+		"duration":    60,
+	})
+
+	if err := server.voteManager.SubmitVote("voter-1", "opt-a"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	server.voteManager.EndVoting()
+
+	const want = "1 vote so far: you chose Option A (opt-a) with morale 7."
+
+	var advanced struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(post("/api/v1/advance", map[string]any{"choice_id": "opt-a"}).Body).Decode(&advanced); err != nil {
+		t.Fatalf("failed to decode advance: %v", err)
+	}
+
+	if !strings.Contains(advanced.Content, want) {
+		t.Errorf("advance content = %s, want it to contain %q", advanced.Content, want)
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/current", nil))
+
+	if !strings.Contains(w.Body.String(), want) {
+		t.Errorf("current chapter = %s, want it to contain %q", w.Body.String(), want)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// thresholdCheck tells whether the leading choice of a vote, which needs a
+// share of the votes to win, reached it.
+type thresholdCheck struct {
+	Choice   string  `json:"choice"`             // leading choice
+	Required float64 `json:"required"`           // share of the votes it needs
+	Share    float64 `json:"share"`              // share of the votes it has
+	Met      bool    `json:"met"`                // whether it wins
+	Fallback string  `json:"fallback,omitempty"` // choice that wins instead when it does not
+}
+
+// choiceThresholds returns the thresholds of the choices up for vote, and the
+// default choice that wins when the leader falls short of its threshold, if
+// it is up for vote too.
+func choiceThresholds(choices []parser.Choice, choiceIDs []string) (map[string]float64, string) {
+	thresholds := make(map[string]float64)
+	fallback := ""
+
+	for _, choice := range choices {
+		if !slices.Contains(choiceIDs, choice.ID) {
+			continue
+		}
+
+		if choice.Threshold > 0 {
+			thresholds[choice.ID] = choice.Threshold
+		}
+
+		if choice.Default {
+			fallback = choice.ID
+		}
+	}
+
+	return thresholds, fallback
+}
+
+// checkThreshold returns whether the leader of the results reaches its
+// threshold, or nil when it has none. Callers must hold vm.mu.
+func (vm *VoteManager) checkThreshold(results map[string]int, leader string) *thresholdCheck {
+	required, ok := vm.thresholds[leader]
+	if !ok {
+		return nil
+	}
+
+	total := 0
+	for _, count := range results {
+		total += count
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	share := float64(results[leader]) / float64(total)
+
+	return &thresholdCheck{
+		Choice:   leader,
+		Required: required,
+		Share:    share,
+		Met:      share >= required,
+		Fallback: vm.fallback,
+	}
+}
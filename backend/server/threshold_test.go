@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestThresholdChoices(t *testing.T) {
+	choices := []parser.Choice{
+		{ID: "big-bang", Threshold: 0.66},
+		{ID: "canary", Default: true},
+		{ID: "wait"},
+	}
+
+	tests := []struct {
+		name       string
+		ballots    map[string]int // choice -> voters
+		wantWinner string
+		wantMet    any // nil when the leader has no threshold
+	}{
+		{"super-majority", map[string]int{"big-bang": 7, "canary": 3}, "big-bang", true},
+		{"exactly the threshold", map[string]int{"big-bang": 66, "wait": 34}, "big-bang", true},
+		{"short of the threshold", map[string]int{"big-bang": 6, "canary": 1, "wait": 3}, "canary", false},
+		{"leader without a threshold", map[string]int{"big-bang": 2, "wait": 5}, "wait", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVoteManager()
+
+			vm.StartVotingWithChoices("migrate", []string{"big-bang", "canary", "wait"}, choices, "", time.Minute, nil)
+
+			n := 0
+
+			for choice, voters := range tt.ballots {
+				for range voters {
+					n++
+					if err := vm.SubmitVote(fmt.Sprintf("voter-%d", n), choice); err != nil {
+						t.Fatalf("SubmitVote() error = %v", err)
+					}
+				}
+			}
+
+			vm.EndVoting()
+
+			var ended *Message
+
+			for ended == nil {
+				select {
+				case msg := <-vm.broadcast:
+					if msg.Type == "voting_ended" {
+						ended = msg
+					}
+				default:
+					t.Fatal("no voting_ended message")
+				}
+			}
+
+			if winner := ended.Payload["winner"]; winner != tt.wantWinner {
+				t.Errorf("winner = %v, want %q", winner, tt.wantWinner)
+			}
+
+			check, _ := ended.Payload["threshold"].(*thresholdCheck)
+
+			switch {
+			case tt.wantMet == nil && check != nil:
+				t.Errorf("threshold = %+v, want none", check)
+			case tt.wantMet != nil && (check == nil || check.Met != tt.wantMet || check.Fallback != "canary"):
+				t.Errorf("threshold = %+v, want met %v falling back to canary", check, tt.wantMet)
+			}
+		})
+	}
+}
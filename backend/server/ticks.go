@@ -0,0 +1,51 @@
+package server
+
+import (
+	"math"
+	"time"
+)
+
+// tickInterval is how often timer_tick events are sent while a vote runs.
+const tickInterval = time.Second
+
+// remaining is how many whole seconds the active vote has left, rounded up.
+// Callers must hold vm.mu.
+func (vm *VoteManager) remaining(now time.Time) float64 {
+	return math.Ceil(max(vm.deadline.Sub(now), 0).Seconds())
+}
+
+// tick tells every client the time left in the vote of the given round once
+// a second, so countdowns stay right across reconnects and timer changes.
+func (vm *VoteManager) tick(round uint64) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if !vm.sendTick(round, now) {
+			return
+		}
+	}
+}
+
+// sendTick broadcasts one timer_tick and reports whether the vote is still running.
+func (vm *VoteManager) sendTick(round uint64, now time.Time) bool {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if !vm.votingActive || vm.round != round {
+		return false
+	}
+
+	vm.broadcast <- &Message{
+		Type: "timer_tick",
+		Payload: map[string]any{
+			"question_id": vm.vote.id,
+			"remaining":   vm.remaining(now),
+			"duration":    vm.deadline.Sub(vm.startedAt).Seconds(),
+		},
+	}
+
+	vm.recordTimeline(now)
+
+	return true
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestTimerTicks(t *testing.T) {
+	vm := NewVoteManager()
+
+	vm.StartVoting("q", []string{"a", "b"}, 3*time.Second, nil)
+	defer vm.EndVoting()
+
+	timeout := time.After(2 * time.Second)
+
+	for {
+		select {
+		case message := <-vm.broadcast:
+			if message.Type != "timer_tick" {
+				continue
+			}
+
+			if message.Payload["remaining"] != float64(2) || message.Payload["duration"] != float64(3) || message.Payload["question_id"] != "q" {
+				t.Errorf("first timer_tick = %v, want 2 of 3 seconds remaining", message.Payload)
+			}
+
+			return
+		case <-timeout:
+			t.Fatal("no timer_tick within two seconds")
+		}
+	}
+}
+
+func TestStateIncludesRemainingTime(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.voteManager.StartVoting("choice1", []string{"opt-a", "opt-b"}, 30*time.Second, nil)
+	defer server.voteManager.EndVoting()
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	voter, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect voter: %v", err)
+	}
+	defer voter.Close()
+
+	var state Message
+	if err := voter.ReadJSON(&state); err != nil || state.Type != "state" {
+		t.Fatalf("expected state, got %q (%v)", state.Type, err)
+	}
+
+	if remaining, _ := state.Payload["remaining"].(float64); remaining < 29 || remaining > 30 || state.Payload["duration"] != float64(30) {
+		t.Errorf("state = %v, want about 30 of 30 seconds remaining", state.Payload)
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"maps"
+	"time"
+)
+
+// maxTimelinePoints bounds the timeline of one vote, an hour of seconds.
+const maxTimelinePoints = 3600
+
+// TimelinePoint is the vote counts of the active question a whole number of
+// seconds into the vote.
+type TimelinePoint struct {
+	Second  int            `json:"t"`
+	Results map[string]int `json:"results"`
+}
+
+// recordTimeline adds the current counts to the timeline of the active vote
+// and streams the point to presenters, which chart how the vote swings.
+// Callers must hold vm.mu.
+func (vm *VoteManager) recordTimeline(now time.Time) {
+	if len(vm.timeline) >= maxTimelinePoints {
+		return
+	}
+
+	point := TimelinePoint{
+		Second:  int(now.Sub(vm.startedAt).Round(time.Second) / time.Second),
+		Results: maps.Clone(vm.vote.results),
+	}
+
+	// a vote ending right after a tick replaces that second's counts
+	if n := len(vm.timeline); n > 0 && vm.timeline[n-1].Second == point.Second {
+		vm.timeline = vm.timeline[:n-1]
+	}
+
+	vm.timeline = append(vm.timeline, point)
+
+	vm.broadcast <- &Message{
+		Type: "vote_timeline",
+		Payload: map[string]any{
+			"question_id": vm.vote.id,
+			"point":       point,
+		},
+		role: RolePresenter,
+	}
+}
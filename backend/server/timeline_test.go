@@ -0,0 +1,80 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVoteTimeline(t *testing.T) {
+	vm := NewVoteManager()
+
+	vm.StartVoting("choice1", []string{"opt-a", "opt-b"}, time.Minute, nil)
+
+	if err := vm.SubmitVote("voter-1", "opt-a"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	vm.mu.RLock()
+	round, startedAt := vm.round, vm.startedAt
+	vm.mu.RUnlock()
+
+	vm.sendTick(round, startedAt.Add(time.Second))
+
+	if err := vm.SubmitVote("voter-2", "opt-b"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	if err := vm.SubmitVote("voter-3", "opt-b"); err != nil {
+		t.Fatalf("SubmitVote() error = %v", err)
+	}
+
+	vm.sendTick(round, startedAt.Add(2*time.Second+100*time.Millisecond))
+
+	var streamed []TimelinePoint
+
+	for len(vm.broadcast) > 0 {
+		msg := <-vm.broadcast
+		if msg.Type != "vote_timeline" {
+			continue
+		}
+
+		if msg.role != RolePresenter {
+			t.Errorf("vote_timeline sent to %q, want presenters only", msg.role)
+		}
+
+		streamed = append(streamed, msg.Payload["point"].(TimelinePoint))
+	}
+
+	vm.mu.RLock()
+	timeline := vm.timeline
+	vm.mu.RUnlock()
+
+	want := []struct {
+		second int
+		a, b   int
+	}{{0, 0, 0}, {1, 1, 0}, {2, 1, 2}}
+
+	if len(timeline) != len(want) {
+		t.Fatalf("timeline = %+v, want %d points", timeline, len(want))
+	}
+
+	for i, w := range want {
+		got := timeline[i]
+		if got.Second != w.second || got.Results["opt-a"] != w.a || got.Results["opt-b"] != w.b {
+			t.Errorf("point %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if len(streamed) != 2 || streamed[1].Second != 2 {
+		t.Errorf("streamed = %+v, want the points after the start", streamed)
+	}
+
+	vm.ResetVoting()
+
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	if vm.timeline != nil {
+		t.Errorf("timeline after reset = %+v, want none", vm.timeline)
+	}
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultTokenTTL is how long a presenter token from /login stays valid.
+const defaultTokenTTL = time.Hour
+
+// defaultSessionTTL is how long a presenter stays logged in in the browser,
+// long enough for a day of talks.
+const defaultSessionTTL = 12 * time.Hour
+
+// sessionCookie holds the presenter token of a browser session. Rooms add
+// their code, so a browser can be logged in to several.
+const sessionCookie = "presenter_session"
+
+// roleCoPresenter is what credentials of co-presenters grant: presenter
+// access, read-only.
+const roleCoPresenter = "copresenter"
+
+// tokenHeader is the JOSE header of every presenter token; tokens claiming
+// another algorithm are refused.
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// tokenClaims are the claims of a presenter token.
+type tokenClaims struct {
+	Subject   string `json:"sub"`           // RolePresenter or roleCoPresenter
+	Audience  string `json:"aud,omitempty"` // code of the room the token is for, empty for the main show
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// randomKey returns a random 32-byte key, for keys that only need to last as
+// long as the process.
+func randomKey() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	return key
+}
+
+// tokenKey signs presenter tokens. It is derived from the signing secret,
+// never from the stored secrets alone, which may be hashes anyone reading the
+// configuration knows. The secrets are mixed in, so changing one revokes
+// every token issued with it.
+func (s *Server) tokenKey() []byte {
+	secrets := s.presenterSecret + "\x00" + s.coPresenter
+	if s.oidc != nil {
+		secrets += "\x00" + s.oidc.key
+	}
+
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("adventure-voter presenter token\x00" + secrets))
+
+	return mac.Sum(nil)
+}
+
+// issueToken signs a JWT granting role until now plus ttl.
+func (s *Server) issueToken(role string, now time.Time, ttl time.Duration) (string, time.Time, error) {
+	expires := now.Add(ttl)
+
+	claims, err := json.Marshal(tokenClaims{Subject: role, Audience: s.roomCode(), IssuedAt: now.Unix(), ExpiresAt: expires.Unix()})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	return unsigned + "." + s.tokenSignature(unsigned), expires, nil
+}
+
+func (s *Server) tokenSignature(unsigned string) string {
+	mac := hmac.New(sha256.New, s.tokenKey())
+	mac.Write([]byte(unsigned))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyToken returns the role a presenter token grants, or an error when it
+// is malformed, forged or expired.
+func (s *Server) verifyToken(token string, now time.Time) (string, error) {
+	header, rest, ok := strings.Cut(token, ".")
+	if !ok || header != tokenHeader {
+		return "", errors.New("not a presenter token")
+	}
+
+	payload, signature, ok := strings.Cut(rest, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.tokenSignature(header+"."+payload))) {
+		return "", errors.New("invalid token signature")
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	var claims tokenClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return "", fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return "", errors.New("token expired")
+	}
+
+	if claims.Audience != s.roomCode() {
+		return "", errors.New("token is for another room")
+	}
+
+	if claims.Subject != RolePresenter && claims.Subject != roleCoPresenter {
+		return "", fmt.Errorf("unknown token subject %q", claims.Subject)
+	}
+
+	return claims.Subject, nil
+}
+
+// credentialRole returns what a credential grants: RolePresenter for the
+// presenter secret or a presenter token, roleCoPresenter for the
+// co-presenter's, and nothing otherwise. In rooms, the credentials of the
+// main server grant the same as there.
+func (s *Server) credentialRole(credential string) string {
+	if !strings.HasPrefix(credential, tokenHeader+".") {
+		return s.secretRole(credential)
+	}
+
+	role, err := s.verifyToken(credential, time.Now())
+	if err != nil && s.admin != nil {
+		return s.admin.credentialRole(credential)
+	}
+
+	return role
+}
+
+// handleLogin exchanges the presenter or co-presenter secret, sent as
+// {"secret": "..."} or as credentials, for a token to use as a Bearer
+// token on presenter endpoints and as ?token= on the presenter WebSocket.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if s.presenterSecret == "" {
+		http.Error(w, "there is no presenter secret to log in with", http.StatusNotFound)
+
+		return
+	}
+
+	if s.outsidePresenterNetworks(w, r) || s.lockedOut(w, r) {
+		return
+	}
+
+	var req struct {
+		Secret string `json:"secret"`
+	}
+
+	secret := s.presenterCredential(r)
+	if secret == "" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+
+			return
+		}
+
+		secret = req.Secret
+	}
+
+	role := s.secretRole(secret)
+	s.recordLogin(r, secret, role != "")
+
+	if role == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	s.writeToken(w, r, role)
+}
+
+// handleRefreshToken swaps a presenter token that has not expired yet for a
+// fresh one, so a show can run longer than the token TTL.
+func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	role, err := s.verifyToken(s.presenterCredential(r), time.Now())
+	if !s.authRequired() || err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	s.writeToken(w, r, role)
+}
+
+func (s *Server) writeToken(w http.ResponseWriter, r *http.Request, role string) {
+	token, expires, err := s.issueToken(role, time.Now(), s.tokenTTL)
+	if err != nil {
+		requestLogger(r).Error("Failed to issue presenter token", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"token":      token,
+		"token_type": "Bearer",
+		"role":       role,
+		"expires_at": expires.UTC().Format(time.RFC3339),
+		"expires_in": int(s.tokenTTL.Seconds()),
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// cookieName returns the name of a presenter cookie of this server: the
+// name itself on the main server, followed by the room code in rooms.
+func (s *Server) cookieName(name string) string {
+	if code := s.roomCode(); code != "" {
+		return name + "_" + code
+	}
+
+	return name
+}
+
+// session returns the session token of the browser's presenter session and
+// the server that started it: in rooms, the room's session or else one of
+// the main server.
+func (s *Server) session(r *http.Request) (*Server, string, bool) {
+	if cookie, err := r.Cookie(s.cookieName(sessionCookie)); err == nil {
+		return s, cookie.Value, true
+	}
+
+	if s.admin != nil {
+		return s.admin.session(r)
+	}
+
+	return nil, "", false
+}
+
+// startSession logs the browser in with a session cookie granting role.
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, role string) error {
+	token, expires, err := s.issueToken(role, time.Now(), s.sessionTTL)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cookieName(sessionCookie),
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	s.setCSRFCookie(w, r, token, expires)
+
+	return nil
+}
+
+// handleLogout ends the browser's presenter session.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cookieName(sessionCookie),
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cookieName(csrfCookie),
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, s.publicPath("/"), http.StatusSeeOther)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPresenterTokens(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.presenterSecret = "lead-secret"
+	server.coPresenter = "helper-secret"
+
+	login := func(t *testing.T, path, body, auth string) (int, map[string]any) {
+		t.Helper()
+
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var resp map[string]any
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+
+		return w.Code, resp
+	}
+
+	call := func(method, endpoint, token string) int {
+		req := httptest.NewRequest(method, endpoint, bytes.NewBufferString("{}"))
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	code, resp := login(t, "/api/v1/login", `{"secret": "lead-secret"}`, "")
+	if code != http.StatusOK || resp["role"] != RolePresenter || resp["expires_in"] != float64(3600) {
+		t.Fatalf("login = %d %v, want a presenter token lasting an hour", code, resp)
+	}
+
+	token, _ := resp["token"].(string)
+	if strings.Count(token, ".") != 2 {
+		t.Fatalf("token = %q, want a JWT", token)
+	}
+
+	if code := call("POST", "/api/v1/advance", token); code != http.StatusOK {
+		t.Errorf("advance with token = %d, want %d", code, http.StatusOK)
+	}
+
+	if code := call("POST", "/api/v1/control/restart?token="+token, ""); code != http.StatusOK {
+		t.Errorf("control button with token = %d, want %d", code, http.StatusOK)
+	}
+
+	code, resp = login(t, "/api/v1/login", "", "Bearer helper-secret")
+	if code != http.StatusOK || resp["role"] != roleCoPresenter {
+		t.Fatalf("co-presenter login = %d %v", code, resp)
+	}
+
+	helper, _ := resp["token"].(string)
+	if code := call("GET", "/api/v1/story/outline", helper); code != http.StatusOK {
+		t.Errorf("co-presenter reads with token = %d, want %d", code, http.StatusOK)
+	}
+
+	if code := call("POST", "/api/v1/advance", helper); code != http.StatusForbidden {
+		t.Errorf("co-presenter advances with token = %d, want %d", code, http.StatusForbidden)
+	}
+
+	if code, _ := login(t, "/api/v1/login", `{"secret": "nope"}`, ""); code != http.StatusUnauthorized {
+		t.Errorf("login with wrong secret = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	code, resp = login(t, "/api/v1/login/refresh", "", "Bearer "+token)
+	if code != http.StatusOK || resp["role"] != RolePresenter {
+		t.Errorf("refresh = %d %v, want a fresh presenter token", code, resp)
+	}
+
+	if code, _ := login(t, "/api/v1/login/refresh", "", "Bearer lead-secret"); code != http.StatusUnauthorized {
+		t.Errorf("refresh with the secret = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		old, _, err := server.issueToken(RolePresenter, time.Now().Add(-2*time.Hour), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code := call("GET", "/api/v1/story/outline", old); code != http.StatusUnauthorized {
+			t.Errorf("expired token = %d, want %d", code, http.StatusUnauthorized)
+		}
+
+		if code, _ := login(t, "/api/v1/login/refresh", "", "Bearer "+old); code != http.StatusUnauthorized {
+			t.Errorf("refresh of expired token = %d, want %d", code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		header, rest, _ := strings.Cut(helper, ".")
+		_, signature, _ := strings.Cut(rest, ".")
+		claims := `{"sub":"presenter","iat":0,"exp":9999999999}`
+		forged := header + "." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + "." + signature
+
+		if code := call("POST", "/api/v1/advance", forged); code != http.StatusUnauthorized {
+			t.Errorf("forged token = %d, want %d", code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("signed with the secrets alone", func(t *testing.T) {
+		// what anyone reading a hashed secret from the configuration could sign
+		signingKey := server.signingKey
+		server.signingKey = []byte(server.presenterSecret)
+		forged, _, err := server.issueToken(RolePresenter, time.Now(), time.Hour)
+		server.signingKey = signingKey
+
+		if err != nil {
+			t.Fatalf("issueToken() error = %v", err)
+		}
+
+		if code := call("GET", "/api/v1/story/outline", forged); code != http.StatusUnauthorized {
+			t.Errorf("token without the signing secret = %d, want %d", code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("secret rotated", func(t *testing.T) {
+		server.presenterSecret = "new-secret"
+		defer func() { server.presenterSecret = "lead-secret" }()
+
+		if code := call("GET", "/api/v1/story/outline", token); code != http.StatusUnauthorized {
+			t.Errorf("token after rotation = %d, want %d", code, http.StatusUnauthorized)
+		}
+	})
+}
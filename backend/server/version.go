@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// APIVersion is the current version of the HTTP API.
+const APIVersion = "1"
+
+// supportedAPIVersions lists every API version this server can answer.
+var supportedAPIVersions = []string{"1"}
+
+// withAPIVersion negotiates the API version. Clients may pin a version with the
+// API-Version request header; unsupported versions are rejected with 406. Every
+// response carries the version it was served with. Legacy (unversioned) routes
+// are additionally marked deprecated and point at their /api/v1 successor
+// under prefix, the path the server is served at, see publicPath.
+func withAPIVersion(legacy bool, prefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("API-Version", APIVersion)
+
+			if requested := r.Header.Get("API-Version"); requested != "" &&
+				!slices.Contains(supportedAPIVersions, strings.TrimPrefix(strings.ToLower(requested), "v")) {
+				w.Header().Set("API-Supported-Versions", strings.Join(supportedAPIVersions, ", "))
+				http.Error(w, "unsupported API version: "+requested, http.StatusNotAcceptable)
+
+				return
+			}
+
+			if legacy {
+				w.Header().Set("Deprecation", "true")
+				w.Header().Set("Link", `<`+prefix+`/api/v1`+strings.TrimPrefix(r.URL.Path, "/api")+`>; rel="successor-version"`)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestVersionedRoutes(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name           string
+		path           string
+		requested      string
+		wantStatus     int
+		wantDeprecated bool
+	}{
+		{"v1 route", "/api/v1/chapter/current", "", http.StatusOK, false},
+		{"legacy alias", "/api/chapter/current", "", http.StatusOK, true},
+		{"explicit supported version", "/api/v1/chapter/current", "1", http.StatusOK, false},
+		{"v-prefixed version", "/api/v1/chapter/current", "v1", http.StatusOK, false},
+		{"unsupported version", "/api/v1/chapter/current", "2", http.StatusNotAcceptable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.requested != "" {
+				req.Header.Set("API-Version", tt.requested)
+			}
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get("API-Version"); got != APIVersion {
+				t.Errorf("API-Version = %q, want %q", got, APIVersion)
+			}
+
+			if got := w.Header().Get("Deprecation") == "true"; got != tt.wantDeprecated {
+				t.Errorf("deprecated = %v, want %v", got, tt.wantDeprecated)
+			}
+		})
+	}
+}
+
+func TestLegacyRouteSuccessorLink(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/chapter/intro", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	want := `</api/v1/chapter/intro>; rel="successor-version"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// minVetoChoices is how many choices a vote needs for one to be vetoed, so a
+// re-vote always has a choice left to make.
+const minVetoChoices = 3
+
+// vetoResult is a choice struck from a vote by vetoes.
+type vetoResult struct {
+	QuestionID string
+	ChoiceID   string
+	Choices    []string // choices left for the re-vote
+}
+
+// Veto spends the voter's one veto of this run of the story against a choice
+// of the running vote. When the vetoes against it pass the share of voters set
+// with WithVetoes, the vote is called off and the struck choice is returned,
+// for the vote to start again without it.
+func (vm *VoteManager) Veto(voterID, choiceID string) (*vetoResult, error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if vm.vetoShare <= 0 {
+		return nil, errors.New("vetoes are disabled")
+	}
+
+	if !vm.votingActive {
+		return nil, errors.New("no active voting session")
+	}
+
+	results := vm.vote.results
+	if _, ok := results[choiceID]; !ok {
+		return nil, errors.New("invalid choice")
+	}
+
+	if vm.vetoSpent[voterID] {
+		return nil, errors.New("veto already used")
+	}
+
+	if len(results) < minVetoChoices {
+		return nil, errors.New("too few choices left to veto one")
+	}
+
+	vm.vetoSpent[voterID] = true
+	vm.vetoes[voterID] = choiceID
+
+	vetoes := 0
+
+	for _, vetoed := range vm.vetoes {
+		if vetoed == choiceID {
+			vetoes++
+		}
+	}
+
+	// voters from other sources than the WebSocket count once they voted
+	voters := max(vm.presence().Voters, len(vm.vote.voters))
+	if float64(vetoes) <= vm.vetoShare*float64(voters) {
+		return nil, nil
+	}
+
+	vm.votingActive = false
+	vm.onVoteComplete = nil
+
+	vm.vote.stop()
+
+	vm.struck[vm.vote.id] = append(vm.struck[vm.vote.id], choiceID)
+
+	choices := slices.Sorted(maps.Keys(results))
+	choices = slices.DeleteFunc(choices, func(id string) bool { return id == choiceID })
+
+	vm.broadcast <- &Message{
+		Type: "vote_vetoed",
+		Payload: map[string]any{
+			"question_id": vm.vote.id,
+			"choice_id":   choiceID,
+			"vetoes":      vetoes,
+			"voters":      voters,
+			"choices":     choices,
+		},
+	}
+
+	return &vetoResult{
+		QuestionID: vm.vote.id,
+		ChoiceID:   choiceID,
+		Choices:    choices,
+	}, nil
+}
+
+// Struck returns the choices of a question that vetoes took out of the vote.
+func (vm *VoteManager) Struck(questionID string) []string {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	return slices.Clone(vm.struck[questionID])
+}
+
+// ResetVetoes gives every voter their veto back and returns struck choices to
+// their questions, for a new run of the story.
+func (vm *VoteManager) ResetVetoes() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vm.vetoSpent = make(map[string]bool)
+	vm.struck = make(map[string][]string)
+}
+
+// withoutChoices returns the chapter without the given choices.
+func withoutChoices(chapter *parser.Chapter, ids []string) *parser.Chapter {
+	out := *chapter
+	out.Metadata.Choices = slices.DeleteFunc(slices.Clone(chapter.Metadata.Choices), func(choice parser.Choice) bool {
+		return slices.Contains(ids, choice.ID)
+	})
+
+	return &out
+}
+
+// vetoMessage is an incoming {"type":"veto"} WebSocket message.
+type vetoMessage struct {
+	ChoiceID string `json:"choice_id"`
+}
+
+// handleVeto spends the voter's veto, and starts the vote again without the
+// choice when the vetoes strike it.
+func (s *Server) handleVeto(client *Client, data []byte) error {
+	var msg vetoMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	voterID := client.voter()
+	if voterID == "" {
+		return errors.New("veto without voter_id")
+	}
+
+	struck, err := s.voteManager.Veto(voterID, msg.ChoiceID)
+	if err != nil || struck == nil {
+		return err
+	}
+
+	slog.Info("Choice vetoed", "question_id", struck.QuestionID, "choice_id", struck.ChoiceID)
+
+	// the re-vote runs as long as the presenter page starts votes for
+	duration := time.Minute
+
+	s.mu.RLock()
+	chapter, err := s.chapter(struck.QuestionID)
+	s.mu.RUnlock()
+
+	if err == nil && chapter.Metadata.Timer > 0 {
+		duration = time.Duration(chapter.Metadata.Timer) * time.Second
+	}
+
+	return s.startVoting(slog.Default(), struck.QuestionID, struck.Choices, duration)
+}
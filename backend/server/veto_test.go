@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVeto(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	chapter := `---
+id: choice1
+type: decision
+timer: 30
+question: Choose your path
+choices:
+  - id: opt-a
+    label: Option A
+    next: path-a
+  - id: opt-b
+    label: Option B
+    next: path-b
+  - id: opt-c
+    label: Option C
+    next: path-b
+---
+# Choose your path`
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "choice.md"), []byte(chapter), 0600); err != nil {
+		t.Fatalf("failed to write chapter: %v", err)
+	}
+
+	if err := server.reloadStoryEngine(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	server.mu.Lock()
+	server.currentNode = "choice1"
+	server.mu.Unlock()
+
+	vm := server.voteManager
+
+	if _, err := vm.Veto("voter-1", "opt-c"); err == nil {
+		t.Error("expected an error while vetoes are disabled")
+	}
+
+	vm.vetoShare = 0.3
+
+	if err := server.startVoting(slog.Default(), "choice1", []string{"opt-a", "opt-b", "opt-c"}, time.Minute); err != nil {
+		t.Fatalf("startVoting() error = %v", err)
+	}
+	defer vm.EndVoting()
+
+	for i := range 4 {
+		if err := vm.SubmitVote(fmt.Sprintf("voter-%d", i+1), "opt-c"); err != nil {
+			t.Fatalf("SubmitVote() error = %v", err)
+		}
+	}
+
+	voter := &Client{Role: RoleVoter, voterID: "voter-1"}
+
+	if err := server.handleClientMessage(voter, []byte(`{"type":"veto","choice_id":"opt-d"}`)); err == nil {
+		t.Error("expected an error for an unknown choice")
+	}
+
+	// one veto of four voters is not more than 30%
+	if err := server.handleClientMessage(voter, []byte(`{"type":"veto","choice_id":"opt-c"}`)); err != nil {
+		t.Fatalf("veto error = %v", err)
+	}
+
+	if err := server.handleClientMessage(voter, []byte(`{"type":"veto","choice_id":"opt-c"}`)); err == nil {
+		t.Error("expected an error for a second veto of the same voter")
+	}
+
+	if results := vm.GetResults("choice1"); results["opt-c"] != 4 {
+		t.Fatalf("results = %v, want the vote still running", results)
+	}
+
+	voter = &Client{Role: RoleVoter, voterID: "voter-2"}
+	if err := server.handleClientMessage(voter, []byte(`{"type":"veto","choice_id":"opt-c"}`)); err != nil {
+		t.Fatalf("veto error = %v", err)
+	}
+
+	if !vm.IsVotingActive() {
+		t.Fatal("the re-vote did not start")
+	}
+
+	results := vm.GetResults("choice1")
+	if len(results) != 2 || results["opt-a"] != 0 || results["opt-b"] != 0 {
+		t.Errorf("re-vote results = %v, want opt-a and opt-b without ballots", results)
+	}
+
+	if struck := vm.Struck("choice1"); !slices.Equal(struck, []string{"opt-c"}) {
+		t.Errorf("Struck() = %v, want opt-c", struck)
+	}
+
+	voter = &Client{Role: RoleVoter, voterID: "voter-3"}
+	if err := server.handleClientMessage(voter, []byte(`{"type":"veto","choice_id":"opt-a"}`)); err == nil {
+		t.Error("expected an error for a veto with two choices left")
+	}
+
+	// a vote started again by the presenter leaves the struck choice out
+	if err := server.startVoting(slog.Default(), "choice1", []string{"opt-a", "opt-b", "opt-c"}, time.Minute); err != nil {
+		t.Fatalf("startVoting() error = %v", err)
+	}
+
+	if results := vm.GetResults("choice1"); len(results) != 2 {
+		t.Errorf("results = %v, want the struck choice left out", results)
+	}
+
+	vm.ResetVetoes()
+
+	if struck := vm.Struck("choice1"); len(struck) != 0 {
+		t.Errorf("Struck() after reset = %v, want none", struck)
+	}
+
+	if vm.vetoSpent["voter-1"] {
+		t.Error("ResetVetoes did not give the veto back")
+	}
+}
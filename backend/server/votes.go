@@ -2,8 +2,9 @@ package server
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -13,81 +14,216 @@ import (
 
 // VoteManager handles vote aggregation and broadcasting.
 type VoteManager struct {
-	mu              sync.RWMutex
-	currentQuestion string
-	votes           map[string]map[string]int // questionID -> choiceID -> count
-	voters          map[string]string         // voterID -> choiceID (for current question)
-	clients         map[*websocket.Conn]bool
-	broadcast       chan *Message
-	register        chan *websocket.Conn
-	unregister      chan *websocket.Conn
-	timer           *time.Timer
-	timerDuration   time.Duration
-	votingActive    bool
-	onVoteComplete  func(results map[string]int, winner string)
+	mu             sync.RWMutex
+	vote           *ballotBox                // ballots of the story's current question
+	votes          map[string]map[string]int // questionID -> choiceID -> count
+	clients        map[*websocket.Conn]*Client
+	broadcast      chan *Message
+	register       chan *Client
+	unregister     chan *websocket.Conn
+	done           chan struct{} // closed by Stop
+	timerDuration  time.Duration
+	votingActive   bool
+	onVoteComplete func(results map[string]int, winner string)
+	correctChoices map[string]bool            // correct choices of the current question, empty unless it is a quiz
+	thresholds     map[string]float64         // share of the votes choices of the current question need to win
+	fallback       string                     // choice that wins when the leader falls short of its threshold
+	quizAnswers    map[string]map[string]bool // questionID -> voters that answered the quiz correctly
+	maxBonus       int                        // extra vote weight that quiz answers can earn, 0 disables weighting
+	round          uint64                     // incremented for every vote started, so stale timers can tell
+	startedAt      time.Time
+	deadline       time.Time              // when the active vote ends unless its pacing changes that
+	lastBallotAt   time.Time              // when the most recent new voter cast a ballot
+	ballotHistory  []ballotRecord         // every decided question, in order, for voter certificates
+	labels         choiceLabels           // labels of the choices of the current question
+	started        *Message               // voting_started of the current question, replayed to reconnecting clients
+	voterStats     map[string]*voterStats // voterID -> how the voter fared over the session, for the leaderboard
+	tracked        *QuestionStats         // participation in the current question, for analytics
+	questionStats  []QuestionStats        // participation in every question that ended, in order
+	metrics        *voteMetrics
+	polls          map[string]*Poll    // side polls open next to the story's vote, and those that ended
+	vetoShare      float64             // share of voters whose vetoes strike a choice, 0 disables vetoes
+	vetoes         map[string]string   // voterID -> choice vetoed in the current vote
+	vetoSpent      map[string]bool     // voters that used their veto in this run of the story
+	struck         map[string][]string // questionID -> choices vetoes took out of the vote
+	timeline       []TimelinePoint     // counts of the active question once a second, for charts
+	shuffle        bool                // voters get the choices of every vote in their own order
+	abstain        bool                // every vote offers AbstainChoice
+	tieBreak       string              // how a tie between the leaders of a vote is broken, see TieBreakRunoff
+
+	observers []func(*Message) // see every broadcast meant for everyone, such as to forward it to webhooks
+
+	presenceInterval time.Duration
+	presencePending  bool // a presence update is scheduled
+	joined, left     int  // voters that connected and disconnected since the last presence update
 }
 
 // Message represents a WebSocket message.
 type Message struct {
 	Type    string         `json:"type"` // vote, results, state, timer, etc.
 	Payload map[string]any `json:"payload"`
+
+	role         string                       // when set, only clients with this role receive the message
+	to           *Client                      // when set, only this client receives the message
+	translations map[string]map[string]any    // language -> payload sent instead to clients of that language
+	presenter    map[string]any               // payload sent instead to presenters, such as one with speaker notes
+	personal     func(*Client) map[string]any // builds the payload for each client, for messages meant for one client alone
+	received     time.Time                    // when the ballot that caused the message arrived, for metrics
+	shuffle      uint64                       // when set, seeds the order voters get the choices in, see shuffled
+}
+
+// forClient returns the message a client should receive: its personal
+// payload, the presenter payload for presenters, or the translation for its
+// language when there is one, with the choices in the voter's own order for
+// shuffled votes.
+func (m *Message) forClient(client *Client) *Message {
+	if m.personal != nil {
+		return &Message{Type: m.Type, Payload: m.personal(client)}
+	}
+
+	if m.presenter != nil && client.Role == RolePresenter {
+		return &Message{Type: m.Type, Payload: m.presenter}
+	}
+
+	payload, ok := m.translations[client.Lang]
+	if !ok {
+		if m.shuffle == 0 {
+			return m
+		}
+
+		payload = m.Payload
+	}
+
+	if m.shuffle != 0 {
+		payload = shuffled(payload, m.shuffle, client)
+	}
+
+	return &Message{Type: m.Type, Payload: payload}
 }
 
 // NewVoteManager creates a new vote manager.
 func NewVoteManager() *VoteManager {
 	return &VoteManager{
-		votes:      make(map[string]map[string]int),
-		voters:     make(map[string]string),
-		clients:    make(map[*websocket.Conn]bool),
-		broadcast:  make(chan *Message, 256),
-		register:   make(chan *websocket.Conn),
-		unregister: make(chan *websocket.Conn),
+		vote:        newBallotBox("", nil),
+		votes:       make(map[string]map[string]int),
+		quizAnswers: make(map[string]map[string]bool),
+		voterStats:  make(map[string]*voterStats),
+		clients:     make(map[*websocket.Conn]*Client),
+		broadcast:   make(chan *Message, 256),
+		register:    make(chan *Client),
+		unregister:  make(chan *websocket.Conn),
+		done:        make(chan struct{}),
+		metrics:     newVoteMetrics(),
+		polls:       make(map[string]*Poll),
+		vetoes:      make(map[string]string),
+		vetoSpent:   make(map[string]bool),
+		struck:      make(map[string][]string),
+		tieBreak:    TieBreakRandom,
+
+		presenceInterval: presenceInterval,
 	}
 }
 
-// Run starts the vote manager.
+// Run starts the vote manager. It returns once Stop is called.
 func (vm *VoteManager) Run() {
 	for {
 		select {
+		case <-vm.done:
+			return
+
 		case client := <-vm.register:
 			vm.mu.Lock()
-			vm.clients[client] = true
+			vm.clients[client.conn] = client
+			vm.presenceChanged(client, true)
 			vm.mu.Unlock()
 
+			if client.Role == RoleOverlay {
+				vm.sendOverlay(client)
+
+				continue
+			}
+
 			vm.sendState(client)
 
+			for _, message := range client.welcome {
+				if err := client.conn.WriteJSON(message); err != nil {
+					slog.Warn("Error sending welcome message to client", "type", message.Type, "error", err)
+				}
+			}
+
 		case client := <-vm.unregister:
 			vm.mu.Lock()
 
-			if _, ok := vm.clients[client]; ok {
+			if known, ok := vm.clients[client]; ok {
 				delete(vm.clients, client)
+				vm.presenceChanged(known, false)
 				_ = client.Close()
 			}
 
 			vm.mu.Unlock()
 
-		case message := <-vm.broadcast:
+		case message, ok := <-vm.broadcast:
+			if !ok {
+				return
+			}
+
+			if message.role == "" && message.personal == nil && message.to == nil {
+				for _, observe := range vm.observers {
+					observe(message)
+				}
+			}
+
 			vm.mu.RLock()
 
-			clients := make([]*websocket.Conn, 0, len(vm.clients))
-			for client := range vm.clients {
-				clients = append(clients, client)
+			clients := make([]*Client, 0, len(vm.clients))
+
+			var overlays []*Client
+
+			for _, client := range vm.clients {
+				switch {
+				case client.Role == RoleOverlay:
+					// overlays get a snapshot of the vote instead of the events changing it
+					if message.role == "" && message.to == nil && overlayEvents[message.Type] {
+						overlays = append(overlays, client)
+					}
+				case message.role != "" && client.Role != message.role:
+				case message.to != nil && client != message.to:
+				default:
+					clients = append(clients, client)
+				}
+			}
+
+			overlay := &Message{Type: "overlay"}
+			if len(overlays) > 0 {
+				overlay.Payload = vm.overlay(time.Now())
 			}
 
 			vm.mu.RUnlock()
 
 			for _, client := range clients {
-				err := client.WriteJSON(message)
-				if err != nil {
-					log.Printf("Error broadcasting to client: %v", err)
+				vm.deliver(client, message.forClient(client))
+			}
 
-					vm.unregister <- client
-				}
+			for _, client := range overlays {
+				vm.deliver(client, overlay)
+			}
+
+			if !message.received.IsZero() {
+				vm.metrics.updateSent(time.Since(message.received))
 			}
 		}
 	}
 }
 
+// deliver writes a broadcast to one client, dropping the client when that fails.
+func (vm *VoteManager) deliver(client *Client, message *Message) {
+	if err := client.conn.WriteJSON(message); err != nil {
+		slog.Warn("Error broadcasting to client", "type", message.Type, "error", err)
+
+		vm.unregister <- client.conn
+	}
+}
+
 // StartVoting begins a new voting session.
 func (vm *VoteManager) StartVoting(questionID string, choices []string, duration time.Duration, onComplete func(map[string]int, string)) {
 	vm.StartVotingWithChoices(questionID, choices, nil, "", duration, onComplete)
@@ -95,52 +231,132 @@ func (vm *VoteManager) StartVoting(questionID string, choices []string, duration
 
 // StartVotingWithChoices begins a new voting session with full choice metadata.
 func (vm *VoteManager) StartVotingWithChoices(questionID string, choiceIDs []string, choiceObjects []parser.Choice, question string, duration time.Duration, onComplete func(map[string]int, string)) {
+	vm.StartLocalizedVoting(questionID, choiceIDs, choiceObjects, question, nil, duration, onComplete)
+}
+
+// LocalizedQuestion is the question and choices of a vote in one language.
+type LocalizedQuestion struct {
+	Question string
+	Choices  []parser.Choice
+}
+
+// StartLocalizedVoting begins a new voting session like StartVotingWithChoices,
+// showing clients of the languages in translations their own question and
+// choice labels.
+func (vm *VoteManager) StartLocalizedVoting(questionID string, choiceIDs []string, choiceObjects []parser.Choice, question string, translations map[string]LocalizedQuestion, duration time.Duration, onComplete func(map[string]int, string)) {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
-	// reset state
-	vm.currentQuestion = questionID
-	vm.voters = make(map[string]string)
-	vm.votingActive = true
-	vm.timerDuration = duration
-	vm.onVoteComplete = onComplete
+	vm.beginVoting(voteRequest{
+		questionID:    questionID,
+		choiceIDs:     choiceIDs,
+		choiceObjects: choiceObjects,
+		question:      question,
+		translations:  translations,
+		duration:      duration,
+		onComplete:    onComplete,
+	})
+}
 
-	vm.votes[questionID] = make(map[string]int)
-	for _, choice := range choiceIDs {
-		vm.votes[questionID][choice] = 0
-	}
+// beginVoting starts the vote req asks for. Callers must hold vm.mu.
+func (vm *VoteManager) beginVoting(req voteRequest) {
+	vm.vote.stop()
+
+	// every question starts with an empty ballot box
+	vm.vote = newBallotBox(req.questionID, req.choiceIDs)
+	vm.vote.request = req
+	vm.votes[req.questionID] = vm.vote.results
 
-	if vm.timer != nil {
-		vm.timer.Stop()
+	vm.votingActive = true
+	vm.timerDuration = req.duration
+	vm.round++
+	vm.startedAt = time.Now()
+	vm.deadline = vm.startedAt.Add(req.duration)
+	vm.lastBallotAt = vm.startedAt
+	vm.onVoteComplete = req.onComplete
+	vm.labels = newChoiceLabels(req.choiceObjects, req.translations)
+
+	vm.correctChoices = make(map[string]bool)
+	for _, choice := range req.choiceObjects {
+		if choice.Correct {
+			vm.correctChoices[choice.ID] = true
+		}
 	}
 
-	vm.timer = time.AfterFunc(duration, func() {
+	vm.thresholds, vm.fallback = choiceThresholds(req.choiceObjects, req.choiceIDs)
+	vm.vetoes = make(map[string]string)
+
+	vm.timeline = []TimelinePoint{{Results: vm.vote.tally()}}
+
+	vm.vote.timer = time.AfterFunc(req.duration, func() {
 		vm.EndVoting()
 	})
 
+	go vm.tick(vm.round)
+
 	payload := map[string]any{
-		"question_id": questionID,
-		"duration":    duration.Seconds(),
+		"question_id": req.questionID,
+		"duration":    req.duration.Seconds(),
 	}
 
-	if question != "" {
-		payload["question"] = question
+	if req.question != "" {
+		payload["question"] = req.question
 	}
 
-	if len(choiceObjects) > 0 {
-		payload["choices"] = choiceObjects
-	} else {
-		payload["choices"] = choiceIDs
+	if req.runoff {
+		payload["runoff"] = true
 	}
 
-	vm.broadcast <- &Message{
-		Type:    "voting_started",
-		Payload: payload,
+	// a story's own choice by that name wins
+	offerAbstain := vm.abstain && !slices.Contains(req.choiceIDs, AbstainChoice)
+	if offerAbstain {
+		vm.vote.abstain = AbstainChoice
+	}
+
+	switch {
+	case len(req.choiceObjects) > 0 && offerAbstain:
+		payload["choices"] = withAbstain(hideAnswers(req.choiceObjects))
+	case len(req.choiceObjects) > 0:
+		payload["choices"] = hideAnswers(req.choiceObjects)
+	case offerAbstain:
+		payload["choices"] = append(slices.Clone(req.choiceIDs), AbstainChoice)
+	default:
+		payload["choices"] = req.choiceIDs
 	}
+
+	message := &Message{
+		Type:         "voting_started",
+		Payload:      payload,
+		translations: make(map[string]map[string]any, len(req.translations)),
+	}
+
+	for lang, text := range req.translations {
+		localized := maps.Clone(payload)
+		localized["choices"] = hideAnswers(text.Choices)
+
+		if offerAbstain {
+			localized["choices"] = withAbstain(localized["choices"].([]parser.Choice))
+		}
+
+		if text.Question != "" {
+			localized["question"] = text.Question
+		}
+		message.translations[lang] = localized
+	}
+
+	if vm.shuffle {
+		message.shuffle = shuffleSeed()
+	}
+
+	vm.started = message
+	vm.trackStart(req.question)
+	vm.broadcast <- message
 }
 
 // SubmitVote records a vote from a user.
 func (vm *VoteManager) SubmitVote(voterID, choiceID string) error {
+	received := time.Now()
+
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
@@ -148,20 +364,29 @@ func (vm *VoteManager) SubmitVote(voterID, choiceID string) error {
 		return nil
 	}
 
-	if previousChoice, hasVoted := vm.voters[voterID]; hasVoted {
-		if vm.votes[vm.currentQuestion] != nil {
-			vm.votes[vm.currentQuestion][previousChoice]--
-		}
+	// ignore ballots for choices that are not up for vote, such as locked ones
+	if len(vm.vote.results) > 0 && !vm.vote.accepts(choiceID) {
+		return nil
+	}
+
+	previousChoice, hasVoted := vm.vote.voters[voterID]
+	if hasVoted && previousChoice != choiceID && vm.vote.request.locked {
+		return errBallotLocked
 	}
 
-	vm.voters[voterID] = choiceID
-	if vm.votes[vm.currentQuestion] == nil {
-		vm.votes[vm.currentQuestion] = make(map[string]int)
+	weight := vm.weightOf(voterID)
+
+	if !hasVoted {
+		vm.lastBallotAt = time.Now()
 	}
 
-	vm.votes[vm.currentQuestion][choiceID]++
+	vm.trackBallot(hasVoted && previousChoice != choiceID)
+	vm.vote.cast(voterID, choiceID, weight)
 
-	vm.broadcastResults()
+	slog.Debug("Vote received", "question_id", vm.vote.id, "choice_id", choiceID, "weight", weight, "voters", len(vm.vote.voters))
+
+	vm.metrics.voteArrived(received.Sub(vm.startedAt))
+	vm.broadcastResults(received)
 
 	return nil
 }
@@ -171,28 +396,63 @@ func (vm *VoteManager) EndVoting() {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
+	vm.endVoting()
+}
+
+// endVoting ends the current voting session. Callers must hold vm.mu.
+func (vm *VoteManager) endVoting() {
 	if !vm.votingActive {
 		return
 	}
 
 	vm.votingActive = false
 
-	if vm.timer != nil {
-		vm.timer.Stop()
-	}
+	vm.vote.stop()
+
+	vm.recordTimeline(time.Now())
 
-	results := vm.votes[vm.currentQuestion]
+	results := vm.vote.results
 	winner := vm.determineWinner(results)
 
+	// a leader short of its threshold gives way to the default choice
+	threshold := vm.checkThreshold(results, winner)
+	fellBack := threshold != nil && !threshold.Met && threshold.Fallback != ""
+
+	if fellBack {
+		winner = threshold.Fallback
+	}
+
+	// otherwise tied leaders may vote again, and the runoff decides instead
+	if !fellBack && vm.startRunoff(results) {
+		return
+	}
+
+	if len(vm.correctChoices) > 0 {
+		vm.scoreQuiz()
+	}
+
+	vm.recordBallots(winner)
+	vm.trackEnd(results, winner)
+
+	payload := map[string]any{
+		"question_id": vm.vote.id,
+		"results":     results,
+		"winner":      winner,
+	}
+	if threshold != nil {
+		payload["threshold"] = threshold
+	}
+	vm.annotateResults(payload, true)
+
 	vm.broadcast <- &Message{
-		Type: "voting_ended",
-		Payload: map[string]any{
-			"question_id": vm.currentQuestion,
-			"results":     results,
-			"winner":      winner,
-		},
+		Type:    "voting_ended",
+		Payload: payload,
 	}
 
+	vm.sendYourResults(winner)
+	vm.scoreDecision(winner)
+	vm.sendLeaderboard()
+
 	if vm.onVoteComplete != nil {
 		go vm.onVoteComplete(results, winner)
 	}
@@ -213,37 +473,74 @@ func (vm *VoteManager) determineWinner(results map[string]int) string {
 	return winner
 }
 
-// broadcastResults sends current vote counts to all clients.
-func (vm *VoteManager) broadcastResults() {
-	results := make(map[string]int)
+// broadcastResults sends current vote counts to all clients after the ballot
+// received at the given time. Callers must hold vm.mu.
+func (vm *VoteManager) broadcastResults(received time.Time) {
+	results := vm.vote.tally()
 
-	if vm.votes[vm.currentQuestion] != nil {
-		maps.Copy(results, vm.votes[vm.currentQuestion])
+	payload := map[string]any{
+		"question_id": vm.vote.id,
+		"results":     results,
+		"total":       len(vm.vote.voters),
+	}
+	if threshold := vm.checkThreshold(results, vm.determineWinner(results)); threshold != nil {
+		payload["threshold"] = threshold
 	}
+	vm.annotateResults(payload, false)
 
 	vm.broadcast <- &Message{
-		Type: "vote_update",
-		Payload: map[string]any{
-			"question_id": vm.currentQuestion,
-			"results":     results,
-			"total":       len(vm.voters),
-		},
+		Type:     "vote_update",
+		Payload:  payload,
+		received: received,
 	}
 }
 
-// sendState sends the current voting state to a specific client.
-func (vm *VoteManager) sendState(client *websocket.Conn) {
+// sendState sends the current voting state to a specific client. While a
+// vote runs it carries the question and choices, and the client's own ballot
+// when its voter ID has one, so a voter that reloads the page mid-vote picks
+// up where they left off.
+func (vm *VoteManager) sendState(client *Client) {
 	vm.mu.RLock()
 	defer vm.mu.RUnlock()
 
 	state := map[string]any{
 		"voting_active": vm.votingActive,
-		"question_id":   vm.currentQuestion,
+		"question_id":   vm.vote.id,
+	}
+
+	if vm.votingActive {
+		state["results"] = vm.vote.results
+		state["total"] = len(vm.vote.voters)
+		state["remaining"] = vm.remaining(time.Now())
+		state["duration"] = vm.deadline.Sub(vm.startedAt).Seconds()
+		vm.annotateResults(state, false)
+
+		if vm.started != nil {
+			started := vm.started.forClient(client).Payload
+			for _, key := range []string{"question", "choices", "choice_order"} {
+				if value, ok := started[key]; ok {
+					state[key] = value
+				}
+			}
+		}
+
+		if voterID := client.voter(); voterID != "" {
+			if choiceID, ok := vm.vote.voters[voterID]; ok {
+				state["your_choice"] = choiceID
+			}
+		}
+	}
+
+	if client.Role == RolePresenter && len(vm.timeline) > 0 {
+		state["timeline"] = vm.timeline
+	}
+
+	if vm.vetoShare > 0 {
+		state["veto_available"] = !vm.vetoSpent[client.voter()]
 	}
 
-	if vm.votingActive && vm.votes[vm.currentQuestion] != nil {
-		state["results"] = vm.votes[vm.currentQuestion]
-		state["total"] = len(vm.voters)
+	if polls := vm.openPolls(client.voter()); len(polls) > 0 {
+		state["polls"] = polls
 	}
 
 	message := &Message{
@@ -251,9 +548,9 @@ func (vm *VoteManager) sendState(client *websocket.Conn) {
 		Payload: state,
 	}
 
-	err := client.WriteJSON(message)
+	err := client.conn.WriteJSON(message)
 	if err != nil {
-		log.Printf("Error sending state to client: %v", err)
+		slog.Warn("Error sending state to client", "error", err)
 	}
 }
 
@@ -272,13 +569,34 @@ func (vm *VoteManager) GetResults(questionID string) map[string]int {
 }
 
 // RegisterClient adds a WebSocket client.
-func (vm *VoteManager) RegisterClient(conn *websocket.Conn) {
-	vm.register <- conn
+func (vm *VoteManager) RegisterClient(client *Client) {
+	select {
+	case vm.register <- client:
+	case <-vm.done:
+		_ = client.conn.Close()
+	}
 }
 
 // UnregisterClient removes a WebSocket client.
 func (vm *VoteManager) UnregisterClient(conn *websocket.Conn) {
-	vm.unregister <- conn
+	select {
+	case vm.unregister <- conn:
+	case <-vm.done:
+	}
+}
+
+// Stop ends Run for good, for a show that is over, such as a closed room.
+// Clients still connecting are turned away.
+func (vm *VoteManager) Stop() {
+	close(vm.done)
+}
+
+// Connected counts the connected clients by role.
+func (vm *VoteManager) Connected() Presence {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	return vm.presence()
 }
 
 // BroadcastMessage sends a custom message to all clients.
@@ -289,6 +607,183 @@ func (vm *VoteManager) BroadcastMessage(msgType string, payload map[string]any)
 	}
 }
 
+// BroadcastLocalized sends a message to all clients, using the payload of a
+// client's language from translations when there is one. Presenters get the
+// presenter payload instead when it is set.
+func (vm *VoteManager) BroadcastLocalized(msgType string, payload map[string]any, translations map[string]map[string]any, presenter map[string]any) {
+	vm.broadcast <- &Message{
+		Type:         msgType,
+		Payload:      payload,
+		translations: translations,
+		presenter:    presenter,
+	}
+}
+
+// Languages returns the languages connected clients asked for.
+func (vm *VoteManager) Languages() []string {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	var langs []string
+
+	for _, client := range vm.clients {
+		if client.Lang != "" && !slices.Contains(langs, client.Lang) {
+			langs = append(langs, client.Lang)
+		}
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// Clients returns a snapshot of every connected client, oldest first.
+func (vm *VoteManager) Clients() []ClientInfo {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	voted := func(voterID string) bool {
+		_, ok := vm.vote.voters[voterID]
+
+		return ok
+	}
+
+	out := make([]ClientInfo, 0, len(vm.clients))
+	for _, client := range vm.clients {
+		out = append(out, client.info(voted))
+	}
+
+	slices.SortFunc(out, func(a, b ClientInfo) int {
+		return a.JoinedAt.Compare(b.JoinedAt)
+	})
+
+	return out
+}
+
+// DisconnectClient closes the connection with the given client ID. The read
+// loop of that connection then unregisters it. Reports whether it was found.
+func (vm *VoteManager) DisconnectClient(id string) bool {
+	vm.mu.RLock()
+
+	var target *Client
+
+	for _, client := range vm.clients {
+		if client.ID == id {
+			target = client
+
+			break
+		}
+	}
+
+	vm.mu.RUnlock()
+
+	if target == nil {
+		return false
+	}
+
+	target.close(websocket.ClosePolicyViolation, "disconnected by presenter")
+
+	return true
+}
+
+// DisconnectAll closes every client connection, telling clients why.
+func (vm *VoteManager) DisconnectAll(code int, reason string) {
+	vm.mu.RLock()
+	clients := slices.Collect(maps.Values(vm.clients))
+	vm.mu.RUnlock()
+
+	for _, client := range clients {
+		client.close(code, reason)
+	}
+}
+
+// DisconnectRole closes the connection of every client with the given role,
+// telling them why.
+func (vm *VoteManager) DisconnectRole(role string, code int, reason string) {
+	vm.mu.RLock()
+
+	var clients []*Client
+
+	for _, client := range vm.clients {
+		if client.Role == role {
+			clients = append(clients, client)
+		}
+	}
+
+	vm.mu.RUnlock()
+
+	for _, client := range clients {
+		client.close(code, reason)
+	}
+}
+
+// BroadcastToRole sends a custom message only to clients with the given role.
+func (vm *VoteManager) BroadcastToRole(role, msgType string, payload map[string]any) {
+	vm.broadcast <- &Message{
+		Type:    msgType,
+		Payload: payload,
+		role:    role,
+	}
+}
+
+// CurrentQuestion returns the ID of the question being (or last) voted on.
+func (vm *VoteManager) CurrentQuestion() string {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	return vm.vote.id
+}
+
+// OpenChoices returns the choices of the running vote that can be picked, in
+// order, or nil when no vote is running.
+func (vm *VoteManager) OpenChoices() []parser.Choice {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	if !vm.votingActive || vm.started == nil {
+		return nil
+	}
+
+	return openChoices(vm.started.Payload)
+}
+
+// openChoices returns the choices of a voting_started payload that can be
+// picked, in order, labelled with their ID when they have no label.
+func openChoices(payload map[string]any) []parser.Choice {
+	var open []parser.Choice
+
+	switch choices := payload["choices"].(type) {
+	case []parser.Choice:
+		for _, choice := range choices {
+			if choice.Locked {
+				continue
+			}
+
+			if choice.Label == "" {
+				choice.Label = choice.ID
+			}
+
+			open = append(open, choice)
+		}
+	case []string:
+		for _, id := range choices {
+			open = append(open, parser.Choice{ID: id, Label: id})
+		}
+	}
+
+	return open
+}
+
+// HasVoted reports whether the voter has a ballot on the current question.
+func (vm *VoteManager) HasVoted(voterID string) bool {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	_, ok := vm.vote.voters[voterID]
+
+	return ok
+}
+
 // IsVotingActive returns whether voting is currently active.
 func (vm *VoteManager) IsVotingActive() bool {
 	vm.mu.RLock()
@@ -302,6 +797,7 @@ type VoteMessage struct {
 	Type     string `json:"type"`
 	VoterID  string `json:"voter_id"`
 	ChoiceID string `json:"choice_id"`
+	Ack      string `json:"ack,omitempty"` // when set, the sender is sent a vote_ack with it, see Acknowledge
 }
 
 // HandleVoteMessage processes incoming vote messages.
@@ -318,19 +814,28 @@ func (vm *VoteManager) HandleVoteMessage(data []byte) error {
 	return nil
 }
 
+// Acknowledge sends client a vote_ack carrying ack. It goes through the hub
+// after the vote_update the vote caused, so a client that times the ack, such
+// as a load test, measures how long everyone takes to see its vote.
+func (vm *VoteManager) Acknowledge(client *Client, ack string) {
+	vm.broadcast <- &Message{
+		Type:    "vote_ack",
+		Payload: map[string]any{"ack": ack},
+		to:      client,
+	}
+}
+
 // ResetVoting clears all voting state.
 func (vm *VoteManager) ResetVoting() {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
-	if vm.timer != nil {
-		vm.timer.Stop()
-		vm.timer = nil
-	}
+	vm.vote.stop()
 
 	vm.votingActive = false
-	vm.currentQuestion = ""
-	vm.voters = make(map[string]string)
+	vm.vote = newBallotBox("", nil)
+	vm.tracked = nil
+	vm.timeline = nil
 	// clear the history
 	vm.votes = make(map[string]map[string]int)
 	vm.onVoteComplete = nil
@@ -348,17 +853,15 @@ func (vm *VoteManager) ClearQuestionVotes(questionID string) {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
-	if vm.timer != nil {
-		vm.timer.Stop()
-		vm.timer = nil
-	}
+	vm.vote.stop()
 
 	vm.votingActive = false
-	vm.currentQuestion = ""
-	vm.voters = make(map[string]string)
+	vm.vote = newBallotBox("", nil)
+	vm.timeline = nil
 
 	if questionID != "" {
 		delete(vm.votes, questionID)
+		delete(vm.quizAnswers, questionID)
 	}
 
 	vm.onVoteComplete = nil
@@ -20,7 +20,7 @@ func TestNewVoteManager(t *testing.T) {
 		t.Error("votes map should be initialized")
 	}
 
-	if vm.voters == nil {
+	if vm.vote.voters == nil {
 		t.Error("voters map should be initialized")
 	}
 
@@ -51,8 +51,8 @@ func TestStartVoting(t *testing.T) {
 	}
 
 	vm.mu.RLock()
-	if vm.currentQuestion != questionID {
-		t.Errorf("currentQuestion = %q, want %q", vm.currentQuestion, questionID)
+	if vm.vote.id != questionID {
+		t.Errorf("question = %q, want %q", vm.vote.id, questionID)
 	}
 	vm.mu.RUnlock()
 
@@ -110,6 +110,7 @@ func TestSubmitVote(t *testing.T) {
 	vm := NewVoteManager()
 	go vm.Run()
 	defer close(vm.broadcast)
+	defer vm.EndVoting() // stop the timer and ticks before the channel closes
 
 	questionID := "test-question"
 	choices := []string{"choice-a", "choice-b"}
@@ -146,6 +147,7 @@ func TestSubmitVote_ChangeVote(t *testing.T) {
 	vm := NewVoteManager()
 	go vm.Run()
 	defer close(vm.broadcast)
+	defer vm.EndVoting() // stop the timer and ticks before the channel closes
 
 	questionID := "test-question"
 	choices := []string{"choice-a", "choice-b"}
@@ -282,6 +284,7 @@ func TestGetResults(t *testing.T) {
 	vm := NewVoteManager()
 	go vm.Run()
 	defer close(vm.broadcast)
+	defer vm.EndVoting() // stop the timer and ticks before the channel closes
 
 	questionID := "test-question"
 	choices := []string{"choice-a", "choice-b"}
@@ -321,11 +324,11 @@ func TestResetVoting(t *testing.T) {
 	}
 
 	vm.mu.RLock()
-	if vm.currentQuestion != "" {
-		t.Errorf("currentQuestion = %q, want empty", vm.currentQuestion)
+	if vm.vote.id != "" {
+		t.Errorf("question = %q, want empty", vm.vote.id)
 	}
-	if len(vm.voters) != 0 {
-		t.Errorf("voters map should be empty, got %d entries", len(vm.voters))
+	if len(vm.vote.voters) != 0 {
+		t.Errorf("voters map should be empty, got %d entries", len(vm.vote.voters))
 	}
 	if len(vm.votes) != 0 {
 		t.Errorf("votes map should be empty, got %d entries", len(vm.votes))
@@ -364,6 +367,7 @@ func TestHandleVoteMessage(t *testing.T) {
 	vm := NewVoteManager()
 	go vm.Run()
 	defer close(vm.broadcast)
+	defer vm.EndVoting() // stop the timer and ticks before the channel closes
 
 	vm.StartVoting("test-q", []string{"a", "b"}, 1*time.Second, nil)
 
@@ -409,6 +413,7 @@ func TestConcurrentVoting(t *testing.T) {
 	vm := NewVoteManager()
 	go vm.Run()
 	defer close(vm.broadcast)
+	defer vm.EndVoting() // stop the timer and ticks before the channel closes
 
 	questionID := "concurrent-test"
 	choices := []string{"a", "b", "c"}
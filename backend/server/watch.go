@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the burst of events a single save produces.
+const reloadDebounce = 200 * time.Millisecond
+
+// WatchContent reloads the story whenever a chapter or story index of the
+// active story changes on disk, until ctx is done. Every successful reload is
+// broadcast as a content_reloaded event. In dev mode browsers also reload,
+// and do so for changed images and other media of the story, too.
+func (s *Server) WatchContent(ctx context.Context) error {
+	return watchDirs(ctx, "content", s.watchedDirs(), s.contentChanged)
+}
+
+// WatchFrontend tells browsers to reload whenever a file under dir, the
+// frontend served from disk in dev mode, changes, until ctx is done.
+func (s *Server) WatchFrontend(ctx context.Context, dir string) error {
+	var dirs []string
+
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to list frontend directories: %w", err)
+	}
+
+	return watchDirs(ctx, "frontend", dirs, func([]string) {
+		s.reloadClients("frontend")
+	})
+}
+
+// watchDirs calls changed with the files changed in dirs, once per burst of
+// changes, until ctx is done.
+func watchDirs(ctx context.Context, what string, dirs []string, changed func(names []string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create %s watcher: %w", what, err)
+	}
+
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var (
+			debounce <-chan time.Time
+			names    []string
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if !slices.Contains(names, event.Name) {
+					names = append(names, event.Name)
+				}
+
+				debounce = time.After(reloadDebounce)
+			case <-debounce:
+				changed(names)
+				debounce, names = nil, nil
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				slog.Warn("Watcher error", "watching", what, "error", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// contentChanged reloads the story when one of the changed files can affect
+// it. In dev mode, changes to other files of the story, such as images,
+// reload browsers.
+func (s *Server) contentChanged(names []string) {
+	media := false
+
+	for _, name := range names {
+		if !s.isActiveContent(name) {
+			continue
+		}
+
+		if isContentFile(name) {
+			s.hotReload()
+
+			return
+		}
+
+		media = true
+	}
+
+	if media && s.devReload {
+		s.reloadClients("content")
+	}
+}
+
+// reloadClients tells browsers to reload the page, after the frontend or
+// the story changed in dev mode.
+func (s *Server) reloadClients(reason string) {
+	slog.Debug("Reloading browsers", "reason", reason)
+
+	s.voteManager.BroadcastMessage("reload", map[string]any{"reason": reason})
+}
+
+// watchedDirs lists the story index directory and every directory under the
+// content directory, where included partials live, of every story.
+func (s *Server) watchedDirs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var dirs []string
+
+	add := func(dir string) {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, bundle := range s.stories {
+		add(filepath.Dir(bundle.StoryPath))
+
+		_ = filepath.WalkDir(bundle.ContentDir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				add(path)
+			}
+
+			return nil
+		})
+	}
+
+	return dirs
+}
+
+// isContentFile reports whether a change to the file can affect the story.
+func isContentFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".yaml", ".yml":
+		return true
+	}
+
+	return false
+}
+
+// isActiveContent reports whether the file belongs to the story being played.
+func (s *Server) isActiveContent(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dir := filepath.Dir(name)
+	if dir == filepath.Dir(s.storyPath) {
+		return true
+	}
+
+	rel, err := filepath.Rel(s.storyEngine.ContentDir, dir)
+
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// hotReload rebuilds the story from disk and tells every client. A story that
+// fails to load leaves the previous version in place.
+func (s *Server) hotReload() {
+	if err := s.reloadStoryEngine(); err != nil {
+		slog.Warn("Content reload failed, keeping the previous version", "error", err)
+
+		return
+	}
+
+	engine := s.engine()
+
+	for _, err := range engine.ValidateStory() {
+		slog.Warn("Story validation warning", "error", err)
+	}
+
+	s.mu.RLock()
+	currentNode := s.currentNode
+	state := s.vars.Clone()
+
+	payload := map[string]any{
+		"id":       currentNode,
+		"chapters": len(s.storyEngine.Story.Nodes),
+	}
+
+	if chapter, err := s.chapter(currentNode); err == nil {
+		chapter = s.present(chapter, state)
+		payload["metadata"] = chapter.Metadata
+		payload["content"] = chapter.Content
+	}
+
+	s.broadcastChapter("content_reloaded", state, payload)
+	s.mu.RUnlock()
+
+	slog.Info("Content reloaded", "chapters", payload["chapters"], "chapter_id", currentNode)
+
+	if s.devReload {
+		s.reloadClients("content")
+	}
+}
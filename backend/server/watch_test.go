@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestIsContentFile(t *testing.T) {
+	tests := map[string]bool{
+		"chapters/intro.md": true,
+		"story.yaml":        true,
+		"story.YML":         true,
+		"intro.md.swp":      false,
+		"images/door.png":   false,
+	}
+
+	for name, want := range tests {
+		if got := isContentFile(name); got != want {
+			t.Errorf("isContentFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestWatchContent(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := server.WatchContent(ctx); err != nil {
+		t.Fatalf("WatchContent() error = %v", err)
+	}
+
+	updated := "---\nid: path-a\ntype: story\n---\n# Path A, rewritten"
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "path-a.md"), []byte(updated), 0600); err != nil {
+		t.Fatalf("failed to update chapter: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		server.mu.RLock()
+		chapter, err := server.chapter("path-a")
+		server.mu.RUnlock()
+
+		if err == nil && strings.Contains(chapter.Content, "rewritten") {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("story was not reloaded after a chapter changed")
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestWatchContentWhileServing(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := server.WatchContent(ctx); err != nil {
+		t.Fatalf("WatchContent() error = %v", err)
+	}
+
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	// clients keep reading chapters while the watcher reloads the story
+	for _, path := range []string{"/api/v1/chapter/path-a", "/api/v1/chapter/current", "/api/v1/story/graph"} {
+		wg.Go(func() {
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				server.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+			}
+		})
+	}
+
+	for i := range 5 {
+		updated := fmt.Sprintf("---\nid: path-a\ntype: story\n---\n# Path A, version %d", i)
+		if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "path-a.md"), []byte(updated), 0600); err != nil {
+			t.Fatalf("failed to update chapter: %v", err)
+		}
+
+		time.Sleep(150 * time.Millisecond)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chapter/path-a", nil))
+
+		if strings.Contains(w.Body.String(), "version 4") {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("story was not reloaded after a chapter changed")
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	close(done)
+	wg.Wait()
+}
+
+func TestDevReload(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.devReload = true
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	frontend := t.TempDir()
+	if err := os.Mkdir(filepath.Join(frontend, "voter"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.WatchFrontend(ctx, frontend); err != nil {
+		t.Fatalf("WatchFrontend() error = %v", err)
+	}
+
+	if err := server.WatchContent(ctx); err != nil {
+		t.Fatalf("WatchContent() error = %v", err)
+	}
+
+	awaitReload := func(reason string) {
+		t.Helper()
+
+		client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		for {
+			var msg Message
+			if err := client.ReadJSON(&msg); err != nil {
+				t.Fatalf("no reload for a %s change: %v", reason, err)
+			}
+
+			if msg.Type == "reload" {
+				if msg.Payload["reason"] != reason {
+					t.Errorf("reload reason = %v, want %s", msg.Payload["reason"], reason)
+				}
+
+				return
+			}
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(frontend, "voter", "index.html"), []byte("<html>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	awaitReload("frontend")
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "chapters", "door.png"), []byte("png"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	awaitReload("content")
+}
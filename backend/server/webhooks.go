@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	webhookTimeout   = 5 * time.Second
+	webhookQueueSize = 64
+)
+
+// webhookEvents are the broadcasts forwarded to webhooks.
+var webhookEvents = map[string]bool{
+	"chapter_changed": true,
+	"voting_started":  true,
+	"voting_ended":    true,
+	"story_restarted": true,
+}
+
+// WebhookEvent is the JSON body POSTed to every webhook.
+type WebhookEvent struct {
+	Event     string         `json:"event"`
+	Payload   map[string]any `json:"payload"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// Webhooks POSTs story lifecycle events to external systems, such as
+// lighting rigs or demo automation. With a secret, every request carries an
+// X-Adventure-Signature header of "sha256=" and the hex HMAC-SHA256 of the
+// body, so receivers can tell the event came from this server.
+type Webhooks struct {
+	urls   []string
+	secret []byte
+	client *http.Client
+	queue  chan webhookDelivery
+}
+
+type webhookDelivery struct {
+	event string
+	body  []byte
+}
+
+// NewWebhooks starts delivering events to the given URLs, signed with secret
+// unless it is empty.
+func NewWebhooks(urls []string, secret string) *Webhooks {
+	wh := &Webhooks{
+		urls:   urls,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan webhookDelivery, webhookQueueSize),
+	}
+
+	go wh.run()
+
+	return wh
+}
+
+// Notify queues a broadcast for delivery when it is a lifecycle event.
+// Deliveries happen in the background; when the receivers fall too far
+// behind, events are dropped rather than holding up the show.
+func (wh *Webhooks) Notify(message *Message) {
+	if !webhookEvents[message.Type] {
+		return
+	}
+
+	body, err := json.Marshal(WebhookEvent{
+		Event:     message.Type,
+		Payload:   message.Payload,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		slog.Warn("Failed to encode webhook event", "event", message.Type, "error", err)
+
+		return
+	}
+
+	select {
+	case wh.queue <- webhookDelivery{event: message.Type, body: body}:
+	default:
+		slog.Warn("Webhook queue full, dropping event", "event", message.Type)
+	}
+}
+
+// run delivers queued events to every URL in order.
+func (wh *Webhooks) run() {
+	for delivery := range wh.queue {
+		for _, url := range wh.urls {
+			if err := wh.post(url, delivery); err != nil {
+				slog.Warn("Webhook delivery failed", "url", url, "event", delivery.event, "error", err)
+			}
+		}
+	}
+}
+
+func (wh *Webhooks) post(url string, delivery webhookDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Adventure-Event", delivery.event)
+
+	if len(wh.secret) > 0 {
+		req.Header.Set("X-Adventure-Signature", "sha256="+signWebhook(wh.secret, delivery.body))
+	}
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of body.
+func signWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
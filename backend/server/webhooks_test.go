@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhooks(t *testing.T) {
+	received := make(chan *http.Request, 4)
+	bodies := make(chan []byte, 4)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	wh := NewWebhooks([]string{receiver.URL}, "s3cret")
+
+	wh.Notify(&Message{Type: "vote_update", Payload: map[string]any{"results": map[string]int{}}})
+	wh.Notify(&Message{Type: "voting_started", Payload: map[string]any{"question_id": "choice1"}})
+
+	select {
+	case r := <-received:
+		body := <-bodies
+
+		if got := r.Header.Get("X-Adventure-Event"); got != "voting_started" {
+			t.Errorf("X-Adventure-Event = %q, want voting_started", got)
+		}
+
+		if got, want := r.Header.Get("X-Adventure-Signature"), "sha256="+signWebhook([]byte("s3cret"), body); got != want {
+			t.Errorf("X-Adventure-Signature = %q, want %q", got, want)
+		}
+
+		var event WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+
+		if event.Event != "voting_started" || event.Payload["question_id"] != "choice1" || event.Timestamp.IsZero() {
+			t.Errorf("event = %+v, want voting_started for choice1", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+
+	select {
+	case r := <-received:
+		t.Errorf("unexpected %s delivery, only lifecycle events are sent", r.Header.Get("X-Adventure-Event"))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
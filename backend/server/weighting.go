@@ -0,0 +1,105 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// weightingActive reports whether votes on the current question are weighted.
+// Quiz questions, decisions with at least one choice marked correct, are
+// always one voter, one vote; answering them correctly earns extra weight on
+// later decisions, up to the cap set with WithVoteBonus.
+func (vm *VoteManager) weightingActive() bool {
+	return vm.maxBonus > 0 && len(vm.correctChoices) == 0
+}
+
+// weightOf returns how many votes a ballot from voterID counts as.
+func (vm *VoteManager) weightOf(voterID string) int {
+	if !vm.weightingActive() {
+		return 1
+	}
+
+	bonus := 0
+
+	for _, voters := range vm.quizAnswers {
+		if voters[voterID] {
+			bonus++
+		}
+	}
+
+	return 1 + min(bonus, vm.maxBonus)
+}
+
+// scoreQuiz records which voters answered the current quiz question correctly.
+// Scoring the same question again replaces the earlier result.
+func (vm *VoteManager) scoreQuiz() {
+	correct := make(map[string]bool)
+
+	for voterID, choiceID := range vm.vote.voters {
+		if vm.correctChoices[choiceID] {
+			correct[voterID] = true
+		}
+	}
+
+	vm.quizAnswers[vm.vote.id] = correct
+}
+
+// rawResults counts voters per choice on the current question, ignoring weights.
+func (vm *VoteManager) rawResults() map[string]int {
+	results := make(map[string]int)
+	for choiceID := range vm.vote.results {
+		results[choiceID] = 0
+	}
+
+	for _, choiceID := range vm.vote.voters {
+		if !vm.vote.abstaining(choiceID) {
+			results[choiceID]++
+		}
+	}
+
+	return results
+}
+
+// annotateResults adds the unweighted counts next to weighted results, the
+// voters that abstained, and the correct answers once a quiz question has
+// ended, so audiences can see both.
+func (vm *VoteManager) annotateResults(payload map[string]any, ended bool) {
+	if vm.abstain {
+		payload["abstained"] = vm.vote.abstentions()
+	}
+
+	if vm.weightingActive() {
+		payload["weighted"] = true
+		payload["raw_results"] = vm.rawResults()
+	}
+
+	if ended && len(vm.correctChoices) > 0 {
+		correct := make([]string, 0, len(vm.correctChoices))
+		for choiceID := range vm.correctChoices {
+			correct = append(correct, choiceID)
+		}
+
+		slices.Sort(correct)
+		payload["correct"] = correct
+	}
+}
+
+// hideAnswers returns a copy of choices without the quiz answers, so voters
+// cannot look them up while the question is open.
+func hideAnswers(choices []parser.Choice) []parser.Choice {
+	out := slices.Clone(choices)
+	for i := range out {
+		out[i].Correct = false
+	}
+
+	return out
+}
+
+// ResetQuizAnswers forgets every quiz answer, removing all earned vote weight.
+func (vm *VoteManager) ResetQuizAnswers() {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vm.quizAnswers = make(map[string]map[string]bool)
+}
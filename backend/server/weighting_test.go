@@ -0,0 +1,98 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+func TestVoteWeighting(t *testing.T) {
+	vm := NewVoteManager()
+	vm.maxBonus = 1
+	go vm.Run()
+	defer close(vm.broadcast)
+
+	quiz := []parser.Choice{{ID: "yes", Correct: true}, {ID: "no"}}
+
+	// quiz: voter-1 answers correctly, voter-2 and voter-3 do not
+	vm.StartVotingWithChoices("quiz-1", []string{"yes", "no"}, quiz, "", time.Minute, nil)
+	vm.SubmitVote("voter-1", "yes")
+	vm.SubmitVote("voter-2", "no")
+	vm.SubmitVote("voter-3", "no")
+
+	if got := vm.GetResults("quiz-1"); got["no"] != 2 || got["yes"] != 1 {
+		t.Errorf("quiz results = %v, quiz answers must not be weighted", got)
+	}
+
+	vm.EndVoting()
+
+	// a second correct answer must not go beyond the cap
+	vm.StartVotingWithChoices("quiz-2", []string{"yes", "no"}, quiz, "", time.Minute, nil)
+	vm.SubmitVote("voter-1", "yes")
+	vm.EndVoting()
+
+	vm.StartVoting("decision", []string{"left", "right"}, time.Minute, nil)
+	vm.SubmitVote("voter-1", "left")
+	vm.SubmitVote("voter-2", "right")
+
+	// changing a vote moves the full weight
+	vm.SubmitVote("voter-1", "right")
+	vm.SubmitVote("voter-1", "left")
+
+	if got := vm.GetResults("decision"); got["left"] != 2 || got["right"] != 1 {
+		t.Errorf("decision results = %v, want left 2 (weighted) and right 1", got)
+	}
+
+	vm.mu.Lock()
+	raw := vm.rawResults()
+	vm.mu.Unlock()
+
+	if want := map[string]int{"left": 1, "right": 1}; !reflect.DeepEqual(raw, want) {
+		t.Errorf("raw results = %v, want %v", raw, want)
+	}
+
+	vm.EndVoting()
+	vm.ResetQuizAnswers()
+
+	vm.StartVoting("decision-2", []string{"left", "right"}, time.Minute, nil)
+	vm.SubmitVote("voter-1", "left")
+
+	if got := vm.GetResults("decision-2"); got["left"] != 1 {
+		t.Errorf("results after reset = %v, earned weight should be gone", got)
+	}
+
+	vm.EndVoting()
+}
+
+func TestVoteWeighting_Disabled(t *testing.T) {
+	vm := NewVoteManager()
+	go vm.Run()
+	defer close(vm.broadcast)
+
+	vm.StartVotingWithChoices("quiz", []string{"yes"}, []parser.Choice{{ID: "yes", Correct: true}}, "", time.Minute, nil)
+	vm.SubmitVote("voter-1", "yes")
+	vm.EndVoting()
+
+	vm.StartVoting("decision", []string{"left"}, time.Minute, nil)
+	vm.SubmitVote("voter-1", "left")
+
+	if got := vm.GetResults("decision"); got["left"] != 1 {
+		t.Errorf("results = %v, votes must not be weighted without a bonus cap", got)
+	}
+
+	vm.EndVoting()
+}
+
+func TestHideAnswers(t *testing.T) {
+	choices := []parser.Choice{{ID: "yes", Correct: true}}
+
+	if hideAnswers(choices)[0].Correct {
+		t.Error("hideAnswers kept the answer")
+	}
+
+	if !choices[0].Correct {
+		t.Error("hideAnswers must not modify the chapter's choices")
+	}
+}
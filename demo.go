@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// sampleContent embeds the bundled sample adventure used by the demo command.
+//
+//go:embed content
+var sampleContent embed.FS
+
+// runDemo starts the server with the embedded sample adventure and simulated
+// voters, so the full experience can be tried without writing any content.
+func runDemo(args []string) {
+	flags := flag.NewFlagSet("demo", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Comma-separated addresses to listen on: host:port, unix:///path/to.sock, or fd:// for systemd socket activation")
+	voters := flags.Int("voters", 25, "Number of simulated voters taking part in every vote")
+	logFormat := flags.String("log-format", "text", "Log output format: text or json")
+	logLevel := flags.String("log-level", "info", "Log level: debug, info, warn or error")
+
+	_ = flags.Parse(args)
+
+	logger, err := server.NewLogger(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+
+	slog.SetDefault(logger)
+
+	// the story engine reads from disk, so unpack the sample into a scratch directory
+	dir, err := os.MkdirTemp("", "adventure-demo-")
+	if err != nil {
+		fatal("Failed to create demo directory", err)
+	}
+
+	sample, err := fs.Sub(sampleContent, "content")
+	if err != nil {
+		fatal("Failed to get embedded sample content", err)
+	}
+
+	if err := os.CopyFS(dir, sample); err != nil {
+		fatal("Failed to unpack sample content", err)
+	}
+
+	embeddedFS, err := fs.Sub(frontendFS, "frontend")
+	if err != nil {
+		fatal("Failed to get embedded frontend", err)
+	}
+
+	srv, err := server.NewServer(
+		filepath.Join(dir, "story.yaml"),
+		filepath.Join(dir, "chapters"),
+		embeddedFS, "", "", false,
+		server.WithSimulatedVoters(*voters),
+		server.WithBuildInfo(buildInfo()),
+	)
+	if err != nil {
+		fatal("Failed to create server", err)
+	}
+
+	slog.Info("Adventure demo starting...",
+		"content", dir,
+		"simulated_voters", *voters,
+		"voter", localURL(*addr)+"/voter",
+		"presenter", localURL(*addr)+"/presenter",
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := srv.Start(ctx, *addr); err != nil {
+		fatal("Server failed", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// runExport writes the story as a static HTML site, every chapter a page
+// linking to where its choices lead, to publish the adventure after a talk.
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	contentDir := flags.String("content", "content/chapters", "Path to content directory")
+	storyFile := flags.String("story", "content/story.yaml", "Path to story.yaml file")
+	output := flags.String("o", "site", "Directory to write the site to")
+	codeTheme := flags.String("code-theme", parser.DefaultCodeTheme, "Chroma style used to highlight code blocks in chapters")
+	inlineImages := flags.Int64("inline-images", 0, "Embed images up to this many bytes in the pages as data URIs (0 copies them to assets/)")
+
+	_ = flags.Parse(args)
+
+	engine, err := parser.NewStoryEngine(*storyFile, *contentDir,
+		parser.WithCodeTheme(*codeTheme),
+		parser.WithAssetURL("assets/"),
+		parser.WithInlineImages(*inlineImages),
+	)
+	if err != nil {
+		fatal("Failed to load story", err)
+	}
+
+	title := engine.Story.Title
+	if title == "" {
+		title = filepath.Base(filepath.Dir(*storyFile))
+	}
+
+	assets, err := fs.Sub(frontendFS, "frontend/assets")
+	if err != nil {
+		fatal("Failed to get embedded assets", err)
+	}
+
+	if err := engine.Export(*output, title, assets); err != nil {
+		fatal("Failed to export story", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s to %s, open %s\n", title, *output, filepath.Join(*output, "index.html"))
+}
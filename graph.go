@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+)
+
+// runGraph writes the story graph as DOT, Mermaid or an interactive HTML page,
+// to see how the chapters of a story connect without starting the server.
+func runGraph(args []string) {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	contentDir := flags.String("content", "content/chapters", "Path to content directory")
+	storyFile := flags.String("story", "content/story.yaml", "Path to story.yaml file")
+	format := flags.String("format", "dot", "Output format: "+strings.Join(parser.GraphFormats, ", "))
+	output := flags.String("o", "", "File to write the graph to (default stdout)")
+
+	_ = flags.Parse(args)
+
+	engine, err := parser.NewStoryEngine(*storyFile, *contentDir)
+	if err != nil {
+		fatal("Failed to load story", err)
+	}
+
+	chapters, err := engine.AllChapters()
+	if err != nil {
+		fatal("Failed to load chapters", err)
+	}
+
+	title := engine.Story.Title
+	if title == "" {
+		title = filepath.Base(filepath.Dir(*storyFile))
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fatal("Failed to create output file", err)
+		}
+	}
+
+	w := bufio.NewWriter(out)
+
+	if err := parser.WriteGraph(w, *format, title, engine.Story.Flow.Start, chapters); err != nil {
+		fatal("Failed to write graph", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		fatal("Failed to write graph", err)
+	}
+
+	if err := out.Close(); err != nil {
+		fatal("Failed to write graph", err)
+	}
+
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %s graph of %d chapters to %s\n", *format, len(chapters), *output)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// runHashSecret hashes a secret read from stdin, so -presenter-secret and
+// -copresenter-secret need not hold the secret in plain text.
+func runHashSecret(args []string) {
+	flags := flag.NewFlagSet("hash-secret", flag.ExitOnError)
+	useArgon2 := flags.Bool("argon2", false, "Hash with argon2id instead of bcrypt")
+
+	_ = flags.Parse(args)
+
+	fmt.Fprintln(os.Stderr, "Secret to hash:")
+
+	secret, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	secret = strings.TrimRight(secret, "\r\n")
+
+	if secret == "" {
+		fatal("Failed to read secret", errors.Join(err, errors.New("empty secret")))
+	}
+
+	hash, err := server.HashSecret(secret, *useArgon2)
+	if err != nil {
+		fatal("Failed to hash secret", err)
+	}
+
+	fmt.Println(hash) //nolint:forbidigo // command output
+}
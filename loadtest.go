@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// runLoadtest connects bot voters to a running server, lets them vote in
+// the votes the presenter starts and reports how quickly votes were counted.
+// It stops after the given number of votes or on Ctrl-C.
+func runLoadtest(args []string) {
+	flags := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	url := flags.String("url", "http://localhost:8080", "Server to test")
+	bots := flags.Int("bots", 100, "Number of bot voters")
+	ramp := flags.Duration("ramp", 0, "Spread the bots' connections over this long")
+	minDelay := flags.Duration("min-delay", 0, "Earliest a bot votes after a vote starts")
+	maxDelay := flags.Duration("max-delay", 0, "Latest a bot votes after a vote starts")
+	weights := flags.String("weights", "", "Comma-separated relative chances of the choices, in order, such as 60,30,10 (default even)")
+	votes := flags.Int("votes", 1, "Votes to take part in before stopping (0 runs until Ctrl-C)")
+
+	_ = flags.Parse(args)
+
+	lt := &server.LoadTest{
+		URL:      *url,
+		Bots:     *bots,
+		Ramp:     *ramp,
+		MinDelay: *minDelay,
+		MaxDelay: max(*minDelay, *maxDelay),
+		Votes:    *votes,
+	}
+
+	for field := range strings.SplitSeq(*weights, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+
+		weight, err := strconv.ParseFloat(field, 64)
+		if err != nil || weight < 0 {
+			fatal("Invalid weight", fmt.Errorf("%q is not a non-negative number", field))
+		}
+
+		lt.Weights = append(lt.Weights, weight)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := os.Stdout
+
+	fmt.Fprintf(w, "Connecting %d bots to %s, start a vote from the presenter view...\n", lt.Bots, lt.URL)
+
+	report, err := lt.Run(ctx)
+	if err != nil {
+		fatal("Load test failed", err)
+	}
+
+	fmt.Fprintf(w, "\nBots: %d connected, %d failed to connect, %d dropped\n", report.Connected, report.Failed, report.Dropped)
+	fmt.Fprintf(w, "Votes: %d sent, %d counted\n", report.Sent, report.Counted)
+
+	if report.Counted > 0 {
+		fmt.Fprintf(w, "Latency: p50 %v, p90 %v, p99 %v, max %v\n", report.P50, report.P90, report.P99, report.Max)
+	}
+
+	if report.Failed > 0 || report.Dropped > 0 || report.Counted < report.Sent {
+		os.Exit(1)
+	}
+}
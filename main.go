@@ -2,80 +2,112 @@ package main
 
 import (
 	"embed"
-	"flag"
 	"fmt"
-	"io/fs"
-	"log"
-	"path/filepath"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/skarlso/kube_adventures/voting/backend/server"
 )
 
-// version is set at build time via -ldflags.
-var version string
+// version, commit and date are set at build time via -ldflags, as
+// GoReleaser does.
+var (
+	version string
+	commit  string
+	date    string
+)
 
 // Frontend embeds the frontend directory at compile time.
 //
 //go:embed frontend
 var frontendFS embed.FS
 
+// command is a subcommand of the adventure binary.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// commands lists the subcommands in the order help shows them. Without one,
+// or with flags only, as in `adventure -addr :9090`, the binary serves.
+var commands = []command{
+	{"serve", "Run the voting server (default)", runServe},
+	{"demo", "Run the server with the bundled sample adventure and simulated voters", runDemo},
+	{"validate", "Check a story for broken links, unreachable chapters and frontmatter mistakes", runValidate},
+	{"graph", "Draw the story graph as DOT, Mermaid or interactive HTML", runGraph},
+	{"simulate", "Play the story many times without an audience and report the paths taken", runSimulate},
+	{"export", "Write the story as a static site to publish after the talk", runExport},
+	{"report", "Write a Markdown or HTML recap of the runs in a sessions file", runReport},
+	{"pack", "Pack a story into a single .tgz or .zip archive to share", runPack},
+	{"loadtest", "Have bot voters vote on a running server and report latencies", runLoadtest},
+	{"hash-secret", "Hash a presenter secret read from stdin for -presenter-secret", runHashSecret},
+	{"version", "Print the version", runVersion},
+}
+
 func main() {
-	addr := flag.String("addr", ":8080", "HTTP server address")
-	contentDir := flag.String("content", "content/chapters", "Path to content directory")
-	storyFile := flag.String("story", "content/story.yaml", "Path to story.yaml file")
-	presenterSecret := flag.String("presenter-secret", "", "Presenter authentication secret (optional, disables auth if empty)")
-	voterURL := flag.String("voter-url", "", "Public voter URL for QR codes (optional, derived from request when empty)")
-	authorMode := flag.Bool("author", false, "Enable story authoring endpoints (writes to content directory)")
-	versionFlag := flag.Bool("version", false, "Print version and exit")
-
-	flag.Parse()
-
-	if *versionFlag {
-		if version == "" {
-			version = "0.0.0-dev"
-		}
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-		fmt.Println(version) //nolint:forbidigo // version printing
+	if name == "help" {
+		usage(os.Stdout)
 
 		return
 	}
 
-	absContentDir, err := filepath.Abs(*contentDir)
-	if err != nil {
-		log.Fatalf("Failed to resolve content directory: %v", err)
-	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			cmd.run(args)
 
-	absStoryFile, err := filepath.Abs(*storyFile)
-	if err != nil {
-		log.Fatalf("Failed to resolve story file: %v", err)
+			return
+		}
 	}
 
-	// frontend filesystem with "frontend" prefix stripped
-	embeddedFS, err := fs.Sub(frontendFS, "frontend")
-	if err != nil {
-		log.Fatalf("Failed to get embedded frontend: %v", err)
-	}
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
 
-	srv, err := server.NewServer(absStoryFile, absContentDir, embeddedFS, *presenterSecret, *voterURL, *authorMode)
-	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+// usage lists the commands.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: adventure [command] [flags]\n\nCommands:\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
 
-	log.Printf("Adventure server starting...")
-	log.Printf("Content: %s", absContentDir)
-	log.Printf("Story: %s", absStoryFile)
-	log.Printf("Static: embedded")
-	log.Printf("Server: http://localhost%s", *addr)
-	log.Printf("Voter: http://localhost%s/voter", *addr)
-	log.Printf("Presenter: http://localhost%s/presenter", *addr)
-
-	if *presenterSecret != "" {
-		log.Printf("Presenter authentication: ENABLED")
-	} else {
-		log.Printf("Presenter authentication: DISABLED")
+	fmt.Fprintf(w, "\nRun adventure <command> -h for the flags of a command.\n")
+}
+
+// runVersion prints the version the binary was built as.
+func runVersion([]string) {
+	fmt.Println(buildInfo().Version) //nolint:forbidigo // version printing
+}
+
+// buildInfo is what the binary was built as, for GET /api/info.
+func buildInfo() server.BuildInfo {
+	if version == "" {
+		version = "0.0.0-dev"
 	}
 
-	if err := srv.Start(*addr); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	return server.BuildInfo{Version: version, Commit: commit, Date: date}
+}
+
+// atExit holds cleanups fatal runs, last first, since os.Exit skips deferred calls.
+var atExit []func()
+
+// fatal logs msg with err, runs atExit and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+
+	for _, cleanup := range slices.Backward(atExit) {
+		cleanup()
 	}
+
+	os.Exit(1)
 }
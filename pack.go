@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// runPack packs a story into a single archive that serve -story-bundle runs,
+// to share an adventure as one file.
+func runPack(args []string) {
+	flags := flag.NewFlagSet("pack", flag.ExitOnError)
+	contentDir := flags.String("content", "content/chapters", "Path to content directory")
+	storyFile := flags.String("story", "content/story.yaml", "Path to story.yaml file")
+	output := flags.String("o", "adventure.tgz", "Archive to write: "+strings.Join(server.ArchiveFormats, ", "))
+
+	_ = flags.Parse(args)
+
+	out, err := os.Create(*output)
+	if err != nil {
+		fatal("Failed to create archive", err)
+	}
+
+	if err := server.PackStory(out, *output, server.StoryBundle{StoryPath: *storyFile, ContentDir: *contentDir}); err != nil {
+		_ = out.Close()
+		_ = os.Remove(*output)
+
+		fatal("Failed to pack story", err)
+	}
+
+	if err := out.Close(); err != nil {
+		fatal("Failed to write archive", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Packed %s and %s into %s\n", *storyFile, *contentDir, *output)
+}
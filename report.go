@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// runReport writes a recap of the runs in a sessions file as Markdown or
+// HTML, to paste into a blog post after the talk.
+func runReport(args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	sessionsFile := flags.String("sessions", "sessions.json", "Sessions file written by serve -sessions-file")
+	storyFile := flags.String("story", "", "Path to story.yaml file, to show chapter titles and choice labels instead of IDs (optional)")
+	contentDir := flags.String("content", "content/chapters", "Path to content directory")
+	format := flags.String("format", server.ReportMarkdown, "Report format: markdown or html")
+	output := flags.String("o", "", "File to write the report to (default stdout)")
+
+	_ = flags.Parse(args)
+
+	runs, err := server.LoadSessions(*sessionsFile)
+	if err != nil {
+		fatal("Failed to load sessions", err)
+	}
+
+	var (
+		title    string
+		chapters map[string]*parser.Chapter
+	)
+
+	if *storyFile != "" {
+		engine, err := parser.NewStoryEngine(*storyFile, *contentDir)
+		if err != nil {
+			fatal("Failed to load story", err)
+		}
+
+		if chapters, err = engine.AllChapters(); err != nil {
+			fatal("Failed to load chapters", err)
+		}
+
+		title = engine.Story.Title
+		if title == "" {
+			title = filepath.Base(filepath.Dir(*storyFile))
+		}
+	}
+
+	report := server.NewReport(title, runs, chapters)
+
+	if *output == "" {
+		if err := report.Write(os.Stdout, *format); err != nil {
+			fatal("Failed to write report", err)
+		}
+
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, *format); err != nil {
+		fatal("Failed to write report", err)
+	}
+
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil { //nolint:gosec // reports are meant to be shared
+		fatal("Failed to write report", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote a recap of %d runs to %s\n", len(runs), *output)
+}
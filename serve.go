@@ -0,0 +1,459 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// devFrontendDir is where -dev serves the frontend from, relative to the
+// working directory, so edits show without rebuilding.
+const devFrontendDir = "frontend"
+
+// runServe runs the voting server, with the frontend built into the binary or
+// one from disk.
+func runServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Comma-separated addresses to listen on: host:port, unix:///path/to.sock, or fd:// for systemd socket activation")
+	basePath := flags.String("base-path", "", "Path prefix the server is mounted at behind a reverse proxy, such as /adventure (optional, the root when empty)")
+	readHeaderTimeout := flags.Duration("http-read-header-timeout", server.DefaultHTTPLimits.ReadHeaderTimeout, "How long clients may take to send the headers of a request")
+	readTimeout := flags.Duration("http-read-timeout", server.DefaultHTTPLimits.ReadTimeout, "How long clients may take to send a whole request, body included")
+	writeTimeout := flags.Duration("http-write-timeout", server.DefaultHTTPLimits.WriteTimeout, "How long writing a response may take, such as to a slow client (WebSocket connections are exempt)")
+	idleTimeout := flags.Duration("http-idle-timeout", server.DefaultHTTPLimits.IdleTimeout, "How long idle keep-alive connections stay open")
+	maxHeaderBytes := flags.Int("http-max-header-bytes", server.DefaultHTTPLimits.MaxHeaderBytes, "Largest request headers accepted, in bytes")
+	maxBodyBytes := flags.Int64("http-max-body-bytes", server.DefaultHTTPLimits.MaxBodyBytes, "Largest request body accepted, in bytes (0 for no limit)")
+	contentDir := flags.String("content", "content/chapters", "Path to content directory")
+	storyFile := flags.String("story", "content/story.yaml", "Path to story.yaml file")
+	storiesDir := flags.String("stories-dir", "", "Directory of story bundles to host side by side, each a subdirectory with a story.yaml; replaces -story and -content (optional)")
+	storyBundle := flags.String("story-bundle", "", "Story archive made by the pack command to run instead of -story and -content (optional)")
+	presenterSecret := flags.String("presenter-secret", "", "Presenter authentication secret, or a bcrypt or argon2id hash of it from hash-secret (optional, disables auth if empty)")
+	coPresenterSecret := flags.String("copresenter-secret", "", "Secret granting read-only presenter access, such as for a helper watching results and notes (optional)")
+	signingSecret := flags.String("signing-secret", "", "Secret presenter tokens and sessions are signed with; replicas need the same one (optional, random on every start when empty)")
+	tokenTTL := flags.Duration("presenter-token-ttl", time.Hour, "How long presenter tokens from /api/login stay valid before they need refreshing")
+	sessionTTL := flags.Duration("presenter-session-ttl", 12*time.Hour, "How long presenters stay logged in in the browser after logging in at /presenter/login")
+	presenterAllow := flags.String("presenter-allow-from", "", "Comma-separated addresses and CIDR ranges presenters may connect from, such as 10.0.40.0/24 (optional, anywhere when empty)")
+	oidcIssuer := flags.String("oidc-issuer", "", "OpenID Connect issuer presenters log in with instead of a shared secret, such as https://accounts.google.com (optional)")
+	oidcClientID := flags.String("oidc-client-id", "", "Client ID registered at the OpenID Connect provider")
+	oidcClientSecret := flags.String("oidc-client-secret", "", "Client secret registered at the OpenID Connect provider (optional for public clients)")
+	oidcRedirectURL := flags.String("oidc-redirect-url", "", "Callback URL registered at the provider (optional, derived from request when empty)")
+	oidcEmails := flags.String("oidc-allowed-emails", "", "Comma-separated email addresses, or domains as @example.com, that may present (optional, anyone the provider lets in when empty)")
+	voterURL := flags.String("voter-url", "", "Public voter URL for QR codes (optional, derived from request when empty)")
+	authorMode := flags.Bool("author", false, "Enable story authoring endpoints (writes to content directory)")
+	watch := flags.Bool("watch", false, "Reload content automatically when chapter files change")
+	dev := flags.Bool("dev", false, "Development mode: implies -watch, serves the frontend from the frontend directory on disk and reloads browsers when it or the story changes")
+	frontendDir := flags.String("frontend", "", "Serve the frontend from this directory instead of the one built into the binary (optional)")
+	staticDir := flags.String("static-dir", "", "Serve files from this directory in place of the frontend's files by the same path, such as voter/index.html to reskin the voter page (optional)")
+	sessionsFile := flags.String("sessions-file", "", "Path to a JSON file for persisting story runs across restarts (optional)")
+	rehearsal := flags.Bool("rehearsal", false, "Rehearse the show: simulated voters take part, vote timers run faster and no runs are persisted")
+	rehearsalVoters := flags.Int("rehearsal-voters", 25, "Number of simulated voters taking part in every vote while rehearsing")
+	rehearsalSpeed := flags.Float64("rehearsal-speed", 4, "How many times faster vote timers run while rehearsing")
+	webhooks := flags.String("webhooks", "", "Comma-separated URLs that receive story lifecycle events as JSON POSTs (optional)")
+	webhookSecret := flags.String("webhook-secret", "", "Secret for signing webhook requests with HMAC-SHA256 (optional)")
+	slackToken := flags.String("slack-token", "", "Slack bot token for posting votes to a channel (optional)")
+	slackChannel := flags.String("slack-channel", "", "Slack channel ID votes are posted to")
+	slackSigningSecret := flags.String("slack-signing-secret", "", "Signing secret of the Slack app, for authenticating vote clicks")
+	discordToken := flags.String("discord-token", "", "Discord bot token for posting votes to a channel (optional)")
+	discordChannel := flags.String("discord-channel", "", "Discord channel ID votes are posted to")
+	twilioAuthToken := flags.String("twilio-auth-token", "", "Twilio auth token; enables voting by SMS at /api/integrations/sms (optional)")
+	twilioWebhookURL := flags.String("twilio-webhook-url", "", "Public URL configured for incoming messages in Twilio, when a proxy changes it (optional)")
+	leaderElect := flags.Bool("leader-elect", false, "Elect one leader among several replicas through a Kubernetes Lease; the others forward to it")
+	leaderLease := flags.String("leader-lease", "adventure-voter", "Name of the Lease used for leader election")
+	leaderNamespace := flags.String("leader-namespace", "", "Namespace of the Lease (optional, defaults to the pod's namespace)")
+	leaderURL := flags.String("leader-url", "", "URL other replicas reach this one at (optional, defaults to http://$POD_IP plus the -addr port)")
+	voteBonus := flags.Int("vote-bonus", 0, "Extra vote weight voters can earn by answering quiz questions correctly (0 disables)")
+	tieBreak := flags.String("tie-break", server.TieBreakRandom, "How a tie between the leaders of a vote is broken: random, or runoff for a shorter vote between the tied choices")
+	abstain := flags.Bool("abstain", false, "Offer an extra choice to abstain in every vote, counted apart from the other choices")
+	shuffleChoices := flags.Bool("shuffle-choices", false, "Show every voter the choices of a vote in their own order, to reduce position bias")
+	vetoShare := flags.Float64("veto-share", 0, "Share of voters, such as 0.3, whose vetoes strike a choice from a vote; every voter has one veto per run (0 disables)")
+	rooms := flags.Bool("rooms", false, "Let presenters open rooms, further shows with their own story position and votes under /r/{code}, for workshop breakouts")
+	roomIdleTTL := flags.Duration("room-idle-ttl", 2*time.Hour, "Close rooms nobody has used for this long (0 keeps them until closed)")
+	joinCode := flags.Bool("join-code", false, "Voters must enter a join code shown on the presenter screen, which the presenter can rotate to shut out link-sharers")
+	rosterFile := flags.String("roster", "", "CSV or JSON participant roster; only listed participants can vote (optional)")
+	codeTheme := flags.String("code-theme", parser.DefaultCodeTheme, "Chroma style used to highlight code blocks in chapters")
+	assetURL := flags.String("asset-url", parser.DefaultAssetURL, "URL prefix for relative image paths in chapters, for servers mounted under a path prefix")
+	inlineImages := flags.Int64("inline-images", 0, "Embed chapter images up to this many bytes as data URIs (0 disables)")
+	sanitize := flags.Bool("sanitize", false, "Sanitize rendered chapter HTML, for stories from untrusted authors")
+	sanitizeAllow := flags.String("sanitize-allow", "", "Comma-separated extra HTML elements the sanitizer keeps, such as video,iframe[src width height] (optional)")
+	features := flags.String("features", "", "Comma-separated list of experimental features to enable (optional)")
+	logFormat := flags.String("log-format", "text", "Log output format: text or json")
+	logLevel := flags.String("log-level", "info", "Log level: debug, info, warn or error")
+	versionFlag := flags.Bool("version", false, "Print version and exit")
+
+	_ = flags.Parse(args)
+
+	if *versionFlag {
+		runVersion(nil)
+
+		return
+	}
+
+	logger, err := server.NewLogger(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+
+	slog.SetDefault(logger)
+
+	if *storiesDir != "" && *storyBundle != "" {
+		fatal("Invalid story configuration", errors.New("-stories-dir and -story-bundle cannot be used together"))
+	}
+
+	if *storyBundle != "" {
+		dir, err := os.MkdirTemp("", "adventure-bundle-")
+		if err != nil {
+			fatal("Failed to create bundle directory", err)
+		}
+		removeBundle := func() { _ = os.RemoveAll(dir) }
+		atExit = append(atExit, removeBundle)
+
+		defer removeBundle()
+
+		bundle, err := server.OpenStoryArchive(*storyBundle, dir)
+		if err != nil {
+			fatal("Failed to open story bundle", err)
+		}
+
+		*storyFile, *contentDir = bundle.StoryPath, bundle.ContentDir
+	}
+
+	absContentDir, err := filepath.Abs(*contentDir)
+	if err != nil {
+		fatal("Failed to resolve content directory", err)
+	}
+
+	absStoryFile, err := filepath.Abs(*storyFile)
+	if err != nil {
+		fatal("Failed to resolve story file", err)
+	}
+
+	if *dev {
+		*watch = true
+
+		if *frontendDir == "" {
+			if info, err := os.Stat(devFrontendDir); err == nil && info.IsDir() {
+				*frontendDir = devFrontendDir
+			} else {
+				slog.Warn("No frontend directory to serve in dev mode, serving the embedded frontend", "dir", devFrontendDir)
+			}
+		}
+	}
+
+	static := "embedded"
+
+	// frontend filesystem with "frontend" prefix stripped
+	staticFS, err := fs.Sub(frontendFS, "frontend")
+	if err != nil {
+		fatal("Failed to get embedded frontend", err)
+	}
+
+	if *frontendDir != "" {
+		if info, err := os.Stat(*frontendDir); err != nil || !info.IsDir() {
+			fatal("Invalid frontend directory", fmt.Errorf("%s is not a directory", *frontendDir))
+		}
+
+		staticFS = os.DirFS(*frontendDir)
+		static = *frontendDir
+	}
+
+	if *staticDir != "" {
+		if info, err := os.Stat(*staticDir); err != nil || !info.IsDir() {
+			fatal("Invalid static directory", fmt.Errorf("%s is not a directory", *staticDir))
+		}
+
+		staticFS = server.OverlayFS(os.DirFS(*staticDir), staticFS)
+		static = *staticDir + " over " + static
+	}
+
+	if *assetURL == parser.DefaultAssetURL {
+		*assetURL = server.CleanBasePath(*basePath) + parser.DefaultAssetURL
+	}
+
+	engineOpts := []parser.EngineOption{
+		parser.WithCodeTheme(*codeTheme),
+		parser.WithAssetURL(*assetURL),
+		parser.WithInlineImages(*inlineImages),
+	}
+
+	if *sanitize {
+		policy, err := parser.SanitizePolicy(strings.Split(*sanitizeAllow, ",")...)
+		if err != nil {
+			fatal("Invalid sanitizer allow-list", err)
+		}
+
+		engineOpts = append(engineOpts, parser.WithSanitizer(policy))
+	}
+
+	presenterNetworks, err := server.ParseNetworks(*presenterAllow)
+	if err != nil {
+		fatal("Invalid presenter allow-list", err)
+	}
+
+	opts := []server.Option{
+		server.WithFeatures(server.ParseFeatures(*features)),
+		server.WithCoPresenterSecret(*coPresenterSecret),
+		server.WithSigningSecret(*signingSecret),
+		server.WithTokenTTL(*tokenTTL),
+		server.WithSessionTTL(*sessionTTL),
+		server.WithPresenterNetworks(presenterNetworks),
+		server.WithEngineOptions(engineOpts...),
+		server.WithBuildInfo(buildInfo()),
+		server.WithBasePath(*basePath),
+		server.WithHTTPLimits(server.HTTPLimits{
+			ReadHeaderTimeout: *readHeaderTimeout,
+			ReadTimeout:       *readTimeout,
+			WriteTimeout:      *writeTimeout,
+			IdleTimeout:       *idleTimeout,
+			MaxHeaderBytes:    *maxHeaderBytes,
+			MaxBodyBytes:      *maxBodyBytes,
+		}),
+	}
+
+	// a directory of story bundles hosts all of them, starting with the first
+	if *storiesDir != "" {
+		dir, err := filepath.Abs(*storiesDir)
+		if err != nil {
+			fatal("Failed to resolve stories directory", err)
+		}
+
+		bundles, err := server.DiscoverStories(dir)
+		if err == nil && len(bundles) == 0 {
+			err = fmt.Errorf("no story bundles in %s", *storiesDir)
+		}
+
+		if err != nil {
+			fatal("Failed to discover stories", err)
+		}
+
+		absStoryFile = bundles[0].StoryPath
+		absContentDir = bundles[0].ContentDir
+
+		opts = append(opts, server.WithStories(bundles))
+	}
+
+	if *sessionsFile != "" {
+		opts = append(opts, server.WithSessionsFile(*sessionsFile))
+	}
+
+	if *dev {
+		opts = append(opts, server.WithDevReload())
+	}
+
+	if *joinCode {
+		opts = append(opts, server.WithJoinCode())
+	}
+
+	if *rooms {
+		opts = append(opts, server.WithRooms(*roomIdleTTL))
+	}
+
+	if *rosterFile != "" {
+		roster, err := server.LoadRoster(*rosterFile)
+		if err != nil {
+			fatal("Failed to load roster", err)
+		}
+
+		opts = append(opts, server.WithRoster(roster))
+	}
+
+	if *rehearsal {
+		if *rehearsalVoters < 0 || *rehearsalSpeed <= 0 {
+			fatal("Invalid rehearsal configuration", errors.New("voters must not be negative and speed must be positive"))
+		}
+
+		opts = append(opts, server.WithRehearsal(*rehearsalVoters, *rehearsalSpeed))
+	}
+
+	if *webhooks != "" {
+		opts = append(opts, server.WithWebhooks(strings.Split(*webhooks, ","), *webhookSecret))
+	}
+
+	if *slackToken != "" {
+		if *slackChannel == "" || *slackSigningSecret == "" {
+			fatal("Invalid Slack configuration", errors.New("-slack-channel and -slack-signing-secret are required with -slack-token"))
+		}
+
+		opts = append(opts, server.WithSlack(*slackToken, *slackChannel, *slackSigningSecret))
+	}
+
+	if *discordToken != "" {
+		if *discordChannel == "" {
+			fatal("Invalid Discord configuration", errors.New("-discord-channel is required with -discord-token"))
+		}
+
+		opts = append(opts, server.WithDiscord(*discordToken, *discordChannel))
+	}
+
+	if *twilioAuthToken != "" {
+		opts = append(opts, server.WithSMS(*twilioAuthToken, *twilioWebhookURL))
+	}
+
+	if *leaderElect {
+		le, err := newLeaderElection(*leaderLease, *leaderNamespace, *leaderURL, tcpAddr(*addr))
+		if err != nil {
+			fatal("Invalid leader election configuration", err)
+		}
+
+		opts = append(opts, server.WithLeaderElection(le))
+	}
+
+	if *voteBonus > 0 {
+		opts = append(opts, server.WithVoteBonus(*voteBonus))
+	}
+
+	if *shuffleChoices {
+		opts = append(opts, server.WithShuffledChoices())
+	}
+
+	if *abstain {
+		opts = append(opts, server.WithAbstain())
+	}
+
+	switch *tieBreak {
+	case server.TieBreakRandom, server.TieBreakRunoff:
+		opts = append(opts, server.WithTieBreak(*tieBreak))
+	default:
+		fatal("Invalid tie-break policy", fmt.Errorf("%q is neither %s nor %s", *tieBreak, server.TieBreakRandom, server.TieBreakRunoff))
+	}
+
+	if *vetoShare != 0 {
+		if *vetoShare < 0 || *vetoShare >= 1 {
+			fatal("Invalid veto share", errors.New("the share must be between 0 and 1"))
+		}
+
+		opts = append(opts, server.WithVetoes(*vetoShare))
+	}
+
+	if *oidcIssuer != "" {
+		config := server.OIDCConfig{
+			Issuer:       *oidcIssuer,
+			ClientID:     *oidcClientID,
+			ClientSecret: *oidcClientSecret,
+			RedirectURL:  *oidcRedirectURL,
+		}
+
+		if *oidcEmails != "" {
+			config.Emails = strings.Split(*oidcEmails, ",")
+		}
+
+		oidc, err := server.NewOIDC(context.Background(), config)
+		if err != nil {
+			fatal("Invalid OIDC configuration", err)
+		}
+
+		opts = append(opts, server.WithOIDC(oidc))
+	}
+
+	srv, err := server.NewServer(absStoryFile, absContentDir, staticFS, *presenterSecret, *voterURL, *authorMode, opts...)
+	if err != nil {
+		fatal("Failed to create server", err)
+	}
+
+	if *watch {
+		if err := srv.WatchContent(context.Background()); err != nil {
+			fatal("Failed to watch content", err)
+		}
+	}
+
+	if *dev && *frontendDir != "" {
+		if err := srv.WatchFrontend(context.Background(), *frontendDir); err != nil {
+			fatal("Failed to watch frontend", err)
+		}
+	}
+
+	if *dev && *staticDir != "" {
+		if err := srv.WatchFrontend(context.Background(), *staticDir); err != nil {
+			fatal("Failed to watch static directory", err)
+		}
+	}
+
+	slog.Info("Adventure server starting...",
+		"content", absContentDir,
+		"story", absStoryFile,
+		"static", static,
+		"server", localURL(*addr)+server.CleanBasePath(*basePath),
+		"voter", localURL(*addr)+server.CleanBasePath(*basePath)+"/voter",
+		"presenter", localURL(*addr)+server.CleanBasePath(*basePath)+"/presenter",
+		"presenter_auth", *presenterSecret != "",
+		"oidc", *oidcIssuer,
+		"copresenter_auth", *presenterSecret != "" && *coPresenterSecret != "",
+		"features", *features,
+		"watch", *watch,
+		"dev", *dev,
+		"rehearsal", *rehearsal,
+		"leader_election", *leaderElect,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := srv.Start(ctx, *addr); err != nil {
+		fatal("Server failed", err)
+	}
+}
+
+// tcpAddr returns the first TCP address of the comma-separated listen specs
+// of -addr, or an empty string when it has none.
+func tcpAddr(specs string) string {
+	for spec := range strings.SplitSeq(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec != "" && !strings.HasPrefix(spec, "unix://") && spec != "fd://" {
+			return strings.TrimPrefix(spec, "tcp://")
+		}
+	}
+
+	return ""
+}
+
+// localURL returns the URL the server is reached at on this machine, for the
+// startup log, or the listen specs themselves when none is over TCP.
+func localURL(specs string) string {
+	addr := tcpAddr(specs)
+	if addr == "" {
+		return specs
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+
+	return "http://localhost:" + port
+}
+
+// newLeaderElection sets up leader election for this pod, named after the
+// pod and reachable at its IP unless advertiseURL says otherwise.
+func newLeaderElection(lease, namespace, advertiseURL, addr string) (*server.LeaderElection, error) {
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod name: %w", err)
+	}
+
+	if advertiseURL == "" {
+		podIP := os.Getenv("POD_IP")
+		if podIP == "" {
+			return nil, errors.New("-leader-url or the POD_IP environment variable is required")
+		}
+
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get port from -addr: %w", err)
+		}
+
+		advertiseURL = "http://" + net.JoinHostPort(podIP, port)
+	}
+
+	return server.NewLeaderElection(lease, namespace, identity, advertiseURL)
+}
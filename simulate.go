@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// runSimulate walks the story many times without an audience and reports
+// the path lengths, endings, chapters never visited and errors on the way.
+// It exits non-zero when a walk ran into an error.
+func runSimulate(args []string) {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	contentDir := flags.String("content", "content/chapters", "Path to content directory")
+	storyFile := flags.String("story", "content/story.yaml", "Path to story.yaml file")
+	runs := flags.Int("runs", 1000, "Number of walks through the story")
+	seed := flags.Uint64("seed", 0, "Seed for the random picks, to reproduce a report (0 picks one)")
+	scriptFile := flags.String("script", "", "File with choice IDs to pick at decisions, one per line, in order (optional)")
+	maxSteps := flags.Int("max-steps", 0, "Chapters a walk may visit before it counts as stuck in a loop (default 1000)")
+	features := flags.String("features", "", "Comma-separated list of experimental features to enable (optional)")
+
+	_ = flags.Parse(args)
+
+	engine, err := parser.NewStoryEngine(*storyFile, *contentDir)
+	if err != nil {
+		fatal("Failed to load story", err)
+	}
+
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano()) //nolint:gosec // any seed will do
+	}
+
+	opts := parser.WalkOptions{
+		Runs:     *runs,
+		Seed:     *seed,
+		MaxSteps: *maxSteps,
+		Enabled:  server.ParseFeatures(*features).Enabled,
+	}
+
+	if *scriptFile != "" {
+		file, err := os.Open(*scriptFile)
+		if err != nil {
+			fatal("Failed to open script", err)
+		}
+
+		opts.Script, err = parser.ReadScript(file)
+		_ = file.Close()
+
+		if err != nil {
+			fatal("Failed to read script", err)
+		}
+	}
+
+	report := engine.Walk(opts)
+
+	title := engine.Story.Title
+	if title == "" {
+		title = filepath.Base(filepath.Dir(*storyFile))
+	}
+
+	w := os.Stdout
+
+	fmt.Fprintf(w, "%s: %d walks (seed %d), %d reached an ending\n", title, report.Runs, *seed, report.Completed)
+
+	if report.Completed > 0 {
+		fmt.Fprintf(w, "\nPath length: min %d, mean %.1f, max %d chapters\n", report.MinSteps, report.MeanSteps, report.MaxSteps)
+	}
+
+	fmt.Fprintf(w, "\nEndings reached (%d):\n", len(report.Endings))
+
+	for _, ending := range byCount(report.Endings) {
+		n := report.Endings[ending]
+		fmt.Fprintf(w, "  %-30s %6d  %5.1f%%\n", ending, n, float64(n)*100/float64(report.Runs))
+	}
+
+	if len(report.Unvisited) > 0 {
+		fmt.Fprintf(w, "\nNever visited (%d):\n", len(report.Unvisited))
+
+		for _, id := range report.Unvisited {
+			fmt.Fprintf(w, "  %s\n", id)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(w, "\nErrors (%d):\n", len(report.Errors))
+
+		for _, message := range byCount(report.Errors) {
+			fmt.Fprintf(w, "  %6d× %s\n", report.Errors[message], message)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// byCount returns the keys with the highest counts first, ties by key.
+func byCount(counts map[string]int) []string {
+	keys := slices.Sorted(maps.Keys(counts))
+
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return cmp.Compare(counts[b], counts[a])
+	})
+
+	return keys
+}
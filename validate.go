@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/skarlso/kube_adventures/voting/backend/parser"
+	"github.com/skarlso/kube_adventures/voting/backend/server"
+)
+
+// runValidate checks a story without starting the server: the chapters
+// parse, their frontmatter is understood, every target exists, and every
+// chapter is reachable and leads somewhere. It exits non-zero when the story
+// has problems, for pre-talk checks and CI of content repositories.
+func runValidate(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	contentDir := flags.String("content", "content/chapters", "Path to content directory, or a directory of story bundles")
+	storyFile := flags.String("story", "content/story.yaml", "Path to story.yaml file")
+
+	_ = flags.Parse(args)
+
+	bundles := []server.StoryBundle{{StoryPath: *storyFile, ContentDir: *contentDir}}
+	if found, err := server.DiscoverStories(*contentDir); err == nil && len(found) > 0 {
+		bundles = found
+	}
+
+	problems := 0
+	for _, bundle := range bundles {
+		problems += validateStory(os.Stdout, bundle)
+	}
+
+	if problems > 0 {
+		fmt.Printf("\n%d problem(s) found\n", problems) //nolint:forbidigo // command output
+		os.Exit(1)
+	}
+
+	fmt.Println("\nNo problems found") //nolint:forbidigo // command output
+}
+
+// validateStory writes a report of the problems of one story to w and
+// returns how many it found.
+func validateStory(w io.Writer, bundle server.StoryBundle) int {
+	engine, err := parser.NewStoryEngine(bundle.StoryPath, bundle.ContentDir)
+	if err != nil {
+		fmt.Fprintf(w, "✗ %s\n  %v\n", bundle.StoryPath, err)
+
+		return 1
+	}
+
+	title := engine.Story.Title
+	if title == "" {
+		title = filepath.Base(filepath.Dir(bundle.StoryPath))
+	}
+
+	fmt.Fprintf(w, "%s (%s, %d chapters)\n", title, bundle.StoryPath, len(engine.Story.Nodes))
+
+	errs := engine.ValidateStory()
+	if len(errs) == 0 {
+		fmt.Fprintln(w, "  ✓ ok")
+
+		return 0
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	slices.Sort(messages)
+
+	for _, message := range messages {
+		fmt.Fprintf(w, "  ✗ %s\n", message)
+	}
+
+	return len(errs)
+}